@@ -0,0 +1,175 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxAdminBody 是PUT请求体的最大长度
+const maxAdminBody = 1 << 20
+
+// adminHandler 是Handler返回的HTTP管理接口
+type adminHandler struct {
+	cfg       *Config
+	auth      func(r *http.Request) error
+	writeAuth func(r *http.Request) error
+	actor     func(r *http.Request) string
+	readOnly  bool
+	mux       *http.ServeMux
+}
+
+// HandlerOption 用于定制Handler
+type HandlerOption func(*adminHandler)
+
+// WithAuth 设置所有请求的鉴权函数，返回错误时请求以403拒绝
+func WithAuth(fn func(r *http.Request) error) HandlerOption {
+	return func(h *adminHandler) {
+		h.auth = fn
+	}
+}
+
+// WithWriteAuth 设置PUT请求额外的鉴权函数，在WithAuth之后执行
+func WithWriteAuth(fn func(r *http.Request) error) HandlerOption {
+	return func(h *adminHandler) {
+		h.writeAuth = fn
+	}
+}
+
+// WithActor 设置从请求中取得操作者的函数，PUT写入的值以该操作者记录到审计日志
+func WithActor(fn func(r *http.Request) string) HandlerOption {
+	return func(h *adminHandler) {
+		h.actor = fn
+	}
+}
+
+// WithReadOnly 关闭PUT接口，只允许查看配置
+func WithReadOnly() HandlerOption {
+	return func(h *adminHandler) {
+		h.readOnly = true
+	}
+}
+
+// Handler 返回用于在线查看和修改配置的HTTP管理接口
+// 提供以下路由，响应均为JSON:
+// - GET /config: 所有生效键值
+// - GET /config/{key}: 单个键的值与来源，键不存在时返回404
+// - PUT /config/{key}: 以请求体作为新值写入文件层，成功时返回204
+//
+// 敏感值(MarkSecret标记的键、ENC(...)加密的值，以及名称含password、secret、token等的键)以"****"代替。
+// 接口默认不做鉴权，暴露到内网以外时应通过WithAuth设置鉴权。
+// 示例:
+//
+//	mux.Handle("/config", config.Handler(cfg, config.WithAuth(checkToken)))
+//	mux.Handle("/config/", config.Handler(cfg, config.WithAuth(checkToken)))
+//
+// 参数:
+// - cfg: 要暴露的配置
+// - opts: 鉴权、只读等可选设置
+// 返回:
+// - http.Handler: 管理接口
+func Handler(cfg *Config, opts ...HandlerOption) http.Handler {
+	h := &adminHandler{cfg: cfg, mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.HandleFunc("GET /config", h.getAll)
+	h.mux.HandleFunc("GET /config/{key...}", h.getKey)
+	h.mux.HandleFunc("PUT /config/{key...}", h.putKey)
+	return h
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.auth != nil {
+		if err := h.auth(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *adminHandler) getAll(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.cfg.GetAll())
+}
+
+// keyResponse 是GET /config/{key}的响应
+type keyResponse struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Source   string `json:"source"`
+	Layer    string `json:"layer"`
+	Modified string `json:"modified,omitempty"`
+}
+
+func (h *adminHandler) getKey(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	val, ok := h.cfg.lookup(key)
+	if !ok {
+		http.Error(w, "key not found", http.StatusNotFound)
+		return
+	}
+	if h.cfg.IsSecret(key) {
+		val = maskedValue
+	}
+	resp := keyResponse{Key: key, Value: val}
+	if src, ok := h.cfg.Source(key); ok {
+		resp.Source = src.String()
+		resp.Layer = src.Layer.String()
+		if !src.Modified.IsZero() {
+			resp.Modified = src.Modified.Format(time.RFC3339Nano)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *adminHandler) putKey(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		http.Error(w, "config is read-only", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.writeAuth != nil {
+		if err := h.writeAuth(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAdminBody+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxAdminBody {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	value := strings.TrimRight(string(body), "\r\n")
+	var actor string
+	if h.actor != nil {
+		actor = h.actor(r)
+	}
+	err = h.cfg.UpdateAs(actor, func(tx *Txn) error {
+		return tx.Set(r.PathValue("key"), value)
+	})
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrFrozen) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON 以JSON写出响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// adminDo 向h发送请求，返回响应
+func adminDo(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.LoadFromReader(strings.NewReader("port = 80\ndb.password = hunter2\n"), WithFileFormat("properties"))
+	var events []AuditEvent
+	cfg.SetAuditHook(func(e AuditEvent) { events = append(events, e) })
+	h := Handler(cfg, WithActor(func(r *http.Request) string { return r.Header.Get("X-User") }))
+
+	rec := adminDo(h, "GET", "/config", "")
+	var all map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET /config = %d %s", rec.Code, rec.Body)
+	}
+	if all["port"] != "80" || all["db.password"] != "****" {
+		t.Errorf("GET /config = %v, want the password masked", all)
+	}
+
+	rec = adminDo(h, "GET", "/config/port", "")
+	var resp keyResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Key != "port" || resp.Value != "80" || resp.Layer != LayerFile.String() || resp.Modified == "" {
+		t.Errorf("GET /config/port = %+v", resp)
+	}
+	rec = adminDo(h, "GET", "/config/db.password", "")
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Value != "****" {
+		t.Errorf("GET /config/db.password value = %q, want it masked", resp.Value)
+	}
+	if rec := adminDo(h, "GET", "/config/missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /config/missing = %d, want 404", rec.Code)
+	}
+
+	req := httptest.NewRequest("PUT", "/config/port", strings.NewReader("9090\n"))
+	req.Header.Set("X-User", "alice")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("PUT /config/port = %d %s", rec.Code, rec.Body)
+	}
+	if got := cfg.Get("port"); got != "9090" {
+		t.Errorf("port after PUT = %q, want 9090 without the trailing newline", got)
+	}
+	if len(events) != 1 || events[0].Actor != "alice" || events[0].Key != "port" {
+		t.Errorf("audit events = %+v, want the PUT recorded as alice", events)
+	}
+	if rec := adminDo(h, "PUT", "/config/big", strings.Repeat("x", maxAdminBody+1)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("PUT with a large body = %d, want 413", rec.Code)
+	}
+	if rec := adminDo(h, "DELETE", "/config/port", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE /config/port = %d, want 405", rec.Code)
+	}
+
+	cfg.Freeze()
+	if rec := adminDo(h, "PUT", "/config/port", "1"); rec.Code != http.StatusConflict {
+		t.Errorf("PUT on a frozen config = %d, want 409", rec.Code)
+	}
+}
+
+func TestHandlerAuth(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("port", "80")
+	token := func(want string) func(r *http.Request) error {
+		return func(r *http.Request) error {
+			if r.Header.Get("Authorization") != want {
+				return errors.New("forbidden")
+			}
+			return nil
+		}
+	}
+	h := Handler(cfg, WithAuth(token("Bearer read")), WithWriteAuth(func(r *http.Request) error {
+		if r.Header.Get("X-Write") == "" {
+			return errors.New("write forbidden")
+		}
+		return nil
+	}))
+	send := func(method, path string, headers map[string]string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader("1"))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := send("GET", "/config", nil); code != http.StatusForbidden {
+		t.Errorf("GET without a token = %d, want 403", code)
+	}
+	if code := send("GET", "/config", map[string]string{"Authorization": "Bearer read"}); code != http.StatusOK {
+		t.Errorf("GET with a token = %d, want 200", code)
+	}
+	if code := send("PUT", "/config/port", map[string]string{"Authorization": "Bearer read"}); code != http.StatusForbidden {
+		t.Errorf("PUT without write access = %d, want 403", code)
+	}
+	if code := send("PUT", "/config/port", map[string]string{"Authorization": "Bearer read", "X-Write": "1"}); code != http.StatusNoContent {
+		t.Errorf("PUT with write access = %d, want 204", code)
+	}
+
+	ro := Handler(cfg, WithReadOnly())
+	if rec := adminDo(ro, "PUT", "/config/port", "2"); rec.Code != http.StatusMethodNotAllowed || cfg.Get("port") != "1" {
+		t.Errorf("PUT on a read-only handler = %d, port = %q", rec.Code, cfg.Get("port"))
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"sync"
+)
+
+// aliasTable 保存Alias与Deprecate登记的信息
+// 其中的map都按写时复制方式修改，因此可以随只读视图发布给无锁的读取者
+type aliasTable struct {
+	aliases    map[string]string   // 旧键到新键
+	aliasedBy  map[string][]string // 新键到指向它的旧键，按登记顺序
+	deprecated map[string]string   // 已弃用的键到说明
+	hook       func(key, message string)
+	warned     *sync.Map // 已发出过警告的键
+}
+
+// Alias 把旧键登记为新键的别名，之后读取旧键得到新键的值
+// 新键不存在而旧键仍存储着值时(例如尚未迁移的配置文件)，读取新键或旧键都得到旧键的值，
+// 因此代码与配置文件可以分别迁移到新名称。Set与Delete传入旧键时作用于新键；
+// Delete同时删除以旧键存储的值，使键真正消失。
+// 新键本身是别名时旧键直接指向最终的键
+// 参数:
+// - oldKey: 旧的键名
+// - newKey: 新的键名
+// 返回:
+// - error: 键为空或形成循环时返回错误
+func (c *Config) Alias(oldKey, newKey string) error {
+	if oldKey == "" || newKey == "" {
+		return errors.New("key cannot be empty")
+	}
+	oldKey, newKey = c.normKey(oldKey), c.normKey(newKey)
+	c.lock()
+	defer c.mutex.Unlock()
+	if target, ok := c.alias.aliases[newKey]; ok {
+		newKey = target
+	}
+	if oldKey == newKey {
+		return fmt.Errorf("alias %q: key cannot be an alias of itself", oldKey)
+	}
+	aliases := make(map[string]string, len(c.alias.aliases)+1)
+	for k, v := range c.alias.aliases {
+		if v == oldKey {
+			// 指向旧键的别名改为指向新键
+			v = newKey
+		}
+		aliases[k] = v
+	}
+	aliases[oldKey] = newKey
+	aliasedBy := make(map[string][]string, len(aliases))
+	for _, k := range c.aliasOrder(aliases, oldKey) {
+		aliasedBy[aliases[k]] = append(aliasedBy[aliases[k]], k)
+	}
+	c.alias.aliases = aliases
+	c.alias.aliasedBy = aliasedBy
+	return nil
+}
+
+// aliasOrder 返回aliases中的旧键，已有的按原登记顺序在前，added在最后
+func (c *Config) aliasOrder(aliases map[string]string, added string) []string {
+	order := make([]string, 0, len(aliases))
+	for _, olds := range c.alias.aliasedBy {
+		for _, k := range olds {
+			if k != added {
+				order = append(order, k)
+			}
+		}
+	}
+	return append(order, added)
+}
+
+// Deprecate 把键标记为已弃用，之后通过Get等方法读取该键时发出一次警告
+// 警告通过OnDeprecated设置的回调发出，未设置时写入SetLogger设置的日志或标准库log。
+// 通常与Alias配合使用，让旧名称继续可用的同时提示调用方迁移：
+//
+//	cfg.Alias("db.host", "database.host")
+//	cfg.Deprecate("db.host", "use database.host")
+//
+// 参数:
+// - key: 已弃用的键
+// - message: 附加在警告中的说明，例如替代的键名
+func (c *Config) Deprecate(key, message string) {
+	key = c.normKey(key)
+	c.lock()
+	defer c.mutex.Unlock()
+	deprecated := make(map[string]string, len(c.alias.deprecated)+1)
+	for k, v := range c.alias.deprecated {
+		deprecated[k] = v
+	}
+	deprecated[key] = message
+	c.alias.deprecated = deprecated
+	if c.alias.warned == nil {
+		c.alias.warned = &sync.Map{}
+	}
+}
+
+// OnDeprecated 设置读取已弃用的键时调用的回调，替代默认写入日志的警告
+// 每个键只在第一次被读取时调用一次；fn在读取的goroutine中调用，不持有锁
+// 参数:
+// - fn: 接收键与Deprecate登记的说明，为nil时恢复默认行为
+func (c *Config) OnDeprecated(fn func(key, message string)) {
+	c.lock()
+	defer c.mutex.Unlock()
+	c.alias.hook = fn
+}
+
+// currentAliases 返回当前的别名登记，供不持有锁的写入方法解析键
+// 只读视图已发布时直接使用视图中的登记，否则在读锁下读取，避免为解析一个键而重建整个视图
+func (c *Config) currentAliases() *aliasTable {
+	if v := c.view.Load(); v != nil {
+		return &v.alias
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	t := c.alias
+	return &t
+}
+
+// canonicalKey 返回key作为别名时指向的新键，不是别名时原样返回
+func (t *aliasTable) canonicalKey(key string) string {
+	if target, ok := t.aliases[key]; ok {
+		return target
+	}
+	return key
+}
+
+// warnDeprecated 在key已弃用且尚未警告过时发出警告
+// 依次交给OnDeprecated的回调、SetLogger设置的日志，都未设置时写入标准库log
+func (t *aliasTable) warnDeprecated(key string, logger *slog.Logger) {
+	message, ok := t.deprecated[key]
+	if !ok {
+		return
+	}
+	if _, loaded := t.warned.LoadOrStore(key, true); loaded {
+		return
+	}
+	if t.hook != nil {
+		t.hook(key, message)
+		return
+	}
+	if logger != nil {
+		logger.Warn("config key is deprecated", "key", key, "message", message)
+		return
+	}
+	log.Printf("config: key %q is deprecated: %s", key, message)
+}
+
+// resolveAliasLocked 按别名解析键：旧键解析为新键，新键不存在时回退到以旧键存储的值
+// 调用方须持有锁
+func (c *Config) resolveAliasLocked(key string) (resolvedEntry, bool) {
+	key = c.alias.canonicalKey(key)
+	if r, ok := c.resolveNameLocked(key); ok {
+		return r, true
+	}
+	for _, old := range c.alias.aliasedBy[key] {
+		if r, ok := c.resolveNameLocked(old); ok {
+			return r, true
+		}
+	}
+	return resolvedEntry{}, false
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// defaultFileMode 是新建配置文件的默认权限
+const defaultFileMode fs.FileMode = 0644
+
+// saveSettings 保存SaveToFile等方法的可选设置
+type saveSettings struct {
+	mode          fs.FileMode
+	modeSet       bool
+	preserveOwner bool
+	sorted        bool               // 忽略记录的文件结构，按键名排序写出
+	grouped       bool               // 排序写出时在首段前缀不同的键之间插入空行
+	separator     string             // WithSeparator指定的分隔符写法，为空时沿用加载时的写法
+	backups       int                // WithBackups指定的备份数
+	signingKey    ed25519.PrivateKey // WithSignature指定的签名私钥
+}
+
+// SaveOption 用于定制SaveToFile等保存方法的行为
+type SaveOption func(*saveSettings)
+
+// WithFileMode 指定保存后文件的权限，覆盖保留原文件权限的默认行为
+func WithFileMode(mode fs.FileMode) SaveOption {
+	return func(s *saveSettings) {
+		s.mode = mode.Perm()
+		s.modeSet = true
+	}
+}
+
+// WithPreserveOwner 保存时保留原文件的属主和属组
+// 仅在Unix系统上生效，且通常需要相应权限；原文件不存在时忽略
+func WithPreserveOwner() SaveOption {
+	return func(s *saveSettings) {
+		s.preserveOwner = true
+	}
+}
+
+// WithSortedKeys 让SaveToFile忽略加载时记录的注释与键顺序，按键名的字典序写出完整的点分键
+// 适用于纳入版本管理的配置文件：输出只取决于键值本身，与加载与修改的顺序无关。
+// grouped为true时键按首段前缀分组(不含"."的键在最前)，组之间以空行分隔，例如：
+//
+//	name = app
+//
+//	server.host = 0.0.0.0
+//	server.port = 8080
+//
+// 未加载过文件时SaveToFile本来就按[section]段落排序写出，不需要此选项
+func WithSortedKeys(grouped bool) SaveOption {
+	return func(s *saveSettings) {
+		s.sorted = true
+		s.grouped = grouped
+	}
+}
+
+// 键值之间的分隔符写法，用于WithSeparator
+const (
+	SeparatorEquals       = "="   // key=value
+	SeparatorSpacedEquals = " = " // key = value，未加载过文件时的默认写法
+	SeparatorColon        = ": "  // key: value
+)
+
+// WithSeparator 指定SaveToFile写出键值行时使用的分隔符写法，如SeparatorEquals
+// 默认沿用加载时文件中最常用的写法：已有的行保持原样，新写入的键采用该写法；
+// 指定后所有键值行都改用sep。sep去掉两侧空白后必须是"="或":"，否则保存失败
+func WithSeparator(sep string) SaveOption {
+	return func(s *saveSettings) {
+		s.separator = sep
+	}
+}
+
+// newSaveSettings 应用opts得到保存设置
+func newSaveSettings(opts []SaveOption) saveSettings {
+	settings := saveSettings{mode: defaultFileMode}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	return settings
+}
+
+// writeFileAtomic 原子地写入文件：先写入同目录下的临时文件并fsync，再重命名覆盖目标
+// 写入过程中崩溃不会破坏原文件。目标为符号链接时写入其指向的文件。
+// 默认保留原文件权限，新文件使用defaultFileMode。
+// 文件名带有RegisterCompression注册的扩展名(如".gz")时压缩后写出。
+func writeFileAtomic(filename string, write func(w io.Writer) error, opts ...SaveOption) (err error) {
+	settings := newSaveSettings(opts)
+
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = resolved
+	}
+	existing, statErr := os.Stat(filename)
+	if statErr == nil && !settings.modeSet {
+		settings.mode = existing.Mode().Perm()
+	}
+
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	writer := bufio.NewWriter(tmp)
+	var signed bytes.Buffer
+	var dst io.Writer = writer
+	if settings.signingKey != nil {
+		dst = io.MultiWriter(writer, &signed)
+	}
+	if comp, ok := compressionFor(filename); ok {
+		if err = writeCompressed(dst, comp, write); err != nil {
+			return err
+		}
+	} else if err = write(dst); err != nil {
+		return err
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(settings.mode); err != nil {
+		return err
+	}
+	if settings.preserveOwner && statErr == nil {
+		if err = chownLike(tmp, existing); err != nil {
+			return err
+		}
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if settings.backups > 0 && statErr == nil {
+		if err = rotateBackups(filename, settings.backups); err != nil {
+			return fmt.Errorf("backup %s: %w", filename, err)
+		}
+	}
+	if err = os.Rename(tmpName, filename); err != nil {
+		return err
+	}
+	syncDir(dir)
+	if settings.signingKey != nil {
+		return writeSignature(filename, settings.signingKey, signed.Bytes(), settings.mode)
+	}
+	return nil
+}
+
+// writeCompressed 以comp压缩write写出的内容后写入w
+func writeCompressed(w io.Writer, comp Compression, write func(w io.Writer) error) error {
+	cw, err := comp.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if err := write(cw); err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+// writeBytesAtomic 原子地写入字节内容
+func writeBytesAtomic(filename string, content []byte, opts ...SaveOption) error {
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	}, opts...)
+}
+
+// syncDir 尽力fsync目录以持久化重命名，部分平台不支持时忽略错误
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package config
+
+import (
+	"io/fs"
+	"os"
+)
+
+// chownLike 在非Unix系统上不做任何处理
+func chownLike(f *os.File, existing fs.FileInfo) error {
+	return nil
+}
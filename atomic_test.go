@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	if err := os.WriteFile(path, []byte("old = 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// 写入中途失败时原文件不变，临时文件被删除
+	errWrite := errors.New("write failed")
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errWrite
+	})
+	if !errors.Is(err, errWrite) {
+		t.Fatalf("writeFileAtomic error = %v, want the write error", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old = 1\n" {
+		t.Errorf("file after a failed write = %q, want the original", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory has %d entries after a failed write, want only the original", len(entries))
+	}
+
+	// 默认保留原文件权限
+	cfg, _ := NewConfig()
+	cfg.Set("new", "2")
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode after save = %v, want the original 0600", info.Mode().Perm())
+	}
+	if err := cfg.SaveToFile(path, WithFileMode(0o640)); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o640 {
+		t.Errorf("mode with WithFileMode = %v, want 0640", info.Mode().Perm())
+	}
+
+	fresh := filepath.Join(dir, "fresh.conf")
+	if err := cfg.SaveToFile(fresh); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(fresh); info.Mode().Perm() != defaultFileMode {
+		t.Errorf("mode of a new file = %v, want %v", info.Mode().Perm(), defaultFileMode)
+	}
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.conf")
+	link := filepath.Join(dir, "app.conf")
+	os.WriteFile(target, []byte("a = 1\n"), 0o644)
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	cfg, _ := NewConfig()
+	cfg.Set("a", "2")
+	if err := cfg.SaveToFile(link); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("save replaced the symlink: %v", err)
+	}
+	loaded, _ := NewConfig()
+	if err := loaded.LoadFromFile(target); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Get("a"); got != "2" {
+		t.Errorf("symlink target a = %q, want 2", got)
+	}
+}
+
+func TestSaveSortedKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	os.WriteFile(path, []byte("# settings\nserver.port = 8080\nname = app\n[db]\nhost = localhost\n"), 0o644)
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("server.host", "0.0.0.0")
+	cfg.Set("debug", "true")
+
+	if err := cfg.SaveToFile(path, WithSortedKeys(false)); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "db.host = localhost\ndebug = true\nname = app\nserver.host = 0.0.0.0\nserver.port = 8080\n"
+	if string(data) != want {
+		t.Errorf("sorted output =\n%s\nwant\n%s", data, want)
+	}
+
+	if err := cfg.SaveToFile(path, WithSortedKeys(true)); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	want = "debug = true\nname = app\n\ndb.host = localhost\n\nserver.host = 0.0.0.0\nserver.port = 8080\n"
+	if string(data) != want {
+		t.Errorf("grouped output =\n%s\nwant\n%s", data, want)
+	}
+
+	// 输出与写入顺序无关
+	other, _ := NewConfig()
+	for _, key := range []string{"server.port", "debug", "server.host", "db.host", "name"} {
+		other.Set(key, cfg.Get(key))
+	}
+	otherPath := filepath.Join(dir, "other.conf")
+	if err := other.SaveToFile(otherPath, WithSortedKeys(true)); err != nil {
+		t.Fatal(err)
+	}
+	if otherData, _ := os.ReadFile(otherPath); string(otherData) != want {
+		t.Errorf("output for another insertion order =\n%s\nwant\n%s", otherData, want)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package config
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// chownLike 将文件的属主和属组设置为与existing相同
+func chownLike(f *os.File, existing fs.FileInfo) error {
+	st, ok := existing.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return f.Chown(int(st.Uid), int(st.Gid))
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditEvent 是审计日志中的一条记录，对应一个键生效值的一次变化
+type AuditEvent struct {
+	// Time 是变化发生的时间
+	Time time.Time `json:"time"`
+	// Actor 是UpdateAs提供的操作者，其它方法引起的变化为空
+	Actor string `json:"actor,omitempty"`
+	// Op 是操作类型："set"表示通过API写入，"load"表示从文件、环境变量、命令行参数或配置源加载，
+	// "delete"表示键被删除
+	Op string `json:"op"`
+	// Key 是配置键
+	Key string `json:"key"`
+	// Old 是变化前的值，新增的键为空
+	Old string `json:"old,omitempty"`
+	// New 是变化后的值，删除的键为空
+	New string `json:"new,omitempty"`
+	// Source 是变化后的值的来源，删除的键为SourceAPI
+	Source KeySource `json:"-"`
+}
+
+// SetAuditHook 设置审计回调，之后每个键生效值的变化都会生成一条AuditEvent
+// 记录的范围与Subscribe相同：Set、Delete、文件加载、各层写入、热加载、过期等引起的生效值变化；
+// 被更高优先级层遮蔽、生效值未变化的写入不产生记录。
+// 敏感键(判断规则见IsSecret)的值以"****"代替；ENC(...)值记录密文。
+// 回调在触发变化的goroutine中、配置锁之外同步执行，在订阅者回调之前调用。
+// 参数:
+// - fn: 审计回调，为nil时关闭审计；可以使用AuditLog写入io.Writer
+func (c *Config) SetAuditHook(fn func(AuditEvent)) {
+	c.lock()
+	defer c.mutex.Unlock()
+	c.audit = fn
+}
+
+// AuditLog 返回把审计记录以每行一个JSON对象的形式写入w的回调，可直接传给SetAuditHook
+// 并发写入通过内部锁串行化，写入错误被忽略
+// 示例:
+//
+//	f, _ := os.OpenFile("config-audit.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+//	cfg.SetAuditHook(config.AuditLog(f))
+//
+// 参数:
+// - w: 审计日志输出
+// 返回:
+// - func(AuditEvent): 审计回调
+func AuditLog(w io.Writer) func(AuditEvent) {
+	var mutex sync.Mutex
+	return func(e AuditEvent) {
+		record := struct {
+			AuditEvent
+			Source string `json:"source,omitempty"`
+		}{AuditEvent: e}
+		if e.Op != "delete" {
+			record.Source = e.Source.String()
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		w.Write(append(line, '\n'))
+	}
+}
+
+// auditEventsLocked 为changes生成审计记录，调用方须持有锁
+// 变化之后若键又被其它写入修改，无法确定来源，Source保持零值
+func (c *Config) auditEventsLocked(changes []change) []AuditEvent {
+	now := time.Now()
+	events := make([]AuditEvent, 0, len(changes))
+	for _, ch := range changes {
+		e := AuditEvent{Time: now, Actor: ch.actor, Key: ch.key, Old: ch.old, New: ch.new}
+		switch {
+		case !ch.newOK:
+			e.Op = "delete"
+		default:
+			if cur, ok := c.resolveLocked(ch.key); ok && cur == ch.new {
+				e.Source, _ = c.sourceLocked(ch.key)
+			}
+			e.Op = "set"
+			if e.Source.Kind != SourceAPI {
+				e.Op = "load"
+			}
+		}
+		if c.isSecretLocked(ch.key) {
+			if ch.oldOK {
+				e.Old = maskedValue
+			}
+			if ch.newOK {
+				e.New = maskedValue
+			}
+		}
+		events = append(events, e)
+	}
+	return events
+}
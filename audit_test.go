@@ -0,0 +1,82 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditHook(t *testing.T) {
+	cfg, _ := NewConfig()
+	var events []AuditEvent
+	cfg.SetAuditHook(func(e AuditEvent) { events = append(events, e) })
+
+	path := filepath.Join(t.TempDir(), "app.ini")
+	os.WriteFile(path, []byte("port = 80\n"), 0o644)
+	cfg.LoadFromFile(path)
+	cfg.Set("port", "9090")
+	cfg.Set("db.password", "hunter2")
+	cfg.UpdateAs("alice", func(tx *Txn) error { return tx.Set("name", "app") })
+	cfg.Delete("port")
+	// 被覆盖层遮蔽的写入不改变生效值，不产生记录
+	cfg.SetOverride("name", "top")
+	cfg.SetDefault("name", "hidden")
+
+	type rec struct{ op, key, old, new, actor string }
+	want := []rec{
+		{"load", "port", "", "80", ""},
+		{"set", "port", "80", "9090", ""},
+		{"set", "db.password", "", "****", ""},
+		{"set", "name", "", "app", "alice"},
+		{"delete", "port", "9090", "", ""},
+		{"set", "name", "app", "top", ""},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("audit events = %+v, want %d", events, len(want))
+	}
+	for i, w := range want {
+		e := events[i]
+		if got := (rec{e.Op, e.Key, e.Old, e.New, e.Actor}); got != w {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("event %d has no time", i)
+		}
+	}
+	if events[0].Source.Kind != SourceFile || events[0].Source.Name != path {
+		t.Errorf("load event source = %+v, want the file", events[0].Source)
+	}
+
+	cfg.SetAuditHook(nil)
+	cfg.Set("after", "1")
+	if len(events) != len(want) {
+		t.Error("audit hook called after it was removed")
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	cfg, _ := NewConfig()
+	cfg.SetAuditHook(AuditLog(&buf))
+	path := filepath.Join(t.TempDir(), "app.ini")
+	os.WriteFile(path, []byte("port = 80\n"), 0o644)
+	cfg.LoadFromFile(path)
+	cfg.Delete("port")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log =\n%s", buf.String())
+	}
+	var first, second map[string]any
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[1]), &second)
+	if first["op"] != "load" || first["new"] != "80" || first["source"] != path+":1" {
+		t.Errorf("first record = %v, want the load with its source", first)
+	}
+	if second["op"] != "delete" || second["old"] != "80" || second["source"] != nil || second["new"] != nil {
+		t.Errorf("second record = %v, want the delete without a source", second)
+	}
+}
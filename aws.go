@@ -0,0 +1,413 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSCredentials 是访问AWS API使用的凭证
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken 是临时凭证的会话令牌，长期凭证为空
+	SessionToken string
+}
+
+// awsClient 通过JSON 1.1协议与SigV4签名调用AWS API，不依赖AWS SDK
+// 凭证为nil时读取AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY与AWS_SESSION_TOKEN环境变量，
+// 区域为空时读取AWS_REGION或AWS_DEFAULT_REGION；不支持共享配置文件与实例元数据凭证
+type awsClient struct {
+	service     string
+	region      string
+	endpoint    string
+	credentials *AWSCredentials
+	client      *http.Client
+}
+
+// call 调用target操作，body编码为请求JSON，响应解码到resp
+func (a *awsClient) call(ctx context.Context, target string, body, resp interface{}) error {
+	region := a.region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return fmt.Errorf("%s: no region configured", a.service)
+	}
+	creds := a.credentials
+	if creds == nil {
+		creds = &AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return fmt.Errorf("%s: no credentials configured", a.service)
+	}
+
+	endpoint := a.endpoint
+	if endpoint == "" {
+		endpoint = "https://" + a.service + "." + region + ".amazonaws.com"
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	signAWSRequest(req, payload, a.service, region, creds, time.Now().UTC())
+
+	client := a.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", a.service, err)
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", a.service, err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		var awsErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+			Upper   string `json:"Message"`
+		}
+		if json.Unmarshal(data, &awsErr) == nil && awsErr.Type != "" {
+			msg := awsErr.Message
+			if msg == "" {
+				msg = awsErr.Upper
+			}
+			// __type可能带有"namespace#"前缀
+			return fmt.Errorf("%s: %s: %s", a.service, awsErr.Type[strings.LastIndexByte(awsErr.Type, '#')+1:], msg)
+		}
+		return fmt.Errorf("%s: %s: %s", a.service, httpResp.Status, bytes.TrimSpace(data))
+	}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("%s: decode response: %w", a.service, err)
+	}
+	return nil
+}
+
+// signAWSRequest 按Signature Version 4为请求添加X-Amz-Date与Authorization请求头
+func signAWSRequest(req *http.Request, payload []byte, service, region string, creds *AWSCredentials, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// SSMProvider 读取AWS Systems Manager Parameter Store中指定路径下的参数
+// 参数名去掉Path后，其中的"/"替换为Delimiter，例如路径"/myapp/"下的"/myapp/db/host"对应键"db.host"；
+// SecureString参数自动解密，StringList参数保持逗号分隔的原始值。Watch按RefreshInterval定期重新读取。
+type SSMProvider struct {
+	// Region 是AWS区域，为空时读取AWS_REGION或AWS_DEFAULT_REGION
+	Region string
+	// Path 是参数路径，递归读取其下的所有参数
+	Path string
+	// Delimiter 替换参数名中的"/"，为空时保留"/"
+	Delimiter string
+	// Credentials 为nil时从环境变量读取凭证
+	Credentials *AWSCredentials
+	// Endpoint 覆盖服务地址，如VPC终端节点或本地模拟服务
+	Endpoint string
+	// Client 用于发送请求，为nil时使用http.DefaultClient
+	Client *http.Client
+	// RefreshInterval 是Watch重新读取的间隔
+	RefreshInterval time.Duration
+	// RetryInterval 是请求失败后的重试间隔
+	RetryInterval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewSSMProvider 创建读取path下参数的Parameter Store配置源
+// 参数:
+// - region: AWS区域，为空时读取环境变量
+// - path: 参数路径，如"/myapp/prod/"
+// 返回:
+// - *SSMProvider: 配置源实例
+func NewSSMProvider(region, path string) *SSMProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SSMProvider{
+		Region:          region,
+		Path:            path,
+		Delimiter:       ".",
+		RefreshInterval: time.Minute,
+		RetryInterval:   5 * time.Second,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *SSMProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// Load 分页读取路径下的全部参数
+func (p *SSMProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时中止请求
+func (p *SSMProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	client := &awsClient{service: "ssm", region: p.Region, endpoint: p.Endpoint, credentials: p.Credentials, client: p.Client}
+	path := "/" + strings.Trim(p.Path, "/")
+	values := make(map[string]string)
+	next := ""
+	for {
+		req := map[string]interface{}{
+			"Path":           path,
+			"Recursive":      true,
+			"WithDecryption": true,
+			"MaxResults":     10,
+		}
+		if next != "" {
+			req["NextToken"] = next
+		}
+		var resp struct {
+			Parameters []struct {
+				Name  string
+				Value string
+			}
+			NextToken string
+		}
+		if err := client.call(ctx, "AmazonSSM.GetParametersByPath", req, &resp); err != nil {
+			return nil, err
+		}
+		for _, param := range resp.Parameters {
+			if key := remoteKey(param.Name, path, p.Delimiter); key != "" {
+				values[key] = param.Value
+			}
+		}
+		if resp.NextToken == "" {
+			return values, nil
+		}
+		next = resp.NextToken
+	}
+}
+
+// Watch 按RefreshInterval重新读取，数据变化时发送完整数据
+func (p *SSMProvider) Watch(ch chan<- Update) error {
+	return pollProvider(p.ctx, p.Load, p.RefreshInterval, p.RetryInterval, ch)
+}
+
+// SecretsManagerProvider 读取AWS Secrets Manager中的机密并放到指定的键前缀下
+// SecretString是JSON对象时按JSON文件的规则展开到前缀下，例如{"password":"x"}对应键"前缀.password"；
+// 否则整个字符串(或解码后的SecretBinary)作为前缀键本身的值。Watch按RefreshInterval定期重新读取，
+// 机密轮换后自动更新。读取的值通常是敏感信息，建议对相应前缀调用MarkSecret。
+type SecretsManagerProvider struct {
+	// Region 是AWS区域，为空时读取AWS_REGION或AWS_DEFAULT_REGION
+	Region string
+	// Secrets 把机密名称或ARN映射为键前缀
+	Secrets map[string]string
+	// Credentials 为nil时从环境变量读取凭证
+	Credentials *AWSCredentials
+	// Endpoint 覆盖服务地址，如VPC终端节点或本地模拟服务
+	Endpoint string
+	// Client 用于发送请求，为nil时使用http.DefaultClient
+	Client *http.Client
+	// RefreshInterval 是Watch重新读取的间隔
+	RefreshInterval time.Duration
+	// RetryInterval 是请求失败后的重试间隔
+	RetryInterval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewSecretsManagerProvider 创建读取secrets中各机密的Secrets Manager配置源
+// 参数:
+// - region: AWS区域，为空时读取环境变量
+// - secrets: 机密名称或ARN到键前缀的映射
+// 返回:
+// - *SecretsManagerProvider: 配置源实例
+func NewSecretsManagerProvider(region string, secrets map[string]string) *SecretsManagerProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SecretsManagerProvider{
+		Region:          region,
+		Secrets:         secrets,
+		RefreshInterval: 5 * time.Minute,
+		RetryInterval:   5 * time.Second,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *SecretsManagerProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// Load 读取全部机密的当前版本
+func (p *SecretsManagerProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时中止请求
+func (p *SecretsManagerProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	client := &awsClient{service: "secretsmanager", region: p.Region, endpoint: p.Endpoint, credentials: p.Credentials, client: p.Client}
+	ids := make([]string, 0, len(p.Secrets))
+	for id := range p.Secrets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	values := make(map[string]string)
+	for _, id := range ids {
+		var resp struct {
+			SecretString *string
+			SecretBinary string
+		}
+		if err := client.call(ctx, "secretsmanager.GetSecretValue", map[string]string{"SecretId": id}, &resp); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		prefix := p.Secrets[id]
+		var raw string
+		if resp.SecretString != nil {
+			raw = *resp.SecretString
+			dec := json.NewDecoder(strings.NewReader(raw))
+			dec.UseNumber()
+			var doc map[string]interface{}
+			if dec.Decode(&doc) == nil {
+				flattenValue(prefix, doc, values)
+				continue
+			}
+		} else {
+			data, err := base64.StdEncoding.DecodeString(resp.SecretBinary)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid SecretBinary: %w", id, err)
+			}
+			raw = string(data)
+		}
+		if prefix == "" {
+			return nil, fmt.Errorf("%s: secret is not a JSON object and needs a key prefix", id)
+		}
+		values[prefix] = raw
+	}
+	return values, nil
+}
+
+// Watch 按RefreshInterval重新读取，数据变化时发送完整数据
+func (p *SecretsManagerProvider) Watch(ch chan<- Update) error {
+	return pollProvider(p.ctx, p.Load, p.RefreshInterval, p.RetryInterval, ch)
+}
+
+// pollProvider 按interval调用load直到ctx结束，数据变化时发送完整数据，失败时按retry重试
+func pollProvider(ctx context.Context, load func() (map[string]string, error), interval, retry time.Duration, ch chan<- Update) error {
+	current, err := load()
+	if err != nil {
+		return err
+	}
+	wait := interval
+	for {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ErrProviderClosed
+		}
+		next, err := load()
+		if ctx.Err() != nil {
+			return ErrProviderClosed
+		}
+		if err != nil {
+			select {
+			case ch <- Update{Err: err}:
+			case <-ctx.Done():
+				return ErrProviderClosed
+			}
+			wait = retry
+			continue
+		}
+		wait = interval
+		if maps.Equal(next, current) {
+			continue
+		}
+		current = next
+		select {
+		case ch <- Update{Values: maps.Clone(next)}:
+		case <-ctx.Done():
+			return ErrProviderClosed
+		}
+	}
+}
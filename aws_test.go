@@ -0,0 +1,180 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 签名向量取自AWS发布的Signature Version 4测试套件(aws-sig-v4-test-suite)
+func TestSignAWSRequestTestSuite(t *testing.T) {
+	creds := &AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		headers       map[string]string
+		body          string
+		signedHeaders string
+		signature     string
+	}{
+		{
+			name: "get-vanilla", method: "GET", url: "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "post-vanilla", method: "POST", url: "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name: "get-vanilla-query-order-key-case", method: "GET", url: "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signedHeaders: "host;x-amz-date",
+			signature:     "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name: "post-header-key-sort", method: "POST", url: "https://example.amazonaws.com/",
+			headers:       map[string]string{"My-Header1": "value1"},
+			signedHeaders: "host;my-header1;x-amz-date",
+			signature:     "c5410059b04c1ee005303aed430f6e6645f61f4dc9e1461ec8f8916fdf18852c",
+		},
+		{
+			name: "post-x-www-form-urlencoded", method: "POST", url: "https://example.amazonaws.com/",
+			headers:       map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:          "Param1=value1",
+			signedHeaders: "content-type;host;x-amz-date",
+			signature:     "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			signAWSRequest(req, []byte(tt.body), "service", "us-east-1", creds, now)
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=" +
+				tt.signedHeaders + ", Signature=" + tt.signature
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestSignAWSRequestSessionToken(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://example.amazonaws.com/", nil)
+	creds := &AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session-token"}
+	signAWSRequest(req, nil, "service", "us-east-1", creds, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "session-token" {
+		t.Errorf("X-Amz-Security-Token = %q, want the session token", got)
+	}
+	if auth := req.Header.Get("Authorization"); !strings.Contains(auth, "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Authorization = %q, want the session token signed", auth)
+	}
+}
+
+// fakeAWS 以JSON 1.1协议回答请求，按X-Amz-Target分派到handle
+func fakeAWS(t *testing.T, handle func(target string, req map[string]interface{}) (int, interface{})) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Errorf("request without a SigV4 Authorization header: %q", r.Header.Get("Authorization"))
+		}
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		status, resp := handle(r.Header.Get("X-Amz-Target"), req)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestSSMProviderLoad(t *testing.T) {
+	endpoint := fakeAWS(t, func(target string, req map[string]interface{}) (int, interface{}) {
+		if target != "AmazonSSM.GetParametersByPath" || req["Path"] != "/myapp/prod" || req["WithDecryption"] != true {
+			return http.StatusBadRequest, map[string]string{"__type": "ValidationException", "message": "unexpected request"}
+		}
+		// 两页结果，第二页由NextToken取得
+		if req["NextToken"] == nil {
+			return http.StatusOK, map[string]interface{}{
+				"Parameters": []map[string]string{{"Name": "/myapp/prod/db/host", "Value": "db1"}},
+				"NextToken":  "page2",
+			}
+		}
+		return http.StatusOK, map[string]interface{}{
+			"Parameters": []map[string]string{{"Name": "/myapp/prod/db/pass", "Value": "hunter2"}, {"Name": "/myapp/prod/hosts", "Value": "a,b"}},
+		}
+	})
+	p := NewSSMProvider("eu-west-1", "/myapp/prod/")
+	defer p.Close()
+	p.Endpoint = endpoint
+	p.Credentials = &AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+	got, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"db.host": "db1", "db.pass": "hunter2", "hosts": "a,b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %v, want %v", got, want)
+	}
+
+	p.Path = "/other"
+	if _, err := p.Load(); err == nil || err.Error() != "ssm: ValidationException: unexpected request" {
+		t.Errorf("Load error = %v, want the AWS error type and message", err)
+	}
+}
+
+func TestSecretsManagerProviderLoad(t *testing.T) {
+	endpoint := fakeAWS(t, func(target string, req map[string]interface{}) (int, interface{}) {
+		switch req["SecretId"] {
+		case "prod/db":
+			return http.StatusOK, map[string]string{"SecretString": `{"user":"app","port":5432}`}
+		case "prod/token":
+			return http.StatusOK, map[string]string{"SecretString": "plain-token"}
+		case "prod/cert":
+			return http.StatusOK, map[string]string{"SecretBinary": b64("PEM")}
+		}
+		return http.StatusBadRequest, map[string]string{"__type": "com.amazonaws#ResourceNotFoundException", "Message": "secret not found"}
+	})
+	creds := &AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+	p := NewSecretsManagerProvider("us-east-1", map[string]string{"prod/db": "db", "prod/token": "api.token", "prod/cert": "tls.cert"})
+	defer p.Close()
+	p.Endpoint, p.Credentials = endpoint, creds
+	got, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"db.user": "app", "db.port": "5432", "api.token": "plain-token", "tls.cert": "PEM"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		secrets map[string]string
+		wantErr string
+	}{
+		{map[string]string{"prod/token": ""}, "prod/token: secret is not a JSON object and needs a key prefix"},
+		{map[string]string{"missing": "x"}, "missing: secretsmanager: ResourceNotFoundException: secret not found"},
+	}
+	for _, tt := range tests {
+		p := NewSecretsManagerProvider("us-east-1", tt.secrets)
+		p.Endpoint, p.Credentials = endpoint, creds
+		if _, err := p.Load(); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("Load(%v) error = %v, want %q", tt.secrets, err, tt.wantErr)
+		}
+		p.Close()
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WithBackups 让保存在覆盖已有文件之前保留最近n个备份
+// 备份与文件位于同一目录，名为"<文件名>.bak.1"(最新)到"<文件名>.bak.n"(最旧)，
+// 每次保存时依次后移，超过n个的最旧备份被删除；备份保留原文件的修改时间，可据此判断备份的时间。
+// 只有新内容完整写入临时文件后才轮转备份，因此保存失败不会改变已有备份。
+// 作用于SaveToFile、SaveAs、SaveToJSON等所有保存方法，可通过RestoreBackup恢复
+// 参数:
+// - n: 保留的备份数，不大于0时不备份
+func WithBackups(n int) SaveOption {
+	return func(s *saveSettings) {
+		s.backups = n
+	}
+}
+
+// RestoreBackup 用filename的第n个备份(1为最新)覆盖该文件，并以其内容整体替换文件层
+// 备份先按文件格式解析，无法解析时文件与配置都保持不变；覆盖同样通过临时文件原子地完成。
+// 未出现在备份中的键(包括Set写入的键)会从文件层移除，与Watch重新加载的行为相同
+// 参数:
+// - filename: 配置文件路径
+// - n: 备份序号，1为最新的备份
+// 返回:
+// - error: 备份不存在、无法解析或文件操作错误(如果有)，配置已冻结时返回ErrFrozen
+func (c *Config) RestoreBackup(filename string, n int) error {
+	if n < 1 {
+		return fmt.Errorf("invalid backup number %d", n)
+	}
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = resolved
+	}
+	content, err := os.ReadFile(backupName(filename, n))
+	if err != nil {
+		return err
+	}
+	if content, err = decompressContent(filename, content); err != nil {
+		return fmt.Errorf("backup %d: %w", n, err)
+	}
+	if _, err := decodeFile(filename, content, nil); err != nil {
+		return fmt.Errorf("backup %d: %w", n, err)
+	}
+	if c.IsFrozen() {
+		return ErrFrozen
+	}
+	release, err := lockConfigFile(context.Background(), filename, true, false)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := writeBytesAtomic(filename, content); err != nil {
+		return err
+	}
+	return c.reloadContent(filename, content, nil)
+}
+
+// backupName 返回filename的第n个备份的路径
+func backupName(filename string, n int) string {
+	return filename + ".bak." + strconv.Itoa(n)
+}
+
+// rotateBackups 把已有备份依次后移一位，最旧的超过n个时删除，再把filename的当前内容保存为第1个备份
+func rotateBackups(filename string, n int) error {
+	if err := os.Remove(backupName(filename, n)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for i := n - 1; i >= 1; i-- {
+		if err := os.Rename(backupName(filename, i), backupName(filename, i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	// 硬链接不复制内容，之后的重命名使其保留旧文件；文件系统不支持时复制
+	if err := os.Link(filename, backupName(filename, 1)); err == nil {
+		return nil
+	}
+	return copyFile(filename, backupName(filename, 1))
+}
+
+// copyFile 复制文件内容，目标保留源文件的权限与修改时间
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	cfg, _ := NewConfig()
+	for _, v := range []string{"1", "2", "3", "4"} {
+		cfg.Set("version", v)
+		if err := cfg.SaveToFile(path, WithBackups(2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	// 第1个备份最新，超过上限的最旧备份被删除
+	if got := read(path); got != "version = 4\n" {
+		t.Errorf("current file = %q", got)
+	}
+	if got := read(path + ".bak.1"); got != "version = 3\n" {
+		t.Errorf("backup 1 = %q, want version 3", got)
+	}
+	if got := read(path + ".bak.2"); got != "version = 2\n" {
+		t.Errorf("backup 2 = %q, want version 2", got)
+	}
+	if _, err := os.Stat(path + ".bak.3"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("backup 3 exists beyond the limit: %v", err)
+	}
+
+	// 不带WithBackups的保存不轮转
+	cfg.Set("version", "5")
+	cfg.SaveToFile(path)
+	if got := read(path + ".bak.1"); got != "version = 3\n" {
+		t.Errorf("backup 1 after a save without backups = %q", got)
+	}
+
+	cfg.Set("extra", "1")
+	if err := cfg.RestoreBackup(path, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(path); got != "version = 2\n" {
+		t.Errorf("file after RestoreBackup = %q", got)
+	}
+	// 文件层整体替换为备份的内容
+	if cfg.Get("version") != "2" || cfg.Has("extra") {
+		t.Errorf("config after RestoreBackup = %v", cfg.GetAll())
+	}
+
+	if err := cfg.RestoreBackup(path, 3); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("RestoreBackup of a missing backup error = %v", err)
+	}
+	if err := cfg.RestoreBackup(path, 0); err == nil {
+		t.Error("RestoreBackup(0) succeeded")
+	}
+	jsonPath := path + ".json"
+	os.WriteFile(jsonPath+".bak.1", []byte("{"), 0o644)
+	if err := cfg.RestoreBackup(jsonPath, 1); err == nil {
+		t.Error("RestoreBackup of an unparsable backup succeeded")
+	}
+	cfg.Freeze()
+	if err := cfg.RestoreBackup(path, 2); !errors.Is(err, ErrFrozen) {
+		t.Errorf("RestoreBackup on a frozen config error = %v, want ErrFrozen", err)
+	}
+}
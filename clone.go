@@ -0,0 +1,76 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// cloneSettings 保存Clone的可选设置
+type cloneSettings struct {
+	subscriptions bool
+}
+
+// CloneOption 用于定制Clone
+type CloneOption func(*cloneSettings)
+
+// WithSubscriptions 让副本也通知原配置上的订阅者
+// 默认不复制订阅，对副本的修改不会触发原配置的回调；复制后原配置上的Unsubscribe不影响副本
+func WithSubscriptions() CloneOption {
+	return func(s *cloneSettings) {
+		s.subscriptions = true
+	}
+}
+
+// Clone 创建与本配置相互独立的副本，适用于测试与"如果这样修改会怎样"的评估
+// 副本包含所有层的数据与来源、文件结构、DefineKey的定义、BindEnv、加密、插值、profile、
+// 敏感键、别名与弃用登记，以及SetLogger、SetMetrics、SetTracer的设置；之后双方的修改互不影响。
+// 副本不冻结，也不包含配置源、写回、OnReload回调、审计回调、历史记录与TTL计时
+// (SetWithTTL写入的值保留但不会过期)，因此修改副本不会写入远程存储或审计日志
+// 参数:
+// - opts: 是否复制订阅等可选设置
+// 返回:
+// - *Config: 副本
+func (c *Config) Clone(opts ...CloneOption) *Config {
+	var settings cloneSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	clone := &Config{
+		data:        copyValues(c.data),
+		cipher:      c.cipher,
+		interpolate: c.interpolate,
+		layout:      c.layout,
+		schema:      maps.Clone(c.schema),
+		profile:     c.profile,
+		secrets:     slices.Clone(c.secrets),
+		revision:    c.revision,
+		alias:       c.alias,
+		noReadView:  c.noReadView,
+		typedValues: c.typedValues,
+		normalizer:  c.normalizer,
+		delimiter:   c.delimiter,
+		types:       maps.Clone(c.types),
+	}
+	for l := range c.layers {
+		clone.layers[l] = copyValues(c.layers[l])
+		clone.meta[l] = copySources(c.meta[l])
+	}
+	if c.env != nil {
+		env := *c.env
+		clone.env = &env
+	}
+	if c.alias.warned != nil {
+		clone.alias.warned = &sync.Map{}
+	}
+	if settings.subscriptions {
+		clone.subs = maps.Clone(c.subs)
+		clone.nextSubID = c.nextSubID
+	}
+	clone.logger.Store(c.logger.Load())
+	clone.metrics.Store(c.metrics.Load())
+	clone.tracer.Store(c.tracer.Load())
+	return clone
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.LoadFromReader(strings.NewReader("# app\nport = 80\n"))
+	cfg.SetDefault("timeout", "30s")
+	cfg.SetOverride("debug", "true")
+	cfg.Set("url", "http://localhost:${port}")
+	cfg.SetInterpolation(true)
+	cfg.MarkSecret("db.password")
+	cfg.Set("db.password", "hunter2")
+
+	var notified []string
+	cfg.Subscribe("*", func(key, _, _ string) { notified = append(notified, key) })
+
+	clone := cfg.Clone()
+	for _, key := range []string{"port", "timeout", "debug", "url"} {
+		if got, want := clone.Get(key), cfg.Get(key); got != want {
+			t.Errorf("clone Get(%s) = %q, want %q", key, got, want)
+		}
+	}
+	if clone.Get("url") != "http://localhost:80" || clone.GetAll()["db.password"] != "****" {
+		t.Errorf("clone settings = %v, want interpolation and secrets copied", clone.GetAll())
+	}
+	var a, b bytes.Buffer
+	cfg.WriteTo(&a)
+	clone.WriteTo(&b)
+	if a.String() != b.String() {
+		t.Errorf("clone WriteTo =\n%s\nwant\n%s", b.String(), a.String())
+	}
+
+	// 双方的修改互不影响，默认不通知原配置的订阅者
+	clone.Set("port", "9090")
+	clone.SetDefault("timeout", "1s")
+	cfg.Set("name", "app")
+	if cfg.Get("port") != "80" || cfg.Get("timeout") != "30s" || clone.Has("name") {
+		t.Errorf("clone and original are not independent: %v / %v", cfg.GetAll(), clone.GetAll())
+	}
+	if len(notified) != 1 || notified[0] != "name" {
+		t.Errorf("original subscribers notified %v, want only the original's change", notified)
+	}
+
+	withSubs := cfg.Clone(WithSubscriptions())
+	notified = nil
+	withSubs.Set("port", "1")
+	if len(notified) != 1 || notified[0] != "port" {
+		t.Errorf("WithSubscriptions clone notified %v, want the copied subscriber", notified)
+	}
+
+	// 副本不冻结
+	cfg.Freeze()
+	thawed := cfg.Clone()
+	if thawed.IsFrozen() || thawed.Set("port", "2") != nil {
+		t.Error("Clone of a frozen config is frozen")
+	}
+}
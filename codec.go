@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Codec 是一种配置格式的编解码器
+// Decode把文档解析为扁平键值对，Encode把扁平键值对写为文档；
+// 嵌套结构的展开方式由实现决定，建议与内置格式一致使用"."与"[i]"
+type Codec interface {
+	Decode(content []byte) (map[string]string, error)
+	Encode(values map[string]string) ([]byte, error)
+}
+
+var (
+	codecMu sync.RWMutex
+	codecs  = make(map[string]Codec)
+)
+
+// RegisterCodec 注册一种配置格式，通常在实现该格式的包的init函数中调用
+// 注册后LoadFromFile、LoadFromGlob、Watch等按扩展名识别".name"文件，
+// WithFileFormat、HTTPProvider的Format与SaveAs也可以通过名称使用该格式。
+// 格式名不区分大小写；codec为nil、名称为空、与内置格式或扩展名冲突或重复注册时panic
+// 参数:
+// - name: 格式名，同时作为文件扩展名(不含".")
+// - codec: 编解码器
+func RegisterCodec(name string, codec Codec) {
+	name = strings.ToLower(strings.TrimPrefix(name, "."))
+	if codec == nil {
+		panic("config: RegisterCodec codec is nil")
+	}
+	if name == "" {
+		panic("config: RegisterCodec name is empty")
+	}
+	if builtinFormat("."+name) != "" {
+		panic("config: RegisterCodec of built-in format " + name)
+	}
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	if _, dup := codecs[name]; dup {
+		panic("config: RegisterCodec called twice for format " + name)
+	}
+	codecs[name] = codec
+}
+
+// lookupCodec 查找已注册的编解码器
+func lookupCodec(name string) (Codec, bool) {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	codec, ok := codecs[name]
+	return codec, ok
+}
+
+// SaveAs 将文件层配置以指定格式保存到文件
+// format为空时与SaveToFile相同按扩展名选择，无法识别时使用key=value格式；"ini"格式写出key=value，
+// 保留最近一次加载的文件结构。内置格式中json、yaml、toml、properties、ini支持写出，
+// env、xml、hcl只能读取，返回包装了errors.ErrUnsupported的错误。
+// 与SaveToFile一样通过临时文件加重命名原子写入
+// 参数:
+// - filename: 目标文件路径
+// - format: 格式名，如"json"或RegisterCodec注册的名称
+// - opts: 文件权限、属主等可选设置
+// 返回:
+// - error: 格式不支持、编码或文件操作错误(如果有)
+func (c *Config) SaveAs(filename, format string, opts ...SaveOption) error {
+	release, err := lockConfigFile(context.Background(), filename, true, false)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return c.saveToFile(filename, strings.ToLower(format), opts)
+}
+
+// encodeFormat 按格式名编码键值对
+func encodeFormat(format string, values map[string]string) ([]byte, error) {
+	switch format {
+	case "json":
+		return encodeJSON(values)
+	case "yaml":
+		return encodeYAML(values)
+	case "toml":
+		return encodeTOML(values)
+	case "properties":
+		return encodeProperties(values), nil
+	case "ini":
+		return encodeKeyValue(values, SeparatorSpacedEquals), nil
+	case "env", "xml", "hcl":
+		return nil, fmt.Errorf("encode %s: %w", format, errors.ErrUnsupported)
+	}
+	if codec, ok := lookupCodec(format); ok {
+		return codec.Encode(values)
+	}
+	return nil, fmt.Errorf("unknown config format %q", format)
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// registerPipe 保证重复运行测试时pipe格式只注册一次
+var registerPipe sync.Once
+
+// pipeCodec 是测试用的格式：每行一个key|value
+type pipeCodec struct{}
+
+func (pipeCodec) Decode(content []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		key, value, ok := strings.Cut(line, "|")
+		if !ok {
+			return nil, errors.New("missing |")
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func (pipeCodec) Encode(values map[string]string) ([]byte, error) {
+	var lines []string
+	for k, v := range values {
+		lines = append(lines, k+"|"+v)
+	}
+	slices.Sort(lines)
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	registerPipe.Do(func() { RegisterCodec(".Pipe", pipeCodec{}) })
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.pipe")
+	os.WriteFile(path, []byte("name|app\nport|80\n"), 0o644)
+
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Get("name") != "app" || cfg.Get("port") != "80" {
+		t.Errorf("loaded values = %v", cfg.GetAll())
+	}
+	if err := cfg.LoadFromReader(strings.NewReader("debug|true"), WithFileFormat("PIPE")); err != nil || cfg.Get("debug") != "true" {
+		t.Errorf("LoadFromReader with the pipe format = %v, debug = %q", err, cfg.Get("debug"))
+	}
+	if err := cfg.LoadFromReader(strings.NewReader("junk"), WithFileFormat("pipe")); err == nil || !strings.Contains(err.Error(), "missing |") {
+		t.Errorf("decode error = %v, want the codec error", err)
+	}
+
+	cfg.Set("port", "9090")
+	out := filepath.Join(dir, "out.txt")
+	if err := cfg.SaveAs(out, "pipe"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "debug|true\nname|app\nport|9090\n" {
+		t.Errorf("SaveAs pipe =\n%s", data)
+	}
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "port|9090") {
+		t.Errorf("SaveToFile by extension =\n%s", data)
+	}
+
+	if err := cfg.SaveAs(out, "xml"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("SaveAs xml error = %v, want ErrUnsupported", err)
+	}
+	if err := cfg.SaveAs(out, "nope"); err == nil {
+		t.Error("SaveAs with an unknown format succeeded")
+	}
+}
+
+func TestRegisterCodecPanics(t *testing.T) {
+	tests := map[string]func(){
+		"nil codec": func() { RegisterCodec("nilcodec", nil) },
+		"empty":     func() { RegisterCodec("", pipeCodec{}) },
+		"built-in":  func() { RegisterCodec("json", pipeCodec{}) },
+		"twice": func() {
+			RegisterCodec("twice", pipeCodec{})
+			RegisterCodec("TWICE", pipeCodec{})
+		},
+	}
+	for name, register := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: RegisterCodec did not panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
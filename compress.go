@@ -0,0 +1,110 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Compression 是配置文件的压缩格式，按扩展名选择
+// 文件名以已注册的扩展名结尾时，LoadFromFile等读取时先解压，SaveToFile与SaveAs保存时压缩写出，格式的选择看去掉该扩展名后的文件名，
+// 例如"app.json.gz"按JSON格式读写。内置".gz"(gzip)；标准库不含zstd，
+// 需要".zst"时可用github.com/klauspost/compress/zstd等实现并通过RegisterCompression注册
+type Compression interface {
+	// NewReader 返回解压r的Reader
+	NewReader(r io.Reader) (io.ReadCloser, error)
+	// NewWriter 返回压缩后写入w的Writer，Close时写出剩余数据但不关闭w
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	compressionMu sync.RWMutex
+	compressions  = map[string]Compression{"gz": gzipCompression{}}
+)
+
+// RegisterCompression 注册一种压缩格式，通常在init函数中调用
+// 扩展名不区分大小写；c为nil、扩展名为空、与已注册的压缩格式或配置格式冲突时panic
+// 参数:
+// - ext: 文件扩展名(不含".")，如"zst"
+// - c: 压缩格式的实现
+func RegisterCompression(ext string, c Compression) {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	if c == nil {
+		panic("config: RegisterCompression compression is nil")
+	}
+	if ext == "" {
+		panic("config: RegisterCompression extension is empty")
+	}
+	if _, ok := lookupCodec(ext); ok || builtinFormat("."+ext) != "" {
+		panic("config: RegisterCompression of format extension " + ext)
+	}
+	compressionMu.Lock()
+	defer compressionMu.Unlock()
+	if _, dup := compressions[ext]; dup {
+		panic("config: RegisterCompression called twice for extension " + ext)
+	}
+	compressions[ext] = c
+}
+
+// compressionFor 返回filename扩展名对应的压缩格式
+func compressionFor(filename string) (Compression, bool) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if ext == "" {
+		return nil, false
+	}
+	compressionMu.RLock()
+	defer compressionMu.RUnlock()
+	c, ok := compressions[ext]
+	return c, ok
+}
+
+// trimCompressionExt 去掉filename的压缩扩展名，用于按扩展名选择格式
+func trimCompressionExt(filename string) string {
+	if _, ok := compressionFor(filename); ok {
+		return strings.TrimSuffix(filename, filepath.Ext(filename))
+	}
+	return filename
+}
+
+// readConfigFile 读取配置文件，文件名带有压缩扩展名时返回解压后的内容
+func readConfigFile(filename string) ([]byte, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return decompressContent(filename, content)
+}
+
+// decompressContent 按filename的扩展名解压content，不是压缩文件时原样返回
+func decompressContent(filename string, content []byte) ([]byte, error) {
+	c, ok := compressionFor(filename)
+	if !ok {
+		return content, nil
+	}
+	r, err := c.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("decompress %s: %w", filename, err)
+	}
+	defer r.Close()
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompress %s: %w", filename, err)
+	}
+	return plain, nil
+}
+
+// gzipCompression 是内置的".gz"压缩格式
+type gzipCompression struct{}
+
+func (gzipCompression) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func (gzipCompression) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
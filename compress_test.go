@@ -0,0 +1,59 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompressedSaveLoadRoundTrip(t *testing.T) {
+	values := map[string]string{"name": "app", "server.port": "8080", "db.host": "db.local"}
+	tests := []struct {
+		file   string
+		prefix string
+	}{
+		{"app.json.gz", "{"},
+		{"app.yaml.gz", "db:"},
+		{"app.ini.gz", "name = app"},
+		{"app.JSON.GZ", "{"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), tt.file)
+			cfg, _ := NewConfig()
+			for k, v := range values {
+				cfg.Set(k, v)
+			}
+			if err := cfg.SaveToFile(filename); err != nil {
+				t.Fatal(err)
+			}
+			raw, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("saved file is not gzip: %v", err)
+			}
+			plain, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(strings.TrimSpace(string(plain)), tt.prefix) {
+				t.Errorf("decompressed content does not start with %q:\n%s", tt.prefix, plain)
+			}
+			back, _ := NewConfig()
+			if err := back.LoadFromFile(filename); err != nil {
+				t.Fatal(err)
+			}
+			if got := back.LayerValues(LayerFile); !reflect.DeepEqual(got, values) {
+				t.Errorf("round trip = %v, want %v", got, values)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithConditions 设置key=value文件中条件指令求值使用的变量
+// 文件中"@if 条件"与"@endif"(可带"@else")之间的行只在条件成立时加载，条件可以是：
+// - "env=prod"：变量env的值为prod
+// - "env!=prod"：变量env的值不是prod
+// - "debug"：变量debug存在且不为空
+// - "!debug"：变量debug不存在或为空
+//
+// 条件块可以嵌套，但必须在同一文件内闭合，否则加载失败；未成立的块中的include指令不被处理。
+// 未设置变量时所有变量视为不存在。未成立块中的行原样保留在文件结构中，SaveToFile不会丢失它们
+// 参数:
+// - vars: 条件中引用的变量，如{"env": "prod", "region": "eu"}
+func WithConditions(vars map[string]string) LoadOption {
+	return func(s *loadSettings) {
+		s.conditions = vars
+	}
+}
+
+// WatchConditions 设置Watch重新加载key=value文件时条件指令使用的变量，含义与WithConditions相同
+func WatchConditions(vars map[string]string) WatchOption {
+	return func(s *watchSettings) {
+		s.conditions = vars
+	}
+}
+
+// condFrame 是一个尚未闭合的条件块
+type condFrame struct {
+	line   int  // "@if"所在行号
+	outer  bool // 外层块是否处于加载状态
+	cond   bool // 条件是否成立
+	inElse bool // 是否已经过"@else"
+}
+
+// active 判断块中当前分支的行是否加载
+func (f condFrame) active() bool {
+	return f.outer && f.cond != f.inElse
+}
+
+// condStack 跟踪一个文件中嵌套的条件块
+type condStack []condFrame
+
+// active 判断当前行是否处于加载状态
+func (s condStack) active() bool {
+	return len(s) == 0 || s[len(s)-1].active()
+}
+
+// apply 识别并处理条件指令，返回line是否为指令
+func (s *condStack) apply(line string, lineNo int, vars map[string]string) (bool, error) {
+	directive, cond := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		directive, cond = line[:i], strings.TrimSpace(line[i:])
+	}
+	switch directive {
+	case "@if":
+		if cond == "" {
+			return true, errors.New("@if without condition")
+		}
+		ok, err := evalCondition(cond, vars)
+		if err != nil {
+			return true, err
+		}
+		*s = append(*s, condFrame{line: lineNo, outer: s.active(), cond: ok})
+	case "@else", "@endif":
+		if cond != "" {
+			return true, fmt.Errorf("unexpected text after %s", directive)
+		}
+		if len(*s) == 0 {
+			return true, fmt.Errorf("%s without @if", directive)
+		}
+		top := &(*s)[len(*s)-1]
+		if directive == "@endif" {
+			*s = (*s)[:len(*s)-1]
+		} else if top.inElse {
+			return true, errors.New("duplicate @else")
+		} else {
+			top.inElse = true
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// evalCondition 对vars求值"name=value"、"name!=value"、"name"或"!name"形式的条件
+func evalCondition(cond string, vars map[string]string) (bool, error) {
+	if name, value, ok := strings.Cut(cond, "!="); ok {
+		name, err := conditionName(name)
+		return vars[name] != conditionValue(value), err
+	}
+	if name, value, ok := strings.Cut(cond, "="); ok {
+		name, err := conditionName(name)
+		return vars[name] == conditionValue(strings.TrimPrefix(value, "=")), err
+	}
+	if name, ok := strings.CutPrefix(cond, "!"); ok {
+		name, err := conditionName(name)
+		return vars[name] == "", err
+	}
+	name, err := conditionName(cond)
+	return vars[name] != "", err
+}
+
+// conditionName 返回条件中的变量名，名称为空或含空白时返回错误
+func conditionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return "", fmt.Errorf("invalid condition variable %q", name)
+	}
+	return name, nil
+}
+
+// conditionValue 返回条件中比较的值，两侧的引号被去除
+func conditionValue(value string) string {
+	value = strings.TrimSpace(value)
+	if unquoted, ok := unquoteValue(value); ok {
+		return unquoted
+	}
+	return value
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const conditionalConf = `name = app
+@if env=prod
+port = 80
+@if region = "eu"
+zone = eu-1
+@else
+zone = us-1
+@endif
+include prod-only.conf
+@else
+port = 8080
+@endif
+@if !debug
+log.level = warn
+@endif
+@if tracing
+trace = on
+@endif
+@if env!=prod
+seed = demo
+@endif
+`
+
+func TestWithConditions(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"app.conf":       conditionalConf,
+		"prod-only.conf": "replicas = 3\n",
+	})
+	path := filepath.Join(dir, "app.conf")
+	load := func(vars map[string]string) *Config {
+		t.Helper()
+		cfg, _ := NewConfig()
+		if err := cfg.LoadFromFile(path, WithConditions(vars)); err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	// 未设置变量时所有变量视为不存在
+	dev := load(nil)
+	if dev.Get("port") != "8080" || dev.Get("log.level") != "warn" || dev.Get("seed") != "demo" {
+		t.Errorf("without conditions = %v", dev.GetAll())
+	}
+	if dev.Get("zone") != "" || dev.Get("replicas") != "" || dev.Get("trace") != "" {
+		t.Errorf("inactive blocks loaded: %v", dev.GetAll())
+	}
+
+	prod := load(map[string]string{"env": "prod", "region": "eu", "debug": "1", "tracing": ""})
+	want := map[string]string{"name": "app", "port": "80", "zone": "eu-1", "replicas": "3"}
+	if got := prod.GetAll(); len(got) != len(want) {
+		t.Errorf("prod = %v, want %v", got, want)
+	}
+	for key, v := range want {
+		if got := prod.Get(key); got != v {
+			t.Errorf("prod Get(%s) = %q, want %q", key, got, v)
+		}
+	}
+	if got := load(map[string]string{"env": "prod", "region": "us"}).Get("zone"); got != "us-1" {
+		t.Errorf("nested @else zone = %q, want us-1", got)
+	}
+	if src, _ := prod.Source("port"); src.Line != 3 {
+		t.Errorf("Source(port).Line = %d, want 3", src.Line)
+	}
+
+	// 未成立块中的行保留在文件结构中
+	prod.Set("name", "renamed")
+	if err := prod.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if got, want := string(data), strings.Replace(conditionalConf, "name = app", "name = renamed", 1); got != want {
+		t.Errorf("saved file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConditionErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	tests := map[string]string{
+		"a = 1\n@if env=prod\nb = 2\n":    ":2: unterminated @if",
+		"@endif\n":                        ":1: @endif without @if",
+		"a = 1\n@else\n":                  ":2: @else without @if",
+		"@if env\n@else\n@else\n@endif\n": ":3: duplicate @else",
+		"@if\n@endif\n":                   ":1: @if without condition",
+		"@if a\n@endif now\n":             ":2: unexpected text after @endif",
+		"@if =prod\n@endif\n":             `invalid condition variable ""`,
+		"@if two words\n@endif\n":         `invalid condition variable "two words"`,
+	}
+	for content, want := range tests {
+		os.WriteFile(path, []byte(content), 0o644)
+		cfg, _ := NewConfig()
+		err := cfg.LoadFromFile(path, WithConditions(map[string]string{"env": "prod"}))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadFromFile(%q) error = %v, want %q", content, err, want)
+		}
+	}
+	// 条件块不能跨越include的文件
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"main.conf":  "@if env=prod\ninclude inner.conf\n@endif\n",
+		"inner.conf": "@endif\n",
+	})
+	cfg, _ := NewConfig()
+	err := cfg.LoadFromFile(filepath.Join(dir, "main.conf"), WithConditions(map[string]string{"env": "prod"}))
+	if err == nil || !strings.Contains(err.Error(), "inner.conf:1: @endif without @if") {
+		t.Errorf("@endif in an included file error = %v", err)
+	}
+}
@@ -1,182 +1,590 @@
-// Package config 提供线程安全的键值对配置管理系统
-//
-// 特性:
-// - 从文件加载配置(key=value格式)
-// - 保存配置到文件
-// - 线程安全的Get/Set操作
-// - 支持默认值
-// - 批量操作(GetAll)
-//
-// 示例:
-//   cfg, err := config.NewConfig()
-//   if err != nil {
-//       log.Fatal(err)
-//   }
-//   err = cfg.LoadFromFile("config.ini")
-//   port := cfg.GetWithDefault("server.port", "8080")
-package config
-
-import (
-	"bufio"
-	"errors"
-	"os"
-	"strings"
-	"sync"
-)
-
-// Config 表示线程安全的键值对配置存储
-// 提供加载、保存和操作配置值的方法
-// 所有操作都通过RWMutex保护以实现并发访问
-type Config struct {
-	data  map[string]string
-	mutex sync.RWMutex // 保证并发安全
-}
-
-// NewConfig 创建并返回新的Config实例
-// 返回:
-// - *Config: 指向新Config实例的指针
-// - error: 初始化错误(如果有)
-func NewConfig() (*Config, error) {
-	return &Config{
-		data: make(map[string]string),
-	}, nil
-}
-
-// LoadFromFile 从key=value格式的文件加载配置
-// 跳过空行和以#开头的行(注释)
-// 参数:
-// - filename: 配置文件路径
-// 返回:
-// - error: 文件操作或解析错误(如果有)
-func (c *Config) LoadFromFile(filename string) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	if c.data == nil {
-		c.data = make(map[string]string)
-	}
-
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if len(line) == 0 || strings.HasPrefix(line, "#") {
-			continue // 跳过空行和注释
-		}
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			c.data[key] = value
-		}
-	}
-
-	return scanner.Err()
-}
-
-// Get 根据键获取配置值
-// 参数:
-// - key: 要查找的配置键
-// 返回:
-// - string: 键存在时返回对应值，否则返回空字符串
-func (c *Config) Get(key string) string {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	return c.data[key]
-}
-
-// GetWithDefault 获取配置值，支持默认值回退
-// 参数:
-// - key: 要查找的配置键
-// - defaultValue: 键不存在时返回的默认值
-// 返回:
-// - string: 键存在时返回对应值，否则返回defaultValue
-func (c *Config) GetWithDefault(key, defaultValue string) string {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	if val, ok := c.data[key]; ok {
-		return val
-	}
-	return defaultValue
-}
-
-// Set 存储配置值
-// 参数:
-// - key: 配置键
-// - value: 要存储的值
-// 返回:
-// - error: 当key为空时返回错误
-func (c *Config) Set(key, value string) error {
-	if key == "" {
-		return errors.New("key cannot be empty")
-	}
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	c.data[key] = value
-	return nil
-}
-
-// Has 检查配置键是否存在
-// 参数:
-// - key: 要检查的配置键
-// 返回:
-// - bool: 键存在时返回true
-func (c *Config) Has(key string) bool {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	_, ok := c.data[key]
-	return ok
-}
-
-// Delete 删除配置键值对
-// 参数:
-// - key: 要删除的配置键
-func (c *Config) Delete(key string) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	delete(c.data, key)
-}
-
-// GetAll 返回所有配置键值对的副本
-// 返回:
-// - map[string]string: 所有配置数据的副本
-func (c *Config) GetAll() map[string]string {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	copy := make(map[string]string, len(c.data))
-	for k, v := range c.data {
-		copy[k] = v
-	}
-	return copy
-}
-
-// SaveToFile 将所有配置以key=value格式保存到文件
-// 参数:
-// - filename: 目标文件路径
-// 返回:
-// - error: 文件操作错误(如果有)
-func (c *Config) SaveToFile(filename string) error {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	for key, value := range c.data {
-		_, err := writer.WriteString(key + " = " + value + "\n")
-		if err != nil {
-			return err
-		}
-	}
-	return writer.Flush()
-}
\ No newline at end of file
+// Package config 提供线程安全的键值对配置管理系统
+//
+// 特性:
+// - 从文件加载配置(key=value格式，支持[section]段落)
+// - 保存配置到文件
+// - 线程安全的Get/Set操作
+// - 支持默认值
+// - 批量操作(GetAll)
+//
+// 示例:
+//
+//	cfg, err := config.NewConfig()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = cfg.LoadFromFile("config.ini")
+//	port := cfg.GetWithDefault("server.port", "8080")
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Config 表示线程安全的键值对配置存储
+// 提供加载、保存和操作配置值的方法
+// 所有操作都通过RWMutex保护以实现并发访问，Get等读取操作在可能时无锁读取已发布的只读视图
+type Config struct {
+	data          map[string]string               // 文件层数据
+	layers        [numLayers]map[string]string    // 其余各层数据，文件层使用data
+	env           *envBinding                     // BindEnv设置的环境变量映射，为nil表示未启用
+	cipher        Cipher                          // ENC(...)值的加解密实现
+	interpolate   bool                            // 是否在读取时展开${...}引用
+	reloadHooks   []func(error)                   // OnReload注册的热加载回调
+	layout        *fileLayout                     // 最近一次LoadFromFile读取的文件结构，为nil时SaveToFile按段落排序写出
+	fileSettings  map[string]loadSettings         // LoadFromFile加载各文件时的解析设置，Watch重新加载时复用
+	schema        map[string]*keyDef              // DefineKey登记的键定义
+	meta          [numLayers]map[string]KeySource // 各层中每个键的来源与修改时间
+	profile       string                          // SetProfile设置的当前profile
+	frozen        bool                            // Freeze之后为true，拒绝所有修改
+	ttls          map[string]*ttlEntry            // SetWithTTL设置的覆盖层中待过期的键
+	secrets       []string                        // MarkSecret标记的敏感键模式
+	audit         func(AuditEvent)                // SetAuditHook设置的审计回调
+	revision      uint64                          // 修订号，每次生效值变化加1
+	history       []revision                      // EnableHistory开启后保留的最近修订
+	historyBase   *Snapshot                       // history中最旧修订之前的状态
+	historyLimit  int                             // 保留的修订数上限，为0表示未开启历史
+	providers     []*providerState                // AddProvider添加的配置源栈，按添加顺序
+	providerKeys  map[string]bool                 // 配置源栈上一次合并写入的键
+	writeBack     WritableProvider                // EnableWriteBack设置的写回配置源，为nil表示未启用
+	alias         aliasTable                      // Alias与Deprecate登记的别名和弃用键
+	logger        atomic.Pointer[slog.Logger]     // SetLogger设置的日志，为nil表示不输出
+	metrics       atomic.Pointer[Metrics]         // SetMetrics设置的指标实现，为nil表示不记录
+	tracer        atomic.Pointer[Tracer]          // SetTracer设置的Tracer，为nil表示不跟踪
+	reloadFailing sync.Map                        // 最近一次后台重新加载失败的来源，用于记录重连事件
+	subs          map[uint64]*subscriber
+	nextSubID     uint64
+	noReadView    bool                     // WithoutReadView设置，不缓存生效值的视图
+	typedValues   bool                     // WithTypedValues设置，加载时记录值的类型
+	types         map[string]interface{}   // 文件层中键在加载时的原始类型，供GetValue使用
+	normalizer    func(string) string      // WithKeyNormalizer设置的键规范化函数，为nil表示不规范化
+	delimiter     string                   // WithKeyDelimiter设置的层级分隔符，为空表示"."
+	mutex         sync.RWMutex             // 保证并发安全
+	view          atomic.Pointer[readView] // 生效值的只读视图，任何加写锁的操作都会清空它
+	viewMu        sync.Mutex               // 保证同一时刻只有一个读取者重建视图
+}
+
+// NewConfig 创建并返回新的Config实例
+// 返回:
+// - *Config: 指向新Config实例的指针
+// - error: 初始化错误(如果有)
+func NewConfig() (*Config, error) {
+	return &Config{
+		data: make(map[string]string),
+	}, nil
+}
+
+// ConfigOption 用于定制NewConfigWithOptions创建的Config
+type ConfigOption func(*Config)
+
+// WithoutReadView 关闭生效值只读视图的缓存，适用于修改频繁、键较多的Config
+// 默认情况下Get在只读视图上无锁读取，视图包含全部生效值，每次修改后由第一次读取重建，
+// 读多写少时最快；但修改频繁时几乎每次读取都要重建视图，代价与键的数量成正比。
+// 设置后不再缓存视图，每次读取在读锁下只解析所请求的键，代价与键的数量无关。
+// 该选项不改变存储：所有修改仍然在同一把锁下串行进行，并发Set本身不会因此变快
+func WithoutReadView() ConfigOption {
+	return func(c *Config) {
+		c.noReadView = true
+	}
+}
+
+// NewConfigWithOptions 创建并按opts定制Config实例
+// 参数:
+// - opts: 可选设置，如WithoutReadView
+// 返回:
+// - *Config: 指向新Config实例的指针
+// - error: 初始化错误(如果有)
+func NewConfigWithOptions(opts ...ConfigOption) (*Config, error) {
+	c, err := NewConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// LoadFromFile 从配置文件加载配置，格式按扩展名自动选择
+// .json、.yaml/.yml、.toml、.properties、.env、.xml、.hcl使用对应格式的解析器，
+// 规则与LoadFromJSON等方法相同；.ini、.conf、.cfg为key=value格式；
+// 其它扩展名或没有扩展名时根据内容猜测格式，无法判断时按key=value格式处理。
+// 传入WithFileFormat可以强制使用指定格式。
+//
+// key=value格式跳过空行和以#或;开头的行(注释)，键与值之间也可以写成"key: value"，
+// 支持INI风格的[section]段落头，其后的键自动加上"section."前缀，
+// 例如[server]之后的port对应键"server.port"
+// "include other.conf"或"@include other.conf"指令加载相对当前文件的其它文件，
+// 路径可以含通配符；循环包含或嵌套过深时返回错误。
+// 值可以写成跨越多行的"""三引号块"""，以"\"结尾的行与下一行拼接；
+// 整个值写在双引号中时保留首尾空白并处理\n、\t、\"等转义，写在单引号中时按字面量处理。
+// 文件的注释、空行和键顺序会被记录，供之后的SaveToFile还原。
+// 通过DefineKey登记过键时，任何格式的文件违反定义都会被整体拒绝，返回*ValidationError，
+// key=value格式的违规带有行号。
+// 传入StrictMode时格式错误的行会使加载失败，而不是被忽略；严格模式与重复键策略只作用于key=value格式。
+// 通过SetProfile启用profile时，随后加载同目录下的profile文件(如config-prod.ini)。
+// 存在SaveToFileLocked创建的锁文件(如config.ini.lock)时，读取期间持有其共享锁，等待加锁的保存完成。
+// 参数:
+// - filename: 配置文件路径
+// - opts: 严格模式、强制格式等解析设置
+// 返回:
+// - error: 文件操作或解析错误(如果有)
+func (c *Config) LoadFromFile(filename string, opts ...LoadOption) error {
+	settings := newLoadSettings(opts)
+	return c.loadFromFile(context.Background(), filename, settings)
+}
+
+// LoadFromFileContext 与LoadFromFile相同，ctx取消或超时时停止等待文件读取并返回ctx.Err()
+// ctx约束主文件与profile文件的读取，适用于位于网络文件系统等可能长时间阻塞的文件；
+// 内容读入后的解析与合并不会被中途取消；主文件合并后profile文件的读取被取消时，主文件的键保留
+// 参数:
+// - ctx: 约束读取过程的context
+// - filename: 配置文件路径
+// - opts: 严格模式、强制格式等解析设置
+// 返回:
+// - error: 文件操作、解析错误或ctx的错误(如果有)
+func (c *Config) LoadFromFileContext(ctx context.Context, filename string, opts ...LoadOption) error {
+	return c.loadFromFile(ctx, filename, newLoadSettings(opts))
+}
+
+// loadFromFile 加载文件及其profile文件，ctx约束文件的读取
+func (c *Config) loadFromFile(ctx context.Context, filename string, settings loadSettings) (err error) {
+	ctx, span := c.startSpan(ctx, "config.LoadFromFile", slog.String("config.file", filename))
+	defer func() { span.End(err) }()
+	release, err := lockConfigFile(ctx, filename, false, false)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if err := c.loadFile(ctx, filename, true, settings); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	if c.fileSettings == nil {
+		c.fileSettings = make(map[string]loadSettings)
+	}
+	c.fileSettings[filename] = settings
+	c.mutex.Unlock()
+	return c.loadProfileFile(ctx, filename, settings)
+}
+
+// LoadFromReader 从r读取key=value格式的内容并加载，规则与LoadFromFile相同
+// 适用于嵌入的资源、网络流或测试数据；include指令中的相对路径相对于当前工作目录解析。
+// 内容是其它格式时通过WithFileFormat指定，r没有文件名，因此不会自动检测格式
+// 参数:
+// - r: 内容来源
+// - opts: 严格模式、强制格式等解析设置
+// 返回:
+// - error: 读取或解析错误(如果有)
+func (c *Config) LoadFromReader(r io.Reader, opts ...LoadOption) error {
+	settings := newLoadSettings(opts)
+	if settings.format == "" || settings.format == "ini" {
+		return c.loadKeyValue(r, "", true, settings)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, types, err := c.decodeContent(settings.format, content)
+	if err != nil {
+		return err
+	}
+	return c.mergeDocumentFrom("", values, types, KeySource{Kind: SourceAPI})
+}
+
+// loadKeyValue 解析key=value内容并合并到文件层
+// name用于错误信息中的位置、include指令的相对路径以及键的来源记录；
+// recordLayout为true时记录文件结构供WriteTo还原
+func (c *Config) loadKeyValue(r io.Reader, name string, recordLayout bool, settings loadSettings) error {
+	settings.warn = c.logLineWarning
+	doc, err := parseKeyValue(r, name, !settings.noInclude, settings)
+	if err != nil {
+		return err
+	}
+	c.normalizeDocument(doc)
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	if err := c.checkDocumentLocked(name, doc.values, doc.pos, false); err != nil {
+		c.mutex.Unlock()
+		return err
+	}
+	var changes []change
+	for k, v := range doc.values {
+		changes = c.setLocked(k, v, changes)
+		if pos := doc.pos[k]; pos.file != "" {
+			c.setSourceLocked(LayerFile, k, KeySource{Kind: SourceFile, Name: pos.file, Line: pos.line})
+		}
+	}
+	if recordLayout {
+		c.layout = doc.layout
+	}
+	c.unlockNotify(changes)
+	return nil
+}
+
+// decodeKeyValue 解析key=value格式的内容，支持注释和[section]段落，不处理include指令
+func decodeKeyValue(r io.Reader) (map[string]string, error) {
+	doc, err := parseKeyValue(r, "", false, loadSettings{})
+	if err != nil {
+		return nil, err
+	}
+	return doc.values, nil
+}
+
+// mergeValues 将解析得到的键值对合并到配置中，同名键被覆盖
+// 配置已冻结时返回ErrFrozen
+func (c *Config) mergeValues(values map[string]string) error {
+	return c.mergeLayerFrom(LayerFile, values, KeySource{Kind: SourceAPI})
+}
+
+// mergeValuesFrom 与mergeValues相同，并把src记录为这些键的来源
+// 来自文件的值先按DefineKey登记的定义检查，规则与key=value文件相同
+func (c *Config) mergeValuesFrom(values map[string]string, src KeySource) error {
+	if src.Kind == SourceFile {
+		return c.mergeDocumentFrom(src.Name, values, nil, src)
+	}
+	return c.mergeLayerFrom(LayerFile, values, src)
+}
+
+// mergeDocumentFrom 按DefineKey登记的定义检查name中解析出的键值对，通过后合并到文件层
+// 违反定义时返回*ValidationError，配置保持不变；解析结果没有行号，违规只记录文件名。
+// types是decodeContent返回的值类型，与值在同一次加锁中记录
+func (c *Config) mergeDocumentFrom(name string, values map[string]string, types map[string]interface{}, src KeySource) error {
+	values = normKeys(c, values)
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	if err := c.checkDocumentLocked(name, values, nil, false); err != nil {
+		c.mutex.Unlock()
+		return err
+	}
+	c.recordTypesLocked(values, types)
+	c.unlockNotify(c.mergeLayerLocked(LayerFile, values, src))
+	return nil
+}
+
+// mergeLayerFrom 将键值对合并到指定层并记录来源，配置已冻结时返回ErrFrozen
+func (c *Config) mergeLayerFrom(layer Layer, values map[string]string, src KeySource) error {
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	c.unlockNotify(c.mergeLayerLocked(layer, values, src))
+	return nil
+}
+
+// mergeLayerLocked 将键值对写入指定层并记录来源，返回生效值的变化；调用方须持有写锁
+func (c *Config) mergeLayerLocked(layer Layer, values map[string]string, src KeySource) []change {
+	var changes []change
+	for k, v := range values {
+		changes = c.writeLayerLocked(layer, k, v, false, changes)
+		c.setSourceLocked(layer, k, src)
+	}
+	return changes
+}
+
+// Get 根据键获取配置值
+// 键不存在与值为空字符串都返回""，需要区分时使用Lookup
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - string: 键存在时返回对应值，否则返回空字符串
+func (c *Config) Get(key string) string {
+	val, _ := c.lookup(key)
+	return val
+}
+
+// Lookup 根据键获取配置值，并报告键是否存在
+// 值的处理方式与Get相同
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - string: 键存在时返回对应值，可能为空字符串
+// - bool: 键是否存在
+func (c *Config) Lookup(key string) (string, bool) {
+	return c.lookup(key)
+}
+
+// lookup 按层优先级查找键，返回值及其是否存在；只读视图可用时无锁读取，否则在读锁保护下解析
+// ENC(...)形式的值在设置了密钥时透明解密，解密失败时返回原始密文；
+// 开启插值时展开${...}引用；DefineKey登记过的键按类型规范化
+func (c *Config) lookup(key string) (string, bool) {
+	val, ok := c.lookupValue(key)
+	c.observeGet(key, ok)
+	return val, ok
+}
+
+// lookupValue 实现lookup，不记录指标
+func (c *Config) lookupValue(key string) (string, bool) {
+	key = c.normKey(key)
+	v := c.loadView()
+	if v.alias.deprecated != nil {
+		v.alias.warnDeprecated(key, c.logger.Load())
+	}
+	key = v.alias.canonicalKey(key)
+	if v.cached {
+		val, ok := v.values[key]
+		return val, ok
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	val, ok := c.resolveLocked(key)
+	if !ok {
+		return "", false
+	}
+	return c.coerceLocked(key, c.expandValueLocked(key, c.decodeLocked(val))), true
+}
+
+// GetWithDefault 获取配置值，支持默认值回退
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 键不存在时返回的默认值
+// 返回:
+// - string: 键存在时返回对应值，否则返回defaultValue
+func (c *Config) GetWithDefault(key, defaultValue string) string {
+	if val, ok := c.lookup(key); ok {
+		return val
+	}
+	return defaultValue
+}
+
+// Set 在文件层存储配置值
+// 通过EnableWriteBack启用写回时先写入配置源，写入失败时不修改本地配置
+// 参数:
+// - key: 配置键
+// - value: 要存储的值
+// 返回:
+// - error: 当key为空时返回错误，配置已冻结时返回ErrFrozen，写回失败时返回其错误
+func (c *Config) Set(key, value string) error {
+	if key == "" {
+		return errors.New("key cannot be empty")
+	}
+	key = c.currentAliases().canonicalKey(c.normKey(key))
+	if err := c.writeBackTo(key, func(p WritableProvider) error { return p.Put(key, value) }); err != nil {
+		return err
+	}
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	changes := c.setLocked(key, value, nil)
+	c.unlockNotify(changes)
+	return nil
+}
+
+// Has 检查配置键是否存在
+// 参数:
+// - key: 要检查的配置键
+// 返回:
+// - bool: 键存在时返回true
+func (c *Config) Has(key string) bool {
+	_, ok := c.lookup(key)
+	return ok
+}
+
+// Delete 从文件层删除配置键值对
+// 默认值层、环境变量层和覆盖层中的同名键不受影响，可使用ClearLayer清除；
+// 启用写回时先从配置源删除
+// 参数:
+// - key: 要删除的配置键
+// 返回:
+// - error: 配置已冻结时返回ErrFrozen，写回失败时返回其错误
+func (c *Config) Delete(key string) error {
+	key = c.currentAliases().canonicalKey(c.normKey(key))
+	if err := c.writeBackTo(key, func(p WritableProvider) error { return p.Delete(key) }); err != nil {
+		return err
+	}
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	changes := c.deleteLocked(key, nil)
+	for _, old := range c.alias.aliasedBy[key] {
+		changes = c.deleteLocked(old, changes)
+	}
+	c.unlockNotify(changes)
+	return nil
+}
+
+// GetAll 返回所有配置键值对的副本
+// 结果为合并各层之后的生效值；BindEnv绑定的环境变量只对已存在于某一层的键生效，
+// ENC(...)形式的值、${...}引用以及DefineKey登记的类型的处理方式与Get一致。
+// 敏感键(判断规则见IsSecret)的值以"****"代替，需要明文时使用Get
+// 返回:
+// - map[string]string: 所有配置数据的副本
+func (c *Config) GetAll() map[string]string {
+	all := c.allValues()
+	c.maskSecrets(all)
+	return all
+}
+
+// allValues 返回所有生效值的副本，不隐藏敏感值
+func (c *Config) allValues() map[string]string {
+	if v := c.loadView(); v.cached {
+		return maps.Clone(v.values)
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	all := c.effectiveLocked()
+	for k, v := range all {
+		all[k] = c.coerceLocked(k, c.expandValueLocked(k, c.decodeLocked(v)))
+	}
+	return all
+}
+
+// SaveToFile 将文件层配置保存到文件，格式按扩展名选择，与LoadFromFile读取时的选择一致
+// ".json"、".yaml"、".toml"、".properties"以及RegisterCodec注册的扩展名按对应格式写出，与SaveAs相同；
+// 带有压缩扩展名时按去掉该扩展名后的文件名选择格式并压缩写出，如"app.json.gz"写出gzip压缩的JSON。
+// 只能读取的格式(.env、.xml、.hcl)返回包装了errors.ErrUnsupported的错误；其余扩展名以key=value格式写出，
+// 内容与WriteTo相同，传入WithSortedKeys时改为按键名排序写出。内容先写入同目录下的临时文件并fsync，再重命名覆盖目标文件，
+// 写入中途崩溃不会损坏原文件；默认保留原文件权限。
+// 存在SaveToFileLocked创建的锁文件时，写入期间持有其排他锁。
+// 参数:
+// - filename: 目标文件路径
+// - opts: 文件权限、属主、键排序等可选设置
+// 返回:
+// - error: 格式不支持、编码或文件操作错误(如果有)
+func (c *Config) SaveToFile(filename string, opts ...SaveOption) error {
+	release, err := lockConfigFile(context.Background(), filename, true, false)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return c.saveToFile(filename, "", opts)
+}
+
+// saveToFile 以指定格式保存文件层，format为空时按扩展名选择，不加锁
+func (c *Config) saveToFile(filename, format string, opts []SaveOption) error {
+	if format == "" {
+		if format = formatByExtension(filename); format == "" {
+			format = "ini"
+		}
+	}
+	if format != "ini" {
+		content, err := encodeFormat(format, c.LayerValues(LayerFile))
+		if err != nil {
+			return err
+		}
+		return writeBytesAtomic(filename, content, opts...)
+	}
+	settings := newSaveSettings(opts)
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := c.writeKeyValue(w, settings)
+		return err
+	}, opts...)
+}
+
+// WriteTo 将文件层配置以key=value格式写入w，实现io.WriterTo
+// 默认值层、环境变量层和覆盖层的值不会写入。之前通过LoadFromFile或LoadFromReader加载过内容时，
+// 按其原有的注释、空行和键顺序写出，已删除的键被省略，新键追加到所属段落末尾；
+// 否则键按首段前缀分组写入INI风格的[section]段落，
+// 不含"."的键写在所有段落之前，段落和键均按字典序排列。
+// 参数:
+// - w: 写入目标
+// 返回:
+// - int64: 写入的字节数
+// - error: 写入错误(如果有)
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	return c.writeKeyValue(w, saveSettings{})
+}
+
+// writeKeyValue 按保存设置以key=value格式写出文件层
+func (c *Config) writeKeyValue(w io.Writer, settings saveSettings) (int64, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	sep := settings.separator
+	if sep != "" {
+		if trimmed := strings.TrimSpace(sep); trimmed != "=" && trimmed != ":" {
+			return 0, fmt.Errorf("invalid separator %q: must be \"=\" or \":\" with optional spaces", sep)
+		}
+	} else if c.layout != nil && c.layout.separator != "" {
+		sep = c.layout.separator
+	} else {
+		sep = SeparatorSpacedEquals
+	}
+
+	cw := &countingWriter{w: w}
+	if settings.sorted {
+		enc := &Encoder{w: bufio.NewWriter(cw), sep: sep, grouped: settings.grouped}
+		err := enc.encodeValues(c.data)
+		if err == nil {
+			err = enc.Flush()
+		}
+		return cw.n, err
+	}
+	if c.layout != nil {
+		err := c.layout.render(cw, c.data, sep, settings.separator != "")
+		return cw.n, err
+	}
+	_, err := cw.Write(encodeKeyValue(c.data, sep))
+	return cw.n, err
+}
+
+// encodeKeyValue 以key=value格式写出键值，键按首段前缀分组为[section]段落，sep分隔键与值
+func encodeKeyValue(data map[string]string, sep string) []byte {
+	var buf bytes.Buffer
+	current := ""
+	for i, key := range sectionOrderedKeys(data) {
+		section, name := splitSection(key)
+		if section != current {
+			if i > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString("[" + section + "]\n")
+			current = section
+		}
+		buf.WriteString(name + sep + formatValue(data[key]) + "\n")
+	}
+	return buf.Bytes()
+}
+
+// countingWriter 记录写入的字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// splitSection 将键拆分为INI段落名和段内键名，不含"."的键段落名为空
+func splitSection(key string) (section, name string) {
+	if i := strings.Index(key, "."); i > 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+// sectionOrderedKeys 返回按段落分组排序的键：无段落的键在前，其余按段落和键名排序
+func sectionOrderedKeys(data map[string]string) []string {
+	keys := sortedKeys(data)
+	sort.SliceStable(keys, func(i, j int) bool {
+		si, _ := splitSection(keys[i])
+		sj, _ := splitSection(keys[j])
+		return si < sj
+	})
+	return keys
+}
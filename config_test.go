@@ -0,0 +1,264 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeKeyValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{
+			name:  "separators and comments",
+			input: "# comment\n; also comment\na=1\nb = 2\nc: 3\n\nd =\n",
+			want:  map[string]string{"a": "1", "b": "2", "c": "3", "d": ""},
+		},
+		{
+			name:  "sections",
+			input: "env = prod\n[server]\nport = 8080\n[db]\nhost = localhost\n",
+			want:  map[string]string{"env": "prod", "server.port": "8080", "db.host": "localhost"},
+		},
+		{
+			name:  "section with dotted name",
+			input: "[server.tls]\nenabled = true\n",
+			want:  map[string]string{"server.tls.enabled": "true"},
+		},
+		{
+			name:  "double quoted escapes",
+			input: `banner = "Welcome\n\tto \"app\"\x21"` + "\nprompt = \"> \"\n",
+			want:  map[string]string{"banner": "Welcome\n\tto \"app\"!", "prompt": "> "},
+		},
+		{
+			name:  "single quoted literal",
+			input: `pattern = '\d+\.\d+'` + "\n",
+			want:  map[string]string{"pattern": `\d+\.\d+`},
+		},
+		{
+			name:  "triple quoted block",
+			input: "cert = \"\"\"\nline1\n  line2\n\"\"\"\nnext = 1\n",
+			want:  map[string]string{"cert": "line1\n  line2", "next": "1"},
+		},
+		{
+			name:  "byte order mark",
+			input: "\ufeffa = 1\n",
+			want:  map[string]string{"a": "1"},
+		},
+		{
+			name:  "empty key ignored",
+			input: "= 1\n[s]\n = 2\nk = 3\n",
+			want:  map[string]string{"s.k": "3"},
+		},
+		{
+			name:  "line continuation",
+			input: "query = SELECT id \\\n    FROM users \\\n    WHERE a = 1\n",
+			want:  map[string]string{"query": "SELECT id FROM users WHERE a = 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeKeyValue(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeKeyValue = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadKeyValueErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []LoadOption
+	}{
+		{"strict missing separator", "a = 1\njunk\n", []LoadOption{StrictMode()}},
+		{"strict unterminated section", "[server\nport = 1\n", []LoadOption{StrictMode()}},
+		{"strict unterminated block", "cert = \"\"\"\nline1\n", []LoadOption{StrictMode()}},
+		{"duplicate error", "a = 1\na = 2\n", []LoadOption{WithDuplicateKeys(DuplicateError)}},
+		{"unclosed condition", "@if env=prod\na = 1\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := NewConfig()
+			if err := cfg.LoadFromReader(strings.NewReader(tt.input), tt.opts...); err == nil {
+				t.Fatalf("LoadFromReader succeeded, values %v", cfg.GetAll())
+			}
+			if n := len(cfg.Keys()); n != 0 {
+				t.Errorf("failed load left %d keys", n)
+			}
+		})
+	}
+}
+
+func TestLoadKeyValueDuplicates(t *testing.T) {
+	input := "a = 1\nb = x\na = 2\n"
+	tests := []struct {
+		policy DuplicatePolicy
+		want   string
+	}{
+		{DuplicateLastWins, "2"},
+		{DuplicateFirstWins, "1"},
+		{DuplicateCollect, "1,2"},
+	}
+	for _, tt := range tests {
+		cfg, _ := NewConfig()
+		if err := cfg.LoadFromReader(strings.NewReader(input), WithDuplicateKeys(tt.policy)); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.Get("a"); got != tt.want {
+			t.Errorf("policy %v: a = %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestKeyValueRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"env":         "prod",
+		"server.port": "8080",
+		"server.tls":  "true",
+		"db.host":     "localhost",
+		"banner":      "Welcome\n\tto \"app\"",
+		"prompt":      "> ",
+		"hash":        "a # not a comment",
+		"empty":       "",
+		"path":        `C:\dir`,
+	}
+	got, err := decodeKeyValue(strings.NewReader(string(encodeKeyValue(values, " = "))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("round trip = %q, want %q", got, values)
+	}
+}
+
+func TestSaveToFileByExtension(t *testing.T) {
+	values := map[string]string{
+		"name":        "app",
+		"server.port": "8080",
+		"server.tls":  "true",
+		"db.host":     "db.local",
+	}
+	tests := []struct {
+		file   string
+		prefix string
+	}{
+		{"app.json", "{"},
+		{"app.yaml", "db:"},
+		{"app.toml", "name = "},
+		{"app.properties", "db.host="},
+		{"app.ini", "name = app"},
+		{"app.conf", "name = app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), tt.file)
+			cfg, _ := NewConfig()
+			for k, v := range values {
+				cfg.Set(k, v)
+			}
+			if err := cfg.SaveToFile(filename); err != nil {
+				t.Fatal(err)
+			}
+			content, _ := os.ReadFile(filename)
+			if !strings.HasPrefix(strings.TrimSpace(string(content)), tt.prefix) {
+				t.Errorf("content does not start with %q:\n%s", tt.prefix, content)
+			}
+			back, _ := NewConfig()
+			if err := back.LoadFromFile(filename); err != nil {
+				t.Fatalf("load saved file: %v\n%s", err, content)
+			}
+			if got := back.LayerValues(LayerFile); !reflect.DeepEqual(got, values) {
+				t.Errorf("round trip = %v, want %v", got, values)
+			}
+		})
+	}
+	for _, file := range []string{"app.env", "app.xml", "app.hcl"} {
+		cfg, _ := NewConfig()
+		cfg.Set("name", "app")
+		filename := filepath.Join(t.TempDir(), file)
+		if err := cfg.SaveToFile(filename); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("SaveToFile(%s) error = %v, want ErrUnsupported", file, err)
+		}
+		if _, err := os.Stat(filename); err == nil {
+			t.Errorf("SaveToFile(%s) created the file", file)
+		}
+	}
+}
+
+func TestLoadFromReaderWriteTo(t *testing.T) {
+	const doc = "# header\nname = app\n\n[server]\nport = 8080\n"
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromReader(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("server.port"); got != "8080" {
+		t.Errorf("server.port = %q, want 8080", got)
+	}
+	cfg.SetDefault("timeout", "30s")
+	var buf bytes.Buffer
+	n, err := cfg.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按读取时的结构写出，只含文件层
+	if buf.String() != doc || n != int64(len(doc)) {
+		t.Errorf("WriteTo wrote %d bytes:\n%s\nwant\n%s", n, buf.String(), doc)
+	}
+
+	errRead := errors.New("read failed")
+	if err := cfg.LoadFromReader(iotest.ErrReader(errRead)); !errors.Is(err, errRead) {
+		t.Errorf("LoadFromReader error = %v, want the read error", err)
+	}
+	if got := cfg.Get("name"); got != "app" {
+		t.Errorf("name after a failed read = %q, want the earlier value", got)
+	}
+	errWrite := errors.New("write failed")
+	if _, err := cfg.WriteTo(failingWriter{errWrite}); !errors.Is(err, errWrite) {
+		t.Errorf("WriteTo error = %v, want the write error", err)
+	}
+}
+
+// failingWriter 的每次写入都返回err
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+func TestLookup(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("empty", "")
+	cfg.Set("name", "app")
+
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"name", "app", true},
+		{"empty", "", true},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := cfg.Lookup(tt.key); got != tt.want || ok != tt.wantOK {
+			t.Errorf("Lookup(%s) = %q, %v, want %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+		if got := cfg.Get(tt.key); got != tt.want {
+			t.Errorf("Get(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	cfg.Delete("empty")
+	if _, ok := cfg.Lookup("empty"); ok {
+		t.Error("Lookup(empty) after Delete reported the key")
+	}
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConsulProvider 通过Consul HTTP API读取KV存储中指定前缀下的键
+// 远程键去掉前缀后，其中的"/"替换为Delimiter，
+// 例如前缀"myapp/"下的"myapp/server/port"对应键"server.port"。
+// Watch使用Consul阻塞查询(blocking query)长轮询变化。
+type ConsulProvider struct {
+	// Address 是Consul地址，如"http://127.0.0.1:8500"；未指定协议时使用http
+	Address string
+	// Prefix 是要读取的键前缀
+	Prefix string
+	// Delimiter 替换远程键中的"/"，为空时保留"/"
+	Delimiter string
+	// Datacenter 指定查询的数据中心，为空时使用代理所在的数据中心
+	Datacenter string
+	// Token 是ACL令牌，通过X-Consul-Token请求头发送
+	Token string
+	// TLSConfig 在Client为nil时用于构造HTTPS客户端
+	TLSConfig *tls.Config
+	// Client 用于发送请求，为nil时根据TLSConfig构造
+	Client *http.Client
+	// WaitTime 是单次阻塞查询的最长等待时间
+	WaitTime time.Duration
+	// RetryInterval 是请求失败后的重试间隔
+	RetryInterval time.Duration
+
+	mutex      sync.Mutex
+	index      uint64
+	httpClient *http.Client
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// NewConsulProvider 创建读取prefix下键的Consul配置源
+// 参数:
+// - address: Consul地址
+// - prefix: 键前缀
+// 返回:
+// - *ConsulProvider: 配置源实例
+func NewConsulProvider(address, prefix string) *ConsulProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ConsulProvider{
+		Address:       address,
+		Prefix:        prefix,
+		Delimiter:     ".",
+		WaitTime:      5 * time.Minute,
+		RetryInterval: time.Second,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// LoadFromConsul 从Consul KV加载prefix下的所有键并合并到文件层
+// 需要设置数据中心、令牌或TLS时直接使用NewConsulProvider
+// 参数:
+// - address: Consul地址
+// - prefix: 键前缀
+// 返回:
+// - error: 请求或解析错误(如果有)
+func (c *Config) LoadFromConsul(address, prefix string) error {
+	p := NewConsulProvider(address, prefix)
+	defer p.Close()
+	return c.LoadFromProvider(p)
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *ConsulProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// Load 读取前缀下的全部键
+func (p *ConsulProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时中止请求
+func (p *ConsulProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	values, index, err := p.fetch(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	p.index = index
+	p.mutex.Unlock()
+	return values, nil
+}
+
+// Watch 通过阻塞查询监听前缀下的变化，仅在ModifyIndex变化时发送更新
+// 按Consul对阻塞查询的建议，索引回退时以本次结果为准并重新开始，索引为0或缺失时按1查询；
+// 响应没有X-Consul-Index时服务端不支持阻塞，每次查询之间等待RetryInterval
+func (p *ConsulProvider) Watch(ch chan<- Update) error {
+	for {
+		p.mutex.Lock()
+		index := p.index
+		p.mutex.Unlock()
+
+		values, next, err := p.fetch(p.ctx, index)
+		if p.ctx.Err() != nil {
+			return ErrProviderClosed
+		}
+		if err != nil {
+			select {
+			case ch <- Update{Err: err}:
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+			select {
+			case <-time.After(p.RetryInterval):
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+			continue
+		}
+
+		// 以0发起的查询不会阻塞，缺少索引时按1查询并限制查询频率
+		unblocked := next == 0
+		if unblocked {
+			next = 1
+		}
+		// 索引回退说明服务端状态被重置，本次结果视为变化，之后从新的索引开始阻塞查询
+		if next < index {
+			index = 0
+		}
+		p.mutex.Lock()
+		p.index = next
+		p.mutex.Unlock()
+
+		if next != index {
+			select {
+			case ch <- Update{Values: values}:
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+		}
+		if unblocked {
+			select {
+			case <-time.After(p.RetryInterval):
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+		}
+	}
+}
+
+// fetch 读取前缀下的全部键；index大于0时发起阻塞查询
+func (p *ConsulProvider) fetch(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	query := url.Values{}
+	query.Set("recurse", "true")
+	if p.Datacenter != "" {
+		query.Set("dc", p.Datacenter)
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%ds", int(p.WaitTime/time.Second)))
+	}
+
+	address := p.Address
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	reqURL := strings.TrimSuffix(address, "/") + "/v1/kv/" +
+		strings.TrimPrefix(p.Prefix, "/") + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if p.Token != "" {
+		req.Header.Set("X-Consul-Token", p.Token)
+	}
+
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	values := make(map[string]string)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// 前缀下没有任何键
+		return values, next, nil
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, 0, fmt.Errorf("consul: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var entries []struct {
+		Key   string
+		Value *string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, 0, fmt.Errorf("consul: decode response: %w", err)
+	}
+	for _, e := range entries {
+		// 以"/"结尾且没有值的条目是目录占位
+		if e.Value == nil && strings.HasSuffix(e.Key, "/") {
+			continue
+		}
+		value := ""
+		if e.Value != nil {
+			raw, err := base64.StdEncoding.DecodeString(*e.Value)
+			if err != nil {
+				return nil, 0, fmt.Errorf("consul: invalid value encoding for %q: %w", e.Key, err)
+			}
+			value = string(raw)
+		}
+		if key := remoteKey(e.Key, strings.TrimPrefix(p.Prefix, "/"), p.Delimiter); key != "" {
+			values[key] = value
+		}
+	}
+	return values, next, nil
+}
+
+// Put 把配置键写入Consul KV，键名按Load相反的规则映射为前缀下的远程键
+func (p *ConsulProvider) Put(key, value string) error {
+	return p.write(http.MethodPut, key, strings.NewReader(value))
+}
+
+// Delete 从Consul KV删除配置键对应的远程键
+func (p *ConsulProvider) Delete(key string) error {
+	return p.write(http.MethodDelete, key, nil)
+}
+
+// write 对配置键对应的远程键发送PUT或DELETE请求
+func (p *ConsulProvider) write(method, key string, body io.Reader) error {
+	query := url.Values{}
+	if p.Datacenter != "" {
+		query.Set("dc", p.Datacenter)
+	}
+	address := p.Address
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	path := remotePath(key, strings.TrimPrefix(p.Prefix, "/"), p.Delimiter, "/")
+	reqURL := strings.TrimSuffix(address, "/") + "/v1/kv/" + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(p.ctx, method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	if p.Token != "" {
+		req.Header.Set("X-Consul-Token", p.Token)
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consul: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	// PUT在未获得写入权(如CAS或锁冲突)时返回false
+	if strings.TrimSpace(string(msg)) == "false" {
+		return fmt.Errorf("consul: %s %s rejected", method, path)
+	}
+	return nil
+}
+
+// client 返回发送请求使用的HTTP客户端
+func (p *ConsulProvider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if p.TLSConfig != nil {
+			transport.TLSClientConfig = p.TLSConfig
+		}
+		p.httpClient = &http.Client{Transport: transport}
+	}
+	return p.httpClient
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// consulReply 是fakeConsul对一次KV请求的响应
+type consulReply struct {
+	index string // X-Consul-Index，为空时不设置该响应头
+	kvs   map[string]string
+}
+
+// fakeConsul 按顺序以replies中的响应回答KV请求，并记录每个请求的index参数
+type fakeConsul struct {
+	replies chan consulReply
+	indexes chan string
+}
+
+func newFakeConsul(t *testing.T) (*fakeConsul, *ConsulProvider) {
+	f := &fakeConsul{replies: make(chan consulReply, 8), indexes: make(chan string, 64)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	p := NewConsulProvider(srv.URL, "app/")
+	p.RetryInterval = 20 * time.Millisecond
+	t.Cleanup(func() { p.Close() })
+	return f, p
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.indexes <- r.URL.Query().Get("index")
+	var reply consulReply
+	select {
+	case reply = <-f.replies:
+	case <-r.Context().Done():
+		return
+	}
+	if reply.index != "" {
+		w.Header().Set("X-Consul-Index", reply.index)
+	}
+	var entries []map[string]string
+	for k, v := range reply.kvs {
+		entries = append(entries, map[string]string{"Key": k, "Value": base64.StdEncoding.EncodeToString([]byte(v))})
+	}
+	json.NewEncoder(w).Encode(entries)
+}
+
+// watchConsul 在后台运行Watch，返回接收更新的通道
+func watchConsul(t *testing.T, p *ConsulProvider) <-chan Update {
+	ch := make(chan Update, 8)
+	done := make(chan struct{})
+	go func() {
+		p.Watch(ch)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		p.Close()
+		<-done
+	})
+	return ch
+}
+
+func nextConsulIndex(t *testing.T, f *fakeConsul) string {
+	t.Helper()
+	select {
+	case idx := <-f.indexes:
+		return idx
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for consul request")
+		return ""
+	}
+}
+
+func nextConsulUpdate(t *testing.T, ch <-chan Update) map[string]string {
+	t.Helper()
+	select {
+	case u := <-ch:
+		if u.Err != nil {
+			t.Fatalf("update error: %v", u.Err)
+		}
+		return u.Values
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for update")
+		return nil
+	}
+}
+
+func TestConsulWatchIndex(t *testing.T) {
+	f, p := newFakeConsul(t)
+	f.replies <- consulReply{"10", map[string]string{"app/a": "1"}}
+	if values, err := p.Load(); err != nil || values["a"] != "1" {
+		t.Fatalf("Load = %v, %v", values, err)
+	}
+	nextConsulIndex(t, f)
+	ch := watchConsul(t, p)
+
+	if got := nextConsulIndex(t, f); got != "10" {
+		t.Errorf("blocking query index = %q, want 10", got)
+	}
+	f.replies <- consulReply{"10", map[string]string{"app/a": "1"}} // 等待超时，索引未变
+	if got := nextConsulIndex(t, f); got != "10" {
+		t.Errorf("index after timeout = %q, want 10", got)
+	}
+	f.replies <- consulReply{"12", map[string]string{"app/a": "2"}}
+	if got, want := nextConsulUpdate(t, ch), map[string]string{"a": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("update = %v, want %v", got, want)
+	}
+
+	// 索引回退(如服务端快照恢复)时本次结果视为变化，之后从新的索引查询
+	if got := nextConsulIndex(t, f); got != "12" {
+		t.Errorf("index = %q, want 12", got)
+	}
+	f.replies <- consulReply{"5", map[string]string{"app/a": "restored"}}
+	if got, want := nextConsulUpdate(t, ch), map[string]string{"a": "restored"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("update after reset = %v, want %v", got, want)
+	}
+	if got := nextConsulIndex(t, f); got != "5" {
+		t.Errorf("index after reset = %q, want 5", got)
+	}
+}
+
+func TestConsulWatchMissingIndex(t *testing.T) {
+	f, p := newFakeConsul(t)
+	for i := 0; i < cap(f.replies); i++ {
+		f.replies <- consulReply{"", map[string]string{"app/a": "1"}}
+	}
+	ch := watchConsul(t, p)
+	if got := nextConsulIndex(t, f); got != "" {
+		t.Errorf("first query index = %q, want none", got)
+	}
+	if got, want := nextConsulUpdate(t, ch), map[string]string{"a": "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("update = %v, want %v", got, want)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if got := nextConsulIndex(t, f); got != "1" {
+			t.Errorf("query %d index = %q, want 1", i+1, got)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*p.RetryInterval {
+		t.Errorf("three queries without X-Consul-Index took %v, want at least %v between them", elapsed, p.RetryInterval)
+	}
+	select {
+	case u := <-ch:
+		t.Errorf("unexpected update %v without index change", u)
+	default:
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyChange 描述一个键在两份配置之间的差异
+// 新增的键Old为空，删除的键New为空；OldSource和NewSource是值在两份配置中的来源
+type KeyChange struct {
+	Key       string
+	Old       string
+	New       string
+	OldSource KeySource
+	NewSource KeySource
+}
+
+// DiffResult 是Diff的比较结果，各列表均按键名排序
+type DiffResult struct {
+	Added   []KeyChange
+	Removed []KeyChange
+	Changed []KeyChange
+}
+
+// Diff 比较两份配置的生效值
+// 值的处理方式与Get一致(解密、插值展开)，因此比较的是应用程序实际读到的值；
+// 结果中敏感键(判断规则见IsSecret)的值以"****"代替
+// 参数:
+// - a: 原配置
+// - b: 新配置
+// 返回:
+// - *DiffResult: 从a到b新增、删除和修改的键
+func Diff(a, b *Config) *DiffResult {
+	d := diffValues(a.allValues(), b.allValues())
+	for _, list := range [][]KeyChange{d.Added, d.Removed, d.Changed} {
+		for i := range list {
+			ch := &list[i]
+			ch.OldSource, _ = a.Source(ch.Key)
+			ch.NewSource, _ = b.Source(ch.Key)
+			if ch.Old != "" && a.IsSecret(ch.Key) {
+				ch.Old = maskedValue
+			}
+			if ch.New != "" && b.IsSecret(ch.Key) {
+				ch.New = maskedValue
+			}
+		}
+	}
+	return d
+}
+
+// diffValues 比较两组键值
+func diffValues(before, after map[string]string) *DiffResult {
+	d := &DiffResult{}
+	for _, key := range sortedKeys(before) {
+		old := before[key]
+		if v, ok := after[key]; !ok {
+			d.Removed = append(d.Removed, KeyChange{Key: key, Old: old})
+		} else if v != old {
+			d.Changed = append(d.Changed, KeyChange{Key: key, Old: old, New: v})
+		}
+	}
+	for _, key := range sortedKeys(after) {
+		if _, ok := before[key]; !ok {
+			d.Added = append(d.Added, KeyChange{Key: key, New: after[key]})
+		}
+	}
+	return d
+}
+
+// Empty 判断两份配置是否没有差异
+func (d *DiffResult) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String 以每行一个键的文本形式返回差异:
+// "+ key = value"表示新增，"- key = value"表示删除，"~ key: old -> new"表示修改。
+// 值不是通过API写入时在行尾附带来源，如"~ port: 80 -> 8080 (app.ini:3)"
+func (d *DiffResult) String() string {
+	var b strings.Builder
+	for _, ch := range d.Added {
+		fmt.Fprintf(&b, "+ %s = %s%s\n", ch.Key, ch.New, sourceSuffix(ch.NewSource))
+	}
+	for _, ch := range d.Removed {
+		fmt.Fprintf(&b, "- %s = %s%s\n", ch.Key, ch.Old, sourceSuffix(ch.OldSource))
+	}
+	for _, ch := range d.Changed {
+		fmt.Fprintf(&b, "~ %s: %s -> %s%s\n", ch.Key, ch.Old, ch.New, sourceSuffix(ch.NewSource))
+	}
+	return b.String()
+}
+
+// sourceSuffix 返回附加在差异行尾的来源描述，SourceAPI时为空
+func sourceSuffix(src KeySource) string {
+	if src.Kind == SourceAPI {
+		return ""
+	}
+	return " (" + src.String() + ")"
+}
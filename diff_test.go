@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a, _ := NewConfig()
+	a.Set("name", "app")
+	a.Set("port", "80")
+	a.Set("old", "gone")
+	a.SetDefault("timeout", "30s")
+
+	path := filepath.Join(t.TempDir(), "app.ini")
+	os.WriteFile(path, []byte("name = app\nport = 8080\n"), 0o644)
+	b, _ := NewConfig()
+	if err := b.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	b.SetDefault("timeout", "30s")
+	b.SetOverride("debug", "true")
+
+	d := Diff(a, b)
+	if d.Empty() {
+		t.Fatal("Diff reported no changes")
+	}
+	want := "+ debug = true\n" +
+		"- old = gone\n" +
+		"~ port: 80 -> 8080 (" + path + ":2)\n"
+	if got := d.String(); got != want {
+		t.Errorf("Diff =\n%s\nwant\n%s", got, want)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].OldSource.Kind != SourceAPI || d.Changed[0].NewSource.Line != 2 {
+		t.Errorf("Changed = %+v, want the sources of both sides", d.Changed)
+	}
+
+	if !Diff(b, b).Empty() {
+		t.Error("Diff of a config with itself is not empty")
+	}
+	// 比较生效值：只是来自不同层的相同值没有差异
+	c, _ := NewConfig()
+	c.SetOverride("name", "app")
+	c.Set("port", "8080")
+	c.Set("debug", "true")
+	c.SetDefault("timeout", "30s")
+	if d := Diff(b, c); !d.Empty() {
+		t.Errorf("Diff of equal effective values =\n%s", d)
+	}
+}
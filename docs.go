@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Description 设置键的说明，由Docs写入生成的配置参考文档
+func Description(text string) KeyOption {
+	return func(d *keyDef) error {
+		d.desc = text
+		return nil
+	}
+}
+
+// KeyDoc 描述DefineKey登记的一个键，是Docs生成JSON时每个元素的结构
+type KeyDoc struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Default     *string  `json:"default,omitempty"`
+	Required    bool     `json:"required"`
+	Description string   `json:"description,omitempty"`
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// Docs 生成DefineKey登记的所有键的参考文档，用于自动生成项目的配置说明
+// 每个键包含键名、类型、默认值、是否必填、Description设置的说明，以及Between、OneOf、Matches、Check等约束，
+// 按键名排序。敏感键(判断规则见IsSecret)的默认值以"****"代替。
+// format为"markdown"(或"md")时生成一个Markdown表格，为"json"时生成KeyDoc数组
+// 参数:
+// - format: "markdown"、"md"或"json"
+// 返回:
+// - []byte: 文档内容
+// - error: 格式不受支持时返回错误
+func (c *Config) Docs(format string) ([]byte, error) {
+	docs := c.keyDocs()
+	switch strings.ToLower(format) {
+	case "json":
+		if docs == nil {
+			docs = []KeyDoc{}
+		}
+		out, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	case "markdown", "md":
+		return markdownDocs(docs), nil
+	}
+	return nil, fmt.Errorf("unknown docs format %q", format)
+}
+
+// keyDocs 收集登记的键的文档，按键名排序
+func (c *Config) keyDocs() []KeyDoc {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	keys := make([]string, 0, len(c.schema))
+	for key := range c.schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var docs []KeyDoc
+	for _, key := range keys {
+		d := c.schema[key]
+		doc := KeyDoc{Key: key, Type: d.rule.Type.String(), Required: d.rule.Required, Description: d.desc}
+		if d.hasDefault {
+			def := d.def
+			if c.isSecretLocked(key) {
+				def = maskedValue
+			}
+			doc.Default = &def
+		}
+		doc.Constraints = d.rule.constraints()
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// constraints 以可读的形式列出规则中除类型与必填以外的约束
+func (r Rule) constraints() []string {
+	var out []string
+	switch {
+	case r.Min != nil && r.Max != nil:
+		out = append(out, fmt.Sprintf("between %s and %s", formatLimit(*r.Min), formatLimit(*r.Max)))
+	case r.Min != nil:
+		out = append(out, ">= "+formatLimit(*r.Min))
+	case r.Max != nil:
+		out = append(out, "<= "+formatLimit(*r.Max))
+	}
+	if len(r.Enum) > 0 {
+		out = append(out, "one of "+strings.Join(r.Enum, ", "))
+	}
+	if r.Pattern != "" {
+		out = append(out, "matches "+r.Pattern)
+	}
+	if n := len(r.Validators); n > 0 {
+		out = append(out, fmt.Sprintf("%d custom check(s)", n))
+	}
+	return out
+}
+
+// markdownDocs 把文档写为Markdown表格
+func markdownDocs(docs []KeyDoc) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("| Key | Type | Default | Required | Description |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, doc := range docs {
+		def := ""
+		if doc.Default != nil {
+			def = `""`
+			if *doc.Default != "" {
+				def = "`" + markdownCell(*doc.Default) + "`"
+			}
+		}
+		required := "no"
+		if doc.Required {
+			required = "yes"
+		}
+		desc := markdownCell(doc.Description)
+		if len(doc.Constraints) > 0 {
+			if desc != "" {
+				desc += "<br>"
+			}
+			desc += markdownCell(strings.Join(doc.Constraints, "; "))
+		}
+		fmt.Fprintf(&buf, "| `%s` | %s | %s | %s | %s |\n", markdownCell(doc.Key), doc.Type, def, required, desc)
+	}
+	return buf.Bytes()
+}
+
+// markdownCell 转义表格单元格中的竖线与换行
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
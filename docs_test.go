@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDocs(t *testing.T) {
+	cfg, _ := NewConfig()
+	defs := []error{
+		cfg.DefineKey("server.port", Int, Default(8080), Between(1, 65535), Description("listen port")),
+		cfg.DefineKey("log.level", String, OneOf("debug", "info"), Required(), Description("log level | verbosity")),
+		cfg.DefineKey("db.password", String, Default("hunter2")),
+		cfg.DefineKey("name", String, Default(""), Matches("^[a-z]*$")),
+	}
+	for _, err := range defs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	md, err := cfg.Docs("markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "| Key | Type | Default | Required | Description |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| `db.password` | string | `****` | no |  |\n" +
+		"| `log.level` | string |  | yes | log level \\| verbosity<br>one of debug, info |\n" +
+		"| `name` | string | \"\" | no | matches ^[a-z]*$ |\n" +
+		"| `server.port` | int | `8080` | no | listen port<br>between 1 and 65535 |\n"
+	if string(md) != want {
+		t.Errorf("Docs(markdown) =\n%s\nwant\n%s", md, want)
+	}
+	if md2, _ := cfg.Docs("MD"); string(md2) != string(md) {
+		t.Error(`Docs("MD") differs from Docs("markdown")`)
+	}
+
+	out, err := cfg.Docs("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []KeyDoc
+	if err := json.Unmarshal(out, &docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 4 || docs[1].Key != "log.level" || !docs[1].Required || docs[1].Default != nil {
+		t.Fatalf("Docs(json) = %s", out)
+	}
+	if port := docs[3]; port.Type != "int" || *port.Default != "8080" || port.Description != "listen port" || len(port.Constraints) != 1 {
+		t.Errorf("server.port doc = %+v", port)
+	}
+
+	empty, _ := NewConfig()
+	if out, _ := empty.Docs("json"); string(out) != "[]\n" {
+		t.Errorf("Docs(json) without keys = %q, want an empty array", out)
+	}
+	if _, err := cfg.Docs("yaml"); err == nil {
+		t.Error(`Docs("yaml") succeeded`)
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadDotEnv 从.env文件加载配置，未指定文件时加载当前目录下的".env"
+// 解析规则与常见的dotenv实现一致:
+// - 以#开头的行是注释，行首的"export "前缀被忽略
+// - 未加引号的值去除首尾空白，" #"之后的内容是行内注释
+// - 双引号内支持\n、\t、\"、\\等转义，单引号内的内容原样保留，两者都可以跨行
+//
+// 值中的${VAR}引用原样保留，需要展开时可启用插值。多个文件按顺序加载，
+// 后加载的文件覆盖之前的同名键。与godotenv不同，加载的键只写入配置，
+// 需要同时设置进程环境变量时调用ExportToEnv
+// 参数:
+// - filenames: .env文件路径，可以是多个
+// 返回:
+// - error: 文件读取或解析错误(如果有)，出错时之前的文件已经加载
+func (c *Config) LoadDotEnv(filenames ...string) error {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+	for _, filename := range filenames {
+		content, err := readConfigFile(filename)
+		if err != nil {
+			return err
+		}
+		values, err := decodeDotEnv(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		if err := c.mergeValuesFrom(values, KeySource{Kind: SourceFile, Name: filename}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportToEnv 把所有生效的配置值通过os.Setenv写入进程环境变量
+// 调用过BindEnv时按其映射规则生成变量名(与EnvName相同)，否则直接使用键名；
+// 写入的是解密和插值后的值，标记为敏感的键同样会被写入
+// 返回:
+// - error: 第一个写入失败的变量(如键名包含"="或NUL字符)
+func (c *Config) ExportToEnv() error {
+	values := c.allValues()
+	c.mutex.RLock()
+	binding := c.env
+	c.mutex.RUnlock()
+
+	for _, key := range sortedKeys(values) {
+		name := key
+		if binding != nil {
+			name = binding.name(key)
+		}
+		if err := os.Setenv(name, values[key]); err != nil {
+			return fmt.Errorf("export %q as %s: %w", key, name, err)
+		}
+	}
+	return nil
+}
+
+// decodeDotEnv 解析.env内容
+func decodeDotEnv(content []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		start := lineNo
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "export "); ok {
+			line = strings.TrimLeft(rest, " \t")
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("dotenv: line %d: expected KEY=VALUE: %q", start, line)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			values[key] = strings.TrimSpace(value)
+			continue
+		}
+
+		// 引号值可以跨行，直到遇到未转义的结束引号
+		end := closingQuote(value)
+		for end < 0 && scanner.Scan() {
+			lineNo++
+			value += "\n" + scanner.Text()
+			end = closingQuote(value)
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("dotenv: line %d: unterminated quoted value for %q", start, key)
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && rest[0] != '#' {
+			return nil, fmt.Errorf("dotenv: line %d: unexpected text after quoted value for %q", start, key)
+		}
+		unquoted, ok := unquoteValue(value[:end+1])
+		if !ok {
+			return nil, fmt.Errorf("dotenv: line %d: malformed quoted value for %q", start, key)
+		}
+		values[key] = unquoted
+	}
+	return values, scanner.Err()
+}
+
+// closingQuote 返回value开头的引号对应的结束引号位置，未闭合时返回-1
+// 双引号内"\"转义下一个字符，单引号内没有转义
+func closingQuote(value string) int {
+	q := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case q == '"' && value[i] == '\\':
+			i++
+		case value[i] == q:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeDotEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "plain values",
+			input: "# comment\nA=1\nB = two words \nexport C=3\nD=\n",
+			want:  map[string]string{"A": "1", "B": "two words", "C": "3", "D": ""},
+		},
+		{
+			name:  "inline comments",
+			input: "A=1 # comment\nB=x#y\nC=\"q # kept\" # comment\n",
+			want:  map[string]string{"A": "1", "B": "x#y", "C": "q # kept"},
+		},
+		{
+			name:  "double quoted escapes",
+			input: `A="line1\nline2\t\"q\" \\"` + "\n",
+			want:  map[string]string{"A": "line1\nline2\t\"q\" \\"},
+		},
+		{
+			name:  "single quoted literal",
+			input: `A='no \n escape ${X}'` + "\n",
+			want:  map[string]string{"A": `no \n escape ${X}`},
+		},
+		{
+			name:  "multiline quoted",
+			input: "KEY=\"-----BEGIN-----\nabc\n-----END-----\"\nNEXT=1\n",
+			want:  map[string]string{"KEY": "-----BEGIN-----\nabc\n-----END-----", "NEXT": "1"},
+		},
+		{
+			name:  "references kept",
+			input: "URL=http://${HOST}:${PORT}/\n",
+			want:  map[string]string{"URL": "http://${HOST}:${PORT}/"},
+		},
+		{name: "missing equals", input: "A\n", wantErr: true},
+		{name: "empty key", input: "=1\n", wantErr: true},
+		{name: "unterminated quote", input: "A=\"x\nB=1\n", wantErr: true},
+		{name: "text after quote", input: "A=\"x\" y\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeDotEnv([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeDotEnv error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeDotEnv = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportToEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		bind bool
+		key  string
+		env  string
+	}{
+		{"key as name", false, "APP_MODE", "APP_MODE"},
+		{"bound prefix", true, "server.port", "MYAPP_SERVER_PORT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := NewConfig()
+			cfg.Set(tt.key, "value")
+			if tt.bind {
+				cfg.BindEnv("MYAPP")
+			}
+			// t.Setenv在测试结束时恢复原值，变量本身须不存在，否则BindEnv会读到空值
+			t.Setenv(tt.env, "")
+			os.Unsetenv(tt.env)
+			if err := cfg.ExportToEnv(); err != nil {
+				t.Fatal(err)
+			}
+			if got := os.Getenv(tt.env); got != "value" {
+				t.Errorf("%s = %q, want value", tt.env, got)
+			}
+		})
+	}
+}
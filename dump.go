@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// dumpLayers 是DumpAnnotated查找被覆盖的值时检查的层，按优先级从高到低排列
+var dumpLayers = []Layer{LayerOverride, LayerEnv, LayerFile, LayerDefault}
+
+// DumpAnnotated 以key=value格式写出所有生效值，每个键之前的注释说明值来自哪一层与哪个来源
+// 注释形如"# file layer, app.ini:12"或"# env layer, env APP_PORT"；其它层中同样存在该键但被覆盖时，
+// 注释末尾列出这些层的来源，如"(overrides file app.ini:12, default)"，用于排查层优先级问题。
+// 值的处理方式与GetAll一致，敏感键(判断规则见IsSecret)的值以"****"代替，但ENC(...)形式的值写出原密文而不是明文或占位符，
+// 读回后仍能解密；键按字典序排列，写出的内容可以作为key=value文件读回
+// 参数:
+// - w: 输出目标
+// 返回:
+// - error: 写入错误(如果有)
+func (c *Config) DumpAnnotated(w io.Writer) error {
+	all := c.GetAll()
+	bw := bufio.NewWriter(w)
+	c.mutex.RLock()
+	for i, key := range sortedKeys(all) {
+		if i > 0 {
+			bw.WriteByte('\n')
+		}
+		val := all[key]
+		if raw, ok := c.resolveLocked(key); ok && isEncrypted(raw) {
+			val = raw
+		}
+		bw.WriteString("# " + c.provenanceLocked(key) + "\n")
+		bw.WriteString(strings.TrimRight(key+" = "+formatValue(val), " ") + "\n")
+	}
+	c.mutex.RUnlock()
+	return bw.Flush()
+}
+
+// provenanceLocked 描述键的生效值所在的层与来源，以及被它覆盖的其它层；调用方须持有锁
+func (c *Config) provenanceLocked(key string) string {
+	src, ok := c.sourceLocked(key)
+	if !ok {
+		return "unknown source"
+	}
+	desc := src.Layer.String() + " layer, " + src.String()
+	r, _ := c.resolveEntryLocked(key)
+	var shadowed []string
+	for _, l := range dumpLayers {
+		e, ok := c.layerEntryLocked(l, c.alias.canonicalKey(c.normKey(key)))
+		if !ok || (l == r.layer && e.stored == r.stored && r.env == "") {
+			continue
+		}
+		if s := c.meta[l][e.stored]; s.Kind != SourceAPI {
+			shadowed = append(shadowed, l.String()+" "+s.String())
+		} else {
+			shadowed = append(shadowed, l.String())
+		}
+	}
+	if len(shadowed) > 0 {
+		desc += " (overrides " + strings.Join(shadowed, ", ") + ")"
+	}
+	return desc
+}
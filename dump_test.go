@@ -0,0 +1,45 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDumpAnnotatedKeepsCiphertext(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	cfg, _ := NewConfig()
+	cfg.SetEncryptionKey(key)
+	if err := cfg.SetEncrypted("db.pass", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("db.host", "localhost")
+	cfg.Set("api.key", "k-123")
+	cfg.MarkSecret("api.key")
+
+	var buf bytes.Buffer
+	if err := cfg.DumpAnnotated(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "k-123"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("dump contains secret %q:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "api.key = ****") {
+		t.Errorf("dump does not mask api.key:\n%s", out)
+	}
+
+	back, _ := NewConfig()
+	back.SetEncryptionKey(key)
+	if err := back.LoadFromReader(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := back.Get("db.pass"); got != "hunter2" {
+		t.Errorf("reloaded db.pass = %q, want hunter2", got)
+	}
+	if got := back.Get("db.host"); got != "localhost" {
+		t.Errorf("reloaded db.host = %q, want localhost", got)
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encoder 以key=value格式把条目流式写入io.Writer，适用于数百万键、作为查找表使用的配置
+// 条目经缓冲直接写出，不拼接整行字符串，也不复制键值映射；写出的内容与WithSortedKeys保存的相同，
+// 可以由LoadFromFile等读回。写入出错后之后的调用都返回同一错误，写完后须调用Flush
+type Encoder struct {
+	w       *bufio.Writer
+	sep     string
+	grouped bool
+	section string // 上一个条目的首段前缀，用于分组
+	started bool
+	err     error
+}
+
+// NewEncoder 创建写入w的Encoder
+// opts中WithSeparator指定分隔符写法(默认SeparatorSpacedEquals)，WithSortedKeys(true)让首段前缀不同的相邻条目之间插入空行，
+// 其它保存选项不生效
+// 参数:
+// - w: 写入目标
+// - opts: 保存选项
+// 返回:
+// - *Encoder: 编码器
+func NewEncoder(w io.Writer, opts ...SaveOption) *Encoder {
+	settings := newSaveSettings(opts)
+	e := &Encoder{w: bufio.NewWriter(w), sep: settings.separator, grouped: settings.sorted && settings.grouped}
+	if e.sep == "" {
+		e.sep = SeparatorSpacedEquals
+	} else if trimmed := strings.TrimSpace(e.sep); trimmed != "=" && trimmed != ":" {
+		e.err = fmt.Errorf("invalid separator %q: must be \"=\" or \":\" with optional spaces", e.sep)
+	}
+	return e
+}
+
+// Encode 写出一个条目
+// Encoder不排序，调用方按需要的顺序(通常为键名的字典序)写入；分组时只比较相邻条目的首段前缀
+// 参数:
+// - key: 配置键，不能为空
+// - value: 配置值，需要时按SaveToFile的规则加引号
+// 返回:
+// - error: key为空或写入错误(如果有)
+func (e *Encoder) Encode(key, value string) error {
+	if e.err != nil {
+		return e.err
+	}
+	if key == "" {
+		return errors.New("key cannot be empty")
+	}
+	if e.grouped {
+		section, _ := splitSection(key)
+		if e.started && section != e.section {
+			e.w.WriteByte('\n')
+		}
+		e.section = section
+	}
+	e.started = true
+	e.w.WriteString(key)
+	e.w.WriteString(e.sep)
+	e.w.WriteString(formatValue(value))
+	_, e.err = e.w.WriteString("\n")
+	return e.err
+}
+
+// EncodeConfig 按键名的字典序写出c的文件层
+// 在读锁下遍历，只对键排序，不复制值；分组时键按首段前缀分组，不含"."的键在最前
+// 参数:
+// - c: 要写出的配置
+// 返回:
+// - error: 写入错误(如果有)
+func (e *Encoder) EncodeConfig(c *Config) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return e.encodeValues(c.data)
+}
+
+// encodeValues 按键名排序写出data，分组时按首段前缀排序
+func (e *Encoder) encodeValues(data map[string]string) error {
+	keys := sortedKeys(data)
+	if e.grouped {
+		keys = sectionOrderedKeys(data)
+	}
+	for _, key := range keys {
+		if err := e.Encode(key, data[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush 把缓冲的数据写入底层Writer
+// 返回:
+// - error: 写入错误(如果有)
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.w.Flush()
+	return e.err
+}
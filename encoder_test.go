@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("server.port", "8080")
+	cfg.Set("name", "app")
+	cfg.Set("server.host", "0.0.0.0")
+	cfg.Set("banner", "  hi\n")
+	cfg.SetDefault("timeout", "30s") // 只写出文件层
+
+	// 与WithSortedKeys保存的内容相同
+	for _, grouped := range []bool{false, true} {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf, WithSortedKeys(grouped))
+		if err := e.EncodeConfig(cfg); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "app.conf")
+		cfg.SaveToFile(path, WithSortedKeys(grouped))
+		if want, _ := os.ReadFile(path); buf.String() != string(want) {
+			t.Errorf("grouped=%v: Encoder output =\n%s\nwant\n%s", grouped, buf.String(), want)
+		}
+		reloaded, _ := NewConfig()
+		reloaded.LoadFromReader(&buf)
+		if got := reloaded.Get("banner"); got != "  hi\n" {
+			t.Errorf("reloaded banner = %q", got)
+		}
+	}
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf, WithSeparator(SeparatorEquals))
+	e.Encode("b", "2")
+	e.Encode("a", "1") // 不排序
+	if err := e.Encode("", "x"); err == nil {
+		t.Error("Encode with an empty key succeeded")
+	}
+	e.Flush()
+	if buf.String() != "b=2\na=1\n" {
+		t.Errorf("Encoder output = %q", buf.String())
+	}
+
+	if err := NewEncoder(&buf, WithSeparator("~")).Encode("a", "1"); err == nil || !strings.Contains(err.Error(), "invalid separator") {
+		t.Errorf("Encode with an invalid separator error = %v", err)
+	}
+	// 写入错误之后的调用返回同一错误
+	werr := errors.New("disk full")
+	e = NewEncoder(failingWriter{werr})
+	e.Encode("a", strings.Repeat("x", 8192))
+	if err := e.Encode("b", "2"); !errors.Is(err, werr) {
+		t.Errorf("Encode after a write error = %v, want %v", err, werr)
+	}
+	if err := e.Flush(); !errors.Is(err, werr) {
+		t.Errorf("Flush after a write error = %v, want %v", err, werr)
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNoCipher 表示需要加解密但尚未设置密钥或Cipher
+var ErrNoCipher = errors.New("no encryption key configured")
+
+const (
+	encPrefix = "ENC("
+	encSuffix = ")"
+)
+
+// Cipher 是值加解密的实现，可接入KMS等外部密钥服务
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// aesGCMCipher 是基于AES-GCM的默认Cipher实现，密文格式为nonce||sealed
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESCipher 使用AES-GCM创建Cipher
+// 参数:
+// - key: 16、24或32字节的AES密钥
+// 返回:
+// - Cipher: 加解密实现
+// - error: 密钥长度不合法时返回错误
+func NewAESCipher(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCMCipher{aead: aead}, nil
+}
+
+func (a *aesGCMCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, a.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return a.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (a *aesGCMCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := a.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, errors.New("ciphertext too short")
+	}
+	return a.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
+
+// SetEncryptionKey 设置用于ENC(...)值的AES-GCM密钥
+// 参数:
+// - key: 16、24或32字节的AES密钥
+// 返回:
+// - error: 密钥长度不合法时返回错误
+func (c *Config) SetEncryptionKey(key []byte) error {
+	ci, err := NewAESCipher(key)
+	if err != nil {
+		return err
+	}
+	c.SetCipher(ci)
+	return nil
+}
+
+// SetCipher 设置用于ENC(...)值的加解密实现
+// 参数:
+// - ci: Cipher实现，为nil时关闭透明解密
+func (c *Config) SetCipher(ci Cipher) {
+	c.lock()
+	defer c.mutex.Unlock()
+	c.cipher = ci
+}
+
+// EncryptValue 加密明文并返回可直接写入配置文件的ENC(base64)形式
+// 参数:
+// - plaintext: 明文
+// 返回:
+// - string: ENC(...)形式的密文
+// - error: 未设置密钥或加密失败时返回错误
+func (c *Config) EncryptValue(plaintext string) (string, error) {
+	c.mutex.RLock()
+	ci := c.cipher
+	c.mutex.RUnlock()
+	if ci == nil {
+		return "", ErrNoCipher
+	}
+	sealed, err := ci.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed) + encSuffix, nil
+}
+
+// SetEncrypted 加密明文后以ENC(...)形式存入文件层
+// Get返回解密后的明文，SaveToFile等保存方法写出密文
+// 参数:
+// - key: 配置键
+// - plaintext: 明文
+// 返回:
+// - error: key为空、未设置密钥或加密失败时返回错误
+func (c *Config) SetEncrypted(key, plaintext string) error {
+	if key == "" {
+		return errors.New("key cannot be empty")
+	}
+	value, err := c.EncryptValue(plaintext)
+	if err != nil {
+		return err
+	}
+	return c.Set(key, value)
+}
+
+// GetDecrypted 获取配置值，若为ENC(...)形式则解密
+// 与Get不同，解密失败时返回错误而不是原始密文
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - string: 明文值
+// - error: 键不存在(ErrKeyNotFound)、未设置密钥或解密失败时返回错误
+func (c *Config) GetDecrypted(key string) (string, error) {
+	c.mutex.RLock()
+	raw, ok := c.resolveLocked(key)
+	ci := c.cipher
+	c.mutex.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("key %q: %w", key, ErrKeyNotFound)
+	}
+	val, err := decryptValue(ci, raw)
+	if err != nil {
+		return "", fmt.Errorf("key %q: %w", key, err)
+	}
+	return val, nil
+}
+
+// isEncrypted 判断值是否为ENC(...)形式
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encPrefix) && strings.HasSuffix(value, encSuffix)
+}
+
+// decryptValue 解密ENC(...)形式的值，其它值原样返回
+func decryptValue(ci Cipher, value string) (string, error) {
+	if !isEncrypted(value) {
+		return value, nil
+	}
+	if ci == nil {
+		return "", ErrNoCipher
+	}
+	sealed, err := base64.StdEncoding.DecodeString(value[len(encPrefix) : len(value)-len(encSuffix)])
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	plain, err := ci.Decrypt(sealed)
+	if err != nil {
+		return "", fmt.Errorf("decrypt value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// decodeLocked 对读取到的原始值做透明解密，失败时保留原始密文，调用方须持有锁
+func (c *Config) decodeLocked(value string) string {
+	if c.cipher == nil || !isEncrypted(value) {
+		return value
+	}
+	if plain, err := decryptValue(c.cipher, value); err == nil {
+		return plain
+	}
+	return value
+}
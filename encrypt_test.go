@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// reverseCipher 是测试用的Cipher，把字节顺序反转
+type reverseCipher struct{}
+
+func (reverseCipher) Encrypt(p []byte) ([]byte, error) { return reverseBytes(p), nil }
+func (reverseCipher) Decrypt(p []byte) ([]byte, error) { return reverseBytes(p), nil }
+
+func reverseBytes(p []byte) []byte {
+	out := bytes.Clone(p)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+func TestEncryptedValues(t *testing.T) {
+	cfg, _ := NewConfig()
+	if _, err := cfg.EncryptValue("x"); !errors.Is(err, ErrNoCipher) {
+		t.Errorf("EncryptValue without a key error = %v, want ErrNoCipher", err)
+	}
+	if err := cfg.SetEncryptionKey([]byte("short")); err == nil {
+		t.Error("SetEncryptionKey accepted a 5-byte key")
+	}
+	key := []byte("0123456789abcdef0123456789abcdef")
+	if err := cfg.SetEncryptionKey(key); err != nil {
+		t.Fatal(err)
+	}
+	enc, err := cfg.EncryptValue("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(enc, "ENC(") || !strings.HasSuffix(enc, ")") {
+		t.Fatalf("EncryptValue = %q, want ENC(...)", enc)
+	}
+
+	// 文件中的密文在读取时透明解密，保存时仍写出密文
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app.conf")
+	os.WriteFile(src, []byte("db.pass = "+enc+"\ndb.user = app\n"), 0o600)
+	if err := cfg.LoadFromFile(src); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("db.pass"); got != "hunter2" {
+		t.Errorf("Get(db.pass) = %q, want the plaintext", got)
+	}
+	if got, err := cfg.GetDecrypted("db.user"); err != nil || got != "app" {
+		t.Errorf("GetDecrypted of a plain value = %q, %v", got, err)
+	}
+	out := filepath.Join(dir, "out.conf")
+	if err := cfg.SaveToFile(out); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); !strings.Contains(string(data), enc) || strings.Contains(string(data), "hunter2") {
+		t.Errorf("saved file does not keep the ciphertext:\n%s", data)
+	}
+
+	// 密钥不匹配时Get返回原始密文，GetDecrypted返回错误
+	other, _ := NewConfig()
+	other.SetEncryptionKey([]byte("fedcba9876543210fedcba9876543210"))
+	other.Set("db.pass", enc)
+	if got := other.Get("db.pass"); got != enc {
+		t.Errorf("Get with the wrong key = %q, want the ciphertext", got)
+	}
+	if _, err := other.GetDecrypted("db.pass"); err == nil {
+		t.Error("GetDecrypted with the wrong key succeeded")
+	}
+	other.SetCipher(nil)
+	if _, err := other.GetDecrypted("db.pass"); !errors.Is(err, ErrNoCipher) {
+		t.Errorf("GetDecrypted without a cipher error = %v, want ErrNoCipher", err)
+	}
+	if _, err := other.GetDecrypted("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetDecrypted(missing) error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestCustomCipher(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.SetCipher(reverseCipher{})
+	if err := cfg.SetEncrypted("api.key", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if raw := cfg.LayerValues(LayerFile)["api.key"]; raw != "ENC(Y2Jh)" {
+		t.Errorf("stored value = %q, want ENC(base64 of the reversed bytes)", raw)
+	}
+	if got := cfg.Get("api.key"); got != "abc" {
+		t.Errorf("Get(api.key) = %q, want abc", got)
+	}
+	if err := cfg.SetEncrypted("", "x"); err == nil {
+		t.Error("SetEncrypted with an empty key succeeded")
+	}
+}
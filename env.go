@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// EnvMode 控制环境变量与已加载配置之间的优先级
+type EnvMode int
+
+const (
+	// EnvOverride 表示环境变量总是覆盖配置中的同名键(默认)
+	EnvOverride EnvMode = iota
+	// EnvFallback 表示仅当配置中不存在该键时才使用环境变量
+	EnvFallback
+)
+
+// envBinding 保存BindEnv的映射规则
+type envBinding struct {
+	prefix    string
+	separator string
+	mode      EnvMode
+}
+
+// EnvOption 用于定制BindEnv的行为
+type EnvOption func(*envBinding)
+
+// WithEnvSeparator 设置键中"."映射到环境变量名时使用的分隔符，默认为"_"
+// 例如分隔符为"__"时，server.port对应MYAPP_SERVER__PORT
+func WithEnvSeparator(sep string) EnvOption {
+	return func(b *envBinding) {
+		b.separator = sep
+	}
+}
+
+// WithEnvMode 设置环境变量的优先级，默认为EnvOverride
+func WithEnvMode(mode EnvMode) EnvOption {
+	return func(b *envBinding) {
+		b.mode = mode
+	}
+}
+
+// BindEnv 启用环境变量覆盖
+// 启用后Get等读取操作会查找与键对应的环境变量：键转为大写，
+// "."替换为分隔符，"-"替换为"_"，并加上"PREFIX_"前缀，
+// 例如prefix为"MYAPP"时server.port对应MYAPP_SERVER_PORT。
+// 环境变量在每次读取时实时查询，不会写入配置数据，
+// 因此SaveToFile不包含环境变量的值；GetAll只对已存在于某一层的键应用环境变量。
+// 重复调用会替换之前的绑定。
+// 参数:
+// - prefix: 环境变量名前缀，为空时不加前缀
+// - opts: 分隔符与优先级等可选设置
+func (c *Config) BindEnv(prefix string, opts ...EnvOption) {
+	binding := &envBinding{
+		prefix:    strings.ToUpper(strings.TrimSuffix(prefix, "_")),
+		separator: "_",
+		mode:      EnvOverride,
+	}
+	for _, opt := range opts {
+		opt(binding)
+	}
+
+	c.lock()
+	defer c.mutex.Unlock()
+	c.env = binding
+}
+
+// EnvName 返回键在当前绑定下对应的环境变量名，未调用BindEnv时返回空字符串
+// 参数:
+// - key: 配置键
+// 返回:
+// - string: 环境变量名
+func (c *Config) EnvName(key string) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.env == nil {
+		return ""
+	}
+	return c.env.name(key)
+}
+
+// name 将配置键映射为环境变量名
+func (b *envBinding) name(key string) string {
+	name := strings.ReplaceAll(key, ".", b.separator)
+	name = strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if b.prefix == "" {
+		return name
+	}
+	return b.prefix + "_" + name
+}
+
+// lookup 查找键对应的环境变量
+func (b *envBinding) lookup(key string) (string, bool) {
+	return os.LookupEnv(b.name(key))
+}
+
+// EnvProvider 把带前缀的环境变量作为Provider，可以与其它配置源一起通过AddProvider组合
+// 与BindEnv在读取时实时查询不同，EnvProvider在Load时读取一次环境变量并写入文件层
+type EnvProvider struct {
+	prefix string
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewEnvProvider 创建读取PREFIX_开头的环境变量的EnvProvider
+// 变量名去掉前缀后转为小写，"_"替换为"."，例如prefix为"MYAPP"时MYAPP_SERVER_PORT对应server.port；
+// prefix为空时读取全部环境变量
+// 参数:
+// - prefix: 环境变量名前缀
+// 返回:
+// - *EnvProvider: 配置源
+func NewEnvProvider(prefix string) *EnvProvider {
+	prefix = strings.ToUpper(strings.TrimSuffix(prefix, "_"))
+	if prefix != "" {
+		prefix += "_"
+	}
+	return &EnvProvider{prefix: prefix, done: make(chan struct{})}
+}
+
+// Load 读取当前进程的环境变量
+func (p *EnvProvider) Load() (map[string]string, error) {
+	values := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		rest, ok := strings.CutPrefix(name, p.prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		values[strings.ReplaceAll(strings.ToLower(rest), "_", ".")] = value
+	}
+	return values, nil
+}
+
+// Watch 环境变量在进程运行期间不会被外部修改，因此只阻塞到Close被调用
+func (p *EnvProvider) Watch(ch chan<- Update) error {
+	<-p.done
+	return nil
+}
+
+// Close 停止Watch，可重复调用
+func (p *EnvProvider) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
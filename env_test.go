@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestBindEnv(t *testing.T) {
+	t.Setenv("MYAPP_SERVER_PORT", "9090")
+	t.Setenv("MYAPP_LOG_MAX_SIZE", "10")
+	t.Setenv("MYAPP_DB__HOST", "db1")
+
+	cfg, _ := NewConfig()
+	cfg.Set("server.port", "8080")
+	cfg.Set("server.host", "localhost")
+	cfg.BindEnv("myapp_")
+
+	if got := cfg.EnvName("log.max-size"); got != "MYAPP_LOG_MAX_SIZE" {
+		t.Errorf("EnvName(log.max-size) = %q, want MYAPP_LOG_MAX_SIZE", got)
+	}
+	if got := cfg.Get("server.port"); got != "9090" {
+		t.Errorf("Get(server.port) = %q, want the environment value 9090", got)
+	}
+	if got := cfg.Get("server.host"); got != "localhost" {
+		t.Errorf("Get(server.host) = %q, want the stored value without a variable", got)
+	}
+	if n, err := cfg.GetInt("log.max-size"); err != nil || n != 10 {
+		t.Errorf("GetInt(log.max-size) = %d, %v, want 10 from the environment", n, err)
+	}
+	// 环境变量只在读取时查询，不写入文件层；GetAll只对已存在的键应用环境变量
+	if got := cfg.LayerValues(LayerFile)["server.port"]; got != "8080" {
+		t.Errorf("file layer server.port = %q, want the stored 8080", got)
+	}
+	all := cfg.GetAll()
+	if all["server.port"] != "9090" {
+		t.Errorf("GetAll()[server.port] = %q, want 9090", all["server.port"])
+	}
+	if _, ok := all["log.max-size"]; ok {
+		t.Error("GetAll() contains log.max-size, which exists only in the environment")
+	}
+
+	cfg.BindEnv("MYAPP", WithEnvSeparator("__"), WithEnvMode(EnvFallback))
+	if got := cfg.Get("db.host"); got != "db1" {
+		t.Errorf("Get(db.host) with separator __ = %q, want db1", got)
+	}
+	if got := cfg.Get("server.port"); got != "8080" {
+		t.Errorf("Get(server.port) in fallback mode = %q, want the stored 8080", got)
+	}
+
+	empty, _ := NewConfig()
+	if got := empty.EnvName("server.port"); got != "" {
+		t.Errorf("EnvName without BindEnv = %q, want empty", got)
+	}
+}
@@ -0,0 +1,452 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EtcdProvider 通过etcd v3的HTTP/JSON网关读取指定前缀下的键
+// 远程键去掉前缀后，其中的"/"替换为Delimiter，
+// 例如前缀"/myapp/"下的"/myapp/server/port"对应键"server.port"。
+type EtcdProvider struct {
+	// Endpoints 是etcd地址列表，如"http://127.0.0.1:2379"，按顺序尝试
+	Endpoints []string
+	// Prefix 是要读取的键前缀
+	Prefix string
+	// Delimiter 替换远程键中的"/"，为空时保留"/"
+	Delimiter string
+	// Username和Password 非空时先通过/v3/auth/authenticate获取令牌
+	Username string
+	Password string
+	// Client 用于发送请求，为nil时使用http.DefaultClient
+	Client *http.Client
+	// RetryInterval 是Watch连接断开后的重试间隔
+	RetryInterval time.Duration
+
+	mutex    sync.Mutex
+	revision int64
+	token    string
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewEtcdProvider 创建读取prefix下键的etcd配置源
+// 参数:
+// - endpoints: etcd地址列表
+// - prefix: 键前缀
+// 返回:
+// - *EtcdProvider: 配置源实例
+func NewEtcdProvider(endpoints []string, prefix string) *EtcdProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &EtcdProvider{
+		Endpoints:     endpoints,
+		Prefix:        prefix,
+		Delimiter:     ".",
+		RetryInterval: time.Second,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// LoadFromEtcd 从etcd加载prefix下的所有键并合并到文件层
+// 需要持续同步时可使用WatchProvider(NewEtcdProvider(endpoints, prefix))
+// 参数:
+// - endpoints: etcd地址列表
+// - prefix: 键前缀
+// 返回:
+// - error: 请求或解析错误(如果有)
+func (c *Config) LoadFromEtcd(endpoints []string, prefix string) error {
+	p := NewEtcdProvider(endpoints, prefix)
+	defer p.Close()
+	return c.LoadFromProvider(p)
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *EtcdProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// etcdKV 是etcd返回的键值对，键和值均为base64编码
+type etcdKV struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	ModRevision string `json:"mod_revision"`
+}
+
+type etcdHeader struct {
+	Revision string `json:"revision"`
+}
+
+// Load 读取前缀下的全部键
+func (p *EtcdProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时中止请求
+func (p *EtcdProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	values, _, err := p.load(ctx)
+	return values, err
+}
+
+// load 读取前缀下的全部键并返回对应的etcd修订号
+func (p *EtcdProvider) load(ctx context.Context) (map[string]string, int64, error) {
+	req := map[string]string{
+		"key":       base64.StdEncoding.EncodeToString(etcdRangeKey(p.Prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(etcdPrefixEnd(p.Prefix)),
+	}
+	var resp struct {
+		Header etcdHeader `json:"header"`
+		Kvs    []etcdKV   `json:"kvs"`
+	}
+	if err := p.post(ctx, "/v3/kv/range", req, &resp); err != nil {
+		return nil, 0, err
+	}
+
+	values := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		key, value, err := p.decodeKV(kv)
+		if err != nil {
+			return nil, 0, err
+		}
+		if key != "" {
+			values[key] = value
+		}
+	}
+	rev, _ := strconv.ParseInt(resp.Header.Revision, 10, 64)
+	p.mutex.Lock()
+	p.revision = rev
+	p.mutex.Unlock()
+	return values, rev, nil
+}
+
+// Watch 通过etcd watch流监听前缀下的变化
+// 连接断开时按RetryInterval重连，并从上次看到的修订号继续；
+// 该修订号已被压缩(compaction)时重新读取前缀下的全部键，发送完整数据后从新的修订号继续
+func (p *EtcdProvider) Watch(ch chan<- Update) error {
+	current, _, err := p.load(p.ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		err := p.watchOnce(current, ch)
+		if p.ctx.Err() != nil {
+			return ErrProviderClosed
+		}
+		if err != nil {
+			select {
+			case ch <- Update{Err: err}:
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+		}
+		select {
+		case <-time.After(p.RetryInterval):
+		case <-p.ctx.Done():
+			return ErrProviderClosed
+		}
+	}
+}
+
+// watchOnce 建立一次watch流并处理事件，current在事件到达时被更新
+func (p *EtcdProvider) watchOnce(current map[string]string, ch chan<- Update) error {
+	p.mutex.Lock()
+	start := p.revision + 1
+	p.mutex.Unlock()
+
+	req := map[string]interface{}{
+		"create_request": map[string]interface{}{
+			"key":            base64.StdEncoding.EncodeToString(etcdRangeKey(p.Prefix)),
+			"range_end":      base64.StdEncoding.EncodeToString(etcdPrefixEnd(p.Prefix)),
+			"start_revision": strconv.FormatInt(start, 10),
+		},
+	}
+	body, err := p.open(p.ctx, "/v3/watch", req)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	for {
+		var msg struct {
+			Result struct {
+				Header          etcdHeader `json:"header"`
+				Canceled        bool       `json:"canceled"`
+				CompactRevision string     `json:"compact_revision"`
+				Events          []struct {
+					Type string `json:"type"`
+					Kv   etcdKV `json:"kv"`
+				} `json:"events"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return errors.New("etcd: watch stream closed")
+			}
+			return fmt.Errorf("etcd: watch: %w", err)
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("etcd: watch: %s", msg.Error.Message)
+		}
+		if msg.Result.Canceled {
+			if compacted, _ := strconv.ParseInt(msg.Result.CompactRevision, 10, 64); compacted > 0 {
+				return p.reloadCompacted(current, ch)
+			}
+			return errors.New("etcd: watch canceled by server")
+		}
+		if len(msg.Result.Events) == 0 {
+			continue
+		}
+
+		for _, ev := range msg.Result.Events {
+			key, value, err := p.decodeKV(ev.Kv)
+			if err != nil {
+				return err
+			}
+			if key == "" {
+				continue
+			}
+			if ev.Type == "DELETE" {
+				delete(current, key)
+			} else {
+				current[key] = value
+			}
+			if rev, err := strconv.ParseInt(ev.Kv.ModRevision, 10, 64); err == nil {
+				p.mutex.Lock()
+				if rev > p.revision {
+					p.revision = rev
+				}
+				p.mutex.Unlock()
+			}
+		}
+
+		snapshot := make(map[string]string, len(current))
+		for k, v := range current {
+			snapshot[k] = v
+		}
+		select {
+		case ch <- Update{Values: snapshot}:
+		case <-p.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// reloadCompacted 在watch的起始修订号已被压缩时重新读取全部键，替换current并发送完整数据
+// load同时把p.revision更新为当前修订号，下一次watchOnce从它之后开始
+func (p *EtcdProvider) reloadCompacted(current map[string]string, ch chan<- Update) error {
+	values, _, err := p.load(p.ctx)
+	if err != nil {
+		return err
+	}
+	clear(current)
+	for k, v := range values {
+		current[k] = v
+	}
+	select {
+	case ch <- Update{Values: values}:
+	case <-p.ctx.Done():
+	}
+	return nil
+}
+
+// Put 把配置键写入etcd，键名按Load相反的规则映射为前缀下的远程键
+func (p *EtcdProvider) Put(key, value string) error {
+	req := map[string]string{
+		"key":   base64.StdEncoding.EncodeToString([]byte(remotePath(key, p.Prefix, p.Delimiter, "/"))),
+		"value": base64.StdEncoding.EncodeToString([]byte(value)),
+	}
+	var resp struct{}
+	return p.post(p.ctx, "/v3/kv/put", req, &resp)
+}
+
+// Delete 从etcd删除配置键对应的远程键
+func (p *EtcdProvider) Delete(key string) error {
+	req := map[string]string{
+		"key": base64.StdEncoding.EncodeToString([]byte(remotePath(key, p.Prefix, p.Delimiter, "/"))),
+	}
+	var resp struct{}
+	return p.post(p.ctx, "/v3/kv/deleterange", req, &resp)
+}
+
+// decodeKV 解码etcd键值并将远程键映射为配置键
+func (p *EtcdProvider) decodeKV(kv etcdKV) (string, string, error) {
+	rawKey, err := base64.StdEncoding.DecodeString(kv.Key)
+	if err != nil {
+		return "", "", fmt.Errorf("etcd: invalid key encoding: %w", err)
+	}
+	rawValue, err := base64.StdEncoding.DecodeString(kv.Value)
+	if err != nil {
+		return "", "", fmt.Errorf("etcd: invalid value encoding for %q: %w", rawKey, err)
+	}
+	return remoteKey(string(rawKey), p.Prefix, p.Delimiter), string(rawValue), nil
+}
+
+// post 向可用的端点发送JSON请求并解码响应
+func (p *EtcdProvider) post(ctx context.Context, path string, req, resp interface{}) error {
+	body, err := p.open(ctx, path, req)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(resp); err != nil {
+		return fmt.Errorf("etcd: decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+// open 依次尝试各端点发送请求，返回第一个成功响应的body
+func (p *EtcdProvider) open(ctx context.Context, path string, req interface{}) (io.ReadCloser, error) {
+	if len(p.Endpoints) == 0 {
+		return nil, errors.New("etcd: no endpoints configured")
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, endpoint := range p.Endpoints {
+		body, err := p.send(ctx, endpoint, path, payload)
+		if errors.Is(err, errEtcdUnauthorized) {
+			// 令牌过期或被吊销时清除缓存，重新认证后再试一次
+			p.mutex.Lock()
+			p.token = ""
+			p.mutex.Unlock()
+			body, err = p.send(ctx, endpoint, path, payload)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return body, nil
+	}
+	return nil, lastErr
+}
+
+// errEtcdUnauthorized 表示携带的令牌被服务端拒绝
+var errEtcdUnauthorized = errors.New("etcd: unauthorized")
+
+// send 向单个端点发送一次请求，携带令牌的请求被拒绝(401)时返回包装了errEtcdUnauthorized的错误
+func (p *EtcdProvider) send(ctx context.Context, endpoint, path string, payload []byte) (io.ReadCloser, error) {
+	token, err := p.authToken(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(endpoint, "/")+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", token)
+	}
+	resp, err := p.client().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("etcd: %s: %w", endpoint, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		err := fmt.Errorf("etcd: %s%s: %s: %s", endpoint, path, resp.Status, bytes.TrimSpace(msg))
+		if resp.StatusCode == http.StatusUnauthorized && token != "" {
+			err = fmt.Errorf("%w: %w", errEtcdUnauthorized, err)
+		}
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// authToken 在配置了用户名时获取并缓存认证令牌，令牌被拒绝时由open清除缓存
+func (p *EtcdProvider) authToken(ctx context.Context, endpoint string) (string, error) {
+	if p.Username == "" {
+		return "", nil
+	}
+	p.mutex.Lock()
+	token := p.token
+	p.mutex.Unlock()
+	if token != "" {
+		return token, nil
+	}
+
+	payload, _ := json.Marshal(map[string]string{"name": p.Username, "password": p.Password})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(endpoint, "/")+"/v3/auth/authenticate", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("etcd: authenticate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("etcd: authenticate: %s", resp.Status)
+	}
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("etcd: authenticate: %w", err)
+	}
+	p.mutex.Lock()
+	p.token = result.Token
+	p.mutex.Unlock()
+	return result.Token, nil
+}
+
+func (p *EtcdProvider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return http.DefaultClient
+}
+
+// etcdRangeKey 返回前缀范围查询的起始键，空前缀对应"\x00"
+func etcdRangeKey(prefix string) []byte {
+	if prefix == "" {
+		return []byte{0}
+	}
+	return []byte(prefix)
+}
+
+// etcdPrefixEnd 计算前缀范围查询的range_end
+func etcdPrefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// 前缀为空或全为0xff时查询所有键
+	return []byte{0}
+}
+
+// remoteKey 去掉远程键的前缀，并把其中的"/"替换为delimiter
+func remoteKey(raw, prefix, delimiter string) string {
+	key := strings.TrimPrefix(raw, prefix)
+	key = strings.Trim(key, "/")
+	if delimiter != "" && delimiter != "/" {
+		key = strings.ReplaceAll(key, "/", delimiter)
+	}
+	return key
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEtcd 模拟etcd v3 HTTP/JSON网关中EtcdProvider用到的接口
+type fakeEtcd struct {
+	mutex    sync.Mutex
+	kvs      map[string]string
+	revision int64
+	password string
+	token    string // 当前有效的令牌，为空表示不要求认证
+	auths    int
+	watches  chan map[string]interface{} // 每个watch请求的create_request
+	stream   chan string                 // 写入当前watch流的消息
+}
+
+func newFakeEtcd(t *testing.T) (*fakeEtcd, *httptest.Server) {
+	f := &fakeEtcd{kvs: map[string]string{}, watches: make(chan map[string]interface{}, 4), stream: make(chan string, 8)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v3/auth/authenticate" {
+		var req struct{ Name, Password string }
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		if req.Password != f.password {
+			http.Error(w, "authentication failed", http.StatusBadRequest)
+			return
+		}
+		f.auths++
+		f.token = "tok-" + strconv.Itoa(f.auths)
+		json.NewEncoder(w).Encode(map[string]string{"token": f.token})
+		return
+	}
+	f.mutex.Lock()
+	if f.password != "" && r.Header.Get("Authorization") != f.token {
+		f.mutex.Unlock()
+		http.Error(w, `{"error":"etcdserver: invalid auth token"}`, http.StatusUnauthorized)
+		return
+	}
+	f.mutex.Unlock()
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		f.mutex.Lock()
+		var kvs []etcdKV
+		for k, v := range f.kvs {
+			kvs = append(kvs, etcdKV{Key: b64(k), Value: b64(v)})
+		}
+		rev := f.revision
+		f.mutex.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"header": etcdHeader{Revision: strconv.FormatInt(rev, 10)},
+			"kvs":    kvs,
+		})
+	case "/v3/watch":
+		var req struct {
+			Create map[string]interface{} `json:"create_request"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.watches <- req.Create
+		for {
+			select {
+			case msg := <-f.stream:
+				fmt.Fprintln(w, msg)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// set 修改服务端数据并推进修订号
+func (f *fakeEtcd) set(kvs map[string]string, revision int64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.kvs = kvs
+	f.revision = revision
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// etcdEvent 返回watch流中的一条事件消息
+func etcdEvent(typ, key, value string, rev int) string {
+	return fmt.Sprintf(`{"result":{"header":{"revision":"%d"},"events":[{"type":%q,"kv":{"key":%q,"value":%q,"mod_revision":"%d"}}]}}`,
+		rev, typ, b64(key), b64(value), rev)
+}
+
+func TestEtcdAuthTokenRefresh(t *testing.T) {
+	f, srv := newFakeEtcd(t)
+	f.password = "s3cret"
+	f.set(map[string]string{"/app/a": "1"}, 3)
+
+	p := NewEtcdProvider([]string{srv.URL}, "/app/")
+	defer p.Close()
+	p.Username, p.Password = "root", "s3cret"
+	for i := 0; i < 2; i++ {
+		values, err := p.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values["a"] != "1" {
+			t.Errorf("Load = %v", values)
+		}
+	}
+	if f.auths != 1 {
+		t.Errorf("authenticated %d times for two loads, want 1", f.auths)
+	}
+
+	// 服务端吊销令牌后，下一次请求得到401，重新认证并重试
+	f.mutex.Lock()
+	f.token = "revoked"
+	f.mutex.Unlock()
+	if _, err := p.Load(); err != nil {
+		t.Fatalf("Load after token revocation: %v", err)
+	}
+	if f.auths != 2 {
+		t.Errorf("authenticated %d times after revocation, want 2", f.auths)
+	}
+
+	p.Password = "wrong"
+	p.token = ""
+	if _, err := p.Load(); err == nil {
+		t.Error("Load with a wrong password succeeded")
+	}
+}
+
+func TestEtcdWatchEventsAndCompaction(t *testing.T) {
+	f, srv := newFakeEtcd(t)
+	f.set(map[string]string{"/app/a": "1", "/app/b": "2"}, 5)
+
+	p := NewEtcdProvider([]string{srv.URL}, "/app/")
+	p.RetryInterval = 5 * time.Millisecond
+	ch := make(chan Update, 8)
+	done := make(chan error, 1)
+	go func() { done <- p.Watch(ch) }()
+	defer func() {
+		p.Close()
+		<-done
+	}()
+
+	next := func() map[string]string {
+		t.Helper()
+		select {
+		case u := <-ch:
+			if u.Err != nil {
+				t.Fatalf("update error: %v", u.Err)
+			}
+			return u.Values
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for update")
+			return nil
+		}
+	}
+	watchStart := func() string {
+		t.Helper()
+		select {
+		case req := <-f.watches:
+			return req["start_revision"].(string)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for watch request")
+			return ""
+		}
+	}
+
+	if got := watchStart(); got != "6" {
+		t.Errorf("first watch start_revision = %s, want 6", got)
+	}
+	f.stream <- `{"result":{"header":{"revision":"5"},"created":true}}`
+	f.stream <- etcdEvent("PUT", "/app/c", "3", 6)
+	if got, want := next(), map[string]string{"a": "1", "b": "2", "c": "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after PUT = %v, want %v", got, want)
+	}
+	f.stream <- etcdEvent("DELETE", "/app/a", "", 7)
+	if got, want := next(), map[string]string{"b": "2", "c": "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after DELETE = %v, want %v", got, want)
+	}
+	// 压缩通知到达前服务端数据已变化，重新读取应得到完整的新数据
+	f.set(map[string]string{"/app/x": "9"}, 30)
+	f.stream <- `{"result":{"header":{"revision":"30"},"canceled":true,"compact_revision":"20"}}`
+	if got, want := next(), map[string]string{"x": "9"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after compaction = %v, want %v", got, want)
+	}
+	if got := watchStart(); got != "31" {
+		t.Errorf("watch after compaction start_revision = %s, want 31", got)
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ToJSON 以嵌套的JSON对象返回所有生效值，适合在调试接口中展示服务的实际配置
+// 键按层级分隔符与"[i]"下标还原为对象与数组，敏感键(判断规则见IsSecret，包括ENC(...)加密的值)的值以"****"代替。
+// 以WithTypedValues创建时叶子的类型与GetValue一致：来自JSON、YAML与TOML文件且未被修改的值保留原始类型，
+// 其余写为字符串；否则值为数字或布尔字面量时写为对应类型，其余写为字符串，规则与SaveAs相同
+// 参数:
+// - indent: 为true时以两个空格缩进
+// 返回:
+// - []byte: JSON文档
+// - error: 同一路径既是叶子又是对象/数组(如同时存在"a"与"a.b")时返回冲突错误
+func (c *Config) ToJSON(indent bool) ([]byte, error) {
+	tree, err := c.nestedValues()
+	if err != nil {
+		return nil, err
+	}
+	if indent {
+		return json.MarshalIndent(tree, "", "  ")
+	}
+	return json.Marshal(tree)
+}
+
+// ToYAML 以嵌套的YAML文档返回所有生效值，值的处理规则与ToJSON相同
+// 返回:
+// - []byte: YAML文档
+// - error: 同一路径既是叶子又是对象/数组时返回冲突错误
+func (c *Config) ToYAML() ([]byte, error) {
+	tree, err := c.nestedValues()
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	writeYAMLMap(&b, tree, 0, false)
+	return []byte(b.String()), nil
+}
+
+// nestedValues 将隐藏敏感值之后的生效值还原为嵌套结构
+func (c *Config) nestedValues() (map[string]interface{}, error) {
+	all := c.GetAll()
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	var root interface{} = map[string]interface{}{}
+	for _, key := range sortedKeys(all) {
+		segs, err := parseKeyPathSep(key, c.sep())
+		if err != nil {
+			return nil, err
+		}
+		leaf := inferScalar(all[key])
+		if c.typedValues {
+			t, typed := c.types[key]
+			leaf = typedLeaf(all[key], t, typed)
+		}
+		if root, err = insertPath(root, segs, leaf); err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	return root.(map[string]interface{}), nil
+}
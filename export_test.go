@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportMasksSecrets(t *testing.T) {
+	for _, typed := range []bool{false, true} {
+		var opts []ConfigOption
+		if typed {
+			opts = append(opts, WithTypedValues())
+		}
+		cfg, err := NewConfigWithOptions(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.SetEncryptionKey([]byte("0123456789abcdef"))
+		if err := cfg.SetEncrypted("db.pass", "hunter2"); err != nil {
+			t.Fatal(err)
+		}
+		cfg.Set("db.port", "5432")
+		cfg.Set("auth.token", "t-456")
+
+		data, err := cfg.ToJSON(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]map[string]interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got["db"]["pass"] != maskedValue || got["auth"]["token"] != maskedValue {
+			t.Errorf("typed=%v: ToJSON = %s, want secrets masked", typed, data)
+		}
+		if !typed && got["db"]["port"] != float64(5432) {
+			t.Errorf("typed=%v: db.port = %v, want 5432", typed, got["db"]["port"])
+		}
+
+		yaml, err := cfg.ToYAML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{"hunter2", "t-456", "ENC("} {
+			if strings.Contains(string(yaml), secret) {
+				t.Errorf("typed=%v: ToYAML contains %q:\n%s", typed, secret, yaml)
+			}
+		}
+	}
+}
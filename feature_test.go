@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestIsEnabled(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("feature.on", "yes")
+	cfg.Set("feature.off", "off")
+	cfg.Set("feature.all", "100%")
+	cfg.Set("feature.none", " 0 % ")
+	cfg.Set("feature.bad", "maybe")
+	cfg.Set("feature.over", "150%")
+
+	tests := map[string]bool{
+		"feature.on":      true,
+		"feature.off":     false,
+		"feature.all":     true,
+		"feature.none":    false,
+		"feature.bad":     false,
+		"feature.over":    false,
+		"feature.missing": false,
+	}
+	for key, want := range tests {
+		if got := cfg.IsEnabled(key); got != want {
+			t.Errorf("IsEnabled(%s) = %v, want %v", key, got, want)
+		}
+		if got := cfg.IsEnabledFor(key, "user-1"); got != want {
+			t.Errorf("IsEnabledFor(%s) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestIsEnabledFor(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("feature.x", "30%")
+	cfg.Set("feature.y", "30%")
+
+	enabled := make(map[string]bool)
+	same := 0
+	for i := 0; i < 2000; i++ {
+		id := "user-" + strconv.Itoa(i)
+		on := cfg.IsEnabledFor("feature.x", id)
+		// 同一对象的结果稳定
+		if cfg.IsEnabledFor("feature.x", id) != on {
+			t.Fatalf("IsEnabledFor(feature.x, %s) is not stable", id)
+		}
+		if on {
+			enabled[id] = true
+		}
+		if cfg.IsEnabledFor("feature.y", id) == on {
+			same++
+		}
+	}
+	if n := len(enabled); n < 500 || n > 700 {
+		t.Errorf("30%% rollout enabled %d of 2000 ids", n)
+	}
+	// 不同开关的分组相互独立
+	if same > 1700 {
+		t.Errorf("feature.x and feature.y agree for %d of 2000 ids, want independent buckets", same)
+	}
+
+	// 调高比例时已开启的对象保持开启
+	cfg.Set("feature.x", "60%")
+	for id := range enabled {
+		if !cfg.IsEnabledFor("feature.x", id) {
+			t.Errorf("%s disabled after raising the rollout", id)
+		}
+	}
+}
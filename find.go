@@ -0,0 +1,55 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Match 是Find找到的一个键
+type Match struct {
+	// Key 是完整键名
+	Key string
+	// Value 是生效值，敏感键为"****"
+	Value string
+	// Source 是生效值的来源
+	Source KeySource
+	// InKey 表示键名匹配
+	InKey bool
+	// InValue 表示值匹配
+	InValue bool
+}
+
+// String 返回"key = value"形式的描述，值不是通过API写入时附带来源，如"db.host = 10.0.0.5 (app.ini:12)"
+func (m Match) String() string {
+	return m.Key + " = " + m.Value + sourceSuffix(m.Source)
+}
+
+// Find 在所有生效的键名与值中搜索正则表达式，返回匹配的键及其来源
+// 用于在大型配置中回答"这个主机是在哪里配置的"之类的问题，例如Find(`10\.0\.0\.5`)。
+// 值的处理方式与GetAll一致，敏感键(判断规则见IsSecret)只按键名搜索，结果中的值以"****"代替
+// 参数:
+// - pattern: regexp包语法的正则表达式，在键名或值中任意位置匹配即可
+// 返回:
+// - []Match: 按键名排序的匹配结果；没有匹配时为空切片
+// - error: 正则表达式不合法时返回错误
+func (c *Config) Find(pattern string) ([]Match, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	all := c.GetAll()
+	matches := []Match{}
+	c.mutex.RLock()
+	for key, val := range all {
+		inKey := re.MatchString(key)
+		inValue := !c.isSecretLocked(key) && re.MatchString(val)
+		if !inKey && !inValue {
+			continue
+		}
+		src, _ := c.sourceLocked(key)
+		matches = append(matches, Match{Key: key, Value: val, Source: src, InKey: inKey, InValue: inValue})
+	}
+	c.mutex.RUnlock()
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Key < matches[j].Key })
+	return matches, nil
+}
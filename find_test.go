@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	os.WriteFile(path, []byte("db.host = 10.0.0.5\ncache.addr = 10.0.0.5:6379\n"), 0o644)
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("backup.host", "10.0.0.6")
+	cfg.Set("db.password", "10.0.0.5") // 敏感键只按键名搜索
+
+	matches, err := cfg.Find(`10\.0\.0\.5`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Key != "cache.addr" || matches[1].Key != "db.host" {
+		t.Fatalf("Find(10.0.0.5) = %v, want cache.addr and db.host", matches)
+	}
+	m := matches[1]
+	if !m.InValue || m.InKey || m.Source.Kind != SourceFile || m.Source.Line != 1 {
+		t.Errorf("db.host match = %+v", m)
+	}
+	if got, want := m.String(), "db.host = 10.0.0.5 ("+path+":1)"; got != want {
+		t.Errorf("Match.String() = %q, want %q", got, want)
+	}
+
+	matches, _ = cfg.Find(`^db\.`)
+	if len(matches) != 2 || matches[1].Key != "db.password" || matches[1].Value != "****" || !matches[1].InKey || matches[1].InValue {
+		t.Errorf("Find(^db.) = %+v, want the password matched by key and masked", matches)
+	}
+	if got := matches[1].String(); got != "db.password = ****" {
+		t.Errorf("Match.String() for an API value = %q", got)
+	}
+
+	if matches, _ := cfg.Find("nowhere"); matches == nil || len(matches) != 0 {
+		t.Errorf("Find(nowhere) = %#v, want an empty slice", matches)
+	}
+	if _, err := cfg.Find("("); err == nil {
+		t.Error("Find with an invalid pattern succeeded")
+	}
+}
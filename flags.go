@@ -0,0 +1,79 @@
+package config
+
+import "flag"
+
+// FlagValue 描述一个命令行参数，用于绑定flag包以外的参数库
+type FlagValue struct {
+	// Name 是参数名，同时作为配置键
+	Name string
+	// Value 是参数的当前值
+	Value string
+	// DefValue 是参数的默认值
+	DefValue string
+	// Changed 表示参数是否在命令行中显式给出
+	Changed bool
+}
+
+// BindFlags 将解析后的命令行参数绑定到配置
+// 参数名直接作为配置键，如"-server.port=9090"对应"server.port"。
+// 所有参数的默认值写入默认值层，命令行中显式给出的参数写入覆盖层，
+// 由此得到"命令行 > 环境变量 > 文件 > 默认值"的优先级。
+// 应在fs.Parse之后调用；之后再次解析需要重新调用。
+// 参数:
+// - fs: 已解析的参数集合，如flag.CommandLine
+// 返回:
+// - error: 配置已冻结时返回ErrFrozen
+func (c *Config) BindFlags(fs *flag.FlagSet) error {
+	changed := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		changed[f.Name] = true
+	})
+	var flags []FlagValue
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, FlagValue{
+			Name:     f.Name,
+			Value:    f.Value.String(),
+			DefValue: f.DefValue,
+			Changed:  changed[f.Name],
+		})
+	})
+	return c.BindFlagValues(flags)
+}
+
+// BindFlagValues 与BindFlags相同，用于github.com/spf13/pflag等其它参数库
+// 示例(pflag):
+//
+//	var flags []config.FlagValue
+//	pflag.CommandLine.VisitAll(func(f *pflag.Flag) {
+//		flags = append(flags, config.FlagValue{
+//			Name: f.Name, Value: f.Value.String(), DefValue: f.DefValue, Changed: f.Changed,
+//		})
+//	})
+//	cfg.BindFlagValues(flags)
+//
+// 参数:
+// - flags: 参数列表，Name为空的项被忽略
+// 返回:
+// - error: 配置已冻结时返回ErrFrozen
+func (c *Config) BindFlagValues(flags []FlagValue) error {
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	var changes []change
+	for _, f := range flags {
+		if f.Name == "" {
+			continue
+		}
+		src := KeySource{Kind: SourceFlag, Name: f.Name}
+		changes = c.writeLayerLocked(LayerDefault, f.Name, f.DefValue, false, changes)
+		c.setSourceLocked(LayerDefault, f.Name, src)
+		if f.Changed {
+			changes = c.writeLayerLocked(LayerOverride, f.Name, f.Value, false, changes)
+			c.setSourceLocked(LayerOverride, f.Name, src)
+		}
+	}
+	c.unlockNotify(changes)
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestBindFlags(t *testing.T) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Int("server.port", 8080, "listen port")
+	fs.String("log.level", "info", "log level")
+	fs.Bool("debug", false, "debug mode")
+	if err := fs.Parse([]string{"-server.port=9090", "-debug"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _ := NewConfig()
+	cfg.Set("server.port", "7070")
+	cfg.Set("log.level", "warn")
+	if err := cfg.MergeLayer(LayerEnv, map[string]string{"debug": "false"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.BindFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	// 显式给出的参数覆盖文件与环境变量，未给出的只提供默认值
+	for key, want := range map[string]string{"server.port": "9090", "debug": "true", "log.level": "warn"} {
+		if got := cfg.Get(key); got != want {
+			t.Errorf("Get(%s) = %q, want %q", key, got, want)
+		}
+	}
+	if got := cfg.LayerValues(LayerDefault)["server.port"]; got != "8080" {
+		t.Errorf("default layer server.port = %q, want the flag default", got)
+	}
+	cfg.Delete("log.level")
+	if got := cfg.Get("log.level"); got != "info" {
+		t.Errorf("log.level without a file value = %q, want the flag default", got)
+	}
+	if src, ok := cfg.Source("server.port"); !ok || src.Kind != SourceFlag || src.Name != "server.port" {
+		t.Errorf("Source(server.port) = %+v, want the flag", src)
+	}
+}
+
+func TestBindFlagValues(t *testing.T) {
+	cfg, _ := NewConfig()
+	err := cfg.BindFlagValues([]FlagValue{
+		{Name: "port", Value: "9090", DefValue: "80", Changed: true},
+		{Name: "host", Value: "0.0.0.0", DefValue: "0.0.0.0"},
+		{Value: "ignored"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Get("port") != "9090" || cfg.Get("host") != "0.0.0.0" || cfg.Len() != 2 {
+		t.Errorf("bound values = %v", cfg.GetAll())
+	}
+
+	cfg.Freeze()
+	if err := cfg.BindFlagValues([]FlagValue{{Name: "port", Value: "1", Changed: true}}); !errors.Is(err, ErrFrozen) {
+		t.Errorf("BindFlagValues on a frozen config error = %v, want ErrFrozen", err)
+	}
+}
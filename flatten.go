@@ -0,0 +1,233 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathSegment 表示键路径中的一段：对象字段名或数组下标
+type pathSegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// Flatten 将嵌套结构展开为本包使用的扁平键值对，便于与viper、koanf或JSON接口交换数据
+// 对象字段以"."连接，数组元素使用"[i]"下标，如{"servers": [{"host": "a"}]}展开为"servers[0].host"=a；
+// 空对象与空数组展开为空字符串。除map[string]interface{}与[]interface{}外，
+// 也接受map[interface{}]interface{}、map[string]string、[]string等其它map与切片类型，键按fmt.Sprint转为字符串
+// 参数:
+// - m: 嵌套结构，如json.Unmarshal得到的map
+// 返回:
+// - map[string]string: 扁平键值对
+func Flatten(m map[string]interface{}) map[string]string {
+	out := make(map[string]string)
+	flattenValue("", m, out)
+	return out
+}
+
+// Nest 将扁平键值对还原为嵌套结构，是Flatten的逆操作
+// 值为数字或布尔字面量时还原为json.Number或bool，其余保持字符串；结果可以直接交给json.Marshal
+// 参数:
+// - m: "servers[0].host"形式的扁平键值对
+// 返回:
+// - map[string]interface{}: 嵌套结构
+// - error: 键的下标不合法，或同一路径既是叶子又是对象/数组时返回错误
+func Nest(m map[string]string) (map[string]interface{}, error) {
+	return nestValues(m)
+}
+
+// flattenValue 将嵌套结构展开为扁平键值对
+// 对象字段以"."连接，数组元素使用"[i]"下标，如"servers[0].host"
+func flattenValue(prefix string, value interface{}, out map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && prefix != "" {
+			out[prefix] = ""
+		}
+		for k, child := range v {
+			flattenValue(joinKey(prefix, k), child, out)
+		}
+	case []interface{}:
+		if len(v) == 0 && prefix != "" {
+			out[prefix] = ""
+		}
+		for i, child := range v {
+			flattenValue(prefix+"["+strconv.Itoa(i)+"]", child, out)
+		}
+	default:
+		rv := reflect.ValueOf(v)
+		switch {
+		case rv.Kind() == reflect.Map:
+			if rv.Len() == 0 && prefix != "" {
+				out[prefix] = ""
+			}
+			iter := rv.MapRange()
+			for iter.Next() {
+				flattenValue(joinKey(prefix, fmt.Sprint(iter.Key().Interface())), iter.Value().Interface(), out)
+			}
+		case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8:
+			if rv.Len() == 0 && prefix != "" {
+				out[prefix] = ""
+			}
+			for i := 0; i < rv.Len(); i++ {
+				flattenValue(prefix+"["+strconv.Itoa(i)+"]", rv.Index(i).Interface(), out)
+			}
+		case prefix != "":
+			out[prefix] = formatScalar(v)
+		}
+	}
+}
+
+// formatScalar 将解析得到的标量值格式化为配置字符串
+func formatScalar(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	case json.Number:
+		return s.String()
+	case bool:
+		return strconv.FormatBool(s)
+	case int:
+		return strconv.Itoa(s)
+	case int64:
+		return strconv.FormatInt(s, 10)
+	case float64:
+		return strconv.FormatFloat(s, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// nestValues 将扁平键值对还原为嵌套结构
+// 值为数字或布尔字面量时还原为对应类型，其余保持字符串
+// 返回:
+// - map[string]interface{}: 嵌套结构
+// - error: 同一路径既是叶子又是对象/数组时返回冲突错误
+func nestValues(data map[string]string) (map[string]interface{}, error) {
+	var root interface{} = map[string]interface{}{}
+	for _, key := range sortedKeys(data) {
+		segs, err := parseKeyPath(key)
+		if err != nil {
+			return nil, err
+		}
+		root, err = insertPath(root, segs, inferScalar(data[key]))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	return root.(map[string]interface{}), nil
+}
+
+// insertPath 沿路径把叶子值插入树中，返回更新后的节点
+func insertPath(node interface{}, segs []pathSegment, value interface{}) (interface{}, error) {
+	if len(segs) == 0 {
+		if node != nil {
+			return nil, fmt.Errorf("conflicts with nested keys")
+		}
+		return value, nil
+	}
+
+	seg := segs[0]
+	if seg.isIndex {
+		var arr []interface{}
+		if node != nil {
+			var ok bool
+			if arr, ok = node.([]interface{}); !ok {
+				return nil, fmt.Errorf("conflicts with non-array value")
+			}
+		}
+		for len(arr) <= seg.index {
+			arr = append(arr, nil)
+		}
+		child, err := insertPath(arr[seg.index], segs[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		arr[seg.index] = child
+		return arr, nil
+	}
+
+	var m map[string]interface{}
+	if node != nil {
+		var ok bool
+		if m, ok = node.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("conflicts with non-object value")
+		}
+	} else {
+		m = make(map[string]interface{})
+	}
+	child, err := insertPath(m[seg.name], segs[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	m[seg.name] = child
+	return m, nil
+}
+
+// parseKeyPath 将"servers[0].host"形式的键解析为路径段
+func parseKeyPath(key string) ([]pathSegment, error) {
+	return parseKeyPathSep(key, ".")
+}
+
+// parseKeyPathSep 与parseKeyPath相同，以sep分隔对象字段
+func parseKeyPathSep(key, sep string) ([]pathSegment, error) {
+	var segs []pathSegment
+	for _, part := range strings.Split(key, sep) {
+		name := part
+		var indexes string
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name, indexes = part[:i], part[i:]
+		}
+		if name != "" {
+			segs = append(segs, pathSegment{name: name})
+		}
+		for indexes != "" {
+			end := strings.IndexByte(indexes, ']')
+			if indexes[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("key %q: malformed index", key)
+			}
+			n, err := strconv.Atoi(indexes[1:end])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("key %q: invalid index %q", key, indexes[1:end])
+			}
+			segs = append(segs, pathSegment{index: n, isIndex: true})
+			indexes = indexes[end+1:]
+		}
+		if name == "" && len(segs) == 0 {
+			return nil, fmt.Errorf("key %q: empty path segment", key)
+		}
+	}
+	return segs, nil
+}
+
+// inferScalar 将配置字符串还原为JSON数字、布尔值或字符串
+func inferScalar(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if s != "" && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) && json.Valid([]byte(s)) {
+		return json.Number(s)
+	}
+	return s
+}
+
+// sortedKeys 返回按字典序排列的键列表
+func sortedKeys(data map[string]string) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	var nested map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(`{
+		"name": "app",
+		"server": {"port": 8080, "tls": false},
+		"servers": [{"host": "a"}, {"host": "b"}],
+		"empty": {},
+		"none": null
+	}`))
+	dec.UseNumber()
+	if err := dec.Decode(&nested); err != nil {
+		t.Fatal(err)
+	}
+	nested["hosts"] = []string{"x", "y"}
+	nested["labels"] = map[interface{}]interface{}{"env": "prod", 1: 2.5}
+
+	want := map[string]string{
+		"name":            "app",
+		"server.port":     "8080",
+		"server.tls":      "false",
+		"servers[0].host": "a",
+		"servers[1].host": "b",
+		"empty":           "",
+		"none":            "",
+		"hosts[0]":        "x",
+		"hosts[1]":        "y",
+		"labels.env":      "prod",
+		"labels.1":        "2.5",
+	}
+	if got := Flatten(nested); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten = %v, want %v", got, want)
+	}
+	if got := Flatten(nil); len(got) != 0 {
+		t.Errorf("Flatten(nil) = %v, want empty", got)
+	}
+}
+
+func TestNest(t *testing.T) {
+	got, err := Nest(map[string]string{
+		"name":            "app",
+		"server.port":     "8080",
+		"server.tls":      "true",
+		"server.ratio":    "-0.5",
+		"servers[0].host": "a",
+		"servers[1].host": "b",
+		"list[2]":         "x",
+		"version":         "1.2.3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name":    "app",
+		"server":  map[string]interface{}{"port": json.Number("8080"), "tls": true, "ratio": json.Number("-0.5")},
+		"servers": []interface{}{map[string]interface{}{"host": "a"}, map[string]interface{}{"host": "b"}},
+		"list":    []interface{}{nil, nil, "x"},
+		"version": "1.2.3",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Nest = %#v, want %#v", got, want)
+	}
+	// Nest是Flatten的逆操作
+	if back := Flatten(got); back["servers[1].host"] != "b" || back["server.ratio"] != "-0.5" || back["list[2]"] != "x" {
+		t.Errorf("Flatten(Nest(...)) = %v", back)
+	}
+
+	for _, bad := range []map[string]string{
+		{"a": "1", "a.b": "2"},
+		{"a[0]": "1", "a.b": "2"},
+		{"a.b": "1", "a[0]": "2"},
+		{"a[x]": "1"},
+		{"a[-1]": "1"},
+		{"a[0": "1"},
+	} {
+		if _, err := Nest(bad); err == nil {
+			t.Errorf("Nest(%v) succeeded", bad)
+		}
+	}
+}
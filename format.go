@@ -0,0 +1,146 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// formatByExtension 返回文件扩展名对应的格式名，无法识别时返回空字符串
+// .ini、.conf、.cfg对应key=value格式"ini"，其它扩展名与RegisterCodec注册的格式名比较
+func formatByExtension(filename string) string {
+	ext := strings.ToLower(filepath.Ext(trimCompressionExt(filename)))
+	if format := builtinFormat(ext); format != "" {
+		return format
+	}
+	if _, ok := lookupCodec(strings.TrimPrefix(ext, ".")); ok {
+		return ext[1:]
+	}
+	return ""
+}
+
+// builtinFormat 返回内置解析器对应扩展名的格式名，不是内置扩展名时返回空字符串
+func builtinFormat(ext string) string {
+	switch ext {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".properties":
+		return "properties"
+	case ".env":
+		return "env"
+	case ".xml":
+		return "xml"
+	case ".hcl":
+		return "hcl"
+	case ".ini", ".conf", ".cfg":
+		return "ini"
+	}
+	return ""
+}
+
+// detectFormat 确定内容的格式：优先按扩展名，无法识别时检查内容
+func detectFormat(filename string, content []byte) string {
+	if format := formatByExtension(filename); format != "" {
+		return format
+	}
+	return sniffFormat(content)
+}
+
+// sniffFormat 根据内容特征猜测格式，无法判断时按key=value格式处理
+// 以"{"开头为JSON，以"<"开头为XML，以"---"开头为YAML；
+// 否则看第一个有效行：[[表数组]]为TOML，"export "开头为.env，以"{"结尾为HCL块，
+// "key: value"为YAML，其余为key=value
+func sniffFormat(content []byte) string {
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case trimmed == "":
+		return "ini"
+	case trimmed[0] == '{':
+		return "json"
+	case trimmed[0] == '<':
+		return "xml"
+	case strings.HasPrefix(trimmed, "---"):
+		return "yaml"
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' || strings.HasPrefix(line, "//") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "[["):
+			return "toml"
+		case strings.HasPrefix(line, "["):
+			return "ini"
+		case strings.HasPrefix(line, "export "):
+			return "env"
+		case strings.HasSuffix(line, "{"):
+			return "hcl"
+		}
+		eq := strings.IndexByte(line, '=')
+		colon := strings.IndexByte(line, ':')
+		if colon > 0 && (eq < 0 || colon < eq) && (colon == len(line)-1 || line[colon+1] == ' ') {
+			return "yaml"
+		}
+		return "ini"
+	}
+	return "ini"
+}
+
+// decodeFormat 按格式名解析内容，"ini"为key=value格式，其它名称查找RegisterCodec注册的编解码器
+func decodeFormat(format string, content []byte) (map[string]string, error) {
+	content = trimBOM(content)
+	switch format {
+	case "json":
+		return decodeJSON(content)
+	case "yaml":
+		return decodeYAML(content)
+	case "toml":
+		return decodeTOML(content)
+	case "properties":
+		return decodeProperties(content)
+	case "env":
+		return decodeDotEnv(content)
+	case "xml":
+		return decodeXML(content)
+	case "hcl":
+		return decodeHCL(content)
+	case "ini":
+		return decodeKeyValue(bytes.NewReader(content))
+	}
+	if codec, ok := lookupCodec(format); ok {
+		return codec.Decode(content)
+	}
+	return nil, fmt.Errorf("unknown config format %q", format)
+}
+
+// trimBOM 去掉内容开头的UTF-8 BOM，sniffFormat忽略BOM，解析器也应忽略
+func trimBOM(content []byte) []byte {
+	return bytes.TrimPrefix(content, []byte("\ufeff"))
+}
+
+// decodeByExtension 根据文件扩展名选择解析器解析内容
+// .json、.yaml/.yml、.toml、.properties、.env、.xml、.hcl使用对应格式，
+// .ini、.conf、.cfg按key=value格式解析，与注册的编解码器同名的扩展名使用该编解码器，其余根据内容猜测格式
+func decodeByExtension(filename string, content []byte) (map[string]string, error) {
+	return decodeFormat(detectFormat(filename, content), content)
+}
+
+// decodeFile 与decodeByExtension相同，但key=value文件中的include指令相对filename解析
+// 仅用于本地文件，远程文档不处理include指令；conditions是条件指令使用的变量
+func decodeFile(filename string, content []byte, conditions map[string]string) (map[string]string, error) {
+	if format := detectFormat(filename, content); format != "ini" {
+		return decodeFormat(format, content)
+	}
+	doc, err := parseKeyValue(bytes.NewReader(content), filename, true, loadSettings{conditions: conditions})
+	if err != nil {
+		return nil, err
+	}
+	return doc.values, nil
+}
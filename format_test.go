@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromFileByExtension(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"app.json":       `{"server": {"port": 8080}}`,
+		"app.YML":        "server:\n  port: 8081\n",
+		"app.toml":       "[server]\nport = 8082\n",
+		"app.properties": "server.port : 8083\n",
+		".env":           "SERVER_PORT=8084\n",
+		"app.xml":        "<config><server><port>8085</port></server></config>",
+		"app.hcl":        "server {\n  port = 8086\n}\n",
+		"app.cfg":        "[server]\nport = 8087\n",
+	})
+	for name, key := range map[string]string{
+		"app.json": "server.port", "app.YML": "server.port", "app.toml": "server.port",
+		"app.properties": "server.port", ".env": "SERVER_PORT", "app.xml": "server.port",
+		"app.hcl": "server.port", "app.cfg": "server.port",
+	} {
+		cfg, _ := NewConfig()
+		if err := cfg.LoadFromFile(filepath.Join(dir, name)); err != nil {
+			t.Errorf("LoadFromFile(%s): %v", name, err)
+			continue
+		}
+		if got := cfg.Get(key); !strings.HasPrefix(got, "808") {
+			t.Errorf("LoadFromFile(%s): %s = %q, all values %v", name, key, got, cfg.GetAll())
+		}
+	}
+
+	// 已知扩展名优先于内容：.conf文件即使以"{"开头也按key=value解析
+	path := filepath.Join(dir, "braces.conf")
+	os.WriteFile(path, []byte("{ = open\n"), 0o644)
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil || cfg.Get("{") != "open" {
+		t.Errorf("braces.conf = %v, %v; want a key=value file", cfg.GetAll(), err)
+	}
+
+	// 压缩扩展名之前的扩展名决定格式
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(`{"name": "zipped"}`))
+	w.Close()
+	path = filepath.Join(dir, "app.json.gz")
+	os.WriteFile(path, gz.Bytes(), 0o644)
+	cfg, _ = NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil || cfg.Get("name") != "zipped" {
+		t.Errorf("app.json.gz name = %q, %v", cfg.Get("name"), err)
+	}
+}
+
+func TestLoadFromFileSniffsContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	load := func(content string) *Config {
+		t.Helper()
+		os.WriteFile(path, []byte(content), 0o644)
+		cfg, _ := NewConfig()
+		if err := cfg.LoadFromFile(path); err != nil {
+			t.Fatalf("LoadFromFile(%q): %v", content, err)
+		}
+		return cfg
+	}
+
+	if cfg := load("\ufeff {\"tags\": [\"a\"]}"); cfg.Get("tags[0]") != "a" {
+		t.Errorf("JSON with a BOM loaded as %v", cfg.GetAll())
+	}
+	typed, _ := NewConfigWithOptions(WithTypedValues())
+	if err := typed.LoadFromFile(path); err != nil || typed.Get("tags[0]") != "a" {
+		t.Errorf("typed JSON with a BOM = %v, %v", typed.GetAll(), err)
+	}
+	if cfg := load(`<?xml version="1.0"?><c><a x="1"/></c>`); cfg.Get("a.@x") != "1" {
+		t.Errorf("XML loaded as %v", cfg.GetAll())
+	}
+	if cfg := load("---\nserver:\n  port: 1\n"); cfg.Get("server.port") != "1" {
+		t.Errorf("YAML document loaded as %v", cfg.GetAll())
+	}
+	if cfg := load("url: http://example.com\n"); cfg.Get("url") != "http://example.com" {
+		t.Errorf("YAML mapping loaded as %v", cfg.GetAll())
+	}
+	// 注释之后的第一个有效行决定格式
+	if cfg := load("# servers\n[[servers]]\nhost = \"a\"\n"); cfg.Get("servers[0].host") != "a" {
+		t.Errorf("TOML table array loaded as %v", cfg.GetAll())
+	}
+	if cfg := load("export API_KEY=abc\n"); cfg.Get("API_KEY") != "abc" {
+		t.Errorf(".env loaded as %v", cfg.GetAll())
+	}
+	if cfg := load("// nomad\njob \"web\" {\n  count = 2\n}\n"); cfg.Get("job.web.count") != "2" {
+		t.Errorf("HCL loaded as %v", cfg.GetAll())
+	}
+	// "="在": "之前时仍是key=value，否则10:30会被误判为YAML
+	if cfg := load("time = 10:30\n"); cfg.Get("time") != "10:30" {
+		t.Errorf("key=value with a colon loaded as %v", cfg.GetAll())
+	}
+	if cfg := load("[server]\nport = 1\n"); cfg.Get("server.port") != "1" {
+		t.Errorf("INI section loaded as %v", cfg.GetAll())
+	}
+}
+
+func TestWithFileFormat(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"settings.txt": `{"port": 80}`,
+		"app.json":     "port = 81\n",
+	})
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(filepath.Join(dir, "settings.txt"), WithFileFormat("JSON")); err != nil || cfg.Get("port") != "80" {
+		t.Errorf("forced JSON port = %q, %v", cfg.Get("port"), err)
+	}
+	// 强制格式优先于扩展名
+	if err := cfg.LoadFromFile(filepath.Join(dir, "app.json"), WithFileFormat("ini")); err != nil || cfg.Get("port") != "81" {
+		t.Errorf("forced ini port = %q, %v", cfg.Get("port"), err)
+	}
+	err := cfg.LoadFromFile(filepath.Join(dir, "app.json"), WithFileFormat("yaml5"))
+	if err == nil || !strings.Contains(err.Error(), `unknown config format "yaml5"`) {
+		t.Errorf("unknown forced format error = %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import "errors"
+
+// ErrFrozen 表示配置已被Freeze冻结，不允许修改
+var ErrFrozen = errors.New("config is frozen")
+
+// Freeze 冻结配置，之后所有修改操作返回ErrFrozen且不改变配置
+// 包括Set、Delete、各层写入、文件与配置源加载、Update、Restore等；
+// Watch与WatchProvider的热加载同样被拒绝，ErrFrozen会传给OnReload回调。
+// 冻结不可撤销，读取操作不受影响。适合在启动阶段加载完成后调用，
+// 防止之后的库代码意外修改配置。
+func (c *Config) Freeze() {
+	c.lock()
+	defer c.mutex.Unlock()
+	c.frozen = true
+}
+
+// IsFrozen 判断配置是否已冻结
+func (c *Config) IsFrozen() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.frozen
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("port", "80")
+	if cfg.IsFrozen() {
+		t.Fatal("new config is frozen")
+	}
+	cfg.Freeze()
+	if !cfg.IsFrozen() {
+		t.Fatal("IsFrozen() = false after Freeze")
+	}
+
+	writes := map[string]func() error{
+		"Set":         func() error { return cfg.Set("port", "9090") },
+		"Delete":      func() error { return cfg.Delete("port") },
+		"SetDefault":  func() error { return cfg.SetDefault("port", "1") },
+		"SetOverride": func() error { return cfg.SetOverride("port", "1") },
+		"MergeLayer":  func() error { return cfg.MergeLayer(LayerFile, map[string]string{"port": "1"}) },
+		"ClearLayer":  func() error { return cfg.ClearLayer(LayerFile) },
+		"LoadFromReader": func() error {
+			return cfg.LoadFromReader(strings.NewReader("port = 1\n"))
+		},
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, ErrFrozen) {
+			t.Errorf("%s on a frozen config error = %v, want ErrFrozen", name, err)
+		}
+	}
+	// 读取不受影响，被拒绝的写入没有改变配置
+	if got := cfg.Get("port"); got != "80" || cfg.Len() != 1 {
+		t.Errorf("frozen config = %v, want it unchanged", cfg.GetAll())
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// LoadFromFS 从fs.FS(如go:embed嵌入的embed.FS)读取配置文件并合并到默认值层
+// 格式按扩展名选择，无法识别时根据内容猜测，规则与LoadFromFile相同。
+// 值写入默认值层，因此之后通过LoadFromFile等加载的磁盘文件会覆盖它们，
+// 且SaveToFile等方法不会把这些内置默认值写回磁盘。
+// 示例:
+//
+//	//go:embed defaults.ini
+//	var defaults embed.FS
+//
+//	cfg.LoadFromFS(defaults, "defaults.ini")
+//	cfg.LoadFromFile("/etc/myapp/app.ini")
+//
+// 参数:
+// - fsys: 文件系统
+// - path: 文件在fsys中的路径，使用"/"分隔
+// 返回:
+// - error: 文件读取或解析错误(如果有)
+func (c *Config) LoadFromFS(fsys fs.FS, path string) error {
+	content, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return err
+	}
+	values, err := decodeByExtension(path, content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return c.mergeLayerFrom(LayerDefault, values, KeySource{Kind: SourceFile, Name: path})
+}
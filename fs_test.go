@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"defaults/app.json": {Data: []byte(`{"server": {"port": 8080, "host": "0.0.0.0"}, "debug": false}`)},
+		"defaults/extra":    {Data: []byte("log.level = info\n")},
+	}
+	cfg, _ := NewConfig()
+	for _, name := range []string{"defaults/app.json", "defaults/extra"} {
+		if err := cfg.LoadFromFS(fsys, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "app.conf")
+	os.WriteFile(path, []byte("server.port = 9090\n"), 0o644)
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// 磁盘文件覆盖内置默认值，未覆盖的默认值仍然生效
+	for key, want := range map[string]string{"server.port": "9090", "server.host": "0.0.0.0", "log.level": "info"} {
+		if got := cfg.Get(key); got != want {
+			t.Errorf("Get(%s) = %q, want %q", key, got, want)
+		}
+	}
+	if got := cfg.LayerValues(LayerDefault)["server.port"]; got != "8080" {
+		t.Errorf("default layer server.port = %q, want 8080", got)
+	}
+	if src, _ := cfg.Source("server.host"); src.Name != "defaults/app.json" {
+		t.Errorf("Source(server.host) = %+v, want the embedded file", src)
+	}
+	// 默认值不写回磁盘
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "host") {
+		t.Errorf("saved file contains embedded defaults:\n%s", data)
+	}
+
+	if err := cfg.LoadFromFS(fsys, "missing.ini"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadFromFS(missing) error = %v, want fs.ErrNotExist", err)
+	}
+	fsys["bad.json"] = &fstest.MapFile{Data: []byte("{")}
+	if err := cfg.LoadFromFS(fsys, "bad.json"); err == nil || !strings.HasPrefix(err.Error(), "bad.json: ") {
+		t.Errorf("LoadFromFS(bad.json) error = %v, want a parse error naming the file", err)
+	}
+}
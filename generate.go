@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// commentTagName 是GenerateTemplate为字段写出说明注释使用的标签名
+const commentTagName = "comment"
+
+// GenerateTemplate 根据带标签的结构体生成带注释的key=value示例配置文件
+// 键名规则与Unmarshal一致，每个键之前以"# "写出`comment:"..."`标签的内容，值为结构体中字段的当前值，
+// 因此传入填好默认值的结构体即可得到可直接编辑的文件，例如`myapp --print-config`的输出。
+// 嵌套结构体写为[section]段落，段落的注释取自嵌套结构体字段的comment标签；
+// omitempty字段与nil指针同样写出(nil指针按零值)，以便示例包含所有键。生成的内容可以由LoadFromFile与Unmarshal读回
+// 参数:
+// - v: 结构体或指向结构体的指针
+// 返回:
+// - []byte: 生成的文件内容
+// - error: 参数类型不合法或字段类型不受支持时返回错误
+func GenerateTemplate(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv = reflect.New(rv.Type().Elem()).Elem()
+			continue
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("template source must be a struct")
+	}
+	var buf bytes.Buffer
+	if err := generateSection(&buf, rv, "", ""); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// generateSection 写出结构体的字段，先写本段落的键，再依次写嵌套结构体的段落
+// section为段落名，comment为段落的说明，顶层结构体的section为空且不写段落头
+func generateSection(buf *bytes.Buffer, rv reflect.Value, section, comment string) error {
+	type nested struct {
+		value   reflect.Value
+		key     string
+		comment string
+	}
+	var children []nested
+	header := section == ""
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			fv = reflect.New(field.Type.Elem())
+		}
+		if isNestedStruct(field.Type) {
+			if fv.Kind() == reflect.Ptr {
+				fv = fv.Elem()
+			}
+			children = append(children, nested{fv, joinKey(section, name), field.Tag.Get(commentTagName)})
+			continue
+		}
+
+		unit, err := fieldUnit(field)
+		if err != nil {
+			return err
+		}
+		val, err := formatFieldValue(fv, unit)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", joinKey(section, name), err)
+		}
+		if !header {
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			writeComment(buf, comment)
+			fmt.Fprintf(buf, "[%s]\n", section)
+			header = true
+		} else if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		writeComment(buf, field.Tag.Get(commentTagName))
+		buf.WriteString(strings.TrimRight(name+" = "+formatValue(val), " "))
+		buf.WriteByte('\n')
+	}
+	for _, child := range children {
+		if err := generateSection(buf, child.value, child.key, child.comment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeComment 把说明逐行写为"# "开头的注释，说明中的"\n"表示换行
+func writeComment(buf *bytes.Buffer, comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(comment, `\n`, "\n"), "\n") {
+		buf.WriteString(strings.TrimRight("# "+line, " "))
+		buf.WriteByte('\n')
+	}
+}
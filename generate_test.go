@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type generatedServer struct {
+	Host    string        `config:"host" comment:"listen address"`
+	Port    int           `config:"port" comment:"listen port\nuse 0 for a random port"`
+	Timeout time.Duration `config:"timeout" unit:"seconds"`
+}
+
+type generatedApp struct {
+	Name   string           `comment:"application name"`
+	Tags   []string         `config:"tags,omitempty"`
+	Debug  *bool            `config:"debug"`
+	Skip   string           `config:"-"`
+	Server generatedServer  `config:"server" comment:"HTTP server"`
+	Cache  *generatedServer `config:"cache"`
+}
+
+func TestGenerateTemplate(t *testing.T) {
+	app := generatedApp{Name: "demo", Skip: "x", Server: generatedServer{Host: "0.0.0.0", Port: 8080, Timeout: 30 * time.Second}}
+	out, err := GenerateTemplate(&app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# application name\n" +
+		"name = demo\n" +
+		"\n" +
+		"tags =\n" +
+		"\n" +
+		"debug = false\n" +
+		"\n" +
+		"# HTTP server\n" +
+		"[server]\n" +
+		"# listen address\n" +
+		"host = 0.0.0.0\n" +
+		"\n" +
+		"# listen port\n" +
+		"# use 0 for a random port\n" +
+		"port = 8080\n" +
+		"\n" +
+		"timeout = 30\n" +
+		"\n" +
+		"[cache]\n" +
+		"# listen address\n" +
+		"host =\n" +
+		"\n" +
+		"# listen port\n" +
+		"# use 0 for a random port\n" +
+		"port = 0\n" +
+		"\n" +
+		"timeout = 0\n"
+	if string(out) != want {
+		t.Errorf("GenerateTemplate =\n%s\nwant\n%s", out, want)
+	}
+
+	// 生成的文件可以读回同样的值
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromReader(bytes.NewReader(out)); err != nil {
+		t.Fatal(err)
+	}
+	var back generatedApp
+	if err := cfg.Unmarshal(&back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Name != "demo" || back.Server != app.Server || back.Cache == nil || back.Skip != "" {
+		t.Errorf("read back %+v", back)
+	}
+
+	if _, err := GenerateTemplate(42); err == nil || !strings.Contains(err.Error(), "must be a struct") {
+		t.Errorf("GenerateTemplate(42) error = %v", err)
+	}
+	if _, err := GenerateTemplate(struct{ C chan int }{}); err == nil {
+		t.Error("GenerateTemplate with an unsupported field type succeeded")
+	}
+}
@@ -0,0 +1,349 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrKeyNotFound 表示请求的配置键不存在
+var ErrKeyNotFound = errors.New("key not found")
+
+// GetInt 获取配置值并解析为int
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - int: 解析后的整数值
+// - error: 键不存在(ErrKeyNotFound)或解析失败时返回错误
+func (c *Config) GetInt(key string) (int, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("key %s: invalid int %q: %w", c.keyRef(key), val, err)
+	}
+	return n, nil
+}
+
+// GetIntWithDefault 获取int配置值，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// 返回:
+// - int: 解析后的值或defaultValue
+func (c *Config) GetIntWithDefault(key string, defaultValue int) int {
+	if n, err := c.GetInt(key); err == nil {
+		return n
+	}
+	return defaultValue
+}
+
+// GetInt64 获取配置值并解析为int64
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - int64: 解析后的整数值
+// - error: 键不存在(ErrKeyNotFound)或解析失败时返回错误
+func (c *Config) GetInt64(key string) (int64, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("key %s: invalid int64 %q: %w", c.keyRef(key), val, err)
+	}
+	return n, nil
+}
+
+// GetInt64WithDefault 获取int64配置值，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// 返回:
+// - int64: 解析后的值或defaultValue
+func (c *Config) GetInt64WithDefault(key string, defaultValue int64) int64 {
+	if n, err := c.GetInt64(key); err == nil {
+		return n
+	}
+	return defaultValue
+}
+
+// GetBool 获取配置值并解析为bool
+// 除strconv.ParseBool支持的取值外，还接受yes/no、on/off(不区分大小写)
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - bool: 解析后的布尔值
+// - error: 键不存在(ErrKeyNotFound)或解析失败时返回错误
+func (c *Config) GetBool(key string) (bool, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return false, err
+	}
+	b, err := parseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("key %s: invalid bool %q: %w", c.keyRef(key), val, err)
+	}
+	return b, nil
+}
+
+// GetBoolWithDefault 获取bool配置值，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// 返回:
+// - bool: 解析后的值或defaultValue
+func (c *Config) GetBoolWithDefault(key string, defaultValue bool) bool {
+	if b, err := c.GetBool(key); err == nil {
+		return b
+	}
+	return defaultValue
+}
+
+// GetFloat64 获取配置值并解析为float64
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - float64: 解析后的浮点值
+// - error: 键不存在(ErrKeyNotFound)或解析失败时返回错误
+func (c *Config) GetFloat64(key string) (float64, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, fmt.Errorf("key %s: invalid float %q: %w", c.keyRef(key), val, err)
+	}
+	return f, nil
+}
+
+// GetFloat64WithDefault 获取float64配置值，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// 返回:
+// - float64: 解析后的值或defaultValue
+func (c *Config) GetFloat64WithDefault(key string, defaultValue float64) float64 {
+	if f, err := c.GetFloat64(key); err == nil {
+		return f
+	}
+	return defaultValue
+}
+
+// GetDuration 获取配置值并解析为time.Duration
+// 值格式与time.ParseDuration一致，如"300ms"、"1h30m"
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - time.Duration: 解析后的时长
+// - error: 键不存在(ErrKeyNotFound)或解析失败时返回错误
+func (c *Config) GetDuration(key string) (time.Duration, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("key %s: invalid duration %q: %w", c.keyRef(key), val, err)
+	}
+	return d, nil
+}
+
+// GetDurationWithDefault 获取time.Duration配置值，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// 返回:
+// - time.Duration: 解析后的值或defaultValue
+func (c *Config) GetDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
+	if d, err := c.GetDuration(key); err == nil {
+		return d
+	}
+	return defaultValue
+}
+
+// GetTime 获取配置值并按layouts依次尝试解析为time.Time
+// 未指定layouts时使用time.RFC3339(兼容带小数秒的时间)；没有时区信息的布局按UTC解析
+// 参数:
+// - key: 要查找的配置键
+// - layouts: time.Parse格式的布局，如"2006-01-02"，按顺序尝试
+// 返回:
+// - time.Time: 解析后的时间
+// - error: 键不存在(ErrKeyNotFound)或所有布局都无法解析时返回错误
+func (c *Config) GetTime(key string, layouts ...string) (time.Time, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+	var firstErr error
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, val)
+		if err == nil {
+			return t, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return time.Time{}, fmt.Errorf("key %s: invalid time %q: %w", c.keyRef(key), val, firstErr)
+}
+
+// GetTimeWithDefault 获取time.Time配置值，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// - layouts: 与GetTime相同
+// 返回:
+// - time.Time: 解析后的值或defaultValue
+func (c *Config) GetTimeWithDefault(key string, defaultValue time.Time, layouts ...string) time.Time {
+	if t, err := c.GetTime(key, layouts...); err == nil {
+		return t
+	}
+	return defaultValue
+}
+
+// GetUnixTime 获取以Unix时间戳(自1970-01-01 UTC起的秒数)表示的时间
+// 值可以带小数部分表示不足一秒的部分，如"1700000000.250"
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - time.Time: 对应的时间
+// - error: 键不存在(ErrKeyNotFound)或不是合法的秒数时返回错误
+func (c *Config) GetUnixTime(key string) (time.Time, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	secs, frac, hasFrac := strings.Cut(val, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	var nsec int64
+	if err == nil && hasFrac {
+		// 小数部分按纳秒精度解析，避免float64在大时间戳上丢失精度
+		if frac == "" || strings.Trim(frac, "0123456789") != "" {
+			err = errors.New("invalid fractional seconds")
+		} else {
+			frac = (frac + "00000000")[:9]
+			nsec, _ = strconv.ParseInt(frac, 10, 64)
+			if strings.HasPrefix(secs, "-") {
+				nsec = -nsec
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("key %s: invalid unix time %q: %w", c.keyRef(key), val, err)
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// GetUnixTimeWithDefault 获取Unix时间戳配置值，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// 返回:
+// - time.Time: 解析后的值或defaultValue
+func (c *Config) GetUnixTimeWithDefault(key string, defaultValue time.Time) time.Time {
+	if t, err := c.GetUnixTime(key); err == nil {
+		return t
+	}
+	return defaultValue
+}
+
+// GetURL 获取配置值并解析为绝对URL
+// 值必须带有协议；除file与unix外的协议还必须带有主机，如"https://api.example.com/v1"
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - *url.URL: 解析后的URL
+// - error: 键不存在(ErrKeyNotFound)、无法解析、缺少协议或主机时返回错误
+func (c *Config) GetURL(key string) (*url.URL, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(val)
+	switch {
+	case err != nil:
+	case u.Scheme == "":
+		err = errors.New("missing scheme")
+	case u.Host == "" && u.Opaque == "" && u.Scheme != "file" && u.Scheme != "unix":
+		err = errors.New("missing host")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: invalid URL %q: %w", c.keyRef(key), val, err)
+	}
+	return u, nil
+}
+
+// GetIP 获取配置值并解析为IPv4或IPv6地址
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - net.IP: 解析后的地址
+// - error: 键不存在(ErrKeyNotFound)或不是合法的IP地址时返回错误
+func (c *Config) GetIP(key string) (net.IP, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(val)
+	if ip == nil {
+		return nil, fmt.Errorf("key %s: invalid IP address %q", c.keyRef(key), val)
+	}
+	return ip, nil
+}
+
+// GetHostPort 获取"host:port"形式的地址并校验端口
+// 主机可以为空(如":8080"表示所有地址)，IPv6地址须写在方括号中，如"[::1]:8080"；
+// 端口必须是1到65535之间的数字
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - string: 主机部分
+// - int: 端口
+// - error: 键不存在(ErrKeyNotFound)、格式错误或端口无效时返回错误
+func (c *Config) GetHostPort(key string) (string, int, error) {
+	val, err := c.getRequired(key)
+	if err != nil {
+		return "", 0, err
+	}
+	host, portStr, err := net.SplitHostPort(val)
+	if err != nil {
+		return "", 0, fmt.Errorf("key %s: invalid host:port %q: %w", c.keyRef(key), val, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("key %s: invalid port %q in %q", c.keyRef(key), portStr, val)
+	}
+	return host, port, nil
+}
+
+// getRequired 获取配置值，键不存在时返回包装了ErrKeyNotFound的错误
+func (c *Config) getRequired(key string) (string, error) {
+	val, ok := c.lookup(key)
+	if !ok {
+		return "", fmt.Errorf("key %q: %w", key, ErrKeyNotFound)
+	}
+	return strings.TrimSpace(val), nil
+}
+
+// parseBool 在strconv.ParseBool基础上增加yes/no、on/off的支持
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
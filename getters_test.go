@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedGetters(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("port", " 8080 ")
+	cfg.Set("big", "9007199254740993")
+	cfg.Set("debug", "Yes")
+	cfg.Set("verbose", "off")
+	cfg.Set("ratio", "0.75")
+	cfg.Set("timeout", "1m30s")
+
+	if n, err := cfg.GetInt("port"); err != nil || n != 8080 {
+		t.Errorf("GetInt(port) = %d, %v, want 8080", n, err)
+	}
+	if n, err := cfg.GetInt64("big"); err != nil || n != 9007199254740993 {
+		t.Errorf("GetInt64(big) = %d, %v", n, err)
+	}
+	if b, err := cfg.GetBool("debug"); err != nil || !b {
+		t.Errorf("GetBool(debug) = %v, %v, want true", b, err)
+	}
+	if b, err := cfg.GetBool("verbose"); err != nil || b {
+		t.Errorf("GetBool(verbose) = %v, %v, want false", b, err)
+	}
+	if f, err := cfg.GetFloat64("ratio"); err != nil || f != 0.75 {
+		t.Errorf("GetFloat64(ratio) = %v, %v, want 0.75", f, err)
+	}
+	if d, err := cfg.GetDuration("timeout"); err != nil || d != 90*time.Second {
+		t.Errorf("GetDuration(timeout) = %v, %v, want 1m30s", d, err)
+	}
+}
+
+func TestTypedGetterErrors(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("bad", "eight")
+	getters := map[string]func(key string) error{
+		"GetInt":      func(k string) error { _, err := cfg.GetInt(k); return err },
+		"GetInt64":    func(k string) error { _, err := cfg.GetInt64(k); return err },
+		"GetBool":     func(k string) error { _, err := cfg.GetBool(k); return err },
+		"GetFloat64":  func(k string) error { _, err := cfg.GetFloat64(k); return err },
+		"GetDuration": func(k string) error { _, err := cfg.GetDuration(k); return err },
+	}
+	for name, get := range getters {
+		if err := get("missing"); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("%s(missing) error = %v, want ErrKeyNotFound", name, err)
+		}
+		err := get("bad")
+		if err == nil || errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("%s(bad) error = %v, want a parse error", name, err)
+		}
+	}
+	var numErr *strconv.NumError
+	if _, err := cfg.GetInt("bad"); !errors.As(err, &numErr) {
+		t.Errorf("GetInt(bad) error = %v, want it to wrap *strconv.NumError", err)
+	}
+}
+
+func TestTypedGettersWithDefault(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("port", "8080")
+	cfg.Set("bad", "eight")
+
+	// 存在且可解析时忽略默认值，缺失或无法解析时返回默认值
+	for _, key := range []string{"missing", "bad"} {
+		if got := cfg.GetIntWithDefault(key, 1); got != 1 {
+			t.Errorf("GetIntWithDefault(%s) = %d, want 1", key, got)
+		}
+		if got := cfg.GetInt64WithDefault(key, 2); got != 2 {
+			t.Errorf("GetInt64WithDefault(%s) = %d, want 2", key, got)
+		}
+		if got := cfg.GetBoolWithDefault(key, true); !got {
+			t.Errorf("GetBoolWithDefault(%s) = false, want true", key)
+		}
+		if got := cfg.GetFloat64WithDefault(key, 1.5); got != 1.5 {
+			t.Errorf("GetFloat64WithDefault(%s) = %v, want 1.5", key, got)
+		}
+		if got := cfg.GetDurationWithDefault(key, time.Second); got != time.Second {
+			t.Errorf("GetDurationWithDefault(%s) = %v, want 1s", key, got)
+		}
+	}
+	if got := cfg.GetIntWithDefault("port", 1); got != 8080 {
+		t.Errorf("GetIntWithDefault(port) = %d, want 8080", got)
+	}
+}
+
+func TestGetTime(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("start", "2024-03-01T08:30:00.5+08:00")
+	cfg.Set("day", "2024-03-01")
+	cfg.Set("epoch", "1700000000.250")
+	cfg.Set("before", "-1.5")
+	cfg.Set("bad", "yesterday")
+
+	got, err := cfg.GetTime("start")
+	if want := time.Date(2024, 3, 1, 0, 30, 0, 5e8, time.UTC); err != nil || !got.Equal(want) {
+		t.Errorf("GetTime(start) = %v, %v, want %v", got, err, want)
+	}
+	// 依次尝试各布局，没有时区的布局按UTC解析
+	got, err = cfg.GetTime("day", time.RFC3339, "2006-01-02")
+	if err != nil || !got.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetTime(day) = %v, %v", got, err)
+	}
+	if _, err := cfg.GetTime("day"); err == nil {
+		t.Error("GetTime(day) with the default layout succeeded")
+	}
+	if _, err := cfg.GetTime("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetTime(missing) error = %v, want ErrKeyNotFound", err)
+	}
+	def := time.Unix(1, 0)
+	if got := cfg.GetTimeWithDefault("bad", def); !got.Equal(def) {
+		t.Errorf("GetTimeWithDefault(bad) = %v, want the default", got)
+	}
+
+	got, err = cfg.GetUnixTime("epoch")
+	if err != nil || !got.Equal(time.Unix(1700000000, 250e6)) {
+		t.Errorf("GetUnixTime(epoch) = %v, %v", got, err)
+	}
+	if got, err := cfg.GetUnixTime("before"); err != nil || !got.Equal(time.Unix(-1, -5e8)) {
+		t.Errorf("GetUnixTime(before) = %v, %v", got, err)
+	}
+	for _, val := range []string{"1700000000.", "12.3x", "soon"} {
+		cfg.Set("bad", val)
+		if _, err := cfg.GetUnixTime("bad"); err == nil {
+			t.Errorf("GetUnixTime(%q) succeeded", val)
+		}
+	}
+	if got := cfg.GetUnixTimeWithDefault("missing", def); !got.Equal(def) {
+		t.Errorf("GetUnixTimeWithDefault(missing) = %v, want the default", got)
+	}
+}
+
+func TestEndpointGetters(t *testing.T) {
+	cfg, _ := NewConfig()
+	values := map[string]string{
+		"api":      "https://api.example.com/v1",
+		"sock":     "unix:///run/app.sock",
+		"relative": "/v1/users",
+		"nohost":   "https:///path",
+		"ip4":      "10.0.0.1",
+		"ip6":      " ::1 ",
+		"listen":   ":8080",
+		"db":       "[::1]:5432",
+		"badport":  "localhost:70000",
+		"noport":   "localhost",
+	}
+	for k, v := range values {
+		cfg.Set(k, v)
+	}
+
+	if u, err := cfg.GetURL("api"); err != nil || u.Host != "api.example.com" || u.Path != "/v1" {
+		t.Errorf("GetURL(api) = %v, %v", u, err)
+	}
+	if u, err := cfg.GetURL("sock"); err != nil || u.Path != "/run/app.sock" {
+		t.Errorf("GetURL(sock) = %v, %v", u, err)
+	}
+	for key, want := range map[string]string{"relative": "missing scheme", "nohost": "missing host"} {
+		if _, err := cfg.GetURL(key); err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), key) {
+			t.Errorf("GetURL(%s) error = %v, want %q", key, err, want)
+		}
+	}
+
+	if ip, err := cfg.GetIP("ip4"); err != nil || !ip.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("GetIP(ip4) = %v, %v", ip, err)
+	}
+	if ip, err := cfg.GetIP("ip6"); err != nil || !ip.Equal(net.IPv6loopback) {
+		t.Errorf("GetIP(ip6) = %v, %v", ip, err)
+	}
+	if _, err := cfg.GetIP("api"); err == nil {
+		t.Error("GetIP(api) succeeded")
+	}
+
+	if host, port, err := cfg.GetHostPort("listen"); err != nil || host != "" || port != 8080 {
+		t.Errorf("GetHostPort(listen) = %q, %d, %v", host, port, err)
+	}
+	if host, port, err := cfg.GetHostPort("db"); err != nil || host != "::1" || port != 5432 {
+		t.Errorf("GetHostPort(db) = %q, %d, %v", host, port, err)
+	}
+	if _, _, err := cfg.GetHostPort("badport"); err == nil || !strings.Contains(err.Error(), `invalid port "70000"`) {
+		t.Errorf("GetHostPort(badport) error = %v", err)
+	}
+	if _, _, err := cfg.GetHostPort("noport"); err == nil {
+		t.Error("GetHostPort(noport) succeeded")
+	}
+	if _, _, err := cfg.GetHostPort("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetHostPort(missing) error = %v, want ErrKeyNotFound", err)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LoadFromGlob 按字典序加载所有匹配pattern的文件并依次合并到文件层
+// 后加载的文件覆盖先加载文件中的同名键，与常见守护进程的conf.d约定一致，
+// 例如LoadFromGlob("/etc/myapp/conf.d/*.conf")。每个文件的格式按扩展名选择，
+// 规则与LoadFromFile相同；每个键来自哪个文件可通过Origin查询。
+// 某个文件加载失败时立即返回，之前的文件已合并的键保留。没有匹配的文件时不做任何事。
+// 参数:
+// - pattern: filepath.Match语法的文件模式
+// - opts: 严格模式、强制格式等解析设置，严格模式只作用于key=value格式的文件
+// 返回:
+// - error: 模式无效或文件读取、解析错误(如果有)
+func (c *Config) LoadFromGlob(pattern string, opts ...LoadOption) error {
+	settings := newLoadSettings(opts)
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	sort.Strings(matches)
+	for _, filename := range matches {
+		if info, err := os.Stat(filename); err != nil || info.IsDir() {
+			continue
+		}
+		if err := c.loadFile(context.Background(), filename, false, settings); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+// loadFile 按WithFileFormat指定的格式或自动检测的格式解析文件并合并到文件层，记录键的来源文件
+// key=value文件执行DefineKey定义的检查，recordLayout为true时替换SaveToFile使用的文件结构；
+// ctx只约束文件的读取，内容读入后的解析与合并不会被中途取消
+func (c *Config) loadFile(ctx context.Context, filename string, recordLayout bool, settings loadSettings) error {
+	content, err := readFileContext(ctx, filename)
+	if err != nil {
+		return err
+	}
+	return c.loadContent(filename, content, recordLayout, settings)
+}
+
+// loadContent 解析已读入的filename的内容并合并到文件层，规则见loadFile
+func (c *Config) loadContent(filename string, content []byte, recordLayout bool, settings loadSettings) error {
+	format := settings.format
+	if format == "" {
+		format = detectFormat(filename, content)
+	}
+	if format == "ini" {
+		return c.loadKeyValue(bytes.NewReader(content), filename, recordLayout, settings)
+	}
+	values, types, err := c.decodeContent(format, content)
+	if err != nil {
+		return err
+	}
+	return c.mergeDocumentFrom(filename, values, types, KeySource{Kind: SourceFile, Name: filename})
+}
+
+// readFileContext 读取文件，ctx先于读取结束时返回ctx.Err()
+// 读取在后台继续直到完成，适用于位于网络文件系统等可能长时间阻塞的文件
+func readFileContext(ctx context.Context, filename string) ([]byte, error) {
+	if ctx.Done() == nil {
+		return readConfigFile(filename)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type result struct {
+		content []byte
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		content, err := readConfigFile(filename)
+		done <- result{content, err}
+	}()
+	select {
+	case r := <-done:
+		return r.content, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Origin 返回文件层中键的来源文件
+// 通过LoadFromFile、LoadFromGlob、LoadFromJSON等从文件加载的键返回对应文件路径，
+// 通过Set等方式写入或不在文件层中的键返回空字符串；更完整的信息见Source
+// 参数:
+// - key: 配置键
+// 返回:
+// - string: 来源文件路径
+func (c *Config) Origin(key string) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if src, ok := c.meta[LayerFile][c.normKey(key)]; ok && src.Kind == SourceFile {
+		return src.Name
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromGlob(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-base.conf":   "name = app\nport = 80\n",
+		"20-local.json":  `{"port": 8080, "debug": true}`,
+		"30-empty.conf":  "",
+		"notes.txt":      "ignored = yes\n",
+		"99-zz.conf/x":   "dir = entry\n", // 目录被跳过
+		"50-tuning.conf": "port = 9090\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		os.WriteFile(path, []byte(content), 0o644)
+	}
+
+	cfg, _ := NewConfig()
+	cfg.Set("port", "1")
+	if err := cfg.LoadFromGlob(filepath.Join(dir, "*.conf")); err != nil {
+		t.Fatal(err)
+	}
+	// 按文件名顺序合并，后加载的覆盖先加载的
+	if got := cfg.Get("port"); got != "9090" {
+		t.Errorf("port = %q, want 9090 from the last file", got)
+	}
+	if got, want := cfg.Origin("name"), filepath.Join(dir, "10-base.conf"); got != want {
+		t.Errorf("Origin(name) = %q, want %q", got, want)
+	}
+	if got, want := cfg.Origin("port"), filepath.Join(dir, "50-tuning.conf"); got != want {
+		t.Errorf("Origin(port) = %q, want %q", got, want)
+	}
+	if cfg.Get("ignored") != "" || cfg.Get("dir") != "" {
+		t.Error("loaded a file that does not match the pattern")
+	}
+
+	if err := cfg.LoadFromGlob(filepath.Join(dir, "*.json")); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("debug"); got != "true" {
+		t.Errorf("debug = %q, want true from the JSON file", got)
+	}
+	cfg.Set("debug", "false")
+	if got := cfg.Origin("debug"); got != "" {
+		t.Errorf("Origin after Set = %q, want empty", got)
+	}
+
+	if err := cfg.LoadFromGlob(filepath.Join(dir, "none-*.conf")); err != nil {
+		t.Errorf("LoadFromGlob without matches: %v", err)
+	}
+	if err := cfg.LoadFromGlob("[" + dir); err == nil {
+		t.Error("LoadFromGlob with a malformed pattern succeeded")
+	}
+	os.WriteFile(filepath.Join(dir, "60-broken.json"), []byte("{"), 0o644)
+	err := cfg.LoadFromGlob(filepath.Join(dir, "*.json"))
+	if err == nil || !strings.Contains(err.Error(), "60-broken.json") {
+		t.Errorf("LoadFromGlob error = %v, want the failing file named", err)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// Hash 返回所有生效键值的SHA-256摘要(十六进制)
+// 键按字典序排列，每个键和值以长度前缀编码后参与计算，因此结果只取决于键值本身，
+// 与加载顺序、来源层和进程无关，可用于比较多个实例的配置是否一致，或作为ETag。
+// 值的处理方式与GetAll一致(解密、插值展开)，但敏感键以明文参与计算，摘要本身不泄露值
+// 返回:
+// - string: 64个字符的十六进制摘要
+func (c *Config) Hash() string {
+	values := c.allValues()
+	h := sha256.New()
+	var n [binary.MaxVarintLen64]byte
+	write := func(s string) {
+		h.Write(n[:binary.PutUvarint(n[:], uint64(len(s)))])
+		h.Write([]byte(s))
+	}
+	for _, key := range sortedKeys(values) {
+		write(key)
+		write(values[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HasChangedSince 报告当前配置的Hash是否与hash不同
+// 适用于定期检查时跳过没有变化的重新加载或同步工作
+// 参数:
+// - hash: 之前Hash返回的摘要
+// 返回:
+// - bool: 配置已变化时返回true
+func (c *Config) HasChangedSince(hash string) bool {
+	return c.Hash() != hash
+}
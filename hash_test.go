@@ -0,0 +1,48 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	empty, _ := NewConfig()
+	sum := sha256.Sum256(nil)
+	if got := empty.Hash(); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("Hash of an empty config = %s", got)
+	}
+
+	a, _ := NewConfig()
+	a.Set("name", "app")
+	a.Set("port", "80")
+	a.MarkSecret("db.password")
+	a.Set("db.password", "hunter2")
+	// 同样的生效值，不同的写入顺序与来源层
+	b, _ := NewConfig()
+	b.SetDefault("db.password", "hunter2")
+	b.SetOverride("port", "80")
+	b.Set("name", "app")
+
+	h := a.Hash()
+	if len(h) != 64 || h != b.Hash() {
+		t.Errorf("Hash = %s and %s, want equal 64-character digests", h, b.Hash())
+	}
+	if a.HasChangedSince(h) || b.HasChangedSince(h) {
+		t.Error("HasChangedSince reported a change for equal configs")
+	}
+
+	// 敏感键以明文参与计算
+	b.SetDefault("db.password", "other")
+	if !b.HasChangedSince(h) {
+		t.Error("HasChangedSince missed a change to a secret value")
+	}
+	// 长度前缀使键与值的边界不同的配置摘要不同
+	c, _ := NewConfig()
+	c.Set("ab", "c")
+	d, _ := NewConfig()
+	d.Set("a", "bc")
+	if c.Hash() == d.Hash() {
+		t.Error("Hash does not separate keys from values")
+	}
+}
@@ -0,0 +1,490 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LoadFromHCL 从HCL文件加载配置
+// 属性以名称为键，块的类型与标签依次以"."连接作为前缀，
+// 例如job "web" { group "api" { count = 2 } }对应键"job.web.group.api.count"；
+// 同一层中类型与标签都相同的块重复出现时使用"[i]"下标，列表与对象的展开规则与JSON相同。
+// 支持#、//与/* */注释、字符串转义、<<EOF与<<-EOF heredoc；
+// 字符串中的${...}模板以及引用、函数调用等表达式不求值，按原始文本保存。
+// 启用profile时随后加载同目录下的profile文件，规则与LoadFromFile相同。
+// 参数:
+// - filename: HCL文件路径
+// 返回:
+// - error: 文件读取或HCL解析错误(如果有)
+func (c *Config) LoadFromHCL(filename string) error {
+	content, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	values, err := decodeHCL(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	if err := c.mergeValuesFrom(values, KeySource{Kind: SourceFile, Name: filename}); err != nil {
+		return err
+	}
+	return c.loadProfileFile(context.Background(), filename, loadSettings{})
+}
+
+// decodeHCL 解析HCL文档并展开为扁平键值对
+func decodeHCL(content []byte) (map[string]string, error) {
+	if !utf8.Valid(content) {
+		return nil, errors.New("hcl: invalid UTF-8")
+	}
+	p := &hclParser{s: strings.ReplaceAll(string(content), "\r\n", "\n"), line: 1}
+	root := make(map[string]interface{})
+	if err := p.parseBody(root, false); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	flattenValue("", root, values)
+	return values, nil
+}
+
+// hclParser 是逐字符工作的HCL解析器，只处理配置文件所需的语法子集
+type hclParser struct {
+	s    string
+	i    int
+	line int
+}
+
+// errorf 返回带当前行号的解析错误
+func (p *hclParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("hcl: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *hclParser) eof() bool { return p.i >= len(p.s) }
+
+func (p *hclParser) peekByte() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+// skip 跳过行内空白与/* */注释，newlines为true时同时跳过换行与行注释
+func (p *hclParser) skip(newlines bool) {
+	for !p.eof() {
+		switch ch := p.s[p.i]; {
+		case ch == ' ' || ch == '\t':
+			p.i++
+		case strings.HasPrefix(p.s[p.i:], "/*"):
+			end := strings.Index(p.s[p.i+2:], "*/")
+			if end < 0 {
+				p.i = len(p.s)
+				return
+			}
+			p.line += strings.Count(p.s[p.i:p.i+2+end], "\n")
+			p.i += end + 4
+		case !newlines:
+			return
+		case ch == '\n':
+			p.i++
+			p.line++
+		case ch == '#' || strings.HasPrefix(p.s[p.i:], "//"):
+			for !p.eof() && p.s[p.i] != '\n' {
+				p.i++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// expectLineEnd 要求当前行剩余部分只有空白或注释
+func (p *hclParser) expectLineEnd() error {
+	p.skip(false)
+	if p.eof() || p.s[p.i] == '\n' || p.s[p.i] == '#' || strings.HasPrefix(p.s[p.i:], "//") {
+		return nil
+	}
+	if p.s[p.i] == '}' {
+		// 单行块如block { a = 1 }
+		return nil
+	}
+	return p.errorf("unexpected %q at end of line", p.s[p.i:min(len(p.s), p.i+10)])
+}
+
+// parseBody 解析属性与块直到文件结束，nested为true时解析到匹配的"}"为止
+func (p *hclParser) parseBody(body map[string]interface{}, nested bool) error {
+	for {
+		p.skip(true)
+		if p.eof() {
+			if nested {
+				return p.errorf("unterminated block")
+			}
+			return nil
+		}
+		if p.s[p.i] == '}' {
+			if !nested {
+				return p.errorf("unexpected '}'")
+			}
+			p.i++
+			return nil
+		}
+		name, err := p.parseIdent()
+		if err != nil {
+			return err
+		}
+		p.skip(false)
+		if p.peekByte() == '=' {
+			p.i++
+			p.skip(false)
+			value, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			if _, exists := body[name]; exists {
+				return p.errorf("attribute %q is defined twice", name)
+			}
+			body[name] = value
+		} else if err := p.parseBlock(body, name); err != nil {
+			return err
+		}
+		if err := p.expectLineEnd(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseBlock 解析块的标签与块体并写入body
+func (p *hclParser) parseBlock(body map[string]interface{}, blockType string) error {
+	path := []string{blockType}
+	for {
+		p.skip(false)
+		switch ch := p.peekByte(); {
+		case ch == '{':
+			p.i++
+			block := make(map[string]interface{})
+			if err := p.parseBody(block, true); err != nil {
+				return err
+			}
+			return p.attachBlock(body, path, block)
+		case ch == '"':
+			label, err := p.parseString()
+			if err != nil {
+				return err
+			}
+			path = append(path, label)
+		case isHCLIdentChar(ch):
+			label, _ := p.parseIdent()
+			path = append(path, label)
+		default:
+			return p.errorf("expected '=' or block body after %q", blockType)
+		}
+	}
+}
+
+// attachBlock 把块放到类型与标签组成的路径下，同一路径的块重复出现时合并为列表
+func (p *hclParser) attachBlock(body map[string]interface{}, path []string, block map[string]interface{}) error {
+	parent := body
+	for _, name := range path[:len(path)-1] {
+		switch next := parent[name].(type) {
+		case nil:
+			child := make(map[string]interface{})
+			parent[name] = child
+			parent = child
+		case map[string]interface{}:
+			parent = next
+		default:
+			return p.errorf("%q is already defined as an attribute", name)
+		}
+	}
+	last := path[len(path)-1]
+	switch existing := parent[last].(type) {
+	case nil:
+		parent[last] = block
+	case map[string]interface{}:
+		parent[last] = []interface{}{existing, block}
+	case []interface{}:
+		parent[last] = append(existing, block)
+	default:
+		return p.errorf("%q is already defined as an attribute", strings.Join(path, "."))
+	}
+	return nil
+}
+
+// parseIdent 解析标识符
+func (p *hclParser) parseIdent() (string, error) {
+	start := p.i
+	for !p.eof() && isHCLIdentChar(p.s[p.i]) {
+		p.i++
+	}
+	if start == p.i {
+		return "", p.errorf("expected identifier, found %q", p.s[p.i:min(len(p.s), p.i+10)])
+	}
+	return p.s[start:p.i], nil
+}
+
+// isHCLIdentChar 判断字符是否可出现在标识符中
+func isHCLIdentChar(ch byte) bool {
+	return ch >= 'A' && ch <= 'Z' || ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-' || ch >= 0x80
+}
+
+// parseExpr 解析属性值
+func (p *hclParser) parseExpr() (interface{}, error) {
+	switch ch := p.peekByte(); {
+	case ch == '"':
+		return p.parseString()
+	case strings.HasPrefix(p.s[p.i:], "<<"):
+		return p.parseHeredoc()
+	case ch == '[':
+		return p.parseList()
+	case ch == '{':
+		return p.parseObject()
+	case p.eof() || ch == '\n':
+		return nil, p.errorf("expected value")
+	}
+	raw := p.parseRawExpr()
+	if raw == "null" {
+		return nil, nil
+	}
+	return raw, nil
+}
+
+// parseList 解析[a, b]形式的元组，允许跨行、注释和末尾逗号
+func (p *hclParser) parseList() (interface{}, error) {
+	p.i++
+	result := []interface{}{}
+	for {
+		p.skip(true)
+		if p.peekByte() == ']' {
+			p.i++
+			return result, nil
+		}
+		v, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+		p.skip(true)
+		switch p.peekByte() {
+		case ',':
+			p.i++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in list")
+		}
+	}
+}
+
+// parseObject 解析{k = v, "k2": v2}形式的对象，元素之间用逗号或换行分隔
+func (p *hclParser) parseObject() (interface{}, error) {
+	p.i++
+	result := make(map[string]interface{})
+	for {
+		p.skip(true)
+		if p.peekByte() == '}' {
+			p.i++
+			return result, nil
+		}
+		var key string
+		var err error
+		if p.peekByte() == '"' {
+			key, err = p.parseString()
+		} else {
+			key, err = p.parseIdent()
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skip(false)
+		if ch := p.peekByte(); ch != '=' && ch != ':' {
+			return nil, p.errorf("expected '=' or ':' after object key %q", key)
+		}
+		p.i++
+		p.skip(false)
+		v, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		result[key] = v
+		p.skip(false)
+		switch p.peekByte() {
+		case ',':
+			p.i++
+		case '}', '\n', '#', '/':
+		default:
+			return nil, p.errorf("expected ',' or '}' in object")
+		}
+	}
+}
+
+// parseString 解析双引号字符串，模板序列${...}与%{...}原样保留
+func (p *hclParser) parseString() (string, error) {
+	p.i++
+	var b strings.Builder
+	depth := 0
+	for {
+		if p.eof() || p.s[p.i] == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		ch := p.s[p.i]
+		switch {
+		case ch == '"' && depth == 0:
+			p.i++
+			return b.String(), nil
+		case ch == '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		case strings.HasPrefix(p.s[p.i:], "$${") || strings.HasPrefix(p.s[p.i:], "%%{"):
+			b.WriteString(p.s[p.i+1 : p.i+3])
+			p.i += 3
+		case strings.HasPrefix(p.s[p.i:], "${") || strings.HasPrefix(p.s[p.i:], "%{"):
+			b.WriteString(p.s[p.i : p.i+2])
+			p.i += 2
+			depth++
+		case ch == '}' && depth > 0:
+			b.WriteByte(ch)
+			p.i++
+			depth--
+		default:
+			b.WriteByte(ch)
+			p.i++
+		}
+	}
+}
+
+// parseEscape 解析字符串中的转义序列
+func (p *hclParser) parseEscape(b *strings.Builder) error {
+	p.i++
+	if p.eof() {
+		return p.errorf("unterminated escape sequence")
+	}
+	ch := p.s[p.i]
+	p.i++
+	switch ch {
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case '"', '\\':
+		b.WriteByte(ch)
+	case 'u', 'U':
+		size := 4
+		if ch == 'U' {
+			size = 8
+		}
+		if p.i+size > len(p.s) {
+			return p.errorf("short unicode escape")
+		}
+		n, err := strconv.ParseUint(p.s[p.i:p.i+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(n)) {
+			return p.errorf("invalid unicode escape \\%c%s", ch, p.s[p.i:p.i+size])
+		}
+		b.WriteRune(rune(n))
+		p.i += size
+	default:
+		return p.errorf("invalid escape sequence \\%c", ch)
+	}
+	return nil
+}
+
+// parseHeredoc 解析<<EOF或<<-EOF形式的多行字符串
+// <<-形式去除各行共同的前导空白，结束标记所在行只能包含标记本身
+func (p *hclParser) parseHeredoc() (string, error) {
+	p.i += 2
+	indent := p.peekByte() == '-'
+	if indent {
+		p.i++
+	}
+	marker, err := p.parseIdent()
+	if err != nil {
+		return "", err
+	}
+	if p.peekByte() != '\n' {
+		return "", p.errorf("expected newline after heredoc marker %q", marker)
+	}
+	p.i++
+	p.line++
+	start := p.line
+
+	var lines []string
+	for !p.eof() {
+		end := strings.IndexByte(p.s[p.i:], '\n')
+		if end < 0 {
+			end = len(p.s) - p.i
+		}
+		line := p.s[p.i : p.i+end]
+		p.i += end
+		if strings.TrimSpace(line) == marker {
+			return joinHeredoc(lines, indent), nil
+		}
+		lines = append(lines, line)
+		if !p.eof() {
+			p.i++
+			p.line++
+		}
+	}
+	return "", fmt.Errorf("hcl: line %d: unterminated heredoc %q", start, marker)
+}
+
+// joinHeredoc 拼接heredoc的各行，indent为true时去除共同的前导空白
+func joinHeredoc(lines []string, indent bool) string {
+	if indent {
+		common := -1
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			n := len(line) - len(strings.TrimLeft(line, " \t"))
+			if common < 0 || n < common {
+				common = n
+			}
+		}
+		for i, line := range lines {
+			if len(line) >= common && common > 0 {
+				lines[i] = line[common:]
+			} else {
+				lines[i] = strings.TrimLeft(line, " \t")
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// parseRawExpr 读取数字、布尔值、引用或函数调用等表达式的原始文本
+// 表达式在行尾或外层的","、"]"、"}"处结束，括号内的内容不拆分
+func (p *hclParser) parseRawExpr() string {
+	start := p.i
+	depth := 0
+	for !p.eof() {
+		ch := p.s[p.i]
+		switch {
+		case ch == '"':
+			// 跳过表达式中的字符串，其中的括号不计入嵌套
+			for p.i++; !p.eof() && p.s[p.i] != '"' && p.s[p.i] != '\n'; p.i++ {
+				if p.s[p.i] == '\\' {
+					p.i++
+				}
+			}
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			if depth == 0 {
+				return strings.TrimSpace(p.s[start:p.i])
+			}
+			depth--
+		case depth == 0 && (ch == '\n' || ch == ',' || ch == '#' || strings.HasPrefix(p.s[p.i:], "//")):
+			return strings.TrimSpace(p.s[start:p.i])
+		}
+		if !p.eof() {
+			p.i++
+		}
+	}
+	return strings.TrimSpace(p.s[start:p.i])
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromHCL(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"web.hcl": `# Nomad style job
+region = "global"
+datacenters = ["dc1", "dc2",]
+
+job "web" {
+  priority = 50 // trailing comment
+
+  group "api" {
+    count = 2
+    meta = {
+      owner = "core"
+      "tier": "frontend"
+    }
+  }
+
+  /* two tasks of the same type and label */
+  task "nginx" { cpu = 500 }
+  task "nginx" { cpu = 250 }
+}
+
+template {
+  data = <<-EOT
+    upstream = ${NOMAD_UPSTREAM_ADDR}
+      keepalive on
+    EOT
+  destination = "local/$${file}.conf"
+  port = var.port
+  limit = max(1, 2)
+  extra = null
+  greeting = "hi\tthere \u00e9"
+}
+`,
+		"web-prod.hcl": "job \"web\" {\n  group \"api\" {\n    count = 6\n  }\n}\n",
+	})
+	path := filepath.Join(dir, "web.hcl")
+
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromHCL(path); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"region":                       "global",
+		"datacenters[0]":               "dc1",
+		"datacenters[1]":               "dc2",
+		"job.web.priority":             "50",
+		"job.web.group.api.count":      "2",
+		"job.web.group.api.meta.owner": "core",
+		"job.web.group.api.meta.tier":  "frontend",
+		"job.web.task.nginx[0].cpu":    "500",
+		"job.web.task.nginx[1].cpu":    "250",
+		// 模板与表达式不求值，按原始文本保存
+		"template.data":        "upstream = ${NOMAD_UPSTREAM_ADDR}\n  keepalive on\n",
+		"template.destination": "local/${file}.conf",
+		"template.port":        "var.port",
+		"template.limit":       "max(1, 2)",
+		"template.extra":       "",
+		"template.greeting":    "hi\tthere é",
+	}
+	if got := cfg.GetAll(); len(got) != len(want) {
+		t.Errorf("GetAll = %v, want %d keys", got, len(want))
+	}
+	for key, v := range want {
+		if got := cfg.Get(key); got != v {
+			t.Errorf("Get(%s) = %q, want %q", key, got, v)
+		}
+	}
+	if ports, _ := cfg.GetStringSlice("datacenters"); len(ports) != 2 {
+		t.Errorf("GetStringSlice(datacenters) = %v", ports)
+	}
+
+	prod, _ := NewConfig()
+	prod.SetProfile("prod")
+	if err := prod.LoadFromHCL(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := prod.Get("job.web.group.api.count"); got != "6" {
+		t.Errorf("count with the prod profile = %q, want 6", got)
+	}
+}
+
+func TestLoadFromHCLErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.hcl")
+	tests := map[string]string{
+		"a = 1\nb = 2\na = 3\n":         "line 3: attribute \"a\" is defined twice",
+		"job \"web\" {\n  count = 1\n":  "unterminated block",
+		"a = 1\n}\n":                    "line 2: unexpected '}'",
+		"name = \"app\n":                "unterminated string",
+		"body = <<EOF\nno end marker\n": "EOF",
+		"port =\n":                      "expected value",
+		"a = \"x\" y\n":                 "at end of line",
+		"a = \"\\q\"\n":                 "invalid escape sequence",
+		"task = 1\ntask { cpu = 1 }\n":  "already defined as an attribute",
+		"a = \"\xff\"\n":                "invalid UTF-8",
+		"= 1\n":                         "expected identifier",
+	}
+	for content, want := range tests {
+		os.WriteFile(path, []byte(content), 0o644)
+		cfg, _ := NewConfig()
+		err := cfg.LoadFromHCL(path)
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.HasPrefix(err.Error(), path+": ") {
+			t.Errorf("LoadFromHCL(%q) error = %v, want the file name and %q", content, err, want)
+		}
+		if cfg.Len() != 0 {
+			t.Errorf("LoadFromHCL(%q) kept values %v after an error", content, cfg.GetAll())
+		}
+	}
+}
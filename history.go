@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// HistoryEntry 是History返回的一个键在某个修订中的变化
+type HistoryEntry struct {
+	// Revision 是产生该变化的修订号
+	Revision uint64
+	// Time 是修订发生的时间
+	Time time.Time
+	// Actor 是UpdateAs提供的操作者，其它方法引起的变化为空
+	Actor string
+	// Old 是变化前的原始值，新增的键为空
+	Old string
+	// New 是变化后的原始值，删除的键为空
+	New string
+	// Deleted 表示键在该修订中被删除
+	Deleted bool
+}
+
+// revision 是历史记录中的一个修订
+type revision struct {
+	number  uint64
+	time    time.Time
+	changes []change
+	after   *Snapshot // 修订完成后的状态
+}
+
+// Revision 返回当前修订号
+// 每次导致至少一个键生效值变化的修改使修订号加1，修订号从0开始单调递增，不受EnableHistory影响
+func (c *Config) Revision() uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.revision
+}
+
+// EnableHistory 开启内存中的修订历史，最多保留最近limit个修订
+// 开启后History可以查询键的变化，Undo可以撤销最近的修改。
+// 每个修订保存一份所有层数据的副本，配置很大或修改很频繁时应设置较小的limit。
+// 再次调用会清空已有历史；limit不大于0时关闭历史记录
+// 参数:
+// - limit: 保留的修订数上限
+func (c *Config) EnableHistory(limit int) {
+	c.lock()
+	defer c.mutex.Unlock()
+	c.history = nil
+	c.historyBase = nil
+	c.historyLimit = 0
+	if limit > 0 {
+		c.historyLimit = limit
+		c.historyBase = c.snapshotLocked()
+	}
+}
+
+// History 返回历史记录中键的变化，按修订从旧到新排列
+// 参数:
+// - key: 配置键
+// 返回:
+// - []HistoryEntry: 键的变化列表，未开启历史或键没有变化时为空
+func (c *Config) History(key string) []HistoryEntry {
+	key = c.normKey(key)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	var entries []HistoryEntry
+	for _, rev := range c.history {
+		for _, ch := range rev.changes {
+			if ch.key == key {
+				entries = append(entries, HistoryEntry{
+					Revision: rev.number,
+					Time:     rev.time,
+					Actor:    ch.actor,
+					Old:      ch.old,
+					New:      ch.new,
+					Deleted:  !ch.newOK,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// Undo 撤销最近的n个修订，将所有层数据恢复到这些修订之前的状态
+// 被撤销的修订从历史中移除，因此连续调用Undo(1)会逐个向前回退；Undo本身使修订号加1。
+// 恢复导致的生效值变化会通知订阅者
+// 参数:
+// - n: 要撤销的修订数
+// 返回:
+// - error: 未开启历史、n不大于0或超过保留的修订数时返回错误，配置已冻结时返回ErrFrozen
+func (c *Config) Undo(n int) error {
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	if c.historyLimit == 0 {
+		c.mutex.Unlock()
+		return fmt.Errorf("history is not enabled")
+	}
+	if n <= 0 || n > len(c.history) {
+		c.mutex.Unlock()
+		return fmt.Errorf("cannot undo %d revisions: %d available", n, len(c.history))
+	}
+	target := c.historyBase
+	if keep := len(c.history) - n; keep > 0 {
+		target = c.history[keep-1].after
+	}
+	c.history = c.history[:len(c.history)-n]
+	changes := c.restoreLocked(target)
+	if len(changes) > 0 {
+		c.revision++
+	}
+	c.mutex.Unlock()
+	c.notify(changes)
+	return nil
+}
+
+// unlockNotify 记录修订、释放写锁并通知订阅者
+// 所有修改配置的方法在持有写锁完成修改后调用它代替直接解锁
+func (c *Config) unlockNotify(changes []change) {
+	if len(changes) > 0 {
+		c.revision++
+		if c.historyLimit > 0 {
+			c.recordRevisionLocked(changes)
+		}
+	}
+	c.mutex.Unlock()
+	c.notify(changes)
+}
+
+// recordRevisionLocked 追加一个修订，超过上限时丢弃最旧的修订，调用方须持有写锁
+func (c *Config) recordRevisionLocked(changes []change) {
+	c.history = append(c.history, revision{
+		number:  c.revision,
+		time:    time.Now(),
+		changes: append([]change(nil), changes...),
+		after:   c.snapshotLocked(),
+	})
+	if len(c.history) > c.historyLimit {
+		c.historyBase = c.history[0].after
+		c.history = append(c.history[:0:0], c.history[1:]...)
+	}
+}
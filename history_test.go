@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHistoryUndo(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("port", "80")
+	if cfg.Revision() != 1 {
+		t.Errorf("Revision() = %d, want 1", cfg.Revision())
+	}
+	if err := cfg.Undo(1); err == nil {
+		t.Error("Undo without history succeeded")
+	}
+
+	cfg.EnableHistory(3)
+	cfg.Set("port", "8080")
+	cfg.UpdateAs("alice", func(tx *Txn) error {
+		tx.Set("port", "9090")
+		return tx.Set("name", "app")
+	})
+	cfg.Set("port", "9090") // 值未变化，不产生修订
+	cfg.Delete("name")
+	if cfg.Revision() != 4 {
+		t.Errorf("Revision() = %d, want 4", cfg.Revision())
+	}
+
+	h := cfg.History("port")
+	if len(h) != 2 || h[0].Old != "80" || h[0].New != "8080" || h[1].Actor != "alice" || h[1].Revision != 3 {
+		t.Errorf("History(port) = %+v", h)
+	}
+	if h := cfg.History("name"); len(h) != 2 || !h[1].Deleted {
+		t.Errorf("History(name) = %+v, want the add and the delete", h)
+	}
+
+	var changed []string
+	cfg.Subscribe("*", func(key, _, _ string) { changed = append(changed, key) })
+	if err := cfg.Undo(1); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Get("name") != "app" || len(changed) != 1 {
+		t.Errorf("after Undo(1) name = %q, notified %v", cfg.Get("name"), changed)
+	}
+	if err := cfg.Undo(2); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Get("port") != "80" || cfg.Has("name") {
+		t.Errorf("after Undo(2) = %v, want the state before the history", cfg.GetAll())
+	}
+	if cfg.Revision() != 6 {
+		t.Errorf("Revision() after two undos = %d, want 6", cfg.Revision())
+	}
+	if err := cfg.Undo(1); err == nil {
+		t.Error("Undo beyond the history succeeded")
+	}
+
+	// 超过上限时丢弃最旧的修订
+	for _, v := range []string{"1", "2", "3", "4"} {
+		cfg.Set("port", v)
+	}
+	if h := cfg.History("port"); len(h) != 3 || h[0].New != "2" {
+		t.Errorf("History(port) with limit 3 = %+v", h)
+	}
+	if err := cfg.Undo(3); err != nil || cfg.Get("port") != "1" {
+		t.Errorf("Undo(3) = %v, port = %q, want the oldest kept state", err, cfg.Get("port"))
+	}
+	cfg.Freeze()
+	if err := cfg.Undo(1); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Undo on a frozen config error = %v, want ErrFrozen", err)
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+// HTTPProvider 通过HTTP/HTTPS下载配置文档
+// 文档格式按照Format、响应的Content-Type、URL扩展名的顺序确定，
+// 均无法确定时根据内容猜测，规则与LoadFromFile相同。重复请求时携带If-None-Match与
+// If-Modified-Since，服务端返回304时视为未变化。
+type HTTPProvider struct {
+	// URL 是配置文档地址
+	URL string
+	// Client 用于发送请求，为nil时使用http.DefaultClient
+	Client *http.Client
+	// Header 是附加到每个请求的请求头，如Authorization
+	Header http.Header
+	// Format 强制指定文档格式，如"json"、"yaml"、"toml"、"ini"
+	Format string
+	// RefreshInterval 是Watch轮询的间隔
+	RefreshInterval time.Duration
+
+	mutex        sync.Mutex
+	etag         string
+	lastModified string
+	last         map[string]string
+	ctx          context.Context
+	cancel       context.CancelFunc
+}
+
+// URLOption 用于定制HTTPProvider和LoadFromURL
+type URLOption func(*HTTPProvider)
+
+// WithHTTPClient 设置发送请求使用的HTTP客户端
+func WithHTTPClient(client *http.Client) URLOption {
+	return func(p *HTTPProvider) {
+		p.Client = client
+	}
+}
+
+// WithHeader 为每个请求添加一个请求头
+func WithHeader(key, value string) URLOption {
+	return func(p *HTTPProvider) {
+		p.Header.Add(key, value)
+	}
+}
+
+// WithFormat 强制使用指定格式解析文档，忽略Content-Type与扩展名
+func WithFormat(format string) URLOption {
+	return func(p *HTTPProvider) {
+		p.Format = format
+	}
+}
+
+// WithRefreshInterval 设置Watch定期刷新的间隔，默认为30秒
+func WithRefreshInterval(d time.Duration) URLOption {
+	return func(p *HTTPProvider) {
+		if d > 0 {
+			p.RefreshInterval = d
+		}
+	}
+}
+
+// NewHTTPProvider 创建从rawURL下载配置的配置源
+// 参数:
+// - rawURL: 配置文档地址
+// - opts: 可选设置
+// 返回:
+// - *HTTPProvider: 配置源实例
+func NewHTTPProvider(rawURL string, opts ...URLOption) *HTTPProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &HTTPProvider{
+		URL:             rawURL,
+		Header:          make(http.Header),
+		RefreshInterval: 30 * time.Second,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// LoadFromURL 下载配置文档并合并到文件层
+// 需要定期刷新时使用WatchProvider(NewHTTPProvider(url, WithRefreshInterval(d)))
+// 参数:
+// - rawURL: 配置文档地址
+// - opts: 可选设置
+// 返回:
+// - error: 请求或解析错误(如果有)
+func (c *Config) LoadFromURL(rawURL string, opts ...URLOption) error {
+	return c.LoadFromURLContext(context.Background(), rawURL, opts...)
+}
+
+// LoadFromURLContext 与LoadFromURL相同，ctx取消或超时时中止下载
+// 参数:
+// - ctx: 约束下载过程的context
+// - rawURL: 配置文档地址
+// - opts: 请求头、格式等可选设置
+// 返回:
+// - error: 请求、解析错误或ctx的错误(如果有)
+func (c *Config) LoadFromURLContext(ctx context.Context, rawURL string, opts ...URLOption) error {
+	p := NewHTTPProvider(rawURL, opts...)
+	defer p.Close()
+	return c.LoadFromProviderContext(ctx, p)
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *HTTPProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// Load 下载并解析配置文档
+func (p *HTTPProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时中止下载
+func (p *HTTPProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	values, _, err := p.fetch(ctx, false)
+	return values, err
+}
+
+// Watch 按RefreshInterval定期刷新文档，内容变化时发送更新
+func (p *HTTPProvider) Watch(ch chan<- Update) error {
+	ticker := time.NewTicker(p.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return ErrProviderClosed
+		case <-ticker.C:
+		}
+
+		values, changed, err := p.fetch(p.ctx, true)
+		if p.ctx.Err() != nil {
+			return ErrProviderClosed
+		}
+		if err == nil && !changed {
+			continue
+		}
+		select {
+		case ch <- Update{Values: values, Err: err}:
+		case <-p.ctx.Done():
+			return ErrProviderClosed
+		}
+	}
+}
+
+// fetch 下载文档；conditional为true时携带缓存校验头
+// 第二个返回值表示文档相对上次是否变化
+func (p *HTTPProvider) fetch(ctx context.Context, conditional bool) (map[string]string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, vs := range p.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	p.mutex.Lock()
+	if conditional && p.last != nil {
+		if p.etag != "" {
+			req.Header.Set("If-None-Match", p.etag)
+		}
+		if p.lastModified != "" {
+			req.Header.Set("If-Modified-Since", p.lastModified)
+		}
+	}
+	p.mutex.Unlock()
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		return p.last, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("fetch %s: %s", p.URL, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch %s: %w", p.URL, err)
+	}
+
+	values, err := decodeByExtension(p.documentName(resp), body)
+	if err != nil {
+		return nil, false, fmt.Errorf("fetch %s: %w", p.URL, err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.etag = resp.Header.Get("ETag")
+	p.lastModified = resp.Header.Get("Last-Modified")
+	p.last = values
+	return values, true, nil
+}
+
+// documentName 返回用于按扩展名选择解析器的文档名
+func (p *HTTPProvider) documentName(resp *http.Response) string {
+	if p.Format != "" {
+		return "document." + p.Format
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		switch mediaType {
+		case "application/json":
+			return "document.json"
+		case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+			return "document.yaml"
+		case "application/toml", "text/toml":
+			return "document.toml"
+		case "application/xml", "text/xml":
+			return "document.xml"
+		}
+	}
+	if u, err := url.Parse(p.URL); err == nil {
+		return path.Base(u.Path)
+	}
+	return ""
+}
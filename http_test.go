@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConfigHost 以ETag提供一个可修改的配置文档
+type fakeConfigHost struct {
+	mutex       sync.Mutex
+	contentType string
+	body        string
+	version     int
+	requests    chan http.Header
+}
+
+func (h *fakeConfigHost) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	select {
+	case h.requests <- r.Header.Clone():
+	default:
+	}
+	etag := fmt.Sprintf(`"v%d"`, h.version)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	if h.contentType != "" {
+		w.Header().Set("Content-Type", h.contentType)
+	}
+	fmt.Fprint(w, h.body)
+}
+
+func (h *fakeConfigHost) set(body string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.body = body
+	h.version++
+}
+
+func TestLoadFromURLFormat(t *testing.T) {
+	docs := map[string]string{
+		"json": `{"server": {"port": 8080}}`,
+		"yaml": "server:\n  port: 8080\n",
+		"ini":  "[server]\nport = 8080\n",
+	}
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		opts        []URLOption
+	}{
+		{"content type", "/config", "application/json; charset=utf-8", docs["json"], nil},
+		{"extension", "/app.yaml", "text/plain", docs["yaml"], nil},
+		{"format option wins", "/app.json", "application/json", docs["ini"], []URLOption{WithFormat("ini")}},
+		{"sniffed", "/config", "", docs["json"], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := &fakeConfigHost{contentType: tt.contentType, body: tt.body, requests: make(chan http.Header, 1)}
+			mux := http.NewServeMux()
+			mux.Handle(tt.path, host)
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			cfg, _ := NewConfig()
+			opts := append(tt.opts, WithHeader("Authorization", "Bearer t"))
+			if err := cfg.LoadFromURL(srv.URL+tt.path, opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.Get("server.port"); got != "8080" {
+				t.Errorf("server.port = %q, want 8080", got)
+			}
+			if h := <-host.requests; h.Get("Authorization") != "Bearer t" {
+				t.Errorf("Authorization header = %q", h.Get("Authorization"))
+			}
+		})
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromURL(srv.URL + "/missing.json"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("LoadFromURL of a missing document error = %v, want the 404 status", err)
+	}
+}
+
+func TestHTTPProviderWatchConditional(t *testing.T) {
+	host := &fakeConfigHost{contentType: "application/json", body: `{"a": "1"}`, requests: make(chan http.Header, 16)}
+	srv := httptest.NewServer(host)
+	defer srv.Close()
+
+	p := NewHTTPProvider(srv.URL, WithRefreshInterval(10*time.Millisecond))
+	if _, err := p.Load(); err != nil {
+		t.Fatal(err)
+	}
+	<-host.requests
+	ch := make(chan Update, 4)
+	done := make(chan error, 1)
+	go func() { done <- p.Watch(ch) }()
+	defer func() {
+		p.Close()
+		<-done
+	}()
+
+	// 未变化时携带If-None-Match并得到304，不发送更新
+	select {
+	case h := <-host.requests:
+		if h.Get("If-None-Match") != `"v0"` {
+			t.Errorf("If-None-Match = %q, want the ETag of the first response", h.Get("If-None-Match"))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a refresh")
+	}
+	select {
+	case u := <-ch:
+		t.Fatalf("update %v for an unchanged document", u)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	host.set(`{"a": "2"}`)
+	select {
+	case u := <-ch:
+		if u.Err != nil || u.Values["a"] != "2" {
+			t.Errorf("update = %+v, want a=2", u)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+}
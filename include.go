@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxIncludeDepth 是include指令允许的最大嵌套深度
+const maxIncludeDepth = 16
+
+// includeTarget 识别"include path"或"@include path"指令并返回路径
+// 含"="的"include ..."行仍按键值对解析，路径两侧的引号被去除
+func includeTarget(line string) (string, bool) {
+	for _, directive := range []string{"@include", "include"} {
+		rest, ok := strings.CutPrefix(line, directive)
+		if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		if directive == "include" && strings.Contains(rest, "=") {
+			return "", false
+		}
+		target := strings.Trim(strings.TrimSpace(rest), `"'`)
+		return target, target != ""
+	}
+	return "", false
+}
+
+// includeFiles 加载include指令引用的文件
+// 相对路径相对于包含它的文件所在目录解析，内容来自io.Reader时相对于当前目录；
+// 路径含通配符时按字典序加载所有匹配的文件，没有匹配时忽略
+func (p *kvParser) includeFiles(from, target string) error {
+	path := target
+	if !filepath.IsAbs(path) && from != "" {
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	if !strings.ContainsAny(path, "*?[") {
+		return p.includeFile(path)
+	}
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return fmt.Errorf("include %s: %w", target, err)
+	}
+	sort.Strings(matches)
+	for _, match := range matches {
+		if err := p.includeFile(match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includeFile 解析被包含的文件，检测循环包含与嵌套深度
+func (p *kvParser) includeFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("include %s: %w", path, err)
+	}
+	for _, f := range p.stack {
+		if f == abs {
+			return fmt.Errorf("include %s: include cycle: %s -> %s", path, strings.Join(p.stack, " -> "), abs)
+		}
+	}
+	if len(p.stack) >= maxIncludeDepth {
+		return fmt.Errorf("include %s: include depth exceeds %d", path, maxIncludeDepth)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("include %s: %w", path, err)
+	}
+	defer file.Close()
+
+	p.stack = append(p.stack, abs)
+	defer func() { p.stack = p.stack[:len(p.stack)-1] }()
+	return p.parse(file, path, nil)
+}
+
+// displayName 返回错误信息中使用的内容名称
+func displayName(name string) string {
+	if name == "" {
+		return "<input>"
+	}
+	return name
+}
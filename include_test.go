@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles 在dir下写入一组文件，返回dir
+func writeFiles(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestInclude(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"app.conf":          "name = app\ninclude common.conf\nport = 9090\n@include \"conf.d/*.conf\"\ninclude = not a directive\n",
+		"common.conf":       "port = 80\nlog.level = info\n",
+		"conf.d/10-db.conf": "db.host = localhost\n",
+		"conf.d/20-db.conf": "db.host = db1\n",
+	})
+	main := filepath.Join(dir, "app.conf")
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(main); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"name":      "app",
+		"port":      "9090", // include之后的行覆盖被包含文件中的值
+		"log.level": "info",
+		"db.host":   "db1", // 通配符按字典序加载
+		"include":   "not a directive",
+	}
+	for key, v := range want {
+		if got := cfg.Get(key); got != v {
+			t.Errorf("Get(%s) = %q, want %q", key, got, v)
+		}
+	}
+
+	// 保存时保留include指令，未修改的引入键不写入主文件，修改过的写在指令之后
+	cfg.Set("log.level", "debug")
+	if err := cfg.SaveToFile(main); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(main)
+	saved := string(data)
+	if !strings.Contains(saved, "include common.conf\n") || strings.Contains(saved, "db.host") {
+		t.Errorf("saved file =\n%s\nwant the directives kept and unmodified included keys omitted", saved)
+	}
+	reloaded, _ := NewConfig()
+	if err := reloaded.LoadFromFile(main); err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Get("log.level"); got != "debug" {
+		t.Errorf("log.level after reload = %q, want the modified debug", got)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"a.conf":       "include b.conf\n",
+		"b.conf":       "include a.conf\n",
+		"missing.conf": "include nowhere.conf\n",
+		"self.conf":    "include self.conf\n",
+	})
+	tests := map[string]string{
+		"a.conf":       "include cycle",
+		"self.conf":    "include cycle",
+		"missing.conf": "nowhere.conf",
+	}
+	for name, want := range tests {
+		cfg, _ := NewConfig()
+		err := cfg.LoadFromFile(filepath.Join(dir, name))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadFromFile(%s) error = %v, want %q", name, err, want)
+		}
+	}
+
+	// 超过最大嵌套深度
+	files := make(map[string]string)
+	for i := 0; i <= maxIncludeDepth; i++ {
+		files[filepath.Join("deep", strings.Repeat("d", i+1)+".conf")] = "include " + strings.Repeat("d", i+2) + ".conf\n"
+	}
+	files[filepath.Join("deep", strings.Repeat("d", maxIncludeDepth+2)+".conf")] = "end = 1\n"
+	writeFiles(t, dir, files)
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(filepath.Join(dir, "deep", "d.conf")); err == nil || !strings.Contains(err.Error(), "include depth exceeds") {
+		t.Errorf("deep include error = %v, want the depth limit", err)
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SetInterpolation 开启或关闭值中的变量引用展开
+// 开启后Get等读取操作会展开值中的引用：
+// - ${other.key} 替换为其它配置键的值(递归展开)
+// - ${ENV_VAR} 在不存在同名配置键时替换为环境变量的值
+// - ${name:-default} 在引用不存在时使用default
+// - $${ 表示字面量"${"，不做展开
+// 引用既不是配置键也不是环境变量且没有默认值时替换为空字符串。
+// 展开在读取时进行，保存到文件的始终是原始值。
+// 参数:
+// - enabled: 是否开启
+func (c *Config) SetInterpolation(enabled bool) {
+	c.lock()
+	defer c.mutex.Unlock()
+	c.interpolate = enabled
+}
+
+// GetExpanded 获取配置值并展开其中的变量引用，不受SetInterpolation开关影响
+// 与Get不同，引用成环时返回错误
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - string: 展开后的值
+// - error: 键不存在(ErrKeyNotFound)或存在循环引用时返回错误
+func (c *Config) GetExpanded(key string) (string, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	raw, ok := c.resolveLocked(key)
+	if !ok {
+		return "", fmt.Errorf("key %q: %w", key, ErrKeyNotFound)
+	}
+	return c.expandLocked(c.decodeLocked(raw), []string{key})
+}
+
+// expandValueLocked 在开启插值时展开值，出错时返回未展开的值，调用方须持有锁
+func (c *Config) expandValueLocked(key, value string) string {
+	if !c.interpolate || !strings.Contains(value, "${") {
+		return value
+	}
+	expanded, err := c.expandLocked(value, []string{key})
+	if err != nil {
+		return value
+	}
+	return expanded
+}
+
+// expandLocked 展开value中的引用，stack为当前展开链上的键，用于检测循环
+// 调用方须持有锁
+func (c *Config) expandLocked(value string, stack []string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		if strings.HasPrefix(value[i:], "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(value[i:], "${") {
+			b.WriteByte(value[i])
+			i++
+			continue
+		}
+		end := closingBrace(value[i+2:])
+		if end < 0 {
+			// 未闭合的引用按字面量保留
+			b.WriteString(value[i:])
+			break
+		}
+		ref := value[i+2 : i+2+end]
+		i += end + 3
+
+		name, def, hasDef := strings.Cut(ref, ":-")
+		name = strings.TrimSpace(name)
+		for _, k := range stack {
+			if k == name {
+				return "", fmt.Errorf("cyclic reference: %s -> %s", strings.Join(stack, " -> "), name)
+			}
+		}
+
+		if raw, ok := c.resolveLocked(name); ok {
+			expanded, err := c.expandLocked(c.decodeLocked(raw), append(stack, name))
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+		} else if env, ok := os.LookupEnv(name); ok {
+			b.WriteString(env)
+		} else if hasDef {
+			expanded, err := c.expandLocked(def, stack)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+		}
+	}
+	return b.String(), nil
+}
+
+// closingBrace 返回与引用开头匹配的"}"在s中的位置，默认值中可以嵌套${...}；没有时返回-1
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInterpolation(t *testing.T) {
+	t.Setenv("CFG_TEST_HOME", "/home/app")
+	cfg, _ := NewConfig()
+	cfg.Set("host", "db.internal")
+	cfg.Set("port", "5432")
+	cfg.Set("dsn", "postgres://${host}:${port}/app")
+	cfg.Set("nested", "[${dsn}]")
+	cfg.Set("home", "${CFG_TEST_HOME}/data")
+	cfg.Set("fallback", "${missing.key:-${port}}")
+	cfg.Set("literal", "$${host} and ${host")
+	cfg.Set("unknown", "a${nope}b")
+
+	// 未开启时Get返回原始值
+	if got := cfg.Get("dsn"); got != "postgres://${host}:${port}/app" {
+		t.Errorf("Get(dsn) before SetInterpolation = %q", got)
+	}
+	cfg.SetInterpolation(true)
+	tests := map[string]string{
+		"dsn":      "postgres://db.internal:5432/app",
+		"nested":   "[postgres://db.internal:5432/app]",
+		"home":     "/home/app/data",
+		"fallback": "5432",
+		"literal":  "${host} and ${host",
+		"unknown":  "ab",
+	}
+	for key, want := range tests {
+		if got := cfg.Get(key); got != want {
+			t.Errorf("Get(%s) = %q, want %q", key, got, want)
+		}
+	}
+
+	// 保存原始值
+	var buf bytes.Buffer
+	if _, err := cfg.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "dsn = postgres://${host}:${port}/app") {
+		t.Errorf("WriteTo expanded references:\n%s", buf.String())
+	}
+}
+
+func TestInterpolationCycle(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("a", "x${b}")
+	cfg.Set("b", "y${c}")
+	cfg.Set("c", "z${a}")
+
+	_, err := cfg.GetExpanded("a")
+	if err == nil || !strings.Contains(err.Error(), "cyclic reference: a -> b -> c -> a") {
+		t.Errorf("GetExpanded(a) error = %v, want the cycle path", err)
+	}
+	// Get在成环时返回未展开的值
+	cfg.SetInterpolation(true)
+	if got := cfg.Get("a"); got != "x${b}" {
+		t.Errorf("Get(a) with a cycle = %q, want the raw value", got)
+	}
+	if _, err := cfg.GetExpanded("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetExpanded(missing) error = %v, want ErrKeyNotFound", err)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// LoadFromJSON 从JSON文件加载配置
+// 嵌套对象展开为以"."连接的键，数组元素使用"[i]"下标，
+// 例如{"server":{"hosts":["a"]}}对应键"server.hosts[0]"。
+// 加载的键会覆盖已有的同名键，其余键保持不变。
+// 启用profile时随后加载同目录下的profile文件，规则与LoadFromFile相同。
+// 参数:
+// - filename: JSON文件路径
+// 返回:
+// - error: 文件读取或JSON解析错误(如果有)
+func (c *Config) LoadFromJSON(filename string) error {
+	content, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	values, types, err := c.decodeContent("json", content)
+	if err != nil {
+		return err
+	}
+	if err := c.mergeDocumentFrom(filename, values, types, KeySource{Kind: SourceFile, Name: filename}); err != nil {
+		return err
+	}
+	return c.loadProfileFile(context.Background(), filename, loadSettings{})
+}
+
+// SaveToJSON 将文件层配置还原为嵌套结构并以缩进JSON格式保存到文件
+// 与SaveToFile一样通过临时文件加重命名原子写入
+// 数字和布尔字面量以对应JSON类型写出，其余值写为字符串
+// 参数:
+// - filename: 目标文件路径
+// - opts: 文件权限、属主等可选设置
+// 返回:
+// - error: 键路径冲突或文件操作错误(如果有)
+func (c *Config) SaveToJSON(filename string, opts ...SaveOption) error {
+	content, err := encodeJSON(c.LayerValues(LayerFile))
+	if err != nil {
+		return err
+	}
+	return writeBytesAtomic(filename, content, opts...)
+}
+
+// decodeJSON 解析JSON文档并展开为扁平键值对
+func decodeJSON(content []byte) (map[string]string, error) {
+	root, err := parseJSONDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	flattenValue("", root, values)
+	return values, nil
+}
+
+// parseJSONDocument 解析JSON文档，顶层必须是对象且其后不能有其它内容，数字保留为json.Number
+func parseJSONDocument(content []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("json config has data after the top-level object")
+	}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("json config must be an object at top level")
+	}
+	return root, nil
+}
+
+// encodeJSON 将扁平键值对编码为缩进的JSON文档
+func encodeJSON(data map[string]string) ([]byte, error) {
+	tree, err := nestValues(data)
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "nested objects",
+			input: `{"server": {"host": "localhost", "port": 8080}}`,
+			want:  map[string]string{"server.host": "localhost", "server.port": "8080"},
+		},
+		{
+			name:  "scalars",
+			input: `{"b": true, "f": 1.5, "big": 12345678901234567890, "n": null, "s": "x y"}`,
+			want:  map[string]string{"b": "true", "f": "1.5", "big": "12345678901234567890", "n": "", "s": "x y"},
+		},
+		{
+			name:  "arrays",
+			input: `{"hosts": ["a", "b"], "servers": [{"name": "x"}], "empty": []}`,
+			want:  map[string]string{"hosts[0]": "a", "hosts[1]": "b", "servers[0].name": "x", "empty": ""},
+		},
+		{
+			name:  "empty object",
+			input: `{"a": {}}`,
+			want:  map[string]string{"a": ""},
+		},
+		{name: "top level array", input: `["a"]`, wantErr: true},
+		{name: "syntax error", input: `{"a": }`, wantErr: true},
+		{name: "trailing data", input: `{"a": 1} {"b": 2}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeJSON([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeJSON error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeJSON = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"server.host":     "localhost",
+		"server.port":     "8080",
+		"debug":           "true",
+		"ratio":           "0.25",
+		"hosts[0]":        "a",
+		"hosts[1]":        "b",
+		"servers[0].name": "x",
+		"version":         "1.0.0",
+	}
+	content, err := encodeJSON(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeJSON(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("round trip = %v, want %v", got, values)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypedKey 是带类型与默认值的配置键，由Key创建
+// 一个键的名称、类型与默认值集中定义一次，各调用处通过Get读取，类型由编译器检查
+type TypedKey[T any] struct {
+	name string
+	def  T
+}
+
+// Key 创建类型为T的配置键
+// T支持Unmarshal字段所支持的类型：字符串、布尔、整数、浮点数、time.Duration、
+// 实现encoding.TextUnmarshaler的类型、上述类型的切片(逗号分隔)以及它们的指针
+//
+//	var port = config.Key("server.port", 8080)
+//	n, err := port.Get(cfg)
+//
+// 参数:
+// - name: 配置键
+// - def: 键不存在时返回的默认值
+// 返回:
+// - *TypedKey[T]: 配置键
+func Key[T any](name string, def T) *TypedKey[T] {
+	return &TypedKey[T]{name: name, def: def}
+}
+
+// Name 返回配置键名称
+func (k *TypedKey[T]) Name() string {
+	return k.name
+}
+
+// Default 返回默认值
+func (k *TypedKey[T]) Default() T {
+	return k.def
+}
+
+// Get 读取并解析键的值，键不存在时返回默认值
+// 参数:
+// - cfg: 配置实例
+// 返回:
+// - T: 解析后的值，解析失败时为默认值
+// - error: 值无法解析为T时返回错误
+func (k *TypedKey[T]) Get(cfg *Config) (T, error) {
+	val, ok := cfg.lookup(k.name)
+	if !ok {
+		return k.def, nil
+	}
+	var v T
+	if err := setFieldValue(reflect.ValueOf(&v).Elem(), val, 0); err != nil {
+		return k.def, fmt.Errorf("key %s: invalid %s %q: %w", cfg.keyRef(k.name), reflect.TypeOf(&v).Elem(), val, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedKey(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("server.port", "9090")
+	cfg.Set("timeout", "5s")
+	cfg.Set("hosts", "a, b,c")
+	cfg.Set("debug", "yes")
+	cfg.Set("bad", "abc")
+
+	port := Key("server.port", 8080)
+	if port.Name() != "server.port" || port.Default() != 8080 {
+		t.Errorf("Key = %s %v", port.Name(), port.Default())
+	}
+	if n, err := port.Get(cfg); err != nil || n != 9090 {
+		t.Errorf("port.Get = %d, %v, want 9090", n, err)
+	}
+	if d, err := Key("timeout", time.Second).Get(cfg); err != nil || d != 5*time.Second {
+		t.Errorf("timeout.Get = %v, %v", d, err)
+	}
+	if hosts, err := Key[[]string]("hosts", nil).Get(cfg); err != nil || !reflect.DeepEqual(hosts, []string{"a", "b", "c"}) {
+		t.Errorf("hosts.Get = %q, %v", hosts, err)
+	}
+	if on, err := Key("debug", false).Get(cfg); err != nil || !on {
+		t.Errorf("debug.Get = %v, %v", on, err)
+	}
+	if p, err := Key[*int]("server.port", nil).Get(cfg); err != nil || p == nil || *p != 9090 {
+		t.Errorf("pointer Get = %v, %v", p, err)
+	}
+
+	// 键不存在时返回默认值，解析失败时返回默认值与错误
+	if v, err := Key("missing", 3.5).Get(cfg); err != nil || v != 3.5 {
+		t.Errorf("missing.Get = %v, %v, want the default", v, err)
+	}
+	v, err := Key("bad", 42).Get(cfg)
+	if err == nil || v != 42 || !strings.Contains(err.Error(), `"bad"`) || !strings.Contains(err.Error(), "int") {
+		t.Errorf("bad.Get = %v, %v, want the default and an error naming the key and type", v, err)
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// kubernetesDataLink 是kubelet在挂载目录中维护的数据符号链接
+// 挂载目录中的每个键都是指向"..data/键"的符号链接，更新时kubelet写入新的时间戳目录，
+// 再原子地把..data切换到新目录，所以只需观察..data的指向即可感知更新
+const kubernetesDataLink = "..data"
+
+// KubernetesProvider 读取以卷方式挂载的ConfigMap或Secret目录，每个文件对应一个键
+// 文件名(加上前缀)作为键，文件内容去掉末尾的一个换行符后作为值；以"."开头的文件与目录
+// (如..data和时间戳目录)被忽略，子目录中的文件名用"."连接。
+// Watch定期检查..data符号链接的指向，kubelet切换后重新读取整个目录；
+// 目录不是kubelet维护的挂载(没有..data)时改为比较读取结果。
+// 注意以subPath方式挂载的文件不会被kubelet更新，应挂载整个卷。
+type KubernetesProvider struct {
+	dir      string
+	prefix   string
+	settings watchSettings
+	done     chan struct{}
+	once     sync.Once
+
+	mutex  sync.Mutex
+	target string            // 最近一次Load时..data的指向，为空表示没有..data
+	values map[string]string // 最近一次Load的结果，没有..data时用于比较
+}
+
+// NewKubernetesProvider 创建读取挂载目录dir的配置源
+// 参数:
+// - dir: ConfigMap或Secret的挂载目录，如"/etc/config"
+// - prefix: 键前缀，为空时文件名直接作为键
+// - opts: 轮询间隔等可选设置
+// 返回:
+// - *KubernetesProvider: 配置源实例
+func NewKubernetesProvider(dir, prefix string, opts ...WatchOption) *KubernetesProvider {
+	settings := watchSettings{interval: DefaultWatchInterval}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	return &KubernetesProvider{dir: dir, prefix: prefix, settings: settings, done: make(chan struct{})}
+}
+
+// LoadFromKubernetes 从挂载的ConfigMap或Secret目录加载所有键并合并到文件层
+// 需要持续同步时可使用AddProvider(NewKubernetesProvider(dir, prefix))
+// 参数:
+// - dir: 挂载目录
+// - prefix: 键前缀
+// 返回:
+// - error: 读取目录或文件的错误(如果有)
+func (c *Config) LoadFromKubernetes(dir, prefix string) error {
+	p := NewKubernetesProvider(dir, prefix)
+	defer p.Close()
+	return c.LoadFromProvider(p)
+}
+
+// Load 读取目录中的全部键
+func (p *KubernetesProvider) Load() (map[string]string, error) {
+	target, _ := os.Readlink(filepath.Join(p.dir, kubernetesDataLink))
+	values := make(map[string]string)
+	if err := readMountDir(p.dir, p.prefix, values); err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	p.target, p.values = target, values
+	p.mutex.Unlock()
+	return maps.Clone(values), nil
+}
+
+// Watch 按轮询间隔检查..data的指向，变化后重新读取并发送完整数据，直到Close被调用
+// 切换过程中读取失败时发送错误，下一次轮询继续重试
+func (p *KubernetesProvider) Watch(ch chan<- Update) error {
+	ticker := time.NewTicker(p.settings.interval)
+	defer ticker.Stop()
+	failed := false
+	for {
+		select {
+		case <-p.done:
+			return nil
+		case <-ticker.C:
+		}
+
+		p.mutex.Lock()
+		last, current := p.target, p.values
+		p.mutex.Unlock()
+		target, err := os.Readlink(filepath.Join(p.dir, kubernetesDataLink))
+		if err == nil && target == last && !failed {
+			continue
+		}
+
+		values, err := p.Load()
+		if err != nil {
+			failed = true
+			select {
+			case ch <- Update{Err: err}:
+			case <-p.done:
+				return nil
+			}
+			continue
+		}
+		if !failed && maps.Equal(values, current) {
+			continue
+		}
+		failed = false
+		select {
+		case ch <- Update{Values: values}:
+		case <-p.done:
+			return nil
+		}
+	}
+}
+
+// Close 停止Watch，可重复调用
+func (p *KubernetesProvider) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+// readMountDir 递归读取挂载目录，符号链接按其指向读取
+func readMountDir(dir, prefix string, values map[string]string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		key := joinKey(prefix, name)
+		if info.IsDir() {
+			if err := readMountDir(path, key, values); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		value := string(content)
+		if strings.HasSuffix(value, "\n") {
+			value = strings.TrimSuffix(value[:len(value)-1], "\r")
+		}
+		values[key] = value
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Layer 表示配置的优先级层，数值越大优先级越高
+type Layer int
+
+const (
+	// LayerDefault 是默认值层，优先级最低
+	LayerDefault Layer = iota
+	// LayerFile 是文件层，保存从文件加载以及通过Set写入的值
+	LayerFile
+	// LayerEnv 是环境变量层，BindEnv绑定的环境变量也在此层解析
+	LayerEnv
+	// LayerOverride 是覆盖层，优先级最高
+	LayerOverride
+
+	numLayers = int(LayerOverride) + 1
+)
+
+// String 返回层的名称
+func (l Layer) String() string {
+	switch l {
+	case LayerDefault:
+		return "default"
+	case LayerFile:
+		return "file"
+	case LayerEnv:
+		return "env"
+	case LayerOverride:
+		return "override"
+	}
+	return fmt.Sprintf("Layer(%d)", int(l))
+}
+
+// valid 检查层是否为已定义的层
+func (l Layer) valid() bool {
+	return l >= LayerDefault && int(l) < numLayers
+}
+
+// SetDefault 在默认值层设置键值
+// 仅当其它层都不存在该键时Get才会返回默认值
+// 参数:
+// - key: 配置键
+// - value: 默认值
+// 返回:
+// - error: 当key为空时返回错误
+func (c *Config) SetDefault(key, value string) error {
+	return c.setLayer(LayerDefault, key, value)
+}
+
+// SetDefaults 在默认值层批量设置键值
+// 默认值与文件层数据分开存放，SaveToFile等保存方法不会写出它们
+// 参数:
+// - values: 键到默认值的映射
+// 返回:
+// - error: 存在空键时返回错误，配置已冻结时返回ErrFrozen
+func (c *Config) SetDefaults(values map[string]string) error {
+	return c.MergeLayer(LayerDefault, values)
+}
+
+// IsSet 判断键是否被显式设置，即生效值来自默认值层以外的层或BindEnv绑定的环境变量
+// 只有默认值的键返回false，显式设置为空字符串的键返回true
+// 参数:
+// - key: 配置键
+// 返回:
+// - bool: 是否显式设置
+func (c *Config) IsSet(key string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	r, ok := c.resolveEntryLocked(key)
+	return ok && r.layer != LayerDefault
+}
+
+// SetOverride 在覆盖层设置键值，其优先级高于文件、环境变量和默认值
+// 参数:
+// - key: 配置键
+// - value: 覆盖值
+// 返回:
+// - error: 当key为空时返回错误
+func (c *Config) SetOverride(key, value string) error {
+	return c.setLayer(LayerOverride, key, value)
+}
+
+// MergeLayer 将一组键值合并到指定层，同名键被覆盖
+// 参数:
+// - layer: 目标层
+// - values: 要合并的键值对
+// 返回:
+// - error: 层不合法或存在空键时返回错误，配置已冻结时返回ErrFrozen
+func (c *Config) MergeLayer(layer Layer, values map[string]string) error {
+	if !layer.valid() {
+		return fmt.Errorf("invalid layer %d", int(layer))
+	}
+	for k := range values {
+		if k == "" {
+			return fmt.Errorf("key cannot be empty")
+		}
+	}
+	return c.mergeLayerFrom(layer, values, KeySource{Kind: SourceAPI})
+}
+
+// ClearLayer 清空指定层的所有键值
+// 参数:
+// - layer: 要清空的层
+// 返回:
+// - error: 层不合法时返回错误，配置已冻结时返回ErrFrozen
+func (c *Config) ClearLayer(layer Layer) error {
+	if !layer.valid() {
+		return fmt.Errorf("invalid layer %d", int(layer))
+	}
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	var changes []change
+	for k := range c.layerMap(layer) {
+		changes = c.writeLayerLocked(layer, k, "", true, changes)
+	}
+	c.unlockNotify(changes)
+	return nil
+}
+
+// LayerValues 返回指定层中键值对的副本，不合并其它层
+// 参数:
+// - layer: 要读取的层
+// 返回:
+// - map[string]string: 该层数据的副本
+func (c *Config) LayerValues(layer Layer) map[string]string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	m := c.layerMap(layer)
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+// setLayer 校验键并在指定层写入单个键值
+func (c *Config) setLayer(layer Layer, key, value string) error {
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	changes := c.writeLayerLocked(layer, key, value, false, nil)
+	c.unlockNotify(changes)
+	return nil
+}
+
+// layerMap 返回指定层的底层map，文件层即c.data；调用方须持有锁
+func (c *Config) layerMap(layer Layer) map[string]string {
+	if layer == LayerFile {
+		return c.data
+	}
+	if !layer.valid() {
+		return nil
+	}
+	return c.layers[layer]
+}
+
+// resolveLocked 按覆盖层、环境变量、文件层、默认值层的顺序解析键，调用方须持有锁
+// BindEnv以EnvOverride模式绑定时优先于环境变量层的map，以EnvFallback模式绑定时
+// 位于文件层之后、默认值层之前。启用profile时，每一层中"profile.key"优先于同层的key
+func (c *Config) resolveLocked(key string) (string, bool) {
+	r, ok := c.resolveEntryLocked(key)
+	return r.value, ok
+}
+
+// resolvedEntry 描述键的生效值来自哪里
+// 值来自某一层时stored是该层中实际存储的键(可能带profile前缀)，来自BindEnv时env为环境变量名
+type resolvedEntry struct {
+	value  string
+	layer  Layer
+	stored string
+	env    string
+}
+
+// resolveEntryLocked 解析键并返回生效值及其来源，解析顺序见resolveLocked
+// 登记过Alias时按别名解析，见resolveAliasLocked
+func (c *Config) resolveEntryLocked(key string) (resolvedEntry, bool) {
+	key = c.normKey(key)
+	if len(c.alias.aliases) > 0 {
+		return c.resolveAliasLocked(key)
+	}
+	return c.resolveNameLocked(key)
+}
+
+// resolveNameLocked 不考虑别名，按层优先级解析键
+func (c *Config) resolveNameLocked(key string) (resolvedEntry, bool) {
+	if r, ok := c.layerEntryLocked(LayerOverride, key); ok {
+		return r, true
+	}
+	if c.env != nil && c.env.mode == EnvOverride {
+		if val, ok := c.env.lookup(key); ok {
+			return resolvedEntry{value: val, layer: LayerEnv, env: c.env.name(key)}, true
+		}
+	}
+	if r, ok := c.layerEntryLocked(LayerEnv, key); ok {
+		return r, true
+	}
+	if r, ok := c.layerEntryLocked(LayerFile, key); ok {
+		return r, true
+	}
+	if c.env != nil && c.env.mode == EnvFallback {
+		if val, ok := c.env.lookup(key); ok {
+			return resolvedEntry{value: val, layer: LayerEnv, env: c.env.name(key)}, true
+		}
+	}
+	return c.layerEntryLocked(LayerDefault, key)
+}
+
+// layerEntryLocked 在单个层中查找键，启用profile时先查找带profile前缀的键
+func (c *Config) layerEntryLocked(layer Layer, key string) (resolvedEntry, bool) {
+	m := c.layerMap(layer)
+	if c.profile != "" {
+		if val, ok := m[c.profileKey(key)]; ok {
+			return resolvedEntry{value: val, layer: layer, stored: c.profileKey(key)}, true
+		}
+	}
+	val, ok := m[key]
+	return resolvedEntry{value: val, layer: layer, stored: key}, ok
+}
+
+// profileKey 返回key在当前profile下的存储键，profile与key之间使用层级分隔符
+func (c *Config) profileKey(key string) string {
+	return c.profile + c.sep() + key
+}
+
+// keysLocked 返回所有层中出现过的键的集合，调用方须持有锁
+// 启用profile时，"profile.key"形式的键同时以去掉前缀的key出现
+func (c *Config) keysLocked() map[string]struct{} {
+	keys := make(map[string]struct{}, len(c.data))
+	for l := 0; l < numLayers; l++ {
+		for k := range c.layerMap(Layer(l)) {
+			keys[k] = struct{}{}
+			if c.profile != "" {
+				if rest, ok := strings.CutPrefix(k, c.profile+c.sep()); ok && rest != "" {
+					keys[rest] = struct{}{}
+				}
+			}
+		}
+	}
+	for old, target := range c.alias.aliases {
+		if _, ok := keys[old]; ok {
+			keys[target] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// effectiveLocked 返回合并各层之后的生效配置，调用方须持有锁
+func (c *Config) effectiveLocked() map[string]string {
+	keys := c.keysLocked()
+	result := make(map[string]string, len(keys))
+	for k := range keys {
+		if val, ok := c.resolveLocked(k); ok {
+			result[k] = val
+		}
+	}
+	return result
+}
+
+// writeLayerLocked 在指定层写入或删除键，并在生效值变化时追加到changes
+// 写入的键来源记录为SourceAPI，来自文件、配置源等的调用方随后通过setSourceLocked重新记录。
+// 调用方须持有写锁
+func (c *Config) writeLayerLocked(layer Layer, key, value string, del bool, changes []change) []change {
+	key = c.normKey(key)
+	old, oldOK := c.resolveLocked(key)
+	m := c.layerMap(layer)
+	if layer == LayerOverride {
+		c.cancelTTLLocked(key)
+	}
+	if del {
+		delete(m, key)
+		delete(c.meta[layer], key)
+	} else {
+		c.setSourceLocked(layer, key, KeySource{Kind: SourceAPI})
+		if m == nil {
+			m = make(map[string]string)
+			if layer == LayerFile {
+				c.data = m
+			} else {
+				c.layers[layer] = m
+			}
+		}
+		m[key] = value
+	}
+	val, ok := c.resolveLocked(key)
+	if ok == oldOK && val == old {
+		return changes
+	}
+	return append(changes, change{key: key, old: old, new: val, oldOK: oldOK, newOK: ok})
+}
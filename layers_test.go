@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLayerPriority(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.SetDefault("port", "80")
+	if got := cfg.Get("port"); got != "80" {
+		t.Errorf("default only: Get = %q, want 80", got)
+	}
+	cfg.Set("port", "8080")
+	if got := cfg.Get("port"); got != "8080" {
+		t.Errorf("file over default: Get = %q, want 8080", got)
+	}
+	if err := cfg.MergeLayer(LayerEnv, map[string]string{"port": "9090"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("port"); got != "9090" {
+		t.Errorf("env over file: Get = %q, want 9090", got)
+	}
+	cfg.SetOverride("port", "1")
+	if got := cfg.Get("port"); got != "1" {
+		t.Errorf("override over env: Get = %q, want 1", got)
+	}
+
+	// 每层保存自己的值，清空高优先级层后露出下一层
+	for layer, want := range map[Layer]string{LayerDefault: "80", LayerFile: "8080", LayerEnv: "9090", LayerOverride: "1"} {
+		if got := cfg.LayerValues(layer)["port"]; got != want {
+			t.Errorf("LayerValues(%s)[port] = %q, want %q", layer, got, want)
+		}
+	}
+	for _, step := range []struct {
+		layer Layer
+		want  string
+	}{{LayerOverride, "9090"}, {LayerEnv, "8080"}, {LayerFile, "80"}} {
+		if err := cfg.ClearLayer(step.layer); err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.Get("port"); got != step.want {
+			t.Errorf("after ClearLayer(%s): Get = %q, want %q", step.layer, got, step.want)
+		}
+	}
+
+	if err := cfg.MergeLayer(Layer(7), map[string]string{"a": "1"}); err == nil {
+		t.Error("MergeLayer with an undefined layer succeeded")
+	}
+	if err := cfg.MergeLayer(LayerEnv, map[string]string{"": "1"}); err == nil {
+		t.Error("MergeLayer with an empty key succeeded")
+	}
+	if got := Layer(7).String(); got != "Layer(7)" {
+		t.Errorf("Layer(7).String() = %q", got)
+	}
+}
+
+func TestSaveWritesFileLayerOnly(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.SetDefault("timeout", "30s")
+	cfg.Set("host", "localhost")
+	cfg.SetOverride("host", "override.example")
+	cfg.SetOverride("debug", "true")
+
+	path := filepath.Join(t.TempDir(), "app.conf")
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "host = localhost") {
+		t.Errorf("saved file does not contain the file-layer host:\n%s", content)
+	}
+	for _, unwanted := range []string{"timeout", "override.example", "debug"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("saved file contains %q from another layer:\n%s", unwanted, content)
+		}
+	}
+	if all := cfg.GetAll(); all["host"] != "override.example" || all["timeout"] != "30s" || all["debug"] != "true" {
+		t.Errorf("GetAll() = %v, want the merged effective values", all)
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	cfg, _ := NewConfig()
+	if err := cfg.SetDefaults(map[string]string{"port": "80", "host": "localhost", "empty": ""}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("port", "8080")
+	cfg.Set("zero", "")
+
+	for key, want := range map[string]bool{"port": true, "zero": true, "host": false, "empty": false, "missing": false} {
+		if got := cfg.IsSet(key); got != want {
+			t.Errorf("IsSet(%s) = %v, want %v", key, got, want)
+		}
+	}
+	if got := cfg.Get("host"); got != "localhost" {
+		t.Errorf("Get(host) = %q, want the default", got)
+	}
+	// 删除显式值后回退到默认值
+	cfg.Delete("port")
+	if cfg.Get("port") != "80" || cfg.IsSet("port") {
+		t.Errorf("port after Delete = %q, IsSet = %v, want the default", cfg.Get("port"), cfg.IsSet("port"))
+	}
+	t.Setenv("APP_HOST", "env.example")
+	cfg.BindEnv("APP")
+	if !cfg.IsSet("host") {
+		t.Error("IsSet(host) = false with APP_HOST set")
+	}
+
+	if err := cfg.SetDefaults(map[string]string{"": "x"}); err == nil {
+		t.Error("SetDefaults with an empty key succeeded")
+	}
+	var buf strings.Builder
+	cfg.WriteTo(&buf)
+	if strings.Contains(buf.String(), "localhost") {
+		t.Errorf("WriteTo wrote the defaults:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,533 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// lineKind 区分key=value文件中各行的类型
+type lineKind int
+
+const (
+	lineRaw     lineKind = iota // 注释、空行及无法识别的行，原样保留
+	lineSection                 // [section]段落头
+	lineKey                     // key=value键值行
+	lineInclude                 // include指令，原样保留
+)
+
+// layoutLine 记录文件中的一行
+// 键值行保存完整键名和分隔符及其后空白之前的原始文本，保存时只替换值部分
+type layoutLine struct {
+	kind    lineKind
+	text    string // 原始行内容；键值行为值之前的部分，如"port = "
+	section string // 段落头对应的段落名
+	key     string // 键值行对应的完整键名
+	name    string // 键值行中分隔符之前的部分，保留缩进，不含尾随空白，如"port"
+}
+
+// fileLayout 记录LoadFromFile读取的文件结构，用于SaveToFile时保留注释、空行和键顺序
+type fileLayout struct {
+	lines     []layoutLine
+	included  map[string]string // 通过include引入的键及其加载时的值，未修改时不写入主文件
+	separator string            // 文件中最常用的分隔符写法，如"="或" = "，没有键值行时为空
+}
+
+// linePos 记录键最后一次出现的文件和行号(从1开始)
+type linePos struct {
+	file string
+	line int
+}
+
+// kvDocument 是解析key=value内容的结果
+type kvDocument struct {
+	values map[string]string
+	pos    map[string]linePos
+	layout *fileLayout
+}
+
+// kvParser 逐行解析key=value内容，include为true时处理include指令
+type kvParser struct {
+	doc      *kvDocument
+	include  bool
+	settings loadSettings
+	stack    []string    // 正在解析的文件(绝对路径)，用于检测循环包含
+	problems []LineError // 严格模式下发现的格式问题
+}
+
+// parseKeyValue 解析key=value格式的内容，同时记录每一行的结构
+// name是内容所在的文件，用于记录键的位置以及解析include指令中的相对路径
+// 严格模式下发现格式问题时返回*ParseError
+func parseKeyValue(r io.Reader, name string, include bool, settings loadSettings) (*kvDocument, error) {
+	p := &kvParser{
+		doc: &kvDocument{
+			values: make(map[string]string),
+			pos:    make(map[string]linePos),
+			layout: &fileLayout{},
+		},
+		include:  include,
+		settings: settings,
+	}
+	if name != "" {
+		if abs, err := filepath.Abs(name); err == nil {
+			p.stack = append(p.stack, abs)
+		}
+	}
+	if err := p.parse(r, name, p.doc.layout); err != nil {
+		return nil, err
+	}
+	if len(p.problems) > 0 {
+		return nil, &ParseError{Errors: p.problems}
+	}
+	p.doc.layout.separator = commonSeparator(p.doc.layout.lines)
+	return p.doc, nil
+}
+
+// parse 解析一个文件的内容；layout为nil表示被包含的文件，不记录行结构
+func (p *kvParser) parse(r io.Reader, name string, layout *fileLayout) error {
+	addLine := func(line layoutLine) {
+		if layout != nil {
+			layout.lines = append(layout.lines, line)
+		}
+	}
+	section := ""
+	lineNo := 0
+	seen := make(map[string]int)       // 本文件中每个键首次出现的行号
+	items := make(map[string][]string) // DuplicateCollect策略下每个键收集到的值
+	policy := p.settings.duplicatePolicy()
+	var conds condStack
+	problem := func(line int, msg, raw string) {
+		p.problems = append(p.problems, LineError{File: displayName(name), Line: line, Content: raw, Message: msg})
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNo++
+		raw := scanner.Text()
+		if lineNo == 1 {
+			// Windows编辑器常在文件开头写入UTF-8 BOM，不应成为第一个键的一部分
+			raw = strings.TrimPrefix(raw, "\ufeff")
+		}
+		if p.settings.strict && !utf8.ValidString(raw) {
+			problem(lineNo, "invalid UTF-8", raw)
+			continue
+		}
+		line := strings.TrimSpace(raw)
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			addLine(layoutLine{kind: lineRaw, text: raw})
+			continue // 跳过空行和注释
+		}
+
+		if ok, err := conds.apply(line, lineNo, p.settings.conditions); err != nil {
+			return fmt.Errorf("%s:%d: %w", displayName(name), lineNo, err)
+		} else if ok || !conds.active() {
+			addLine(layoutLine{kind: lineRaw, text: raw})
+			continue
+		}
+
+		if p.include {
+			if target, ok := includeTarget(line); ok {
+				addLine(layoutLine{kind: lineInclude, text: raw})
+				if err := p.includeFiles(name, target); err != nil {
+					return fmt.Errorf("%s:%d: %w", displayName(name), lineNo, err)
+				}
+				continue
+			}
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			addLine(layoutLine{kind: lineSection, text: raw, section: section})
+			continue
+		}
+
+		sep := separatorIndex(line)
+		if sep < 0 {
+			if p.settings.strict {
+				if strings.HasPrefix(line, "[") {
+					problem(lineNo, "unterminated section header", raw)
+				} else {
+					problem(lineNo, "missing '='", raw)
+				}
+			} else if p.settings.warn != nil {
+				p.settings.warn(LineError{File: displayName(name), Line: lineNo, Content: raw, Message: "line ignored: missing '='"})
+			}
+			addLine(layoutLine{kind: lineRaw, text: raw})
+			continue
+		}
+		key := joinKey(section, strings.TrimSpace(line[:sep]))
+		value := strings.TrimSpace(line[sep+1:])
+		keyLine := lineNo
+		if strings.HasPrefix(value, `"""`) {
+			var closed bool
+			value, closed = readBlock(scanner, value[len(`"""`):], &lineNo)
+			if !closed && p.settings.strict {
+				problem(keyLine, "unterminated triple-quoted value", raw)
+			}
+		} else if unquoted, ok := unquoteValue(value); ok {
+			value = unquoted
+		} else {
+			value = readContinuation(scanner, value, &lineNo)
+		}
+		if strings.TrimSpace(line[:sep]) == "" {
+			if p.settings.strict {
+				problem(keyLine, "empty key", raw)
+			} else if p.settings.warn != nil {
+				p.settings.warn(LineError{File: displayName(name), Line: keyLine, Content: raw, Message: "line ignored: empty key"})
+			}
+			addLine(layoutLine{kind: lineRaw, text: raw})
+			continue
+		}
+		if first, dup := seen[key]; dup {
+			le := LineError{
+				File:    displayName(name),
+				Line:    keyLine,
+				Content: raw,
+				Message: fmt.Sprintf("duplicate key %q (first defined on line %d)", key, first),
+			}
+			if p.settings.onDuplicate != nil {
+				p.settings.onDuplicate(le)
+			}
+			if policy != DuplicateError && p.settings.warn != nil {
+				p.settings.warn(le)
+			}
+			switch policy {
+			case DuplicateFirstWins:
+				addLine(layoutLine{kind: lineRaw, text: raw})
+				continue
+			case DuplicateError:
+				p.problems = append(p.problems, le)
+				continue
+			case DuplicateCollect:
+				items[key] = append(items[key], value)
+				p.doc.values[key] = joinList(items[key])
+				if layout == nil {
+					p.markIncluded(key, p.doc.values[key])
+				}
+				continue
+			}
+		} else {
+			seen[key] = keyLine
+			items[key] = []string{value}
+		}
+		p.doc.values[key] = value
+		p.doc.pos[key] = linePos{file: name, line: keyLine}
+		if layout == nil {
+			p.markIncluded(key, value)
+			continue
+		}
+		delete(p.doc.layout.included, key)
+
+		// 值之前的部分保留原有缩进和分隔符两侧的空白
+		eq := separatorIndex(raw)
+		prefix := raw[:eq+1]
+		rest := raw[eq+1:]
+		prefix += rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+		addLine(layoutLine{kind: lineKey, text: prefix, key: key, name: strings.TrimRight(raw[:eq], " \t")})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(conds) > 0 {
+		return fmt.Errorf("%s:%d: unterminated @if", displayName(name), conds[len(conds)-1].line)
+	}
+	return nil
+}
+
+// separatorIndex 返回键值行中分隔键与值的字符位置，不是键值行时返回-1
+// 分隔符是第一个"="，或者第一个其后为空白或位于行尾的":"("key: value"写法)，两者取靠前的一个
+func separatorIndex(line string) int {
+	eq := strings.IndexByte(line, '=')
+	for i := 0; i < len(line); i++ {
+		if eq >= 0 && i > eq {
+			break
+		}
+		if line[i] == ':' && (i == len(line)-1 || line[i+1] == ' ' || line[i+1] == '\t') {
+			return i
+		}
+	}
+	return eq
+}
+
+// commonSeparator 返回键值行中出现最多的分隔符写法(分隔符及其两侧的空白)，次数相同时取先出现的
+func commonSeparator(lines []layoutLine) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, line := range lines {
+		if line.kind != lineKey {
+			continue
+		}
+		sep := strings.TrimPrefix(line.text, line.name)
+		counts[sep]++
+		if counts[sep] > counts[best] {
+			best = sep
+		}
+	}
+	return best
+}
+
+// readBlock 读取三引号块的剩余部分，first是开头的"""之后的内容
+// 块内各行原样保留并以"\n"连接，开头"""之后与结尾"""之前只有空白时不计入内容；
+// 到达文件末尾仍未遇到结尾的"""时closed为false，已读取的内容作为值
+func readBlock(scanner *bufio.Scanner, first string, lineNo *int) (value string, closed bool) {
+	if end := strings.Index(first, `"""`); end >= 0 {
+		return first[:end], true
+	}
+	var lines []string
+	if strings.TrimSpace(first) != "" {
+		lines = append(lines, first)
+	}
+	for scanner.Scan() {
+		*lineNo++
+		text := scanner.Text()
+		if end := strings.Index(text, `"""`); end >= 0 {
+			if strings.TrimSpace(text[:end]) != "" {
+				lines = append(lines, text[:end])
+			}
+			closed = true
+			break
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n"), closed
+}
+
+// readContinuation 处理以"\"结尾的续行：去掉"\"并接上下一行去除首尾空白后的内容
+func readContinuation(scanner *bufio.Scanner, value string, lineNo *int) string {
+	for strings.HasSuffix(value, `\`) {
+		value = value[:len(value)-1]
+		if !scanner.Scan() {
+			break
+		}
+		*lineNo++
+		value += strings.TrimSpace(scanner.Text())
+	}
+	return value
+}
+
+// formatValue 返回值在key=value文件中的写法
+// 含换行的值写成三引号块；有首尾空白、含制表符等控制字符、以"\"结尾，
+// 或本身会被当作引号值、三引号块解析的值写成带转义的双引号值，使重新加载得到相同的值
+func formatValue(value string) string {
+	if strings.Contains(value, "\n") && !strings.Contains(value, `"""`) {
+		return `"""` + "\n" + value + "\n" + `"""`
+	}
+	if needsQuote(value) {
+		return quoteValue(value)
+	}
+	return value
+}
+
+// needsQuote 判断值按原样写出后能否被原样读回
+func needsQuote(value string) bool {
+	if value == "" {
+		return false
+	}
+	if strings.TrimSpace(value) != value || strings.HasSuffix(value, `\`) || strings.HasPrefix(value, `"""`) {
+		return true
+	}
+	if _, ok := unquoteValue(value); ok {
+		return true
+	}
+	return strings.ContainsFunc(value, func(r rune) bool { return r < ' ' || r == 0x7f })
+}
+
+// quoteValue 返回值的双引号形式，转义引号、反斜杠与控制字符
+func quoteValue(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < ' ' || r == 0x7f {
+				fmt.Fprintf(&b, `\x%02x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquoteValue 解析整个值是一个引号字符串的情况
+// 双引号值支持\n、\t、\r、\\、\"、\'与\xHH转义，其它反斜杠原样保留；单引号值不处理转义。
+// 值不是以引号开头并恰好在末尾闭合(如列表值"a", "b")时ok为false
+func unquoteValue(value string) (string, bool) {
+	if len(value) < 2 {
+		return "", false
+	}
+	q := value[0]
+	if q == '\'' {
+		end := strings.IndexByte(value[1:], '\'')
+		if end != len(value)-2 {
+			return "", false
+		}
+		return value[1 : len(value)-1], true
+	}
+	if q != '"' {
+		return "", false
+	}
+	var b strings.Builder
+	for i := 1; i < len(value); i++ {
+		ch := value[i]
+		switch {
+		case ch == '"':
+			if i != len(value)-1 {
+				return "", false
+			}
+			return b.String(), true
+		case ch == '\\' && i+1 < len(value):
+			i++
+			switch value[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '\\', '"', '\'':
+				b.WriteByte(value[i])
+			case 'x':
+				if n, err := strconv.ParseUint(value[i+1:min(i+3, len(value))], 16, 8); err == nil && i+3 <= len(value) {
+					b.WriteByte(byte(n))
+					i += 2
+				} else {
+					b.WriteString(`\x`)
+				}
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(value[i])
+			}
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return "", false
+}
+
+// markIncluded 记录通过include引入的键
+func (p *kvParser) markIncluded(key, value string) {
+	if p.doc.layout.included == nil {
+		p.doc.layout.included = make(map[string]string)
+	}
+	p.doc.layout.included[key] = value
+}
+
+// render 按记录的结构写出data中的键值
+// 已删除的键对应的行被省略，布局中没有的新键追加到所属段落的末尾(通过include引入且未修改的键除外)，
+// 文件中不存在的段落追加到文件末尾；无段落的新键写在第一个段落头之前。
+// 新键以sep分隔键与值，rewrite为true时已有的键值行也改用sep
+func (l *fileLayout) render(w io.Writer, data map[string]string, sep string, rewrite bool) error {
+	known := make(map[string]bool)
+	sections := make(map[string]bool)
+	for _, line := range l.lines {
+		switch line.kind {
+		case lineKey:
+			known[line.key] = true
+		case lineSection:
+			sections[line.section] = true
+		}
+	}
+
+	// 按段落收集需要追加的新键
+	pending := make(map[string][]string)
+	var newSections []string
+	for _, key := range sectionOrderedKeys(data) {
+		if known[key] {
+			continue
+		}
+		if v, ok := l.included[key]; ok && v == data[key] {
+			continue
+		}
+		section, _ := splitSection(key)
+		if section != "" && !sections[section] && len(pending[section]) == 0 {
+			newSections = append(newSections, section)
+		}
+		pending[section] = append(pending[section], key)
+	}
+
+	// 已有段落的新键写在该段落最后一个键值行或include指令之后，没有时写在段落头之后；
+	// 无段落的新键写在第一个段落头之前的最后一个键值行或include指令之后，没有时写在文件开头。
+	// 写在include指令之后保证修改过的引入键在重新加载时覆盖被包含文件中的值
+	insertAt := map[string]int{"": -1}
+	current := ""
+	for i, line := range l.lines {
+		switch line.kind {
+		case lineSection:
+			current = line.section
+			if _, ok := insertAt[current]; !ok {
+				insertAt[current] = i
+			}
+		case lineKey, lineInclude:
+			insertAt[current] = i
+		}
+	}
+	after := make(map[int]string)
+	for section, at := range insertAt {
+		if len(pending[section]) > 0 {
+			after[at] = section
+		}
+	}
+
+	if section, ok := after[-1]; ok {
+		if err := writeLayoutKeys(w, pending[section], data, sep); err != nil {
+			return err
+		}
+	}
+	for i, line := range l.lines {
+		text, keep := line.text, true
+		if line.kind == lineKey {
+			var value string
+			value, keep = data[line.key]
+			if rewrite {
+				text = line.name + sep
+			}
+			text = strings.TrimRight(text+formatValue(value), " \t")
+		}
+		if keep {
+			if _, err := io.WriteString(w, text+"\n"); err != nil {
+				return err
+			}
+		}
+		if section, ok := after[i]; ok {
+			if err := writeLayoutKeys(w, pending[section], data, sep); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, section := range newSections {
+		if _, err := io.WriteString(w, "\n["+section+"]\n"); err != nil {
+			return err
+		}
+		if err := writeLayoutKeys(w, pending[section], data, sep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLayoutKeys 以"name"+sep+"value"形式写出一组同段落的键
+func writeLayoutKeys(w io.Writer, keys []string, data map[string]string, sep string) error {
+	for _, key := range keys {
+		_, name := splitSection(key)
+		if _, err := io.WriteString(w, name+sep+formatValue(data[key])+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSavePreservesLayout(t *testing.T) {
+	const original = `# application settings
+name = app
+debug: false
+
+; database
+[db]
+user = app
+host = localhost
+
+[server]
+port=8080
+`
+	const want = `# application settings
+name = app
+debug: true
+version = 2
+
+; database
+[db]
+user = app
+pool = 10
+
+[server]
+port=8080
+
+[cache]
+ttl = 60s
+`
+	path := filepath.Join(t.TempDir(), "app.conf")
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改、删除与新增；新键使用文件中最常用的" = "写法并写在所属段落末尾
+	cfg.Set("debug", "true")
+	cfg.Delete("db.host")
+	cfg.Set("db.pool", "10")
+	cfg.Set("version", "2")
+	cfg.Set("cache.ttl", "60s")
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != want {
+		t.Errorf("saved file =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestSaveWithoutLayoutUsesSections(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("server.port", "8080")
+	cfg.Set("name", "app")
+	cfg.Set("db.host", "localhost")
+	path := filepath.Join(t.TempDir(), "app.conf")
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	const want = "name = app\n\n[db]\nhost = localhost\n\n[server]\nport = 8080\n"
+	if string(data) != want {
+		t.Errorf("saved file =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestMultilineValues(t *testing.T) {
+	const pem = "-----BEGIN CERTIFICATE-----\nMIIB\n  indented\n-----END CERTIFICATE-----"
+	path := filepath.Join(t.TempDir(), "app.conf")
+	content := "cert = \"\"\"\n" + pem + "\n\"\"\"\n" +
+		"inline = \"\"\"one line\"\"\"\n" +
+		"query = SELECT id \\\n    FROM users\n" +
+		"after = 1\n"
+	os.WriteFile(path, []byte(content), 0o644)
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"cert": pem, "inline": "one line", "query": "SELECT id FROM users", "after": "1"}
+	for key, v := range want {
+		if got := cfg.Get(key); got != v {
+			t.Errorf("Get(%s) = %q, want %q", key, got, v)
+		}
+	}
+	if src, _ := cfg.Source("after"); src.Line != 10 {
+		t.Errorf("Source(after).Line = %d, want 10 counting the block and continuation lines", src.Line)
+	}
+
+	// 保存时含换行的值写成三引号块，重新加载得到相同的值
+	cfg.Set("sql", "SELECT *\nFROM t")
+	cfg.Set("cert", pem+"\n")
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "sql = \"\"\"\nSELECT *\nFROM t\n\"\"\"\n") {
+		t.Errorf("saved file =\n%s\nwant sql as a triple-quoted block", data)
+	}
+	reloaded, _ := NewConfig()
+	if err := reloaded.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"sql", "cert", "query", "after"} {
+		if got, want := reloaded.Get(key), cfg.Get(key); got != want {
+			t.Errorf("reloaded %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestQuotedValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	os.WriteFile(path, []byte("indent = \"  two spaces\"\nsingle = ' keep \\n raw '\nplain = a \"quoted\" word\n"), 0o644)
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"indent": "  two spaces",
+		"single": ` keep \n raw `,
+		"plain":  `a "quoted" word`, // 不是整个值被引号包围时原样保留
+	}
+	for key, v := range want {
+		if got := cfg.Get(key); got != v {
+			t.Errorf("Get(%s) = %q, want %q", key, got, v)
+		}
+	}
+
+	// 保存时只给需要的值加引号
+	values := map[string]string{
+		"trailing": "value ",
+		"tab":      "a\tb",
+		"quoted":   `"looks quoted"`,
+		"slash":    `ends with \`,
+		"plain":    "a b",
+	}
+	for k, v := range values {
+		cfg.Set(k, v)
+	}
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	saved := string(data)
+	for _, line := range []string{
+		`trailing = "value "`,
+		`tab = "a\tb"`,
+		`quoted = "\"looks quoted\""`,
+		`slash = "ends with \\"`,
+		"plain = a b\n",
+	} {
+		if !strings.Contains(saved, line) {
+			t.Errorf("saved file =\n%s\nwant it to contain %q", saved, line)
+		}
+	}
+	reloaded, _ := NewConfig()
+	if err := reloaded.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range values {
+		if got := reloaded.Get(k); got != v {
+			t.Errorf("reloaded %s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestSaveSeparatorStyle(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	os.WriteFile(path, []byte("name=app\nport=80\nurl: http://x\n"), 0o644)
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("url"); got != "http://x" {
+		t.Errorf("url = %q, want the colon-separated value", got)
+	}
+	cfg.Set("port", "8080")
+	cfg.Set("debug", "true")
+
+	// 默认沿用文件中最常用的写法，已有行保持原样
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "name=app\nport=8080\nurl: http://x\ndebug=true\n"; string(data) != want {
+		t.Errorf("saved =\n%s\nwant\n%s", data, want)
+	}
+
+	tests := map[string]string{
+		SeparatorSpacedEquals: "name = app\nport = 8080\nurl = http://x\ndebug = true\n",
+		SeparatorColon:        "name: app\nport: 8080\nurl: http://x\ndebug: true\n",
+		SeparatorEquals:       "name=app\nport=8080\nurl=http://x\ndebug=true\n",
+	}
+	for sep, want := range tests {
+		if err := cfg.SaveToFile(path, WithSeparator(sep)); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != want {
+			t.Errorf("WithSeparator(%q) =\n%s\nwant\n%s", sep, data, want)
+		}
+	}
+	if err := cfg.SaveToFile(path, WithSeparator(" -> ")); err == nil {
+		t.Error("SaveToFile with an invalid separator succeeded")
+	}
+
+	// 未加载过文件时使用" = "
+	fresh, _ := NewConfig()
+	fresh.Set("a", "1")
+	var buf strings.Builder
+	fresh.WriteTo(&buf)
+	if buf.String() != "a = 1\n" {
+		t.Errorf("WriteTo without a loaded file = %q", buf.String())
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockFileSuffix 是锁文件相对配置文件追加的后缀
+	lockFileSuffix = ".lock"
+	// lockPollInterval 是锁被其它进程持有时重试的间隔
+	lockPollInterval = 10 * time.Millisecond
+)
+
+// SaveToFileLocked 与SaveToFile相同(包括按扩展名选择格式)，但在写入期间持有配置文件的排他锁
+// 锁是同目录下名为"<文件名>.lock"的锁文件上的建议锁(Unix上为flock，Windows上为LockFileEx)，
+// 不存在时创建。锁文件存在时，LoadFromFile在读取期间持有共享锁、SaveToFile在写入期间持有排他锁，
+// 因此多个进程共享一个配置文件时，读取不会与加锁的保存交错，保存之间也不会交错。
+// 建议锁只约束同样使用锁文件的进程；在不支持flock的系统上不加锁
+// 参数:
+// - filename: 目标文件路径
+// - opts: 文件权限、属主等可选设置
+// 返回:
+// - error: 加锁或文件操作错误(如果有)
+func (c *Config) SaveToFileLocked(filename string, opts ...SaveOption) error {
+	release, err := lockConfigFile(context.Background(), filename, true, true)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return c.saveToFile(filename, "", opts)
+}
+
+// lockConfigFile 对filename的锁文件加锁，等待期间ctx结束时返回ctx.Err()
+// create为false且锁文件不存在时不加锁，返回的release什么也不做
+func lockConfigFile(ctx context.Context, filename string, exclusive, create bool) (release func(), err error) {
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = resolved
+	}
+	flag := os.O_RDONLY
+	if create {
+		flag = os.O_RDWR | os.O_CREATE
+	}
+	f, err := os.OpenFile(filename+lockFileSuffix, flag, defaultFileMode)
+	if err != nil {
+		if !create && errors.Is(err, fs.ErrNotExist) {
+			return func() {}, nil
+		}
+		return nil, err
+	}
+	for {
+		ok, err := tryLockFile(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if ok {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows && (!unix || solaris || aix)
+
+package config
+
+import "os"
+
+// tryLockFile 在不支持flock的系统上不加锁，总是成功
+func tryLockFile(f *os.File, exclusive bool) (bool, error) {
+	return true, nil
+}
+
+// unlockFile 在不支持flock的系统上不做任何处理
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+//go:build (unix && !solaris && !aix) || windows
+
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveToFileLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	cfg, _ := NewConfig()
+	cfg.Set("port", "80")
+	if err := cfg.SaveToFileLocked(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + lockFileSuffix); err != nil {
+		t.Fatalf("lock file not created: %v", err)
+	}
+
+	// 其它持有者持有排他锁时，读取与保存都等待锁释放
+	release, err := lockConfigFile(context.Background(), path, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	reader, _ := NewConfig()
+	if err := reader.LoadFromFileContext(ctx, path); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LoadFromFileContext while locked error = %v, want context.DeadlineExceeded", err)
+	}
+
+	saved := make(chan error, 1)
+	cfg.Set("port", "8080")
+	go func() { saved <- cfg.SaveToFileLocked(path) }()
+	select {
+	case err := <-saved:
+		t.Fatalf("SaveToFileLocked did not wait for the lock: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	if err := <-saved; err != nil {
+		t.Fatal(err)
+	}
+	if err := reader.LoadFromFile(path); err != nil || reader.Get("port") != "8080" {
+		t.Errorf("LoadFromFile after the lock was released = %v, port = %q", err, reader.Get("port"))
+	}
+
+	// 共享锁之间不互斥
+	shared, err := lockConfigFile(context.Background(), path, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shared()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := reader.LoadFromFileContext(ctx, path); err != nil {
+		t.Errorf("LoadFromFile while a shared lock is held: %v", err)
+	}
+}
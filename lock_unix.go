@@ -0,0 +1,34 @@
+//go:build unix && !solaris && !aix
+
+package config
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile 以flock尝试对f加共享锁或排他锁，锁被其它进程持有时返回false
+func tryLockFile(f *os.File, exclusive bool) (bool, error) {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+		switch {
+		case err == nil:
+			return true, nil
+		case errors.Is(err, syscall.EINTR):
+			continue
+		case errors.Is(err, syscall.EWOULDBLOCK):
+			return false, nil
+		}
+		return false, err
+	}
+}
+
+// unlockFile 释放f上的flock锁
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
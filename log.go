@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetLogger 设置记录配置运行情况的日志
+// 设置后以下情况通过l输出，而不是被静默忽略：
+// - Watch、WatchProvider与AddProvider在后台重新加载成功(Info)或失败(Error)，附带来源
+// - 读取Deprecate标记的键(Warn)，OnDeprecated设置了回调时由回调处理
+// - 非严格模式下被忽略的格式错误行以及重复的键(Warn)，附带文件与行号
+//
+// 这些事件通过OnReload等回调同样可以取得，SetLogger只是把它们接入应用的日志体系。
+// 参数:
+// - l: 日志，为nil时不再输出
+func (c *Config) SetLogger(l *slog.Logger) {
+	c.logger.Store(l)
+}
+
+// logEnabled 报告是否设置了日志且level级别的日志会被输出
+func (c *Config) logEnabled(level slog.Level) (*slog.Logger, bool) {
+	l := c.logger.Load()
+	if l == nil || !l.Enabled(context.Background(), level) {
+		return nil, false
+	}
+	return l, true
+}
+
+// logReload 记录一次后台重新加载的结果
+func (c *Config) logReload(source string, err error) {
+	if err != nil {
+		if l, ok := c.logEnabled(slog.LevelError); ok {
+			l.Error("config reload failed", "source", source, "error", err)
+		}
+		return
+	}
+	if l, ok := c.logEnabled(slog.LevelInfo); ok {
+		l.Info("config reloaded", "source", source)
+	}
+}
+
+// logLineWarning 记录加载时被忽略或被覆盖的行
+func (c *Config) logLineWarning(le LineError) {
+	if l, ok := c.logEnabled(slog.LevelWarn); ok {
+		l.Warn("config file warning", "file", le.File, "line", le.Line, "reason", le.Message, "content", le.Content)
+	}
+}
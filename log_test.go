@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// logRecorder 以JSON记录日志，可以被多个goroutine同时写入
+type logRecorder struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (r *logRecorder) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.buf.Write(p)
+}
+
+// records 返回已记录的日志，每条解析为map
+func (r *logRecorder) records(t *testing.T) []map[string]any {
+	t.Helper()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(r.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestSetLogger(t *testing.T) {
+	rec := &logRecorder{}
+	cfg, _ := NewConfig()
+	cfg.SetLogger(slog.New(slog.NewJSONHandler(rec, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	cfg.LoadFromReader(strings.NewReader("a = 1\njunk\na = 2\n"))
+	cfg.Set("old.key", "1")
+	cfg.Deprecate("old.key", "use new.key")
+	cfg.Get("old.key")
+
+	p := newPushProvider(map[string]string{"x": "1"})
+	done := reloads(cfg)
+	cfg.AddProvider(p)
+	p.push(Update{Values: map[string]string{"x": "2"}})
+	waitReload(t, done)
+	p.push(Update{Err: errors.New("backend down")})
+	waitReload(t, done)
+	cfg.Close()
+
+	records := rec.records(t)
+	type entry struct{ level, msg string }
+	var got []entry
+	for _, r := range records {
+		got = append(got, entry{r["level"].(string), r["msg"].(string)})
+	}
+	want := []entry{
+		{"WARN", "config file warning"},
+		{"WARN", "config file warning"},
+		{"WARN", "config key is deprecated"},
+		{"INFO", "config reloaded"},
+		{"ERROR", "config reload failed"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("log records = %v, want %v", records, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("record %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if records[0]["line"] != float64(2) || records[0]["content"] != "junk" || records[1]["line"] != float64(3) {
+		t.Errorf("line warnings = %v, want lines 2 and 3", records[:2])
+	}
+	if records[2]["key"] != "old.key" || records[2]["message"] != "use new.key" {
+		t.Errorf("deprecation record = %v", records[2])
+	}
+	if records[4]["source"] != "config.pushProvider" || records[4]["error"] != "backend down" {
+		t.Errorf("reload failure record = %v", records[4])
+	}
+
+	// 关闭日志后不再输出
+	cfg.SetLogger(nil)
+	cfg.LoadFromReader(strings.NewReader("junk\n"))
+	if n := len(rec.records(t)); n != len(want) {
+		t.Errorf("%d records after SetLogger(nil)", n-len(want))
+	}
+}
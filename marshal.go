@@ -0,0 +1,167 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Marshal 遍历带标签的结构体并将字段值写入配置
+// 键名规则与Unmarshal一致；标签带",omitempty"选项的字段在零值时跳过，
+// nil指针字段始终跳过。切片以逗号连接写入，含逗号、引号或首尾空白的元素以及空元素加双引号(规则与GetStringSlice相同)，
+// 元素为结构体的切片写为"servers[0].host"形式的下标键块，time.Duration使用其String形式；
+// 带unit标签的time.Duration字段在能被单位整除时写为不带单位的整数，以便旧程序继续读取。
+// 所有字段先完成转换再一次性写入，转换失败时配置保持不变。
+// 参数:
+// - v: 结构体或指向结构体的指针
+// 返回:
+// - error: 参数类型不合法或字段类型不受支持时返回错误
+func (c *Config) Marshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return errors.New("marshal source must not be nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return errors.New("marshal source must be a struct")
+	}
+
+	values := make(map[string]string)
+	if err := marshalStruct(rv, "", c.sep(), values); err != nil {
+		return err
+	}
+
+	return c.mergeValues(values)
+}
+
+// marshalStruct 递归收集结构体字段对应的键值对，sep为层级分隔符
+func marshalStruct(rv reflect.Value, prefix, sep string, out map[string]string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + sep + name
+		}
+		fv := rv.Field(i)
+		unit, err := fieldUnit(field)
+		if err != nil {
+			return err
+		}
+
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			continue
+		}
+		if hasTagOption(field, "omitempty") && fv.IsZero() {
+			continue
+		}
+
+		if isNestedStruct(field.Type) {
+			if fv.Kind() == reflect.Ptr {
+				fv = fv.Elem()
+			}
+			if err := marshalStruct(fv, key, sep, out); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if isStructSlice(field.Type) {
+			for j := 0; j < fv.Len(); j++ {
+				elem := fv.Index(j)
+				if elem.Kind() == reflect.Ptr {
+					if elem.IsNil() {
+						continue
+					}
+					elem = elem.Elem()
+				}
+				if err := marshalStruct(elem, IndexKey(key, j), sep, out); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		val, err := formatFieldValue(fv, unit)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+		out[key] = val
+	}
+	return nil
+}
+
+// hasTagOption 检查字段标签是否包含指定选项(如omitempty)
+func hasTagOption(field reflect.StructField, option string) bool {
+	_, opts, _ := strings.Cut(field.Tag.Get(tagName), ",")
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFieldValue 将字段值格式化为配置字符串，unit是time.Duration字段的默认单位
+func formatFieldValue(fv reflect.Value, unit time.Duration) (string, error) {
+	if fv.Kind() == reflect.Ptr {
+		return formatFieldValue(fv.Elem(), unit)
+	}
+
+	if fv.Type().Implements(textMarshalerType) {
+		text, err := fv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if fv.CanAddr() && fv.Addr().Type().Implements(textMarshalerType) {
+		text, err := fv.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	if fv.Type() == durationType {
+		if d := time.Duration(fv.Int()); unit > 0 && d%unit == 0 {
+			return strconv.FormatInt(int64(d/unit), 10), nil
+		}
+		return time.Duration(fv.Int()).String(), nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'g', -1, fv.Type().Bits()), nil
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			part, err := formatFieldValue(fv.Index(i), unit)
+			if err != nil {
+				return "", fmt.Errorf("element %d: %w", i, err)
+			}
+			parts[i] = part
+		}
+		return joinList(parts), nil
+	}
+	return "", fmt.Errorf("unsupported field type %s", fv.Type())
+}
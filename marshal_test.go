@@ -0,0 +1,110 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshalSliceRoundTrip(t *testing.T) {
+	type settings struct {
+		Tags    []string        `config:"tags"`
+		Ports   []int           `config:"ports"`
+		Backoff []time.Duration `config:"backoff"`
+	}
+	tests := []struct {
+		name string
+		in   settings
+		want string
+	}{
+		{"plain", settings{Tags: []string{"a", "b"}}, "a,b"},
+		{"delimiter and quotes", settings{Tags: []string{"a,b", "c", " d ", `"q"`}}, `"a,b",c," d ","\"q\""`},
+		{"empty and backslash", settings{Tags: []string{"", `C:\dir`, "it's"}}, `"","C:\\dir","it's"`},
+		{"numbers", settings{Tags: []string{"x"}, Ports: []int{80, 443}, Backoff: []time.Duration{time.Second, 2 * time.Minute}}, "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := NewConfig()
+			if err := cfg.Marshal(tt.in); err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.Get("tags"); got != tt.want {
+				t.Errorf("tags = %s, want %s", got, tt.want)
+			}
+			var out settings
+			if err := cfg.Unmarshal(&out); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(out.Tags, tt.in.Tags) {
+				t.Errorf("Unmarshal tags = %q, want %q", out.Tags, tt.in.Tags)
+			}
+			if len(tt.in.Ports) > 0 && !reflect.DeepEqual(out.Ports, tt.in.Ports) {
+				t.Errorf("Unmarshal ports = %v, want %v", out.Ports, tt.in.Ports)
+			}
+			if len(tt.in.Backoff) > 0 && !reflect.DeepEqual(out.Backoff, tt.in.Backoff) {
+				t.Errorf("Unmarshal backoff = %v, want %v", out.Backoff, tt.in.Backoff)
+			}
+			if got, err := cfg.GetStringSlice("tags"); err != nil || !reflect.DeepEqual(got, tt.in.Tags) {
+				t.Errorf("GetStringSlice(tags) = %q, %v, want %q", got, err, tt.in.Tags)
+			}
+		})
+	}
+}
+
+func TestStructSliceRoundTrip(t *testing.T) {
+	type server struct {
+		Host string `config:"host"`
+		Port int    `config:"port"`
+	}
+	type cluster struct {
+		Name    string    `config:"name"`
+		Servers []server  `config:"servers"`
+		Backups []*server `config:"backups"`
+	}
+	cfg, _ := NewConfig()
+	err := cfg.LoadFromReader(strings.NewReader(`{"name": "c1",
+		"servers": [{"host": "a", "port": 1}, {"host": "b", "port": 2}],
+		"backups": [{"host": "z", "port": 9}]}`), WithFileFormat("json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := cluster{
+		Name:    "c1",
+		Servers: []server{{"a", 1}, {"b", 2}},
+		Backups: []*server{{"z", 9}},
+	}
+	var got cluster
+	if err := cfg.Unmarshal(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal = %+v, want %+v", got, want)
+	}
+
+	out, _ := NewConfig()
+	if err := out.Marshal(want); err != nil {
+		t.Fatal(err)
+	}
+	wantKeys := map[string]string{
+		"name":            "c1",
+		"servers[0].host": "a",
+		"servers[0].port": "1",
+		"servers[1].host": "b",
+		"servers[1].port": "2",
+		"backups[0].host": "z",
+		"backups[0].port": "9",
+	}
+	if all := out.GetAll(); !reflect.DeepEqual(all, wantKeys) {
+		t.Errorf("Marshal keys = %v, want %v", all, wantKeys)
+	}
+
+	keep := cluster{Servers: []server{{"keep", 0}}}
+	empty, _ := NewConfig()
+	if err := empty.Unmarshal(&keep); err != nil {
+		t.Fatal(err)
+	}
+	if len(keep.Servers) != 1 || keep.Servers[0].Host != "keep" {
+		t.Errorf("Unmarshal without indexed keys changed Servers to %+v", keep.Servers)
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrMergeConflict 表示Merge时两份配置中同一键的值不同，由MergeError返回
+var ErrMergeConflict = errors.New("merge conflict")
+
+// ErrMergeConcurrent 表示Merge解决冲突期间本配置中涉及的键被反复并发修改，配置保持不变
+var ErrMergeConcurrent = errors.New("merge: configuration modified concurrently")
+
+// mergeAttempts 是Merge因并发修改而重新解决冲突的最大次数
+const mergeAttempts = 3
+
+// MergeStrategy 决定Merge时两份配置中值不同的键如何处理
+// existing是本配置中的值，incoming是另一份配置中的值，返回合并后的值；
+// 返回错误时Merge失败，配置保持不变。可以使用MergeOverwrite、MergeKeepExisting、MergeError，
+// 或自定义函数，例如按版本号取较大者
+type MergeStrategy func(key, existing, incoming string) (string, error)
+
+// MergeOverwrite 以另一份配置中的值为准
+func MergeOverwrite(key, existing, incoming string) (string, error) {
+	return incoming, nil
+}
+
+// MergeKeepExisting 保留本配置中的值
+func MergeKeepExisting(key, existing, incoming string) (string, error) {
+	return existing, nil
+}
+
+// MergeError 遇到冲突时使Merge失败，错误可用errors.Is(err, ErrMergeConflict)判断
+func MergeError(key, existing, incoming string) (string, error) {
+	return "", fmt.Errorf("key %q: %w", key, ErrMergeConflict)
+}
+
+// Merge 把另一份配置的文件层合并到本配置的文件层
+// 只合并other的文件层，other中SetDefault、SetOverride、BindEnv等其它层的值不参与合并，也不影响本配置的其它层。
+// 只存在于other中的键直接加入，两份配置中值相同的键不变，值不同的键交给strategy决定；
+// 从other合并来的值保留它们在other中的来源。所有冲突都解决后才一次性写入，
+// strategy对任一冲突返回错误时配置保持不变，错误为各冲突错误的errors.Join。
+// strategy在不持有锁时调用，可以读取两份配置；写入前发现本配置中涉及的键已被并发修改时，
+// 以修改后的值重新解决冲突(strategy可能被再次调用)，连续3次都被修改时返回ErrMergeConcurrent
+// 参数:
+// - other: 要合并进来的配置
+// - strategy: 冲突的处理方式，为nil时使用MergeOverwrite
+// 返回:
+// - []string: 值不同的键，按键名排序
+// - error: strategy返回的错误，配置已冻结时返回ErrFrozen，反复被并发修改时返回ErrMergeConcurrent
+func (c *Config) Merge(other *Config, strategy MergeStrategy) ([]string, error) {
+	if other == c {
+		return nil, nil
+	}
+	if strategy == nil {
+		strategy = MergeOverwrite
+	}
+	other.mutex.RLock()
+	incoming := maps.Clone(other.data)
+	sources := maps.Clone(other.meta[LayerFile])
+	other.mutex.RUnlock()
+
+	for attempt := 1; ; attempt++ {
+		c.mutex.RLock()
+		current := maps.Clone(c.data)
+		c.mutex.RUnlock()
+
+		conflicts, merged, err := resolveMerge(incoming, current, strategy)
+		if err != nil {
+			return conflicts, err
+		}
+
+		c.lock()
+		if c.frozen {
+			c.mutex.Unlock()
+			return conflicts, ErrFrozen
+		}
+		if !c.unchangedLocked(incoming, current) {
+			c.mutex.Unlock()
+			if attempt == mergeAttempts {
+				return conflicts, ErrMergeConcurrent
+			}
+			continue
+		}
+		var changes []change
+		for key, val := range merged {
+			changes = c.setLocked(key, val, changes)
+			if src, ok := sources[key]; ok && val == incoming[key] {
+				c.setSourceLocked(LayerFile, key, src)
+			}
+		}
+		c.unlockNotify(changes)
+		return conflicts, nil
+	}
+}
+
+// resolveMerge 按strategy解决incoming与current的冲突，返回冲突的键与要写入的值
+func resolveMerge(incoming, current map[string]string, strategy MergeStrategy) ([]string, map[string]string, error) {
+	var conflicts []string
+	var errs []error
+	merged := make(map[string]string, len(incoming))
+	for _, key := range sortedKeys(incoming) {
+		val := incoming[key]
+		if existing, ok := current[key]; ok {
+			if existing == val {
+				continue
+			}
+			conflicts = append(conflicts, key)
+			resolved, err := strategy(key, existing, val)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if resolved == existing {
+				continue
+			}
+			val = resolved
+		}
+		merged[key] = val
+	}
+	if len(errs) > 0 {
+		return conflicts, nil, errors.Join(errs...)
+	}
+	return conflicts, merged, nil
+}
+
+// unchangedLocked 判断incoming中的键在文件层的值是否仍与current一致，调用方须持有锁
+func (c *Config) unchangedLocked(incoming, current map[string]string) bool {
+	for key := range incoming {
+		val, ok := c.data[key]
+		if old, had := current[key]; ok != had || val != old {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMergeStrategies(t *testing.T) {
+	newPair := func() (*Config, *Config) {
+		cfg, _ := NewConfig()
+		cfg.Set("name", "app")
+		cfg.Set("port", "80")
+		cfg.Set("version", "3")
+		cfg.SetDefault("timeout", "30s")
+		other, _ := NewConfig()
+		other.Set("name", "app")
+		other.Set("port", "8080")
+		other.Set("version", "10")
+		other.Set("debug", "true")
+		other.SetOverride("timeout", "1s") // 只合并文件层
+		return cfg, other
+	}
+	higher := func(key, existing, incoming string) (string, error) {
+		a, errA := strconv.Atoi(existing)
+		b, errB := strconv.Atoi(incoming)
+		if errA != nil || errB != nil {
+			return "", errors.New("not a number")
+		}
+		return strconv.Itoa(max(a, b)), nil
+	}
+
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		want     map[string]string
+		wantErr  bool
+	}{
+		{"nil overwrites", nil, map[string]string{"port": "8080", "version": "10"}, false},
+		{"overwrite", MergeOverwrite, map[string]string{"port": "8080", "version": "10"}, false},
+		{"keep existing", MergeKeepExisting, map[string]string{"port": "80", "version": "3"}, false},
+		{"custom resolver", higher, map[string]string{"port": "8080", "version": "10"}, false},
+		{"error on conflict", MergeError, map[string]string{"port": "80", "version": "3", "debug": ""}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, other := newPair()
+			conflicts, err := cfg.Merge(other, tt.strategy)
+			if !reflect.DeepEqual(conflicts, []string{"port", "version"}) {
+				t.Errorf("conflicts = %v, want [port version]", conflicts)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrMergeConflict) {
+					t.Errorf("Merge error = %v, want ErrMergeConflict", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				tt.want["debug"] = "true"
+			}
+			for key, want := range tt.want {
+				if got := cfg.Get(key); got != want {
+					t.Errorf("Get(%s) = %q, want %q", key, got, want)
+				}
+			}
+			if got := cfg.Get("timeout"); got != "30s" {
+				t.Errorf("timeout = %q, want the local default", got)
+			}
+		})
+	}
+
+	cfg, other := newPair()
+	if conflicts, err := cfg.Merge(cfg, MergeError); err != nil || conflicts != nil {
+		t.Errorf("Merge with itself = %v, %v", conflicts, err)
+	}
+	cfg.Freeze()
+	if _, err := cfg.Merge(other, nil); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Merge into a frozen config error = %v, want ErrFrozen", err)
+	}
+}
+
+func TestMergeConcurrentModification(t *testing.T) {
+	tests := []struct {
+		name      string
+		writes    int // strategy在前几次调用中修改本配置的次数
+		file      map[string]string
+		conflicts []string
+		wantErr   error
+	}{
+		{
+			name:      "no concurrent write",
+			file:      map[string]string{"a": "1", "b": "2"},
+			conflicts: []string{"a"},
+		},
+		{
+			name:      "retried after concurrent write",
+			writes:    1,
+			file:      map[string]string{"a": "1", "b": "local1"},
+			conflicts: []string{"a", "b"},
+		},
+		{
+			name:      "gives up after repeated writes",
+			writes:    mergeAttempts,
+			file:      map[string]string{"a": "1", "b": "local3"},
+			conflicts: []string{"a", "b"},
+			wantErr:   ErrMergeConcurrent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := NewConfig()
+			cfg.Set("a", "1")
+			other, _ := NewConfig()
+			other.Set("a", "2")
+			other.Set("b", "2")
+			other.SetDefault("c", "3")
+			calls := 0
+			keep := func(key, existing, incoming string) (string, error) {
+				if key == "a" && calls < tt.writes {
+					// 模拟解决冲突期间其它goroutine写入本配置
+					calls++
+					cfg.Set("b", "local"+strconv.Itoa(calls))
+				}
+				return existing, nil
+			}
+			conflicts, err := cfg.Merge(other, keep)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Merge error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(conflicts, tt.conflicts) {
+				t.Errorf("conflicts = %v, want %v", conflicts, tt.conflicts)
+			}
+			if got := cfg.LayerValues(LayerFile); !reflect.DeepEqual(got, tt.file) {
+				t.Errorf("file layer = %v, want %v", got, tt.file)
+			}
+			if cfg.Has("c") {
+				t.Error("default layer of other was merged")
+			}
+		})
+	}
+}
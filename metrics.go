@@ -0,0 +1,181 @@
+package config
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics 接收配置的运行指标，由SetMetrics设置
+// 方法在读取或重新加载的goroutine中同步调用，不持有锁，实现应当足够快且并发安全
+type Metrics interface {
+	// ObserveGet 在每次读取键时调用，hit表示键是否存在
+	// Get、Lookup、Has、GetInt等所有读取方法都会触发
+	ObserveGet(key string, hit bool)
+	// ObserveReload 在Watch、WatchProvider与AddProvider每次后台重新加载后调用，
+	// source为文件或配置源，失败时err非nil
+	ObserveReload(source string, err error)
+}
+
+// SetMetrics 设置接收运行指标的实现，为nil时停止记录
+// 内置的Counters实现了Metrics，并可以通过expvar或Prometheus文本格式导出
+// 参数:
+// - m: 指标实现
+func (c *Config) SetMetrics(m Metrics) {
+	if m == nil {
+		c.metrics.Store(nil)
+		return
+	}
+	c.metrics.Store(&m)
+}
+
+// observeGet 向SetMetrics设置的实现报告一次读取
+func (c *Config) observeGet(key string, hit bool) {
+	if m := c.metrics.Load(); m != nil {
+		(*m).ObserveGet(key, hit)
+	}
+}
+
+// observeReload 向SetMetrics设置的实现报告一次重新加载
+func (c *Config) observeReload(source string, err error) {
+	if m := c.metrics.Load(); m != nil {
+		(*m).ObserveReload(source, err)
+	}
+}
+
+// Stats 是Counters在某一时刻的计数
+type Stats struct {
+	Hits           uint64    // 读取到存在的键的次数
+	Misses         uint64    // 读取不存在的键的次数
+	Reloads        uint64    // 重新加载的次数，包括失败的
+	ReloadFailures uint64    // 重新加载失败的次数
+	LastReload     time.Time // 最近一次成功重新加载的时间，从未成功时为零值
+}
+
+// Counters 是以原子计数器实现的Metrics
+//
+//	m := config.NewCounters()
+//	cfg.SetMetrics(m)
+//	expvar.Publish("config", m.Expvar())
+//	http.Handle("/metrics", m.PrometheusHandler("myapp"))
+type Counters struct {
+	hits           atomic.Uint64
+	misses         atomic.Uint64
+	reloads        atomic.Uint64
+	reloadFailures atomic.Uint64
+	lastReload     atomic.Int64 // Unix纳秒，为0表示从未成功
+}
+
+// NewCounters 创建计数全部为0的Counters
+// 返回:
+// - *Counters: 计数器
+func NewCounters() *Counters {
+	return &Counters{}
+}
+
+// ObserveGet 实现Metrics
+func (m *Counters) ObserveGet(key string, hit bool) {
+	if hit {
+		m.hits.Add(1)
+	} else {
+		m.misses.Add(1)
+	}
+}
+
+// ObserveReload 实现Metrics
+func (m *Counters) ObserveReload(source string, err error) {
+	m.reloads.Add(1)
+	if err != nil {
+		m.reloadFailures.Add(1)
+		return
+	}
+	m.lastReload.Store(time.Now().UnixNano())
+}
+
+// Stats 返回当前的计数
+func (m *Counters) Stats() Stats {
+	s := Stats{
+		Hits:           m.hits.Load(),
+		Misses:         m.misses.Load(),
+		Reloads:        m.reloads.Load(),
+		ReloadFailures: m.reloadFailures.Load(),
+	}
+	if ns := m.lastReload.Load(); ns != 0 {
+		s.LastReload = time.Unix(0, ns)
+	}
+	return s
+}
+
+// Expvar 返回以JSON对象导出当前计数的expvar.Var，可通过expvar.Publish发布
+// 对象的字段为hits、misses、reloads、reload_failures与last_reload(Unix秒，从未成功时为0)
+func (m *Counters) Expvar() expvar.Var {
+	return expvar.Func(func() any {
+		s := m.Stats()
+		return map[string]any{
+			"hits":            s.Hits,
+			"misses":          s.Misses,
+			"reloads":         s.Reloads,
+			"reload_failures": s.ReloadFailures,
+			"last_reload":     unixSeconds(s.LastReload),
+		}
+	})
+}
+
+// WritePrometheus 以Prometheus文本格式写出当前计数
+// namespace非空时指标名以"namespace_"开头，其后为：
+// config_get_hits_total、config_get_misses_total、config_reloads_total、
+// config_reload_failures_total与config_last_reload_timestamp_seconds
+// 参数:
+// - w: 写出目标
+// - namespace: 指标名前缀
+// 返回:
+// - error: 写入错误(如果有)
+func (m *Counters) WritePrometheus(w io.Writer, namespace string) error {
+	prefix := "config_"
+	if namespace != "" {
+		prefix = namespace + "_config_"
+	}
+	s := m.Stats()
+	metrics := []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"get_hits_total", "counter", "Number of reads of existing config keys.", float64(s.Hits)},
+		{"get_misses_total", "counter", "Number of reads of missing config keys.", float64(s.Misses)},
+		{"reloads_total", "counter", "Number of config reloads, including failed ones.", float64(s.Reloads)},
+		{"reload_failures_total", "counter", "Number of failed config reloads.", float64(s.ReloadFailures)},
+		{"last_reload_timestamp_seconds", "gauge", "Unix time of the last successful config reload.", unixSeconds(s.LastReload)},
+	}
+	for _, mt := range metrics {
+		name := prefix + mt.name
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, mt.help, name, mt.kind, name, strconv.FormatFloat(mt.value, 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrometheusHandler 返回以Prometheus文本格式输出当前计数的http.Handler，可直接注册为/metrics
+// 已有Prometheus客户端库的程序可以在自定义Collector中读取Stats
+// 参数:
+// - namespace: 指标名前缀，见WritePrometheus
+// 返回:
+// - http.Handler: 处理器
+func (m *Counters) PrometheusHandler(namespace string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WritePrometheus(w, namespace)
+	})
+}
+
+// unixSeconds 返回t的Unix秒数(带小数)，t为零值时返回0
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCounters(t *testing.T) {
+	cfg, _ := NewConfig()
+	m := NewCounters()
+	cfg.SetMetrics(m)
+	cfg.Set("port", "80")
+	cfg.Get("port")
+	cfg.Has("port")
+	cfg.GetInt("port")
+	cfg.Get("missing")
+	cfg.Lookup("missing")
+
+	p := newPushProvider(map[string]string{"x": "1"})
+	done := reloads(cfg)
+	cfg.AddProvider(p)
+	before := time.Now()
+	p.push(Update{Values: map[string]string{"x": "2"}})
+	waitReload(t, done)
+	p.push(Update{Err: errors.New("down")})
+	waitReload(t, done)
+	cfg.Close()
+
+	s := m.Stats()
+	if s.Hits != 3 || s.Misses != 2 || s.Reloads != 2 || s.ReloadFailures != 1 {
+		t.Errorf("Stats() = %+v, want 3 hits, 2 misses, 2 reloads and 1 failure", s)
+	}
+	if s.LastReload.Before(before) {
+		t.Errorf("LastReload = %v, want the successful reload", s.LastReload)
+	}
+
+	var exported map[string]float64
+	if err := json.Unmarshal([]byte(m.Expvar().String()), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if exported["hits"] != 3 || exported["reload_failures"] != 1 || exported["last_reload"] < float64(before.Unix()) {
+		t.Errorf("Expvar() = %v", exported)
+	}
+
+	rec := httptest.NewRecorder()
+	m.PrometheusHandler("app").ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE app_config_get_hits_total counter\napp_config_get_hits_total 3\n",
+		"app_config_get_misses_total 2\n",
+		"app_config_reload_failures_total 1\n",
+		"# TYPE app_config_last_reload_timestamp_seconds gauge\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("Prometheus output =\n%s\nwant it to contain %q", body, line)
+		}
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Content-Type = %q", rec.Header().Get("Content-Type"))
+	}
+
+	// 停止记录
+	cfg.SetMetrics(nil)
+	cfg.Get("port")
+	if m.Stats().Hits != 3 {
+		t.Error("Counters still observe reads after SetMetrics(nil)")
+	}
+	var empty strings.Builder
+	NewCounters().WritePrometheus(&empty, "")
+	if !strings.Contains(empty.String(), "\nconfig_last_reload_timestamp_seconds 0\n") {
+		t.Errorf("WritePrometheus without a namespace =\n%s", empty.String())
+	}
+}
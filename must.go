@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MustGet 获取配置值，键不存在时panic
+// 适用于启动阶段的必需配置，程序宁可立即退出也不要带着缺失的配置运行；
+// 希望一次报告全部问题时使用Collector
+// 参数:
+// - key: 要查找的配置键
+// 返回:
+// - string: 配置值
+func (c *Config) MustGet(key string) string {
+	val, ok := c.lookup(key)
+	if !ok {
+		panic(fmt.Errorf("key %q: %w", key, ErrKeyNotFound))
+	}
+	return val
+}
+
+// MustGetInt 与GetInt相同，出错时以该错误panic
+func (c *Config) MustGetInt(key string) int {
+	return must(c.GetInt(key))
+}
+
+// MustGetInt64 与GetInt64相同，出错时以该错误panic
+func (c *Config) MustGetInt64(key string) int64 {
+	return must(c.GetInt64(key))
+}
+
+// MustGetBool 与GetBool相同，出错时以该错误panic
+func (c *Config) MustGetBool(key string) bool {
+	return must(c.GetBool(key))
+}
+
+// MustGetFloat64 与GetFloat64相同，出错时以该错误panic
+func (c *Config) MustGetFloat64(key string) float64 {
+	return must(c.GetFloat64(key))
+}
+
+// MustGetDuration 与GetDuration相同，出错时以该错误panic
+func (c *Config) MustGetDuration(key string) time.Duration {
+	return must(c.GetDuration(key))
+}
+
+// MustGetStringSlice 与GetStringSlice相同，出错时以该错误panic
+func (c *Config) MustGetStringSlice(key string, opts ...SliceOption) []string {
+	return must(c.GetStringSlice(key, opts...))
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Collector 以不panic的方式读取必需配置并收集所有错误
+// 每个方法在出错时返回零值并记录错误，读取完成后通过Err一次取得全部问题：
+//
+//	r := cfg.Collector()
+//	host := r.Get("db.host")
+//	port := r.Int("db.port")
+//	if err := r.Err(); err != nil {
+//		log.Fatal(err) // 列出所有缺失或无效的键
+//	}
+//
+// Collector可以被多个goroutine同时使用
+type Collector struct {
+	c     *Config
+	mutex sync.Mutex
+	errs  []error
+}
+
+// Collector 返回读取本配置的错误收集器
+// 返回:
+// - *Collector: 错误收集器
+func (c *Config) Collector() *Collector {
+	return &Collector{c: c}
+}
+
+// Get 获取配置值，键不存在时记录错误
+func (r *Collector) Get(key string) string {
+	val, ok := r.c.lookup(key)
+	if !ok {
+		r.check(fmt.Errorf("key %q: %w", key, ErrKeyNotFound))
+	}
+	return val
+}
+
+// Int 与GetInt相同，出错时记录错误
+func (r *Collector) Int(key string) int {
+	v, err := r.c.GetInt(key)
+	r.check(err)
+	return v
+}
+
+// Int64 与GetInt64相同，出错时记录错误
+func (r *Collector) Int64(key string) int64 {
+	v, err := r.c.GetInt64(key)
+	r.check(err)
+	return v
+}
+
+// Bool 与GetBool相同，出错时记录错误
+func (r *Collector) Bool(key string) bool {
+	v, err := r.c.GetBool(key)
+	r.check(err)
+	return v
+}
+
+// Float64 与GetFloat64相同，出错时记录错误
+func (r *Collector) Float64(key string) float64 {
+	v, err := r.c.GetFloat64(key)
+	r.check(err)
+	return v
+}
+
+// Duration 与GetDuration相同，出错时记录错误
+func (r *Collector) Duration(key string) time.Duration {
+	v, err := r.c.GetDuration(key)
+	r.check(err)
+	return v
+}
+
+// StringSlice 与GetStringSlice相同，出错时记录错误
+func (r *Collector) StringSlice(key string, opts ...SliceOption) []string {
+	v, err := r.c.GetStringSlice(key, opts...)
+	r.check(err)
+	return v
+}
+
+// Err 返回收集到的全部错误，用errors.Join连接，没有错误时返回nil
+// 返回的错误可用errors.Is(err, ErrKeyNotFound)判断是否有缺失的键
+func (r *Collector) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return errors.Join(r.errs...)
+}
+
+// check 在err非nil时记录错误
+func (r *Collector) check(err error) {
+	if err != nil {
+		r.mutex.Lock()
+		r.errs = append(r.errs, err)
+		r.mutex.Unlock()
+	}
+}
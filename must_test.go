@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mustPanic 调用fn并返回其panic的值，没有panic时为nil
+func mustPanic(fn func()) (v any) {
+	defer func() { v = recover() }()
+	fn()
+	return nil
+}
+
+func TestMustGetters(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("name", "app")
+	cfg.Set("port", "8080")
+	cfg.Set("debug", "on")
+	cfg.Set("timeout", "5s")
+	cfg.Set("hosts", "a,b")
+
+	if cfg.MustGet("name") != "app" || cfg.MustGetInt("port") != 8080 || cfg.MustGetInt64("port") != 8080 ||
+		!cfg.MustGetBool("debug") || cfg.MustGetDuration("timeout") != 5*time.Second ||
+		cfg.MustGetFloat64("port") != 8080 || len(cfg.MustGetStringSlice("hosts")) != 2 {
+		t.Error("Must getters returned unexpected values")
+	}
+
+	v := mustPanic(func() { cfg.MustGet("missing") })
+	if err, ok := v.(error); !ok || !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("MustGet(missing) panicked with %v, want ErrKeyNotFound", v)
+	}
+	v = mustPanic(func() { cfg.MustGetInt("name") })
+	if err, ok := v.(error); !ok || !strings.Contains(err.Error(), `"name"`) {
+		t.Errorf("MustGetInt(name) panicked with %v, want the parse error", v)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("db.host", "localhost")
+	cfg.Set("db.port", "abc")
+	cfg.Set("db.timeout", "10s")
+
+	r := cfg.Collector()
+	if r.Get("db.host") != "localhost" || r.Duration("db.timeout") != 10*time.Second {
+		t.Error("Collector returned unexpected values")
+	}
+	if r.Err() != nil {
+		t.Fatalf("Err() = %v before any failure", r.Err())
+	}
+	var wg sync.WaitGroup
+	for _, key := range []string{"db.user", "db.password"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Get(key)
+		}()
+	}
+	wg.Wait()
+	if n := r.Int("db.port"); n != 0 {
+		t.Errorf("Int(db.port) = %d, want the zero value", n)
+	}
+	r.Bool("db.ssl")
+
+	err := r.Err()
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Err() = %v, want it to wrap ErrKeyNotFound", err)
+	}
+	for _, key := range []string{"db.user", "db.password", "db.port", "db.ssl"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Err() =\n%v\nwant it to mention %s", err, key)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import "strings"
+
+// WithCaseInsensitiveKeys 让键不区分大小写：所有键规范化为小写后存储与查找
+// 与WithKeyNormalizer同时使用时按传入NewConfigWithOptions的顺序依次应用
+func WithCaseInsensitiveKeys() ConfigOption {
+	return WithKeyNormalizer(strings.ToLower)
+}
+
+// WithKeyNormalizer 设置键的规范化函数，用于统一环境变量、命令行参数与文件中同一设置的不同写法
+// 例如把"-"替换为"_"后，"http-port"与"http_port"是同一个键。规范化作用于写入、读取与删除的键，
+// 以及DefineKey、Alias、Deprecate、MarkSecret、Subscribe、GetMatching等登记的键与模式；
+// 加载的文件保留原有写法，SaveToFile写出时已有的行保持原样，新键以规范化后的形式写出。
+// fn必须是幂等的，即fn(fn(k)) == fn(k)，且不应改变通配符"*"与"?"
+// 参数:
+// - fn: 规范化函数
+func WithKeyNormalizer(fn func(key string) string) ConfigOption {
+	return func(c *Config) {
+		if prev := c.normalizer; prev != nil {
+			c.normalizer = func(key string) string { return fn(prev(key)) }
+			return
+		}
+		c.normalizer = fn
+	}
+}
+
+// WithKeyDelimiter 设置键的层级分隔符，默认为"."
+// 例如设为"/"后从Consul或etcd保留"/"(Delimiter为空)导入的"db/primary/host"可以用Sub("db")、
+// Sub("db/primary")取子配置。分隔符作用于Sub、SubSlice、GetSliceLen、GetValue、Unmarshal与Marshal的层级，
+// 以及Subscribe、MarkSecret、GetMatching等通配符中"*"与"?"不跨越的边界、GetStringMap去掉的前缀与SetProfile的profile前缀
+// (如"prod/db/host")；文件格式展开嵌套结构与[section]段落仍使用"."
+// 参数:
+// - delimiter: 分隔符，如"/"或"::"，为空时使用"."
+func WithKeyDelimiter(delimiter string) ConfigOption {
+	return func(c *Config) {
+		c.delimiter = delimiter
+	}
+}
+
+// sep 返回键的层级分隔符
+func (c *Config) sep() string {
+	if c.delimiter == "" {
+		return "."
+	}
+	return c.delimiter
+}
+
+// joinKey 使用层级分隔符连接键前缀与键名
+func (c *Config) joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + c.sep() + name
+}
+
+// matchKey 以层级分隔符为边界判断key是否匹配通配符模式
+func (c *Config) matchKey(pattern, key string) bool {
+	return matchPatternSep(pattern, key, c.sep())
+}
+
+// NormalizeKey 返回key规范化后的形式，即配置中实际存储的键名
+// 未设置WithCaseInsensitiveKeys或WithKeyNormalizer时原样返回
+// 参数:
+// - key: 配置键
+// 返回:
+// - string: 规范化后的键
+func (c *Config) NormalizeKey(key string) string {
+	return c.normKey(key)
+}
+
+// normKey 规范化键，normalizer只在创建时设置，读取无需加锁
+func (c *Config) normKey(key string) string {
+	if c.normalizer == nil {
+		return key
+	}
+	return c.normalizer(key)
+}
+
+// normKeys 返回键规范化后的新映射，未设置规范化时原样返回
+func normKeys[V any](c *Config, m map[string]V) map[string]V {
+	if c.normalizer == nil || m == nil {
+		return m
+	}
+	out := make(map[string]V, len(m))
+	for k, v := range m {
+		out[c.normKey(k)] = v
+	}
+	return out
+}
+
+// normalizeDocument 规范化解析结果中的键，文件结构中的原始行保持不变
+func (c *Config) normalizeDocument(doc *kvDocument) {
+	if c.normalizer == nil {
+		return
+	}
+	doc.values = normKeys(c, doc.values)
+	doc.pos = normKeys(c, doc.pos)
+	if doc.layout == nil {
+		return
+	}
+	for i := range doc.layout.lines {
+		line := &doc.layout.lines[i]
+		if line.key != "" {
+			line.key = c.normKey(line.key)
+		}
+		if line.section != "" {
+			line.section = c.normKey(line.section)
+		}
+	}
+	doc.layout.included = normKeys(c, doc.layout.included)
+}
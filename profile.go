@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetProfile 设置当前profile(如"prod")，传入空字符串关闭
+// 启用后，每一层中"prod.server.port"形式的键优先于同层的"server.port"(profile与键之间使用WithKeyDelimiter设置的分隔符)；
+// 此后LoadFromFile、LoadFromJSON、LoadFromYAML、LoadFromTOML在加载"config.ini"之后，
+// 若存在同目录下的"config-prod.ini"，会继续加载它以覆盖基础值。
+// 因此应在加载文件之前调用。切换profile导致的生效值变化会通知订阅者。
+// 参数:
+// - name: profile名称
+// 返回:
+// - error: 配置已冻结时返回ErrFrozen
+func (c *Config) SetProfile(name string) error {
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	changes := c.diffLocked(func() {
+		c.profile = name
+	})
+	c.unlockNotify(changes)
+	return nil
+}
+
+// Profile 返回当前profile，未启用时返回空字符串
+func (c *Config) Profile() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.profile
+}
+
+// loadProfileFile 在启用profile且存在filename对应的profile文件时加载它
+func (c *Config) loadProfileFile(ctx context.Context, filename string, settings loadSettings) error {
+	profile := c.Profile()
+	if profile == "" {
+		return nil
+	}
+	variant := profileFilename(filename, profile)
+	if _, err := os.Stat(variant); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return c.loadFile(ctx, variant, false, settings)
+}
+
+// profileFilename 返回文件的profile版本，如"conf/app.ini"对应"conf/app-prod.ini"
+func profileFilename(filename, profile string) string {
+	base := trimCompressionExt(filename)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-" + profile + ext + filename[len(base):]
+}
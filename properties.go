@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// LoadFromProperties 从Java .properties文件加载配置
+// 解析规则与java.util.Properties.load一致:
+// - 以#或!开头的行是注释
+// - 键与值之间用"="、":"或空白分隔
+// - 以奇数个"\"结尾的行与下一行拼接，下一行的前导空白被去除
+// - 支持\t、\n、\r、\f与\uXXXX转义，其它字符前的"\"被去掉
+//
+// 内容不是合法UTF-8时按ISO-8859-1解码。加载的键会覆盖已有的同名键
+// 参数:
+// - filename: .properties文件路径
+// 返回:
+// - error: 文件读取或解析错误(如果有)
+func (c *Config) LoadFromProperties(filename string) error {
+	content, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	values, err := decodeProperties(content)
+	if err != nil {
+		return err
+	}
+	if err := c.mergeValuesFrom(values, KeySource{Kind: SourceFile, Name: filename}); err != nil {
+		return err
+	}
+	return c.loadProfileFile(context.Background(), filename, loadSettings{})
+}
+
+// SaveToProperties 将文件层配置以.properties格式保存到文件
+// 键按字典序写出，特殊字符与非ASCII字符按Properties.store的规则转义，
+// 与SaveToFile一样通过临时文件加重命名原子写入
+// 参数:
+// - filename: 目标文件路径
+// - opts: 文件权限、属主等可选设置
+// 返回:
+// - error: 文件操作错误(如果有)
+func (c *Config) SaveToProperties(filename string, opts ...SaveOption) error {
+	return writeBytesAtomic(filename, encodeProperties(c.LayerValues(LayerFile)), opts...)
+}
+
+// decodeProperties 解析.properties内容
+func decodeProperties(content []byte) (map[string]string, error) {
+	text := string(content)
+	if !utf8.Valid(content) {
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	}
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		start := lineNo
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		for endsWithEscape(line) {
+			line = line[:len(line)-1]
+			if !scanner.Scan() {
+				break
+			}
+			lineNo++
+			line += strings.TrimLeft(scanner.Text(), " \t\f")
+		}
+		key, value := splitProperty(line)
+		k, err := unescapeProperty(key)
+		if err != nil {
+			return nil, fmt.Errorf("properties: line %d: %w", start, err)
+		}
+		v, err := unescapeProperty(value)
+		if err != nil {
+			return nil, fmt.Errorf("properties: line %d: %w", start, err)
+		}
+		values[k] = v
+	}
+	return values, scanner.Err()
+}
+
+// endsWithEscape 判断行是否以奇数个"\"结尾，即需要与下一行拼接
+func endsWithEscape(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty 在第一个未转义的"="、":"或空白处拆分键与值
+// 键之后的空白以及紧随其后的一个"="或":"都属于分隔符
+func splitProperty(line string) (key, value string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	key, rest := line[:end], line[end:]
+	rest = strings.TrimLeft(rest, " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+// unescapeProperty 处理.properties中的转义序列
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uXXXX escape in %q", s)
+			}
+			n, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uXXXX escape in %q", s)
+			}
+			r := rune(n)
+			i += 4
+			// 代理对由两个连续的\uXXXX表示
+			if utf16.IsSurrogate(r) && strings.HasPrefix(s[i+1:], `\u`) && i+7 <= len(s) {
+				if low, err := strconv.ParseUint(s[i+3:i+7], 16, 16); err == nil {
+					if dec := utf16.DecodeRune(r, rune(low)); dec != utf8.RuneError {
+						r = dec
+						i += 6
+					}
+				}
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// encodeProperties 以.properties格式写出键值，键按字典序排列
+func encodeProperties(values map[string]string) []byte {
+	var buf bytes.Buffer
+	for _, key := range sortedKeys(values) {
+		buf.WriteString(escapeProperty(key, true))
+		buf.WriteByte('=')
+		buf.WriteString(escapeProperty(values[key], false))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// escapeProperty 按Properties.store的规则转义键或值
+// 键中的空白全部转义，值只转义前导空白；非ASCII字符写为\uXXXX
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if isKey || i == 0 {
+				b.WriteString(`\ `)
+			} else {
+				b.WriteByte(' ')
+			}
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':', '#', '!', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			if r < 0x20 || r > 0x7e {
+				for _, u := range utf16.Encode([]rune{r}) {
+					fmt.Fprintf(&b, `\u%04X`, u)
+				}
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeProperties(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "separators and comments",
+			input: "# comment\n! also comment\na=1\nb : 2\nc 3\nd\n  e = 5\n",
+			want:  map[string]string{"a": "1", "b": "2", "c": "3", "d": "", "e": "5"},
+		},
+		{
+			name:  "continuation lines",
+			input: "fruits = apple, \\\n         banana\nodd = a\\\\\nnext = 1\n",
+			want:  map[string]string{"fruits": "apple, banana", "odd": `a\`, "next": "1"},
+		},
+		{
+			name:  "comments are not continued",
+			input: "# comment \\\na=x\\\n#y\n",
+			want:  map[string]string{"a": "x#y"},
+		},
+		{
+			name:  "escapes",
+			input: `tab=a\tb` + "\n" + `uni=caf\u00e9` + "\n" + `other=\a\=\:` + "\n",
+			want:  map[string]string{"tab": "a\tb", "uni": "café", "other": "a=:"},
+		},
+		{
+			name:  "escaped separators in key",
+			input: `key\ with\=sep=value` + "\n" + `url\:port=8080` + "\n",
+			want:  map[string]string{"key with=sep": "value", "url:port": "8080"},
+		},
+		{
+			name:  "value keeps trailing spaces and later separators",
+			input: "a = x = y  \n",
+			want:  map[string]string{"a": "x = y  "},
+		},
+		{
+			name:  "latin-1 content",
+			input: "name=caf\xe9\n",
+			want:  map[string]string{"name": "café"},
+		},
+		{
+			name:  "crlf",
+			input: "a=1\r\nb=2\r\n",
+			want:  map[string]string{"a": "1", "b": "2"},
+		},
+		{name: "malformed unicode escape", input: `a=\u12` + "\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeProperties([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeProperties error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeProperties = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropertiesRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"server.port":  "8080",
+		"key with=sep": "value",
+		"lead":         "  spaced",
+		"multi":        "line1\nline2",
+		"unicode":      "café ☕",
+		"comment":      "#not",
+		"empty":        "",
+		"backslash":    `C:\dir\`,
+	}
+	got, err := decodeProperties(encodeProperties(values))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("round trip of\n%s= %q, want %q", encodeProperties(values), got, values)
+	}
+}
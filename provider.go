@@ -0,0 +1,355 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ErrProviderClosed 表示配置源已被关闭
+var ErrProviderClosed = errors.New("provider closed")
+
+// Provider 是远程或外部配置源
+// Load返回配置源当前的全部键值；Watch阻塞运行，每当数据变化时向ch发送
+// 包含完整数据的Update，直到配置源被关闭(返回nil)或发生不可恢复的错误。
+// 需要停止监视的实现应同时实现io.Closer。
+type Provider interface {
+	Load() (map[string]string, error)
+	Watch(ch chan<- Update) error
+}
+
+// Update 是Provider.Watch发送的数据更新
+type Update struct {
+	// Values 是配置源变化后的完整数据
+	Values map[string]string
+	// Err 非nil时表示本次获取失败，Values无效
+	Err error
+}
+
+// LoadFromProvider 从配置源加载数据并合并到文件层
+// 参数:
+// - p: 配置源
+// 返回:
+// - error: 配置源加载错误(如果有)
+func (c *Config) LoadFromProvider(p Provider) error {
+	return c.LoadFromProviderContext(context.Background(), p)
+}
+
+// ContextProvider 是加载过程可以通过context取消的配置源
+// 内置的HTTP、etcd、Consul、Redis、Vault、AWS与ZooKeeper配置源都实现了该接口
+type ContextProvider interface {
+	Provider
+	LoadContext(ctx context.Context) (map[string]string, error)
+}
+
+// LoadFromProviderContext 与LoadFromProvider相同，ctx取消或超时时中止加载并返回ctx.Err()
+// p未实现ContextProvider时，Load在后台继续运行直到完成，但其结果被丢弃
+// 参数:
+// - ctx: 约束加载过程的context
+// - p: 配置源
+// 返回:
+// - error: 配置源加载错误或ctx的错误(如果有)
+func (c *Config) LoadFromProviderContext(ctx context.Context, p Provider) error {
+	values, err := c.loadProvider(ctx, p)
+	if err != nil {
+		return err
+	}
+	return c.mergeValuesFrom(values, providerSource(p))
+}
+
+// loadProvider 在ctx的约束下调用配置源的Load，设置了Tracer时记录span
+func (c *Config) loadProvider(ctx context.Context, p Provider) (values map[string]string, err error) {
+	ctx, span := c.startSpan(ctx, "config.provider.Load", slog.String("config.provider", providerSource(p).Name))
+	defer func() { span.End(err) }()
+	return callLoad(ctx, p)
+}
+
+// callLoad 在ctx的约束下调用配置源的Load
+func callLoad(ctx context.Context, p Provider) (map[string]string, error) {
+	if cp, ok := p.(ContextProvider); ok {
+		return cp.LoadContext(ctx)
+	}
+	if ctx.Done() == nil {
+		return p.Load()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type result struct {
+		values map[string]string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		values, err := p.Load()
+		done <- result{values, err}
+	}()
+	select {
+	case r := <-done:
+		return r.values, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// providerContext 返回在ctx结束或配置源关闭(closed结束)时取消的context，供LoadContext的实现使用
+func providerContext(ctx, closed context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(closed, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// WatchProvider 从配置源加载数据并在后台持续应用其更新
+// 每次更新时，新数据合并到文件层，上一次存在而本次消失的键被删除；
+// 包含Err的更新不修改配置。每次处理更新后调用OnReload回调。
+// 参数:
+// - p: 配置源
+// 返回:
+// - func(): 停止监视的函数；p实现io.Closer时会调用其Close
+// - error: 初始加载错误(如果有)
+func (c *Config) WatchProvider(p Provider) (func(), error) {
+	return c.watchProvider(context.Background(), p)
+}
+
+// WatchProviderContext 与WatchProvider相同，ctx约束初始加载，ctx结束时停止监视
+// 参数:
+// - ctx: 约束初始加载并决定监视期限的context
+// - p: 配置源
+// 返回:
+// - error: 初始加载错误或ctx的错误(如果有)
+func (c *Config) WatchProviderContext(ctx context.Context, p Provider) error {
+	stop, err := c.watchProvider(ctx, p)
+	if err != nil {
+		return err
+	}
+	context.AfterFunc(ctx, stop)
+	return nil
+}
+
+// watchProvider 在ctx的约束下加载配置源，然后在后台应用其更新
+func (c *Config) watchProvider(ctx context.Context, p Provider) (func(), error) {
+	values, err := c.loadProvider(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	src := providerSource(p)
+	if err := c.mergeValuesFrom(values, src); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Update)
+	done := make(chan struct{})
+	go func() {
+		defer close(ch)
+		if err := p.Watch(ch); err != nil && !errors.Is(err, ErrProviderClosed) {
+			c.fireReload(src.Name, err)
+		}
+	}()
+
+	go func() {
+		prev := values
+		for {
+			select {
+			case <-done:
+				// 继续排空ch，避免Watch在发送时阻塞
+				for range ch {
+				}
+				return
+			case u, ok := <-ch:
+				if !ok {
+					return
+				}
+				if u.Err != nil {
+					c.fireReload(src.Name, u.Err)
+					continue
+				}
+				if err := c.applyProviderValues(prev, u.Values, src); err != nil {
+					c.fireReload(src.Name, err)
+					continue
+				}
+				prev = u.Values
+				c.fireReload(src.Name, nil)
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			if closer, ok := p.(io.Closer); ok {
+				closer.Close()
+			}
+		})
+	}
+	return stop, nil
+}
+
+// providerState 是AddProvider添加的配置源及其最近一次提供的数据
+type providerState struct {
+	provider Provider
+	src      KeySource
+	values   map[string]string
+	closed   bool
+}
+
+// AddProvider 把配置源加入配置源栈，加载其数据并在后台持续应用其更新
+// 通过AddProvider添加的配置源按添加顺序叠加，后添加的配置源覆盖先添加的配置源中的同名键，
+// 合并结果写入文件层；某个配置源删除键时，如果先添加的配置源仍提供该键，则恢复为那个值。
+// 包含Err的更新不修改配置，每次处理更新后调用OnReload回调。
+// 文件、环境变量、HTTP、etcd、Consul都可以作为配置源，见NewFileProvider、NewEnvProvider等，
+// 自定义的配置服务只需实现Provider接口。
+// 参数:
+// - p: 配置源
+// 返回:
+// - error: 初始加载错误(如果有)，配置已冻结时返回ErrFrozen
+func (c *Config) AddProvider(p Provider) error {
+	return c.AddProviderContext(context.Background(), p)
+}
+
+// AddProviderContext 与AddProvider相同，ctx只约束初始加载，之后的监视持续到Close
+// 参数:
+// - ctx: 约束初始加载的context
+// - p: 配置源
+// 返回:
+// - error: 初始加载错误或ctx的错误(如果有)，配置已冻结时返回ErrFrozen
+func (c *Config) AddProviderContext(ctx context.Context, p Provider) error {
+	values, err := c.loadProvider(ctx, p)
+	if err != nil {
+		return err
+	}
+	state := &providerState{provider: p, src: providerSource(p), values: values}
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	c.providers = append(c.providers, state)
+	changes := c.applyProvidersLocked(nil)
+	c.unlockNotify(changes)
+
+	ch := make(chan Update)
+	go func() {
+		defer close(ch)
+		if err := p.Watch(ch); err != nil && !errors.Is(err, ErrProviderClosed) {
+			c.fireReload(state.src.Name, err)
+		}
+	}()
+	go func() {
+		for u := range ch {
+			if u.Err != nil {
+				c.fireReload(state.src.Name, u.Err)
+				continue
+			}
+			if ok, err := c.updateProvider(state, u.Values); ok {
+				c.fireReload(state.src.Name, err)
+			}
+		}
+	}()
+	return nil
+}
+
+// Close 停止所有通过AddProvider添加的配置源，实现io.Closer的配置源会被关闭
+// 已写入配置的值保持不变，之后收到的更新被忽略
+// 返回:
+// - error: 关闭配置源时发生的错误(如果有)
+func (c *Config) Close() error {
+	c.lock()
+	states := c.providers
+	c.providers = nil
+	c.providerKeys = nil
+	for _, s := range states {
+		s.closed = true
+	}
+	c.mutex.Unlock()
+
+	var errs []error
+	for _, s := range states {
+		if closer, ok := s.provider.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// updateProvider 记录配置源的新数据并重新合并配置源栈
+// 配置源已被Close时返回false，表示更新被忽略
+func (c *Config) updateProvider(state *providerState, values map[string]string) (bool, error) {
+	c.lock()
+	if state.closed {
+		c.mutex.Unlock()
+		return false, nil
+	}
+	if c.frozen {
+		c.mutex.Unlock()
+		return true, ErrFrozen
+	}
+	state.values = values
+	changes := c.applyProvidersLocked(nil)
+	c.unlockNotify(changes)
+	return true, nil
+}
+
+// applyProvidersLocked 按添加顺序合并配置源栈的数据并写入文件层，
+// 删除上次合并结果中存在而本次消失的键，调用方须持有写锁
+func (c *Config) applyProvidersLocked(changes []change) []change {
+	merged := make(map[string]string)
+	sources := make(map[string]KeySource)
+	for _, s := range c.providers {
+		for k, v := range s.values {
+			merged[k] = v
+			sources[k] = s.src
+		}
+	}
+	for k := range c.providerKeys {
+		if _, ok := merged[k]; !ok {
+			changes = c.deleteLocked(k, changes)
+		}
+	}
+	keys := make(map[string]bool, len(merged))
+	for k, v := range merged {
+		changes = c.setLocked(k, v, changes)
+		c.setSourceLocked(LayerFile, k, sources[k])
+		keys[k] = true
+	}
+	c.providerKeys = keys
+	return changes
+}
+
+// applyProviderValues 将配置源的新数据写入文件层，并删除已从配置源消失的键
+func (c *Config) applyProviderValues(prev, next map[string]string, src KeySource) error {
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	var changes []change
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			changes = c.deleteLocked(k, changes)
+		}
+	}
+	for k, v := range next {
+		changes = c.setLocked(k, v, changes)
+		c.setSourceLocked(LayerFile, k, src)
+	}
+	c.unlockNotify(changes)
+	return nil
+}
+
+// providerSource 返回配置源写入的键的来源记录，名称为配置源的类型名；FileProvider记录为来自文件
+func providerSource(p Provider) KeySource {
+	if fp, ok := p.(*FileProvider); ok {
+		return KeySource{Kind: SourceFile, Name: fp.filename}
+	}
+	return KeySource{Kind: SourceProvider, Name: strings.TrimPrefix(fmt.Sprintf("%T", p), "*")}
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pushProvider 是测试用的配置源，Load返回初始数据，Watch转发push发送的更新直到Close
+type pushProvider struct {
+	values  map[string]string
+	loadErr error
+	updates chan Update
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newPushProvider(values map[string]string) *pushProvider {
+	return &pushProvider{values: values, updates: make(chan Update), done: make(chan struct{})}
+}
+
+func (p *pushProvider) Load() (map[string]string, error) { return p.values, p.loadErr }
+
+func (p *pushProvider) Watch(ch chan<- Update) error {
+	for {
+		select {
+		case <-p.done:
+			return ErrProviderClosed
+		case u := <-p.updates:
+			ch <- u
+		}
+	}
+}
+
+func (p *pushProvider) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+// push 发送一个更新
+func (p *pushProvider) push(u Update) {
+	p.updates <- u
+}
+
+// reloads 注册OnReload回调，返回接收每次回调结果的通道
+func reloads(cfg *Config) <-chan error {
+	ch := make(chan error, 16)
+	cfg.OnReload(func(err error) { ch <- err })
+	return ch
+}
+
+// waitReload 等待一次热加载回调并返回其结果
+func waitReload(t *testing.T, ch <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a reload")
+		return nil
+	}
+}
+
+func TestAddProvider(t *testing.T) {
+	base := newPushProvider(map[string]string{"host": "base", "port": "80"})
+	local := newPushProvider(map[string]string{"port": "8080"})
+	cfg, _ := NewConfig()
+	done := reloads(cfg)
+	if err := cfg.AddProvider(base); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.AddProvider(local); err != nil {
+		t.Fatal(err)
+	}
+	// 后添加的配置源覆盖先添加的
+	if cfg.Get("port") != "8080" || cfg.Get("host") != "base" {
+		t.Errorf("stacked values = %v", cfg.GetAll())
+	}
+	if src, _ := cfg.Source("port"); src.Kind != SourceProvider || src.Name != "config.pushProvider" {
+		t.Errorf("Source(port) = %+v, want the provider", src)
+	}
+
+	// 上层删除键时恢复为下层的值，两层都不提供时删除
+	local.push(Update{Values: map[string]string{"debug": "true"}})
+	if err := waitReload(t, done); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Get("port") != "80" || cfg.Get("debug") != "true" {
+		t.Errorf("after the local update = %v", cfg.GetAll())
+	}
+	base.push(Update{Values: map[string]string{"host": "base"}})
+	waitReload(t, done)
+	if cfg.Has("port") {
+		t.Errorf("port = %q after both providers dropped it", cfg.Get("port"))
+	}
+
+	// 包含Err的更新不修改配置
+	base.push(Update{Err: errors.New("backend down")})
+	if err := waitReload(t, done); err == nil || err.Error() != "backend down" {
+		t.Errorf("reload error = %v, want the update error", err)
+	}
+	if cfg.Get("host") != "base" {
+		t.Errorf("host after a failed update = %q", cfg.Get("host"))
+	}
+
+	if err := cfg.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-base.done:
+	default:
+		t.Error("Close did not close the providers")
+	}
+	if cfg.Get("host") != "base" {
+		t.Error("Close removed the provided values")
+	}
+
+	failing := newPushProvider(nil)
+	failing.loadErr = errors.New("unreachable")
+	if err := cfg.AddProvider(failing); err == nil {
+		t.Error("AddProvider with a failing Load succeeded")
+	}
+	cfg.Freeze()
+	if err := cfg.AddProvider(newPushProvider(nil)); !errors.Is(err, ErrFrozen) {
+		t.Errorf("AddProvider on a frozen config error = %v, want ErrFrozen", err)
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("PROVTEST_SERVER_PORT", "9090")
+	t.Setenv("PROVTEST_", "ignored")
+	p := NewEnvProvider("provtest_")
+	values, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values["server.port"] != "9090" {
+		t.Errorf("Load() = %v, want only server.port", values)
+	}
+	cfg, _ := NewConfig()
+	if err := cfg.AddProvider(p); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("server.port"); got != "9090" {
+		t.Errorf("server.port = %q", got)
+	}
+	cfg.Close()
+	p.Close()
+}
+
+// blockingProvider 的Load阻塞到release被关闭，不支持context取消
+type blockingProvider struct {
+	release chan struct{}
+}
+
+func (p *blockingProvider) Load() (map[string]string, error) {
+	<-p.release
+	return map[string]string{"late": "1"}, nil
+}
+
+func (p *blockingProvider) Watch(chan<- Update) error { return nil }
+
+func TestContextLoads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	os.WriteFile(path, []byte("port = 80\n"), 0o644)
+	cfg, _ := NewConfig()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cfg.LoadFromFileContext(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("LoadFromFileContext with a canceled ctx error = %v, want context.Canceled", err)
+	}
+	if cfg.Len() != 0 {
+		t.Errorf("canceled load left %v", cfg.GetAll())
+	}
+	if err := cfg.LoadFromFileContext(context.Background(), path); err != nil || cfg.Get("port") != "80" {
+		t.Errorf("LoadFromFileContext = %v, port = %q", err, cfg.Get("port"))
+	}
+
+	// 未实现ContextProvider的配置源在超时时放弃等待，结果被丢弃
+	p := &blockingProvider{release: make(chan struct{})}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := cfg.LoadFromProviderContext(ctx, p); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LoadFromProviderContext error = %v, want context.DeadlineExceeded", err)
+	}
+	close(p.release)
+	time.Sleep(10 * time.Millisecond)
+	if cfg.Has("late") {
+		t.Error("the result of an abandoned Load was merged")
+	}
+}
+
+func TestWatchProviderContext(t *testing.T) {
+	p := newPushProvider(map[string]string{"port": "80"})
+	cfg, _ := NewConfig()
+	done := reloads(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := cfg.WatchProviderContext(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	p.push(Update{Values: map[string]string{"port": "8080"}})
+	waitReload(t, done)
+	if got := cfg.Get("port"); got != "8080" {
+		t.Errorf("port after the update = %q", got)
+	}
+	// ctx结束时停止监视并关闭配置源
+	cancel()
+	select {
+	case <-p.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("WatchProviderContext did not close the provider after ctx ended")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := cfg.WatchProviderContext(ctx, &blockingProvider{release: make(chan struct{})}); !errors.Is(err, context.Canceled) {
+		t.Errorf("WatchProviderContext with a canceled ctx error = %v", err)
+	}
+}
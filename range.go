@@ -0,0 +1,135 @@
+package config
+
+import (
+	"iter"
+	"slices"
+	"strings"
+)
+
+// Range 对每个生效的键值调用fn，fn返回false时停止遍历
+// 值的处理方式与GetAll一致，但遍历在读锁下直接进行，不复制整个配置。
+// 遍历顺序不确定；fn执行期间持有读锁，fn中不能调用c的任何方法，否则可能死锁。
+// 参数:
+// - fn: 遍历函数
+func (c *Config) Range(fn func(key, value string) bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	c.rangeLocked(fn)
+}
+
+// All 返回遍历所有生效键值的迭代器，与Range相同但可以用于for range语句
+// 示例:
+//
+//	for key, value := range cfg.All() {
+//		fmt.Println(key, value)
+//	}
+//
+// 循环体执行期间持有读锁，循环体中不能调用cfg的任何方法
+// 返回:
+// - iter.Seq2[string, string]: 键值迭代器
+func (c *Config) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		c.Range(yield)
+	}
+}
+
+// Keys 返回所有生效的键，按字典序排序
+// 键的集合与GetAll相同，但不复制值，适用于确定性地枚举配置
+// 返回:
+// - []string: 排序后的键
+func (c *Config) Keys() []string {
+	return c.KeysWithPrefix("")
+}
+
+// KeysWithPrefix 返回以prefix开头的生效键，按字典序排序，键保持完整键名
+// 参数:
+// - prefix: 键前缀，如"server."
+// 返回:
+// - []string: 排序后的键；没有匹配的键时返回空切片
+func (c *Config) KeysWithPrefix(prefix string) []string {
+	prefix = c.normKey(prefix)
+	keys := []string{}
+	c.effectiveKeys(func(key string) {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	})
+	slices.Sort(keys)
+	return keys
+}
+
+// Len 返回生效的键的数量，与len(GetAll())相同但不复制配置
+func (c *Config) Len() int {
+	n := 0
+	c.effectiveKeys(func(string) { n++ })
+	return n
+}
+
+// effectiveKeys 对每个生效的键调用fn，只读视图可用时无锁遍历
+func (c *Config) effectiveKeys(fn func(key string)) {
+	if v := c.loadView(); v.cached {
+		for k := range v.values {
+			fn(k)
+		}
+		return
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for k := range c.keysLocked() {
+		fn(k)
+	}
+}
+
+// rangeLocked 遍历各层中存储的键，每个生效的键只交给fn一次；调用方须持有锁
+func (c *Config) rangeLocked(fn func(key, value string) bool) bool {
+	for l := 0; l < numLayers; l++ {
+		layer := Layer(l)
+		for k := range c.layerMap(layer) {
+			if !c.emitLocked(layer, k, k, fn) {
+				return false
+			}
+			if c.profile != "" {
+				if rest, ok := strings.CutPrefix(k, c.profile+c.sep()); ok && rest != "" {
+					if !c.emitLocked(layer, rest, k, fn) {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// emitLocked 在layer中存储的stored是key的最高优先级存储位置时，把key的生效值交给fn
+func (c *Config) emitLocked(layer Layer, key, stored string, fn func(key, value string) bool) bool {
+	if !c.ownsLocked(layer, key, stored) {
+		return true
+	}
+	val, ok := c.resolveLocked(key)
+	if !ok {
+		return true
+	}
+	return fn(key, c.coerceLocked(key, c.expandValueLocked(key, c.decodeLocked(val))))
+}
+
+// ownsLocked 判断layer中的stored是否是key在各层中优先级最高的存储位置
+// 更高的层存有key(或带profile前缀的key)时不是；同层中带profile前缀的键优先于不带前缀的键
+func (c *Config) ownsLocked(layer Layer, key, stored string) bool {
+	for l := int(layer) + 1; l < numLayers; l++ {
+		m := c.layerMap(Layer(l))
+		if _, ok := m[key]; ok {
+			return false
+		}
+		if c.profile != "" {
+			if _, ok := m[c.profileKey(key)]; ok {
+				return false
+			}
+		}
+	}
+	if c.profile != "" && stored == key {
+		if _, ok := c.layerMap(layer)[c.profileKey(key)]; ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.SetDefault("port", "80")
+	cfg.SetDefault("timeout", "30s")
+	cfg.Set("port", "8080")
+	cfg.Set("server.host", "localhost")
+	cfg.Set("server.url", "http://${server.host}:${port}")
+	cfg.SetOverride("debug", "true")
+	cfg.SetInterpolation(true)
+
+	got := make(map[string]string)
+	cfg.Range(func(key, value string) bool {
+		if _, dup := got[key]; dup {
+			t.Errorf("Range visited %s twice", key)
+		}
+		got[key] = value
+		return true
+	})
+	if want := cfg.GetAll(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Range = %v, want GetAll %v", got, want)
+	}
+	if got["server.url"] != "http://localhost:8080" {
+		t.Errorf("Range value of server.url = %q, want it expanded", got["server.url"])
+	}
+
+	n := 0
+	cfg.Range(func(string, string) bool { n++; return n < 2 })
+	if n != 2 {
+		t.Errorf("Range called fn %d times after it returned false, want 2", n)
+	}
+	if all := maps.Collect(cfg.All()); !reflect.DeepEqual(all, got) {
+		t.Errorf("All = %v, want %v", all, got)
+	}
+	for range cfg.All() {
+		break
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.SetDefault("timeout", "30s")
+	cfg.SetDefault("port", "80")
+	cfg.Set("port", "8080")
+	cfg.Set("server.url", "http://localhost")
+	cfg.Set("server.host", "localhost")
+	cfg.SetOverride("debug", "true")
+	cfg.Set("serverless", "no")
+
+	// 同一个键在多层中只列出一次，按字典序排序
+	want := []string{"debug", "port", "server.host", "server.url", "serverless", "timeout"}
+	if keys := cfg.Keys(); !reflect.DeepEqual(keys, want) {
+		t.Errorf("Keys() = %v, want %v", keys, want)
+	}
+	if cfg.Len() != len(want) || cfg.Len() != len(cfg.GetAll()) {
+		t.Errorf("Len() = %d, want %d", cfg.Len(), len(want))
+	}
+	if keys := cfg.KeysWithPrefix("server."); !reflect.DeepEqual(keys, []string{"server.host", "server.url"}) {
+		t.Errorf("KeysWithPrefix(server.) = %v", keys)
+	}
+	if keys := cfg.KeysWithPrefix("none."); keys == nil || len(keys) != 0 {
+		t.Errorf("KeysWithPrefix(none.) = %#v, want an empty slice", keys)
+	}
+
+	// 删除后不再列出；环境变量只影响已有键的值，不增加键
+	cfg.Delete("serverless")
+	t.Setenv("KEYSTEST_EXTRA", "1")
+	cfg.BindEnv("KEYSTEST")
+	if keys := cfg.Keys(); len(keys) != len(want)-1 || slices.Contains(keys, "serverless") {
+		t.Errorf("Keys() after Delete = %v", keys)
+	}
+	if cfg.Len() != len(want)-1 {
+		t.Errorf("Len() with BindEnv = %d, want %d", cfg.Len(), len(want)-1)
+	}
+}
@@ -0,0 +1,466 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedisProvider 从Redis读取配置，通过RESP协议直接访问，不依赖第三方客户端
+// Hash非空时读取该hash的全部字段，字段名即配置键；否则读取所有以Prefix开头的字符串键，
+// 去掉前缀后其中的":"替换为Delimiter，例如前缀"myapp:"下的"myapp:server:port"对应键"server.port"。
+// Watch订阅键空间通知(服务端需开启notify-keyspace-events，如"K$h")，
+// 以及可选的发布/订阅频道Channel，收到任何消息后重新读取全部数据。
+type RedisProvider struct {
+	// Addr 是Redis地址，如"127.0.0.1:6379"
+	Addr string
+	// Username和Password 非空时在连接后发送AUTH，只设置Password时使用旧式AUTH
+	Username string
+	Password string
+	// DB 是要SELECT的数据库编号
+	DB int
+	// Prefix 是要读取的键前缀
+	Prefix string
+	// Hash 非空时读取该hash而不是按前缀扫描
+	Hash string
+	// Delimiter 替换键名中的":"，为空时保留":"
+	Delimiter string
+	// Channel 非空时同时订阅该频道，发布任何消息都会触发重新读取
+	Channel string
+	// TLSConfig 非nil时使用TLS连接
+	TLSConfig *tls.Config
+	// DialTimeout 是建立连接与每次请求的超时时间
+	DialTimeout time.Duration
+	// RetryInterval 是Watch连接断开后的重试间隔
+	RetryInterval time.Duration
+
+	mutex  sync.Mutex
+	sub    net.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewRedisProvider 创建读取prefix开头的键的Redis配置源
+// 需要读取hash时在返回值上设置Hash字段
+// 参数:
+// - addr: Redis地址
+// - prefix: 键前缀
+// 返回:
+// - *RedisProvider: 配置源实例
+func NewRedisProvider(addr, prefix string) *RedisProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RedisProvider{
+		Addr:          addr,
+		Prefix:        prefix,
+		Delimiter:     ".",
+		DialTimeout:   5 * time.Second,
+		RetryInterval: time.Second,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// LoadFromRedis 从Redis加载prefix开头的所有键并合并到文件层
+// 需要持续同步时可使用AddProvider(NewRedisProvider(addr, prefix))
+// 参数:
+// - addr: Redis地址
+// - prefix: 键前缀
+// 返回:
+// - error: 连接、请求或解析错误(如果有)
+func (c *Config) LoadFromRedis(addr, prefix string) error {
+	p := NewRedisProvider(addr, prefix)
+	defer p.Close()
+	return c.LoadFromProvider(p)
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *RedisProvider) Close() error {
+	p.cancel()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.sub != nil {
+		p.sub.Close()
+	}
+	return nil
+}
+
+// Load 读取hash的全部字段或前缀下的全部字符串键
+func (p *RedisProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时关闭连接以中止请求
+func (p *RedisProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.c.Close() })
+	defer stop()
+	values, err := p.read(conn)
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("redis: %w", ctx.Err())
+	}
+	return values, err
+}
+
+// read 在已建立的连接上读取全部数据
+func (p *RedisProvider) read(conn *redisConn) (map[string]string, error) {
+	values := make(map[string]string)
+	if p.Hash != "" {
+		reply, err := conn.do("HGETALL", p.Hash)
+		if err != nil {
+			return nil, err
+		}
+		fields, _ := reply.([]interface{})
+		for i := 0; i+1 < len(fields); i += 2 {
+			field, _ := fields[i].(string)
+			value, _ := fields[i+1].(string)
+			values[field] = value
+		}
+		return values, nil
+	}
+
+	keys, err := conn.scan(redisPattern(p.Prefix))
+	if err != nil {
+		return nil, err
+	}
+	// 分批MGET，避免单个请求过大；不是字符串类型或已被删除的键返回nil，直接跳过
+	for start := 0; start < len(keys); start += 500 {
+		batch := keys[start:min(start+500, len(keys))]
+		args := append([]string{"MGET"}, batch...)
+		reply, err := conn.do(args...)
+		if err != nil {
+			return nil, err
+		}
+		items, _ := reply.([]interface{})
+		for i, item := range items {
+			if value, ok := item.(string); ok && i < len(batch) {
+				if key := p.configKey(batch[i]); key != "" {
+					values[key] = value
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+// Watch 订阅键空间通知与Channel，数据变化时发送完整数据
+// 连接断开时按RetryInterval重连，重连后重新读取以弥补断开期间错过的变化
+func (p *RedisProvider) Watch(ch chan<- Update) error {
+	current, err := p.Load()
+	if err != nil {
+		return err
+	}
+	for {
+		err := p.watchOnce(&current, ch)
+		if p.ctx.Err() != nil {
+			return ErrProviderClosed
+		}
+		if err != nil {
+			select {
+			case ch <- Update{Err: err}:
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+		}
+		select {
+		case <-time.After(p.RetryInterval):
+		case <-p.ctx.Done():
+			return ErrProviderClosed
+		}
+	}
+}
+
+// watchOnce 建立一次订阅连接并处理消息，current在数据变化时被更新
+func (p *RedisProvider) watchOnce(current *map[string]string, ch chan<- Update) error {
+	conn, err := p.dial(p.ctx)
+	if err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	if p.ctx.Err() != nil {
+		p.mutex.Unlock()
+		conn.Close()
+		return nil
+	}
+	p.sub = conn.c
+	p.mutex.Unlock()
+	defer func() {
+		p.mutex.Lock()
+		p.sub = nil
+		p.mutex.Unlock()
+		conn.Close()
+	}()
+
+	target := "__keyspace@" + strconv.Itoa(p.DB) + "__:"
+	if p.Hash != "" {
+		err = conn.send("SUBSCRIBE", target+p.Hash)
+	} else {
+		err = conn.send("PSUBSCRIBE", target+redisPattern(p.Prefix))
+	}
+	if err == nil && p.Channel != "" {
+		err = conn.send("SUBSCRIBE", p.Channel)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 订阅建立期间可能已有变化，先对比一次
+	changed := true
+	for {
+		if changed {
+			next, err := p.Load()
+			if err != nil {
+				return err
+			}
+			if !maps.Equal(next, *current) {
+				*current = next
+				select {
+				case ch <- Update{Values: maps.Clone(next)}:
+				case <-p.ctx.Done():
+					return nil
+				}
+			}
+		}
+
+		// 订阅连接上只会收到推送消息，读取不设超时
+		conn.c.SetReadDeadline(time.Time{})
+		reply, err := conn.read()
+		if err != nil {
+			return fmt.Errorf("redis: subscription: %w", err)
+		}
+		msg, _ := reply.([]interface{})
+		kind := ""
+		if len(msg) > 0 {
+			kind, _ = msg[0].(string)
+		}
+		changed = kind == "message" || kind == "pmessage"
+	}
+}
+
+// Put 把配置键写入Redis：设置了Hash时写入hash字段，否则按Load相反的规则写入前缀下的字符串键
+func (p *RedisProvider) Put(key, value string) error {
+	if p.Hash != "" {
+		return p.exec("HSET", p.Hash, key, value)
+	}
+	return p.exec("SET", p.redisKey(key), value)
+}
+
+// Delete 从Redis删除配置键对应的hash字段或字符串键
+func (p *RedisProvider) Delete(key string) error {
+	if p.Hash != "" {
+		return p.exec("HDEL", p.Hash, key)
+	}
+	return p.exec("DEL", p.redisKey(key))
+}
+
+// exec 建立一次连接执行命令
+func (p *RedisProvider) exec(args ...string) error {
+	conn, err := p.dial(p.ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.do(args...)
+	return err
+}
+
+// redisKey 是configKey的逆映射
+func (p *RedisProvider) redisKey(key string) string {
+	return remotePath(key, p.Prefix, p.Delimiter, ":")
+}
+
+// configKey 把Redis键映射为配置键
+func (p *RedisProvider) configKey(raw string) string {
+	key := strings.Trim(strings.TrimPrefix(raw, p.Prefix), ":")
+	if p.Delimiter != "" && p.Delimiter != ":" {
+		key = strings.ReplaceAll(key, ":", p.Delimiter)
+	}
+	return key
+}
+
+// dial 建立连接并完成认证与选库
+func (p *RedisProvider) dial(ctx context.Context) (*redisConn, error) {
+	if p.Addr == "" {
+		return nil, errors.New("redis: no address configured")
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.DialTimeout)
+	defer cancel()
+	var (
+		c   net.Conn
+		err error
+	)
+	if p.TLSConfig != nil {
+		d := &tls.Dialer{Config: p.TLSConfig}
+		c, err = d.DialContext(ctx, "tcp", p.Addr)
+	} else {
+		var d net.Dialer
+		c, err = d.DialContext(ctx, "tcp", p.Addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: %s: %w", p.Addr, err)
+	}
+
+	conn := &redisConn{c: c, r: bufio.NewReader(c), timeout: p.DialTimeout}
+	if p.Password != "" {
+		args := []string{"AUTH", p.Password}
+		if p.Username != "" {
+			args = []string{"AUTH", p.Username, p.Password}
+		}
+		if _, err := conn.do(args...); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("redis: auth: %w", err)
+		}
+	}
+	if p.DB != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(p.DB)); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("redis: select %d: %w", p.DB, err)
+		}
+	}
+	return conn, nil
+}
+
+// redisConn 是一条RESP协议连接
+type redisConn struct {
+	c       net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+}
+
+// redisError 是服务端返回的错误回复
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func (conn *redisConn) Close() error { return conn.c.Close() }
+
+// do 发送命令并读取回复，服务端的错误回复作为error返回
+func (conn *redisConn) do(args ...string) (interface{}, error) {
+	if err := conn.send(args...); err != nil {
+		return nil, err
+	}
+	return conn.read()
+}
+
+// send 以RESP数组格式写出命令
+func (conn *redisConn) send(args ...string) error {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	if conn.timeout > 0 {
+		conn.c.SetDeadline(time.Now().Add(conn.timeout))
+	}
+	_, err := io.WriteString(conn.c, b.String())
+	return err
+}
+
+// read 读取一个回复：简单字符串与批量字符串为string，整数为int64，数组为[]interface{}，空值为nil
+func (conn *redisConn) read() (interface{}, error) {
+	line, err := conn.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(conn.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := conn.read()
+			var rerr redisError
+			if err != nil && !errors.As(err, &rerr) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+// scan 通过SCAN遍历匹配pattern的全部键
+func (conn *redisConn) scan(pattern string) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := conn.do("SCAN", cursor, "MATCH", pattern, "COUNT", "500")
+		if err != nil {
+			return nil, err
+		}
+		parts, _ := reply.([]interface{})
+		if len(parts) != 2 {
+			return nil, errors.New("redis: malformed SCAN reply")
+		}
+		cursor, _ = parts[0].(string)
+		batch, _ := parts[1].([]interface{})
+		for _, item := range batch {
+			if key, ok := item.(string); ok {
+				keys = append(keys, key)
+			}
+		}
+		if cursor == "0" || cursor == "" {
+			return keys, nil
+		}
+	}
+}
+
+// redisPattern 返回匹配prefix开头的所有键的glob模式
+func redisPattern(prefix string) string {
+	return redisEscape(prefix) + "*"
+}
+
+// redisEscape 转义glob模式中的特殊字符
+func redisEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`*?[]\`, s[i]) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
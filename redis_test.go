@@ -0,0 +1,354 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis 在net.Listen上以RESP协议实现RedisProvider用到的命令
+// SCAN每页最多返回两个键，以覆盖游标翻页；SUBSCRIBE与PSUBSCRIBE之后的连接由notify推送消息
+type fakeRedis struct {
+	addr     string
+	mutex    sync.Mutex
+	user     string // 非空时AUTH须同时给出用户名
+	password string // 非空时要求AUTH
+	strings  map[string]string
+	hashes   map[string]map[string]string
+	selected []string    // 每个连接SELECT的库编号
+	subs     []net.Conn  // 订阅连接
+	patterns chan string // 每个订阅请求的频道或模式
+}
+
+func newFakeRedis(t *testing.T, user, password string) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeRedis{
+		addr:     ln.Addr().String(),
+		user:     user,
+		password: password,
+		strings:  map[string]string{},
+		hashes:   map[string]map[string]string{},
+		patterns: make(chan string, 8),
+	}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	conn := &redisConn{c: c, r: bufio.NewReader(c)}
+	authed := f.password == ""
+	for {
+		reply, err := conn.read()
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		if len(args) == 0 {
+			return
+		}
+		cmd := strings.ToUpper(args[0])
+		if !authed && cmd != "AUTH" {
+			fmt.Fprint(c, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		f.mutex.Lock()
+		out := f.execLocked(c, cmd, args[1:], &authed)
+		f.mutex.Unlock()
+		if _, err := c.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+// execLocked 执行一条命令并返回RESP编码的回复
+func (f *fakeRedis) execLocked(c net.Conn, cmd string, args []string, authed *bool) string {
+	switch cmd {
+	case "AUTH":
+		user, pass := "", args[len(args)-1]
+		if len(args) == 2 {
+			user = args[0]
+		}
+		if user != f.user || pass != f.password {
+			return "-WRONGPASS invalid username-password pair\r\n"
+		}
+		*authed = true
+		return "+OK\r\n"
+	case "SELECT":
+		f.selected = append(f.selected, args[0])
+		return "+OK\r\n"
+	case "SCAN":
+		prefix := strings.TrimSuffix(strings.ReplaceAll(args[2], `\`, ""), "*")
+		var keys []string
+		for k := range f.strings {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		for k := range f.hashes {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		start, _ := strconv.Atoi(args[0])
+		end := min(start+2, len(keys))
+		next := strconv.Itoa(end)
+		if end == len(keys) {
+			next = "0"
+		}
+		return "*2\r\n" + respBulk(next) + respArray(keys[start:end])
+	case "MGET":
+		out := "*" + strconv.Itoa(len(args)) + "\r\n"
+		for _, k := range args {
+			if v, ok := f.strings[k]; ok {
+				out += respBulk(v)
+			} else {
+				out += "$-1\r\n"
+			}
+		}
+		return out
+	case "HGETALL":
+		var fields []string
+		for k, v := range f.hashes[args[0]] {
+			fields = append(fields, k, v)
+		}
+		return respArray(fields)
+	case "SET":
+		f.strings[args[0]] = args[1]
+		return "+OK\r\n"
+	case "DEL":
+		delete(f.strings, args[0])
+		return ":1\r\n"
+	case "HSET":
+		if f.hashes[args[0]] == nil {
+			f.hashes[args[0]] = map[string]string{}
+		}
+		f.hashes[args[0]][args[1]] = args[2]
+		return ":1\r\n"
+	case "HDEL":
+		delete(f.hashes[args[0]], args[1])
+		return ":1\r\n"
+	case "SUBSCRIBE", "PSUBSCRIBE":
+		f.subs = append(f.subs, c)
+		f.patterns <- args[0]
+		return "*3\r\n" + respBulk(strings.ToLower(cmd)) + respBulk(args[0]) + ":1\r\n"
+	}
+	return "-ERR unknown command '" + cmd + "'\r\n"
+}
+
+// set 修改字符串键，value为空时删除
+func (f *fakeRedis) set(key, value string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if value == "" {
+		delete(f.strings, key)
+		return
+	}
+	f.strings[key] = value
+}
+
+// notify 向所有订阅连接推送一条消息
+func (f *fakeRedis) notify(kind string, parts ...string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	msg := respArray(append([]string{kind}, parts...))
+	for _, c := range f.subs {
+		c.Write([]byte(msg))
+	}
+}
+
+func respBulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func respArray(items []string) string {
+	out := "*" + strconv.Itoa(len(items)) + "\r\n"
+	for _, item := range items {
+		out += respBulk(item)
+	}
+	return out
+}
+
+func TestRedisLoad(t *testing.T) {
+	f := newFakeRedis(t, "", "")
+	f.mutex.Lock()
+	f.strings = map[string]string{
+		"myapp:name":        "app",
+		"myapp:server:port": "8080",
+		"myapp:server:host": "0.0.0.0",
+		"myapp:db:url":      "postgres://x",
+		"other:key":         "ignored",
+	}
+	// 前缀下的哈希键在MGET中返回nil，应被跳过
+	f.hashes = map[string]map[string]string{
+		"myapp:list": {"not": "a string"},
+		"settings":   {"log.level": "debug", "port": "80"},
+	}
+	settings := maps.Clone(f.hashes["settings"])
+	f.mutex.Unlock()
+
+	p := NewRedisProvider(f.addr, "myapp:")
+	defer p.Close()
+	got, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"name": "app", "server.port": "8080", "server.host": "0.0.0.0", "db.url": "postgres://x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load prefix = %v, want %v", got, want)
+	}
+
+	p.Hash = "settings"
+	if got, err = p.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, settings) {
+		t.Errorf("Load hash = %v, want %v", got, settings)
+	}
+}
+
+func TestRedisAuthAndSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		wantErr  string
+	}{
+		{"acl user", "cfg", "s3cret", ""},
+		{"wrong password", "cfg", "nope", "WRONGPASS"},
+		{"no credentials", "", "", "NOAUTH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRedis(t, "cfg", "s3cret")
+			f.set("a:x", "1")
+			p := NewRedisProvider(f.addr, "a:")
+			defer p.Close()
+			p.Username, p.Password, p.DB = tt.user, tt.password, 3
+			got, err := p.Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got["x"] != "1" {
+				t.Errorf("Load = %v", got)
+			}
+			f.mutex.Lock()
+			defer f.mutex.Unlock()
+			if !reflect.DeepEqual(f.selected, []string{"3"}) {
+				t.Errorf("SELECT calls = %v, want [3]", f.selected)
+			}
+		})
+	}
+}
+
+func TestRedisPutDelete(t *testing.T) {
+	f := newFakeRedis(t, "", "")
+	p := NewRedisProvider(f.addr, "myapp:")
+	defer p.Close()
+	if err := p.Put("server.port", "9090"); err != nil {
+		t.Fatal(err)
+	}
+	f.mutex.Lock()
+	if f.strings["myapp:server:port"] != "9090" {
+		t.Errorf("Put stored %v", f.strings)
+	}
+	f.mutex.Unlock()
+	if err := p.Delete("server.port"); err != nil {
+		t.Fatal(err)
+	}
+	f.mutex.Lock()
+	if len(f.strings) != 0 {
+		t.Errorf("Delete left %v", f.strings)
+	}
+	f.mutex.Unlock()
+
+	p.Hash = "settings"
+	if err := p.Put("log.level", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.hashes["settings"]["log.level"] != "warn" {
+		t.Errorf("Put to hash stored %v", f.hashes)
+	}
+}
+
+func TestRedisWatch(t *testing.T) {
+	f := newFakeRedis(t, "", "")
+	f.set("myapp:port", "80")
+	p := NewRedisProvider(f.addr, "myapp:")
+	p.Channel = "config-updates"
+	p.RetryInterval = 10 * time.Millisecond
+	ch := make(chan Update, 4)
+	done := make(chan error, 1)
+	go func() { done <- p.Watch(ch) }()
+	defer func() {
+		p.Close()
+		<-done
+	}()
+
+	for _, want := range []string{`__keyspace@0__:myapp:*`, "config-updates"} {
+		select {
+		case got := <-f.patterns:
+			if got != want {
+				t.Errorf("subscribed to %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for subscription")
+		}
+	}
+
+	for i, change := range []struct {
+		kind  string
+		parts []string
+	}{
+		{"pmessage", []string{"__keyspace@0__:myapp:*", "__keyspace@0__:myapp:port", "set"}},
+		{"message", []string{"config-updates", "reload"}},
+	} {
+		value := strconv.Itoa(81 + i)
+		f.set("myapp:port", value)
+		f.notify(change.kind, change.parts...)
+		select {
+		case u := <-ch:
+			if u.Err != nil {
+				t.Fatal(u.Err)
+			}
+			if u.Values["port"] != value {
+				t.Errorf("after %s: update = %v, want port=%s", change.kind, u.Values, value)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for update after %s", change.kind)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keyDef 是DefineKey登记的键定义
+type keyDef struct {
+	rule       Rule
+	re         *regexp.Regexp
+	def        string
+	hasDefault bool
+	desc       string // Description设置的说明
+}
+
+// KeyOption 用于定制DefineKey登记的键
+type KeyOption func(*keyDef) error
+
+// Default 设置键的默认值，写入默认值层，值的格式化方式与Marshal一致
+func Default(v interface{}) KeyOption {
+	return func(d *keyDef) error {
+		s, err := formatFieldValue(reflect.ValueOf(v), 0)
+		if err != nil {
+			return err
+		}
+		d.def, d.hasDefault = s, true
+		return nil
+	}
+}
+
+// Required 要求键必须在默认值层以外的某一层中存在
+func Required() KeyOption {
+	return func(d *keyDef) error {
+		d.rule.Required = true
+		return nil
+	}
+}
+
+// Between 限制数值的取值范围(包含边界)
+func Between(min, max float64) KeyOption {
+	return func(d *keyDef) error {
+		d.rule.Min, d.rule.Max = Limit(min), Limit(max)
+		return nil
+	}
+}
+
+// OneOf 限制键只能取给定的值之一
+func OneOf(values ...string) KeyOption {
+	return func(d *keyDef) error {
+		d.rule.Enum = append(d.rule.Enum, values...)
+		return nil
+	}
+}
+
+// Matches 要求值完整匹配正则表达式
+func Matches(pattern string) KeyOption {
+	return func(d *keyDef) error {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		d.rule.Pattern, d.re = pattern, re
+		return nil
+	}
+}
+
+// Check 添加自定义校验函数
+func Check(fn Validator) KeyOption {
+	return func(d *keyDef) error {
+		d.rule.Validators = append(d.rule.Validators, fn)
+		return nil
+	}
+}
+
+// DefineKey 登记键的类型与约束
+// 登记后Get等读取方法返回规范化后的值(如布尔值"yes"读作"true"，时长"90s"读作"1m30s")，
+// 值不符合类型时原样返回；LoadFromFile加载的文件违反定义时整个文件被拒绝，
+// 返回带文件名和行号的*ValidationError。重复登记同一个键会替换之前的定义。
+// 参数:
+// - key: 配置键
+// - typ: 值的类型
+// - opts: 默认值、必填等约束
+// 返回:
+// - error: 键为空、约束无效或默认值不符合定义时返回错误，配置已冻结时返回ErrFrozen
+func (c *Config) DefineKey(key string, typ ValueType, opts ...KeyOption) error {
+	if key == "" {
+		return errors.New("key cannot be empty")
+	}
+	key = c.normKey(key)
+	d := &keyDef{rule: Rule{Type: typ}}
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	if d.hasDefault {
+		if msgs := d.rule.check(d.def, d.re); len(msgs) > 0 {
+			return fmt.Errorf("key %q: default: %s", key, msgs[0])
+		}
+	}
+
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	if c.schema == nil {
+		c.schema = make(map[string]*keyDef)
+	}
+	c.schema[key] = d
+	var changes []change
+	if d.hasDefault {
+		changes = c.writeLayerLocked(LayerDefault, key, d.def, false, nil)
+	}
+	c.unlockNotify(changes)
+	return nil
+}
+
+// coerceLocked 按登记的类型规范化值，未登记或无法解析时原样返回
+func (c *Config) coerceLocked(key, value string) string {
+	d, ok := c.schema[key]
+	if !ok {
+		return value
+	}
+	s := strings.TrimSpace(value)
+	switch d.rule.Type {
+	case Int:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case Float:
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case Bool:
+		if b, err := parseBool(s); err == nil {
+			return strconv.FormatBool(b)
+		}
+	case Duration:
+		if dur, err := time.ParseDuration(s); err == nil {
+			return dur.String()
+		}
+	}
+	return value
+}
+
+// checkDocumentLocked 按登记的定义检查即将加载的文档
+// pos给出每个键所在的文件和行号；必填键在文档中缺失且配置中也不存在时同样报告，
+// replace为true表示文档将整体替换文件层(Watch的重新加载)，此时文件层中现有的键不算存在
+func (c *Config) checkDocumentLocked(filename string, values map[string]string, pos map[string]linePos, replace bool) error {
+	if len(c.schema) == 0 {
+		return nil
+	}
+	var violations []Violation
+	keys := make([]string, 0, len(c.schema))
+	for key := range c.schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		d := c.schema[key]
+		value, ok := values[key]
+		if !ok {
+			if d.rule.Required && !c.setOutsideDefaultsLocked(key, replace) {
+				violations = append(violations, Violation{Key: key, Message: "required key is missing", File: filename})
+			}
+			continue
+		}
+		for _, msg := range d.rule.check(strings.TrimSpace(c.decodeLocked(value)), d.re) {
+			p := pos[key]
+			if p.file == "" {
+				p.file = filename
+			}
+			violations = append(violations, Violation{Key: key, Message: msg, File: p.file, Line: p.line})
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// setOutsideDefaultsLocked 判断键是否在默认值层以外的某一层中存在，skipFile为true时不检查文件层
+func (c *Config) setOutsideDefaultsLocked(key string, skipFile bool) bool {
+	for l := LayerFile; l.valid(); l++ {
+		if l == LayerFile && skipFile {
+			continue
+		}
+		if _, ok := c.layerMap(l)[key]; ok {
+			return true
+		}
+	}
+	if c.env != nil {
+		if _, ok := c.env.lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// schemaFormats 是每种格式中port为valid或"abc"的文件内容
+var schemaFormats = []struct {
+	ext   string
+	valid string
+	bad   string
+}{
+	{".ini", "port = 8080\n", "port = abc\n"},
+	{".json", `{"port": 8080}`, `{"port": "abc"}`},
+	{".yaml", "port: 8080\n", "port: abc\n"},
+	{".toml", "port = 8080\n", "port = \"abc\"\n"},
+	{".properties", "port=8080\n", "port=abc\n"},
+	{".env", "port=8080\n", "port=abc\n"},
+	{".xml", "<config><port>8080</port></config>", "<config><port>abc</port></config>"},
+	{".hcl", "port = 8080\n", "port = \"abc\"\n"},
+}
+
+func TestSchemaRejectsEveryFormat(t *testing.T) {
+	for _, tt := range schemaFormats {
+		t.Run(tt.ext, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg, _ := NewConfig()
+			if err := cfg.DefineKey("port", Int); err != nil {
+				t.Fatal(err)
+			}
+			good := filepath.Join(dir, "good"+tt.ext)
+			os.WriteFile(good, []byte(tt.valid), 0o644)
+			if err := cfg.LoadFromFile(good); err != nil {
+				t.Fatalf("valid file: %v", err)
+			}
+			bad := filepath.Join(dir, "bad"+tt.ext)
+			os.WriteFile(bad, []byte(tt.bad), 0o644)
+			err := cfg.LoadFromFile(bad)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("invalid file: got %v, want *ValidationError", err)
+			}
+			if got := verr.Violations[0].File; got != bad {
+				t.Errorf("violation file = %q, want %q", got, bad)
+			}
+			if got := cfg.Get("port"); got != "8080" {
+				t.Errorf("port = %q after rejected load, want 8080", got)
+			}
+		})
+	}
+}
+
+func TestSchemaLoadFromReader(t *testing.T) {
+	tests := []struct {
+		format  string
+		content string
+	}{
+		{"ini", "port = abc\n"},
+		{"json", `{"port": "abc"}`},
+		{"yaml", "port: abc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cfg, _ := NewConfig()
+			cfg.DefineKey("port", Int)
+			err := cfg.LoadFromReader(strings.NewReader(tt.content), WithFileFormat(tt.format))
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("got %v, want *ValidationError", err)
+			}
+			if _, ok := cfg.Lookup("port"); ok {
+				t.Error("rejected content was merged")
+			}
+		})
+	}
+}
+
+func TestSchemaRequiredAcrossFormats(t *testing.T) {
+	dir := t.TempDir()
+	cfg, _ := NewConfig()
+	cfg.DefineKey("name", String, Required())
+	filename := filepath.Join(dir, "app.json")
+	os.WriteFile(filename, []byte(`{"port": 1}`), 0o644)
+	var verr *ValidationError
+	if err := cfg.LoadFromFile(filename); !errors.As(err, &verr) {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+	os.WriteFile(filename, []byte(`{"name": "app"}`), 0o644)
+	if err := cfg.LoadFromFile(filename); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// 加密文件的格式：magic | kdf | [iterations(uint32) | salt] | nonce | 密文
+// magic到salt为文件头，作为AES-GCM的附加数据，篡改文件头同样导致解密失败
+const (
+	sealMagic          = "GCFGENC\x01"
+	sealKDFNone   byte = 0 // 直接使用调用方提供的密钥
+	sealKDFPBKDF2 byte = 1 // PBKDF2-HMAC-SHA256
+
+	sealSaltSize = 16
+	// sealIterations 是由口令派生密钥时PBKDF2的迭代次数
+	sealIterations = 600000
+	// sealMaxIterations 是加载时接受的最大迭代次数，防止被篡改的文件头耗尽CPU
+	sealMaxIterations = 10000000
+)
+
+// FileKey 是SaveEncrypted与LoadEncrypted使用的密钥，由AESKey或Passphrase创建
+type FileKey struct {
+	key        []byte
+	passphrase []byte
+}
+
+// AESKey 直接使用key作为AES-GCM密钥
+// 参数:
+// - key: 16、24或32字节的AES密钥
+func AESKey(key []byte) FileKey {
+	return FileKey{key: key}
+}
+
+// Passphrase 由口令派生AES-256密钥，每次保存使用新的随机盐
+// 派生函数为标准库crypto/pbkdf2的PBKDF2-HMAC-SHA256(60万次迭代)：argon2id不在标准库中，本包不引入第三方依赖。
+// 盐与迭代次数记录在文件头中，加载时无需另外提供
+// 参数:
+// - passphrase: 口令，不能为空
+func Passphrase(passphrase string) FileKey {
+	return FileKey{passphrase: []byte(passphrase)}
+}
+
+// SaveEncrypted 将文件层配置以key=value格式加密后保存到文件
+// 明文内容与SaveToFile写出的相同，以AES-GCM加密，写入方式(原子替换、权限、备份、签名、锁文件)也与SaveToFile相同。
+// 生成的文件只能由LoadEncrypted以同一密钥或口令读取
+// 参数:
+// - filename: 目标文件路径
+// - key: AESKey或Passphrase
+// - opts: 文件权限、键排序等可选设置
+// 返回:
+// - error: 密钥不合法、加密或文件操作错误(如果有)
+func (c *Config) SaveEncrypted(filename string, key FileKey, opts ...SaveOption) error {
+	release, err := lockConfigFile(context.Background(), filename, true, false)
+	if err != nil {
+		return err
+	}
+	defer release()
+	var plain bytes.Buffer
+	if _, err := c.writeKeyValue(&plain, newSaveSettings(opts)); err != nil {
+		return err
+	}
+	sealed, err := sealFile(plain.Bytes(), key)
+	if err != nil {
+		return err
+	}
+	return writeBytesAtomic(filename, sealed, opts...)
+}
+
+// LoadEncrypted 解密SaveEncrypted生成的文件并合并到文件层
+// 解密后的内容按key=value格式解析(可用WithFileFormat指定其它格式)，并记录文件结构以便SaveEncrypted保留注释与键顺序；
+// include指令不被处理，profile文件不被加载；写回时应使用SaveEncrypted，SaveToFile会写出明文。密钥错误或文件被篡改时解密失败，配置保持不变
+// 参数:
+// - filename: 配置文件路径
+// - key: 保存时使用的AESKey或Passphrase
+// - opts: 严格模式、格式等解析设置
+// 返回:
+// - error: 文件不是加密配置、解密失败、文件操作或解析错误(如果有)
+func (c *Config) LoadEncrypted(filename string, key FileKey, opts ...LoadOption) error {
+	release, err := lockConfigFile(context.Background(), filename, false, false)
+	if err != nil {
+		return err
+	}
+	content, err := readConfigFile(filename)
+	release()
+	if err != nil {
+		return err
+	}
+	plain, err := openFile(content, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	settings := newLoadSettings(opts)
+	if settings.format == "" {
+		settings.format = "ini"
+	}
+	settings.noInclude = true
+	return c.loadContent(filename, plain, true, settings)
+}
+
+// sealFile 以key加密plain，返回完整的加密文件内容
+func sealFile(plain []byte, key FileKey) ([]byte, error) {
+	header := []byte(sealMagic)
+	aesKey := key.key
+	if key.passphrase != nil {
+		if len(key.passphrase) == 0 {
+			return nil, errors.New("passphrase cannot be empty")
+		}
+		salt := make([]byte, sealSaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, err
+		}
+		header = append(header, sealKDFPBKDF2)
+		header = binary.BigEndian.AppendUint32(header, sealIterations)
+		header = append(header, salt...)
+		var err error
+		if aesKey, err = passphraseKey(key.passphrase, salt, sealIterations); err != nil {
+			return nil, err
+		}
+	} else {
+		header = append(header, sealKDFNone)
+	}
+	aead, err := newFileAEAD(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, plain, header), nil
+}
+
+// openFile 解析加密文件头并以key解密，返回明文
+func openFile(content []byte, key FileKey) ([]byte, error) {
+	if !bytes.HasPrefix(content, []byte(sealMagic)) || len(content) <= len(sealMagic) {
+		return nil, errors.New("not an encrypted config file")
+	}
+	pos := len(sealMagic) + 1
+	aesKey := key.key
+	switch kdf := content[len(sealMagic)]; kdf {
+	case sealKDFNone:
+		if key.passphrase != nil {
+			return nil, errors.New("file is encrypted with a key, not a passphrase")
+		}
+	case sealKDFPBKDF2:
+		if key.passphrase == nil {
+			return nil, errors.New("file is encrypted with a passphrase, not a key")
+		}
+		if len(content) < pos+4+sealSaltSize {
+			return nil, errors.New("truncated encrypted config file")
+		}
+		iterations := binary.BigEndian.Uint32(content[pos:])
+		if iterations == 0 || iterations > sealMaxIterations {
+			return nil, fmt.Errorf("invalid key derivation iterations %d", iterations)
+		}
+		salt := content[pos+4 : pos+4+sealSaltSize]
+		pos += 4 + sealSaltSize
+		var err error
+		if aesKey, err = passphraseKey(key.passphrase, salt, int(iterations)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key derivation %d", kdf)
+	}
+	aead, err := newFileAEAD(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	header := content[:pos]
+	if len(content) < pos+aead.NonceSize() {
+		return nil, errors.New("truncated encrypted config file")
+	}
+	nonce := content[pos : pos+aead.NonceSize()]
+	plain, err := aead.Open(nil, nonce, content[pos+aead.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt config file: %w", err)
+	}
+	return plain, nil
+}
+
+// newFileAEAD 以key创建AES-GCM
+func newFileAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// passphraseKey 以PBKDF2-HMAC-SHA256由口令派生AES-256密钥
+func passphraseKey(passphrase, salt []byte, iterations int) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, string(passphrase), salt, iterations, 32)
+}
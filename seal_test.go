@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+)
+
+// 已知答案取自RFC 7914第11节，以及按RFC 6070的输入计算的PBKDF2-HMAC-SHA256公开向量
+// passphraseKey固定派生32字节，PBKDF2输出的前缀与更短的派生长度一致，因此只比较共同的前缀
+func TestPassphraseKey(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+			"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+			"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+		{"password", "salt", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"password", "salt", 2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"password", "salt", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+		{"passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096,
+			"348c89dbcbd32b2f32d814b8116e84cf2b17347ebc1800181c4e2a1fb8dd53e1c635518c7dac47e9"},
+		{"pass\x00word", "sa\x00lt", 4096, "89b69d0516f829893c696226650a8687"},
+	}
+	for _, tt := range tests {
+		want, err := hex.DecodeString(tt.want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := passphraseKey([]byte(tt.password), []byte(tt.salt), tt.iterations)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := min(len(got), len(want))
+		if got, want := got[:n], want[:n]; hex.EncodeToString(got) != hex.EncodeToString(want) {
+			t.Errorf("pbkdf2(%q, %q, %d) = %x, want %x", tt.password, tt.salt, tt.iterations, got, want)
+		}
+	}
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name    string
+		save    FileKey
+		load    FileKey
+		wantErr bool
+	}{
+		{"aes key", AESKey(key), AESKey(key), false},
+		{"passphrase", Passphrase("correct horse"), Passphrase("correct horse"), false},
+		{"wrong passphrase", Passphrase("correct horse"), Passphrase("battery staple"), true},
+		{"passphrase file with aes key", Passphrase("correct horse"), AESKey(key), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "app.enc")
+			cfg, _ := NewConfig()
+			cfg.Set("db.password", "s3cret")
+			if err := cfg.SaveEncrypted(filename, tt.save); err != nil {
+				t.Fatal(err)
+			}
+			loaded, _ := NewConfig()
+			err := loaded.LoadEncrypted(filename, tt.load)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadEncrypted error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := "s3cret"
+			if tt.wantErr {
+				want = ""
+			}
+			if got := loaded.Get("db.password"); got != want {
+				t.Errorf("db.password = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maskedValue 是代替敏感值输出的占位符
+const maskedValue = "****"
+
+// MarkSecret 将键标记为敏感信息
+// 标记后GetAll、String、Diff、导出与Handler管理接口等输出以"****"代替其值，Get等读取方法仍返回明文。
+// 模式使用与Subscribe相同的通配符，例如"db.password"、"**password**"(任意位置含password的键)、
+// "*.token"；可以多次调用，标记累加
+// 参数:
+// - patterns: 键或通配符模式
+// 返回:
+// - error: 模式为空时返回错误
+func (c *Config) MarkSecret(patterns ...string) error {
+	for _, p := range patterns {
+		if p == "" {
+			return errors.New("secret pattern cannot be empty")
+		}
+	}
+	c.lock()
+	defer c.mutex.Unlock()
+	for _, p := range patterns {
+		c.secrets = append(c.secrets, c.normKey(p))
+	}
+	return nil
+}
+
+// IsSecret 判断键的值在输出时是否应隐藏
+// 除MarkSecret标记的键外，原始值为ENC(...)形式，或键的最后一段名称含password、secret、token等时也视为敏感；
+// GetAll、String、Diff、导出与Handler管理接口等所有输出路径都按此判断
+// 参数:
+// - key: 配置键
+// 返回:
+// - bool: 是否敏感
+func (c *Config) IsSecret(key string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.isSecretLocked(c.normKey(key))
+}
+
+// isSecretLocked 判断键的值是否敏感，判断规则见IsSecret，调用方须持有锁
+func (c *Config) isSecretLocked(key string) bool {
+	for _, p := range c.secrets {
+		if c.matchKey(p, key) {
+			return true
+		}
+	}
+	if raw, ok := c.resolveLocked(key); ok && isEncrypted(raw) {
+		return true
+	}
+	name := strings.ToLower(key[strings.LastIndex(key, c.sep())+1:])
+	for _, word := range secretWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretWords 是键名中表示敏感信息的关键词
+var secretWords = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "credential", "private"}
+
+// maskSecrets 将values中敏感键的值替换为占位符
+func (c *Config) maskSecrets(values map[string]string) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for k := range values {
+		if c.isSecretLocked(k) {
+			values[k] = maskedValue
+		}
+	}
+}
+
+// String 以按键排序的"key = value"行返回所有生效值，敏感值以"****"代替，适合写入日志
+func (c *Config) String() string {
+	all := c.GetAll()
+	var b strings.Builder
+	for _, k := range sortedKeys(all) {
+		fmt.Fprintf(&b, "%s = %s\n", k, all[k])
+	}
+	return b.String()
+}
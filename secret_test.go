@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newSecretConfig 返回含一个ENC(...)值、一个MarkSecret键、一个按名称判断的敏感键和一个普通键的配置
+func newSecretConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg, _ := NewConfig()
+	if err := cfg.SetEncryptionKey([]byte("0123456789abcdef")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetEncrypted("db.pass", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("db.host", "localhost")
+	cfg.Set("api.key", "k-123")
+	cfg.Set("auth.token", "t-456")
+	if err := cfg.MarkSecret("api.*"); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestIsSecret(t *testing.T) {
+	cfg := newSecretConfig(t)
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"db.pass", true},
+		{"api.key", true},
+		{"auth.token", true},
+		{"db.host", false},
+		{"missing", false},
+	}
+	for _, tt := range tests {
+		if got := cfg.IsSecret(tt.key); got != tt.want {
+			t.Errorf("IsSecret(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+	if got := cfg.Get("db.pass"); got != "hunter2" {
+		t.Errorf("Get(db.pass) = %q, want the decrypted value", got)
+	}
+}
+
+func TestSecretsMaskedOnOutput(t *testing.T) {
+	cfg := newSecretConfig(t)
+	plain := []string{"hunter2", "k-123", "t-456"}
+
+	all := cfg.GetAll()
+	for _, key := range []string{"db.pass", "api.key", "auth.token"} {
+		if all[key] != maskedValue {
+			t.Errorf("GetAll()[%q] = %q, want %q", key, all[key], maskedValue)
+		}
+	}
+	if all["db.host"] != "localhost" {
+		t.Errorf("GetAll()[db.host] = %q, want localhost", all["db.host"])
+	}
+
+	empty, _ := NewConfig()
+	rec := httptest.NewRecorder()
+	Handler(cfg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config", nil))
+	var served map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	outputs := map[string]string{
+		"String":  cfg.String(),
+		"Diff":    Diff(empty, cfg).String(),
+		"Handler": strings.Join([]string{served["db.pass"], served["api.key"], served["auth.token"]}, "\n"),
+	}
+	for name, out := range outputs {
+		for _, p := range plain {
+			if strings.Contains(out, p) {
+				t.Errorf("%s output contains secret %q:\n%s", name, p, out)
+			}
+		}
+	}
+	if !strings.Contains(outputs["String"], "db.pass = ****") {
+		t.Errorf("String() = %q, want db.pass masked", outputs["String"])
+	}
+}
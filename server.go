@@ -0,0 +1,299 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultServerWait 是请求未指定wait时长轮询的等待时间
+	defaultServerWait = 30 * time.Second
+	// maxServerWait 是长轮询等待时间的上限
+	maxServerWait = 5 * time.Minute
+)
+
+// serverResponse 是配置服务器返回的文档
+type serverResponse struct {
+	Revision uint64            `json:"revision"`
+	Values   map[string]string `json:"values"`
+}
+
+// configServer 是ServerHandler返回的配置服务器
+type configServer struct {
+	cfg  *Config
+	auth func(r *http.Request) error
+
+	mutex   sync.Mutex
+	changed chan struct{} // 生效值变化时被关闭并替换，唤醒所有等待的请求
+}
+
+// Serve 在addr上运行配置服务器，把cfg的生效值提供给同一主机上的其它进程
+// 与http.ListenAndServe相同，阻塞直到服务器出错；需要TLS、优雅退出等时把ServerHandler挂到自己的http.Server上。
+// 其它进程通过NewServerProvider或LoadFromServer读取，并以长轮询及时取得变化。
+// 参数:
+// - addr: 监听地址，通常为"127.0.0.1:port"
+// - cfg: 要提供的配置
+// - opts: 可选设置，只有WithAuth生效
+// 返回:
+// - error: 监听或服务错误
+func Serve(addr string, cfg *Config, opts ...HandlerOption) error {
+	return http.ListenAndServe(addr, ServerHandler(cfg, opts...))
+}
+
+// ServerHandler 返回配置服务器的http.Handler
+// GET请求返回{"revision": n, "values": {...}}形式的JSON，值为明文(包括MarkSecret标记的键)，
+// 因此服务器应只监听本机地址或通过WithAuth鉴权。请求带有revision参数且与当前修订号相同时，
+// 服务器等待到生效值变化或wait参数指定的时间(默认30秒，最长5分钟)，超时返回304。
+// Handler在cfg上持有一个订阅，与cfg的生命周期相同
+// 参数:
+// - cfg: 要提供的配置
+// - opts: 可选设置，只有WithAuth生效
+// 返回:
+// - http.Handler: 配置服务器
+func ServerHandler(cfg *Config, opts ...HandlerOption) http.Handler {
+	h := &adminHandler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	s := &configServer{cfg: cfg, auth: h.auth, changed: make(chan struct{})}
+	cfg.Subscribe("**", func(key, oldValue, newValue string) {
+		s.mutex.Lock()
+		close(s.changed)
+		s.changed = make(chan struct{})
+		s.mutex.Unlock()
+	})
+	return s
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.auth != nil {
+		if err := s.auth(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	query := r.URL.Query()
+	if raw := query.Get("revision"); raw != "" {
+		known, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid revision", http.StatusBadRequest)
+			return
+		}
+		wait := defaultServerWait
+		if raw := query.Get("wait"); raw != "" {
+			if wait, err = time.ParseDuration(raw); err != nil || wait < 0 {
+				http.Error(w, "invalid wait", http.StatusBadRequest)
+				return
+			}
+		}
+		if !s.wait(r.Context(), known, min(wait, maxServerWait)) {
+			w.Header().Set("X-Config-Revision", strconv.FormatUint(known, 10))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	// 先取修订号再取值，值可能比修订号新，但不会比它旧
+	resp := serverResponse{Revision: s.cfg.Revision(), Values: s.cfg.allValues()}
+	w.Header().Set("X-Config-Revision", strconv.FormatUint(resp.Revision, 10))
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// wait 等待修订号不再等于known，返回false表示等待超时或请求被取消
+func (s *configServer) wait(ctx context.Context, known uint64, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.mutex.Lock()
+		changed := s.changed
+		s.mutex.Unlock()
+		if s.cfg.Revision() != known {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// ServerProvider 读取Serve或ServerHandler提供的配置
+// Watch以长轮询等待服务器上的变化，变化后立即发送完整数据
+type ServerProvider struct {
+	// Address 是服务器地址，如"http://127.0.0.1:7070"；未指定协议时使用http
+	Address string
+	// Client 用于发送请求，为nil时使用http.DefaultClient
+	Client *http.Client
+	// Header 是附加到每个请求的请求头，如Authorization
+	Header http.Header
+	// WaitTime 是单次长轮询的最长等待时间，服务器限制为最长5分钟
+	WaitTime time.Duration
+	// RetryInterval 是请求失败后的重试间隔
+	RetryInterval time.Duration
+
+	mutex    sync.Mutex
+	revision uint64
+	loaded   bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewServerProvider 创建读取address上配置服务器的配置源
+// 参数:
+// - address: 服务器地址
+// 返回:
+// - *ServerProvider: 配置源实例
+func NewServerProvider(address string) *ServerProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ServerProvider{
+		Address:       address,
+		Header:        make(http.Header),
+		WaitTime:      time.Minute,
+		RetryInterval: time.Second,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// LoadFromServer 从配置服务器加载全部键并合并到文件层
+// 需要持续同步时使用AddProvider(NewServerProvider(address))
+// 参数:
+// - address: 服务器地址
+// 返回:
+// - error: 请求或解析错误(如果有)
+func (c *Config) LoadFromServer(address string) error {
+	p := NewServerProvider(address)
+	defer p.Close()
+	return c.LoadFromProvider(p)
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *ServerProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// Load 读取服务器上的全部键
+func (p *ServerProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时中止请求
+func (p *ServerProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	resp, err := p.fetch(ctx, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	p.revision, p.loaded = resp.Revision, true
+	p.mutex.Unlock()
+	return resp.Values, nil
+}
+
+// Watch 以长轮询等待服务器上的修订号变化，每次变化发送完整数据
+func (p *ServerProvider) Watch(ch chan<- Update) error {
+	for {
+		p.mutex.Lock()
+		revision, loaded := p.revision, p.loaded
+		p.mutex.Unlock()
+
+		resp, err := p.fetch(p.ctx, revision, loaded)
+		if p.ctx.Err() != nil {
+			return ErrProviderClosed
+		}
+		if err != nil {
+			select {
+			case ch <- Update{Err: err}:
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+			select {
+			case <-time.After(p.RetryInterval):
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+			continue
+		}
+		if resp == nil {
+			// 等待超时，没有变化
+			continue
+		}
+		p.mutex.Lock()
+		p.revision, p.loaded = resp.Revision, true
+		p.mutex.Unlock()
+
+		select {
+		case ch <- Update{Values: resp.Values}:
+		case <-p.ctx.Done():
+			return ErrProviderClosed
+		}
+	}
+}
+
+// fetch 请求服务器；wait为true时以revision发起长轮询，服务器返回304时结果为nil
+func (p *ServerProvider) fetch(ctx context.Context, revision uint64, wait bool) (*serverResponse, error) {
+	address := p.Address
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	if wait {
+		query := url.Values{}
+		query.Set("revision", strconv.FormatUint(revision, 10))
+		query.Set("wait", p.WaitTime.String())
+		sep := "?"
+		if strings.Contains(address, "?") {
+			sep = "&"
+		}
+		address += sep + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range p.Header {
+		req.Header[k] = vs
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("config server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, nil
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("config server: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var doc serverResponse
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("config server: decode response: %w", err)
+	}
+	if doc.Values == nil {
+		doc.Values = make(map[string]string)
+	}
+	return &doc, nil
+}
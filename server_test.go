@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// getServer 请求配置服务器，返回状态码、X-Config-Revision与解码后的文档
+func getServer(t *testing.T, url string) (int, string, serverResponse) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var doc serverResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode, resp.Header.Get("X-Config-Revision"), doc
+}
+
+func TestServerHandlerLongPoll(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("db.host", "localhost")
+	srv := httptest.NewServer(ServerHandler(cfg))
+	defer srv.Close()
+
+	status, header, doc := getServer(t, srv.URL)
+	if status != http.StatusOK || doc.Values["db.host"] != "localhost" {
+		t.Fatalf("GET = %d %v", status, doc)
+	}
+	rev := strconv.FormatUint(doc.Revision, 10)
+	if header != rev {
+		t.Errorf("X-Config-Revision = %q, want %s", header, rev)
+	}
+
+	// 修订号未变化时等到超时返回304
+	start := time.Now()
+	status, header, _ = getServer(t, srv.URL+"?revision="+rev+"&wait=50ms")
+	if status != http.StatusNotModified || header != rev {
+		t.Errorf("long poll without change = %d revision %q, want 304 revision %s", status, header, rev)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("long poll returned after %v, want it to wait 50ms", elapsed)
+	}
+
+	// 已过期的修订号立即返回当前值
+	start = time.Now()
+	if status, _, _ = getServer(t, srv.URL+"?revision="+strconv.FormatUint(doc.Revision-1, 10)+"&wait=5s"); status != http.StatusOK {
+		t.Errorf("stale revision = %d, want 200", status)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("stale revision waited %v", elapsed)
+	}
+
+	// 等待期间的变化唤醒请求
+	done := make(chan serverResponse, 1)
+	go func() {
+		var woken serverResponse
+		if resp, err := http.Get(srv.URL + "?revision=" + rev + "&wait=5s"); err == nil {
+			json.NewDecoder(resp.Body).Decode(&woken)
+			resp.Body.Close()
+		}
+		done <- woken
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cfg.Set("db.host", "db1")
+	select {
+	case woken := <-done:
+		if woken.Values["db.host"] != "db1" || woken.Revision <= doc.Revision {
+			t.Errorf("woken long poll = %+v, want the new value and a newer revision", woken)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("long poll was not woken by the change")
+	}
+}
+
+func TestServerHandlerRejects(t *testing.T) {
+	cfg, _ := NewConfig()
+	srv := httptest.NewServer(ServerHandler(cfg, WithAuth(func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			return errors.New("bad token")
+		}
+		return nil
+	})))
+	defer srv.Close()
+
+	tests := []struct {
+		method string
+		query  string
+		auth   string
+		want   int
+	}{
+		{http.MethodGet, "", "", http.StatusForbidden},
+		{http.MethodPost, "", "Bearer t", http.StatusMethodNotAllowed},
+		{http.MethodGet, "?revision=x", "Bearer t", http.StatusBadRequest},
+		{http.MethodGet, "?revision=1&wait=-1s", "Bearer t", http.StatusBadRequest},
+		{http.MethodGet, "", "Bearer t", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, srv.URL+tt.query, nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %q auth=%q = %d, want %d", tt.method, tt.query, tt.auth, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestServerProviderWatch(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("a", "1")
+	srv := httptest.NewServer(ServerHandler(cfg))
+	defer srv.Close()
+
+	p := NewServerProvider(srv.URL)
+	p.WaitTime = 20 * time.Millisecond
+	values, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["a"] != "1" {
+		t.Fatalf("Load = %v", values)
+	}
+	ch := make(chan Update, 4)
+	done := make(chan error, 1)
+	go func() { done <- p.Watch(ch) }()
+	defer func() {
+		p.Close()
+		if err := <-done; err != ErrProviderClosed {
+			t.Errorf("Watch returned %v, want ErrProviderClosed", err)
+		}
+	}()
+
+	// 几次长轮询超时都不应产生更新
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case u := <-ch:
+		t.Fatalf("update %v without a change", u)
+	default:
+	}
+
+	cfg.Set("b", "2")
+	select {
+	case u := <-ch:
+		if u.Err != nil {
+			t.Fatal(u.Err)
+		}
+		if want := map[string]string{"a": "1", "b": "2"}; !reflect.DeepEqual(u.Values, want) {
+			t.Errorf("update = %v, want %v", u.Values, want)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+}
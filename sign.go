@@ -0,0 +1,99 @@
+package config
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// signatureSuffix 是签名文件相对配置文件追加的后缀
+const signatureSuffix = ".sig"
+
+// ErrInvalidSignature 表示配置文件的签名与内容不符，文件可能被篡改
+var ErrInvalidSignature = errors.New("config signature verification failed")
+
+// WithSignature 让保存在写出文件后以key对内容做ed25519签名，写入同目录下的"<文件名>.sig"
+// 签名文件是单独的一行base64文本，覆盖写出的全部字节，可通过LoadVerified验证。
+// 签名在文件替换之后写入，两者之间读取的进程可能看到新文件与旧签名而验证失败，
+// 需要避免时写入方使用SaveToFileLocked，LoadVerified在锁文件存在时等待保存完成
+// 参数:
+// - key: ed25519私钥
+func WithSignature(key ed25519.PrivateKey) SaveOption {
+	return func(s *saveSettings) {
+		s.signingKey = key
+	}
+}
+
+// SignFile 为已有的配置文件生成"<文件名>.sig"签名文件，适用于由其它工具生成、在发布流程中签名的文件
+// 参数:
+// - filename: 配置文件路径
+// - key: ed25519私钥
+// 返回:
+// - error: 文件操作错误(如果有)
+func SignFile(filename string, key ed25519.PrivateKey) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return writeSignature(filename, key, content, info.Mode().Perm())
+}
+
+// LoadVerified 验证配置文件的ed25519签名后加载，签名不符或缺失时拒绝加载
+// 签名从"<文件名>.sig"读取(由WithSignature或SignFile生成)，验证的内容即被解析的内容，
+// 读取后文件再被替换也不影响结果。格式的选择与解析规则与LoadFromFile相同，
+// 但include指令不被处理、profile文件不被加载，因为它们不在签名覆盖的范围内
+// 参数:
+// - filename: 配置文件路径
+// - key: ed25519公钥
+// - opts: 严格模式、强制格式等解析设置
+// 返回:
+// - error: 签名不符时返回包装了ErrInvalidSignature的错误，以及文件操作或解析错误
+func (c *Config) LoadVerified(filename string, key ed25519.PublicKey, opts ...LoadOption) error {
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ed25519 public key length %d", len(key))
+	}
+	release, err := lockConfigFile(context.Background(), filename, false, false)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(filename)
+	var encoded []byte
+	if err == nil {
+		encoded, err = os.ReadFile(filename + signatureSuffix)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%s: missing signature file: %w", filename, ErrInvalidSignature)
+		}
+	}
+	release()
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || !ed25519.Verify(key, content, sig) {
+		return fmt.Errorf("%s: %w", filename, ErrInvalidSignature)
+	}
+	if content, err = decompressContent(filename, content); err != nil {
+		return err
+	}
+	settings := newLoadSettings(opts)
+	settings.noInclude = true
+	return c.loadContent(filename, content, true, settings)
+}
+
+// writeSignature 对content签名并原子地写入filename的签名文件
+func writeSignature(filename string, key ed25519.PrivateKey, content []byte, mode fs.FileMode) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid ed25519 private key length %d", len(key))
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, content)) + "\n"
+	return writeBytesAtomic(filename+signatureSuffix, []byte(sig), WithFileMode(mode))
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignedFiles(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+
+	cfg, _ := NewConfig()
+	cfg.Set("port", "8080")
+	if err := cfg.SaveToFile(path, WithSignature(priv)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ := NewConfig()
+	if err := loaded.LoadVerified(path, pub); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Get("port"); got != "8080" {
+		t.Errorf("port = %q after LoadVerified", got)
+	}
+
+	if err := loaded.LoadVerified(path, otherPub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("LoadVerified with another key error = %v, want ErrInvalidSignature", err)
+	}
+	// 篡改内容后拒绝加载，配置保持不变
+	os.WriteFile(path, []byte("port = 1\n"), 0o644)
+	if err := loaded.LoadVerified(path, pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("LoadVerified of a tampered file error = %v, want ErrInvalidSignature", err)
+	}
+	if got := loaded.Get("port"); got != "8080" {
+		t.Errorf("port after a rejected load = %q", got)
+	}
+
+	// SignFile为其它工具生成的文件签名
+	if err := SignFile(path, priv); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadVerified(path, pub); err != nil || loaded.Get("port") != "1" {
+		t.Errorf("LoadVerified after SignFile = %v, port = %q", err, loaded.Get("port"))
+	}
+
+	unsigned := filepath.Join(dir, "unsigned.conf")
+	os.WriteFile(unsigned, []byte("a = 1\n"), 0o644)
+	if err := loaded.LoadVerified(unsigned, pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("LoadVerified without a signature file error = %v, want ErrInvalidSignature", err)
+	}
+	os.WriteFile(unsigned+signatureSuffix, []byte("not base64!\n"), 0o644)
+	if err := loaded.LoadVerified(unsigned, pub); err == nil {
+		t.Error("LoadVerified with a malformed signature succeeded")
+	}
+	if err := loaded.LoadVerified(path, pub[:10]); err == nil {
+		t.Error("LoadVerified with a short public key succeeded")
+	}
+}
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sliceSettings 是列表值的解析设置
+type sliceSettings struct {
+	delimiter string
+	quotes    bool
+}
+
+// SliceOption 用于定制GetStringSlice等方法的列表解析规则
+type SliceOption func(*sliceSettings)
+
+// WithDelimiter 设置列表元素之间的分隔符，默认为","
+func WithDelimiter(delimiter string) SliceOption {
+	return func(s *sliceSettings) {
+		if delimiter != "" {
+			s.delimiter = delimiter
+		}
+	}
+}
+
+// WithoutQuotes 关闭引号处理，引号作为普通字符保留在元素中
+func WithoutQuotes() SliceOption {
+	return func(s *sliceSettings) {
+		s.quotes = false
+	}
+}
+
+// GetStringSlice 获取配置值并按分隔符拆分为字符串列表
+// 元素两侧的空白被去除，空元素被忽略；元素可以用双引号或单引号包围以包含分隔符或首尾空白，
+// 双引号内支持\"与\\转义。键本身不存在但存在key[0]、key[1]...形式的下标键
+// (如从JSON/YAML数组加载)时，按下标顺序返回这些值。
+// 参数:
+// - key: 要查找的配置键
+// - opts: 分隔符、引号等解析设置
+// 返回:
+// - []string: 列表元素
+// - error: 键不存在(ErrKeyNotFound)或引号不匹配时返回错误
+func (c *Config) GetStringSlice(key string, opts ...SliceOption) ([]string, error) {
+	settings := sliceSettings{delimiter: ",", quotes: true}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	val, ok := c.lookup(key)
+	if !ok {
+		if items := c.indexedValues(key); items != nil {
+			return items, nil
+		}
+		return nil, fmt.Errorf("key %q: %w", key, ErrKeyNotFound)
+	}
+	items, err := splitList(val, settings)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", c.keyRef(key), err)
+	}
+	return items, nil
+}
+
+// GetStringSliceWithDefault 获取字符串列表，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// - opts: 分隔符、引号等解析设置
+// 返回:
+// - []string: 列表元素或defaultValue
+func (c *Config) GetStringSliceWithDefault(key string, defaultValue []string, opts ...SliceOption) []string {
+	if items, err := c.GetStringSlice(key, opts...); err == nil {
+		return items
+	}
+	return defaultValue
+}
+
+// GetIntSlice 获取配置值并拆分为int列表，解析规则与GetStringSlice相同
+// 参数:
+// - key: 要查找的配置键
+// - opts: 分隔符、引号等解析设置
+// 返回:
+// - []int: 解析后的整数列表
+// - error: 键不存在(ErrKeyNotFound)或任一元素解析失败时返回错误
+func (c *Config) GetIntSlice(key string, opts ...SliceOption) ([]int, error) {
+	items, err := c.GetStringSlice(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	nums := make([]int, len(items))
+	for i, item := range items {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: element %d: invalid int %q: %w", c.keyRef(key), i, item, err)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// GetIntSliceWithDefault 获取int列表，键不存在或无法解析时返回默认值
+// 参数:
+// - key: 要查找的配置键
+// - defaultValue: 回退默认值
+// - opts: 分隔符、引号等解析设置
+// 返回:
+// - []int: 解析后的列表或defaultValue
+func (c *Config) GetIntSliceWithDefault(key string, defaultValue []int, opts ...SliceOption) []int {
+	if nums, err := c.GetIntSlice(key, opts...); err == nil {
+		return nums
+	}
+	return defaultValue
+}
+
+// GetSliceLen 返回key[0]、key[1]...形式的下标键组成的数组的长度，即最大下标加1
+// 从JSON、YAML等格式的数组展开的键形如"servers[0].host"、"ports[1]"，下标之后可以接"."或下一维的"[j]"；
+// 下标不连续时空缺的元素也计入长度，与SaveToJSON还原数组的方式一致
+// 参数:
+// - key: 数组的键，如"servers"
+// 返回:
+// - int: 数组长度，没有下标键时为0
+func (c *Config) GetSliceLen(key string) int {
+	key = c.normKey(key)
+	n := 0
+	c.effectiveKeys(func(k string) {
+		if i, ok := elementIndex(k, key, c.sep()); ok && i+1 > n {
+			n = i + 1
+		}
+	})
+	return n
+}
+
+// SubSlice 返回数组中每个元素的子配置，相当于对0到GetSliceLen(key)-1的每个i调用Sub("key[i]")
+// 用于逐个处理重复的配置块：
+//
+//	for i, srv := range cfg.SubSlice("servers") {
+//		fmt.Println(i, srv.Get("host"), srv.Get("port"))
+//	}
+//
+// 元素是标量时子配置为空，此时使用GetStringSlice或Get("key[i]")
+// 参数:
+// - key: 数组的键
+// 返回:
+// - []*Config: 各元素的子配置，没有下标键时为空切片
+func (c *Config) SubSlice(key string) []*Config {
+	subs := make([]*Config, c.GetSliceLen(key))
+	for i := range subs {
+		subs[i] = c.Sub(IndexKey(key, i))
+	}
+	return subs
+}
+
+// IndexKey 返回数组元素的键，如IndexKey("servers", 0)返回"servers[0]"
+func IndexKey(key string, i int) string {
+	return key + "[" + strconv.Itoa(i) + "]"
+}
+
+// elementIndex 判断k是否为key的数组元素或元素下的键，返回元素下标；sep为层级分隔符
+func elementIndex(k, key, sep string) (int, bool) {
+	rest, ok := strings.CutPrefix(k, key+"[")
+	if !ok {
+		return 0, false
+	}
+	end := strings.IndexByte(rest, ']')
+	if end <= 0 {
+		return 0, false
+	}
+	if tail := rest[end+1:]; tail != "" && !strings.HasPrefix(tail, sep) && tail[0] != '[' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(rest[:end])
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
+// indexedValues 按下标顺序收集key[0]、key[1]...的值，key[0]不存在时返回nil
+func (c *Config) indexedValues(key string) []string {
+	var items []string
+	for i := 0; ; i++ {
+		val, ok := c.lookup(IndexKey(key, i))
+		if !ok {
+			return items
+		}
+		items = append(items, strings.TrimSpace(val))
+	}
+}
+
+// splitList 按设置拆分列表值
+func splitList(val string, settings sliceSettings) ([]string, error) {
+	items := []string{}
+	var cur strings.Builder
+	quoted := false // 当前元素是否包含引号部分，包含时即使为空也保留
+	flush := func() {
+		item := cur.String()
+		if !quoted {
+			item = strings.TrimSpace(item)
+		}
+		if item != "" || quoted {
+			items = append(items, item)
+		}
+		cur.Reset()
+		quoted = false
+	}
+
+	for i := 0; i < len(val); {
+		ch := val[i]
+		switch {
+		case settings.quotes && (ch == '"' || ch == '\'') && strings.TrimSpace(cur.String()) == "":
+			// 引号只在元素开头生效，引号之前的空白被丢弃
+			cur.Reset()
+			end := i + 1
+			for ; end < len(val) && val[end] != ch; end++ {
+				if ch == '"' && val[end] == '\\' && end+1 < len(val) {
+					end++
+					cur.WriteByte(val[end])
+					continue
+				}
+				cur.WriteByte(val[end])
+			}
+			if end >= len(val) {
+				return nil, fmt.Errorf("unterminated quote in %q", val)
+			}
+			quoted = true
+			i = end + 1
+			// 跳过引号与分隔符之间的空白
+			for i < len(val) && (val[i] == ' ' || val[i] == '\t') {
+				i++
+			}
+			if i < len(val) && !strings.HasPrefix(val[i:], settings.delimiter) {
+				return nil, fmt.Errorf("unexpected character after quoted element in %q", val)
+			}
+		case strings.HasPrefix(val[i:], settings.delimiter):
+			flush()
+			i += len(settings.delimiter)
+		default:
+			cur.WriteByte(ch)
+			i++
+		}
+	}
+	flush()
+	return items, nil
+}
+
+// joinList 把元素以","连接为splitList可以还原的列表值
+// 含有分隔符、引号或首尾空白的元素以及空元素用双引号包围
+func joinList(items []string) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		if item == "" || strings.ContainsAny(item, ",\"'\\") || strings.TrimSpace(item) != item {
+			item = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(item) + `"`
+		}
+		parts[i] = item
+	}
+	return strings.Join(parts, ",")
+}
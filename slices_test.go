@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetStringSlice(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("hosts", " a , b,,c ")
+	cfg.Set("quoted", `"x, y", ' z ', "say \"hi\"", back\slash`)
+	cfg.Set("paths", "/bin:/usr/bin")
+	cfg.Set("raw", `"a,b"`)
+	cfg.Set("unclosed", `"a, b`)
+
+	tests := []struct {
+		key  string
+		opts []SliceOption
+		want []string
+	}{
+		{"hosts", nil, []string{"a", "b", "c"}},
+		{"quoted", nil, []string{"x, y", " z ", `say "hi"`, `back\slash`}},
+		{"paths", []SliceOption{WithDelimiter(":")}, []string{"/bin", "/usr/bin"}},
+		{"raw", []SliceOption{WithoutQuotes()}, []string{`"a`, `b"`}},
+	}
+	for _, tt := range tests {
+		got, err := cfg.GetStringSlice(tt.key, tt.opts...)
+		if err != nil {
+			t.Errorf("GetStringSlice(%s) error: %v", tt.key, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetStringSlice(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	if _, err := cfg.GetStringSlice("unclosed"); err == nil || errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetStringSlice(unclosed) error = %v, want a quoting error", err)
+	}
+	if _, err := cfg.GetStringSlice("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetStringSlice(missing) error = %v, want ErrKeyNotFound", err)
+	}
+	if got := cfg.GetStringSliceWithDefault("unclosed", []string{"d"}); !reflect.DeepEqual(got, []string{"d"}) {
+		t.Errorf("GetStringSliceWithDefault(unclosed) = %q, want the default", got)
+	}
+}
+
+func TestGetIntSlice(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("ports", "80, 443,8080")
+	cfg.Set("bad", "80, http")
+	if got, err := cfg.GetIntSlice("ports"); err != nil || !reflect.DeepEqual(got, []int{80, 443, 8080}) {
+		t.Errorf("GetIntSlice(ports) = %v, %v", got, err)
+	}
+	_, err := cfg.GetIntSlice("bad")
+	if err == nil || !strings.Contains(err.Error(), `element 1: invalid int "http"`) {
+		t.Errorf("GetIntSlice(bad) error = %v, want the failing element", err)
+	}
+	if got := cfg.GetIntSliceWithDefault("bad", []int{1}); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("GetIntSliceWithDefault(bad) = %v, want the default", got)
+	}
+}
+
+func TestGetStringSliceIndexedKeys(t *testing.T) {
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromReader(strings.NewReader(`{"tags": ["b", "a", "c"]}`), WithFileFormat("json")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cfg.GetStringSlice("tags"); err != nil || !reflect.DeepEqual(got, []string{"b", "a", "c"}) {
+		t.Errorf("GetStringSlice(tags) from a JSON array = %q, %v", got, err)
+	}
+	if n := cfg.GetSliceLen("tags"); n != 3 {
+		t.Errorf("GetSliceLen(tags) = %d, want 3", n)
+	}
+}
+
+func TestSubSlice(t *testing.T) {
+	cfg, _ := NewConfig()
+	doc := `{"servers": [{"host": "a", "port": 80}, {"host": "b", "tags": ["x", "y"]}], "matrix": [[1, 2], [3]]}`
+	if err := cfg.LoadFromReader(strings.NewReader(doc), WithFileFormat("json")); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("servers[3].host", "d") // 下标不连续时空缺的元素也计入长度
+	cfg.Set("serversx[0]", "other")
+	cfg.Set("servers[bad]", "ignored")
+
+	if n := cfg.GetSliceLen("servers"); n != 4 {
+		t.Errorf("GetSliceLen(servers) = %d, want 4", n)
+	}
+	if n := cfg.GetSliceLen("matrix"); n != 2 {
+		t.Errorf("GetSliceLen(matrix) = %d, want 2", n)
+	}
+	if n := cfg.GetSliceLen(IndexKey("matrix", 0)); n != 2 {
+		t.Errorf("GetSliceLen(matrix[0]) = %d, want 2", n)
+	}
+	if n := cfg.GetSliceLen("missing"); n != 0 {
+		t.Errorf("GetSliceLen(missing) = %d, want 0", n)
+	}
+
+	subs := cfg.SubSlice("servers")
+	if len(subs) != 4 {
+		t.Fatalf("SubSlice(servers) returned %d elements", len(subs))
+	}
+	if subs[0].Get("host") != "a" || subs[0].Get("port") != "80" || subs[1].Get("host") != "b" {
+		t.Errorf("SubSlice elements = %v, %v", subs[0].GetAll(), subs[1].GetAll())
+	}
+	if got, _ := subs[1].GetStringSlice("tags"); !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("servers[1].tags = %q", got)
+	}
+	if subs[2].Len() != 0 || subs[3].Get("host") != "d" {
+		t.Errorf("sparse elements = %v, %v", subs[2].GetAll(), subs[3].GetAll())
+	}
+	if got := cfg.Get(IndexKey("servers", 1) + ".host"); got != "b" {
+		t.Errorf("Get(IndexKey(servers, 1).host) = %q", got)
+	}
+	if subs := cfg.SubSlice("missing"); subs == nil || len(subs) != 0 {
+		t.Errorf("SubSlice(missing) = %#v, want an empty slice", subs)
+	}
+}
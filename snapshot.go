@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// Snapshot 是配置在某一时刻的不可变副本，由Config.Snapshot创建
+type Snapshot struct {
+	data   map[string]string
+	layers [numLayers]map[string]string
+	meta   [numLayers]map[string]KeySource
+	layout *fileLayout
+	taken  time.Time
+}
+
+// Time 返回快照创建的时间
+func (s *Snapshot) Time() time.Time {
+	return s.taken
+}
+
+// Snapshot 创建包含所有层数据的快照，之后对配置的修改不影响快照
+// BindEnv绑定的环境变量、加密与插值等设置不属于快照内容
+// 返回:
+// - *Snapshot: 快照句柄
+func (c *Config) Snapshot() *Snapshot {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.snapshotLocked()
+}
+
+// snapshotLocked 创建快照，调用方须持有锁
+func (c *Config) snapshotLocked() *Snapshot {
+	s := &Snapshot{
+		data:   copyValues(c.data),
+		layout: c.layout,
+		taken:  time.Now(),
+	}
+	for l := range c.layers {
+		s.layers[l] = copyValues(c.layers[l])
+		s.meta[l] = copySources(c.meta[l])
+	}
+	return s
+}
+
+// Restore 将所有层数据回滚到快照时的状态
+// 回滚导致的生效值变化会通知订阅者，SetWithTTL设置的过期计时被取消；同一快照可以多次恢复
+// 参数:
+// - s: Snapshot返回的快照
+// 返回:
+// - error: s为nil时返回错误，配置已冻结时返回ErrFrozen
+func (c *Config) Restore(s *Snapshot) error {
+	if s == nil {
+		return errors.New("snapshot is nil")
+	}
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	changes := c.restoreLocked(s)
+	c.unlockNotify(changes)
+	return nil
+}
+
+// restoreLocked 将所有层数据替换为快照内容并返回生效值的差异，调用方须持有写锁
+func (c *Config) restoreLocked(s *Snapshot) []change {
+	for key := range c.ttls {
+		c.cancelTTLLocked(key)
+	}
+	return c.diffLocked(func() {
+		c.data = copyValues(s.data)
+		if c.data == nil {
+			c.data = make(map[string]string)
+		}
+		for l := range c.layers {
+			c.layers[l] = copyValues(s.layers[l])
+			c.meta[l] = copySources(s.meta[l])
+		}
+		c.layout = s.layout
+	})
+}
+
+// copyValues 返回m的副本，m为nil时返回nil
+func copyValues(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
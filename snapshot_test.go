@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromReader(strings.NewReader("# comment\nport = 80\nname = app\n")); err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetDefault("timeout", "30s")
+	cfg.SetOverride("debug", "false")
+	before := time.Now()
+	snap := cfg.Snapshot()
+	if snap.Time().Before(before) {
+		t.Errorf("Snapshot().Time() = %v, want at or after %v", snap.Time(), before)
+	}
+	want := cfg.GetAll()
+
+	cfg.Set("port", "9090")
+	cfg.Delete("name")
+	cfg.Set("extra", "1")
+	cfg.SetDefault("timeout", "5s")
+	cfg.ClearLayer(LayerOverride)
+
+	var changed []string
+	cfg.Subscribe("**", func(key, _, _ string) { changed = append(changed, key) })
+	if err := cfg.Restore(snap); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.GetAll(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after Restore = %v, want %v", got, want)
+	}
+	if want := []string{"debug", "extra", "name", "port", "timeout"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Restore notified %v, want %v", changed, want)
+	}
+	// 文件结构随快照恢复
+	var buf bytes.Buffer
+	cfg.WriteTo(&buf)
+	if buf.String() != "# comment\nport = 80\nname = app\n" {
+		t.Errorf("WriteTo after Restore =\n%s", buf.String())
+	}
+
+	// 恢复后的修改不影响快照，同一快照可以再次恢复
+	cfg.Set("port", "1")
+	if err := cfg.Restore(snap); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("port"); got != "80" {
+		t.Errorf("port after the second Restore = %q, want 80", got)
+	}
+	if err := cfg.Restore(nil); err == nil {
+		t.Error("Restore(nil) succeeded")
+	}
+}
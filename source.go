@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// SourceKind 表示键值的来源类别
+type SourceKind int
+
+const (
+	// SourceAPI 表示值通过Set、MergeLayer、Update等方法写入
+	SourceAPI SourceKind = iota
+	// SourceFile 表示值从配置文件加载
+	SourceFile
+	// SourceEnv 表示值来自BindEnv绑定的环境变量
+	SourceEnv
+	// SourceFlag 表示值来自BindFlags绑定的命令行参数
+	SourceFlag
+	// SourceProvider 表示值来自LoadFromProvider或WatchProvider的配置源
+	SourceProvider
+)
+
+// String 返回来源类别的名称
+func (k SourceKind) String() string {
+	switch k {
+	case SourceAPI:
+		return "api"
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	case SourceProvider:
+		return "provider"
+	}
+	return fmt.Sprintf("SourceKind(%d)", int(k))
+}
+
+// KeySource 描述键的生效值来自哪里以及何时被修改
+type KeySource struct {
+	// Kind 是来源类别
+	Kind SourceKind
+	// Name 是来源名称：文件路径、环境变量名、参数名或配置源类型名，SourceAPI时为空
+	Name string
+	// Line 是值在文件中的行号，仅对key=value格式的文件有效，未知时为0
+	Line int
+	// Layer 是生效值所在的层
+	Layer Layer
+	// Modified 是值最后一次写入的时间，来自环境变量的值为零值
+	Modified time.Time
+}
+
+// String 返回来源的简短描述，如"app.ini:12"、"env APP_PORT"、"flag -port"、"api"
+func (s KeySource) String() string {
+	switch s.Kind {
+	case SourceFile:
+		if s.Line > 0 {
+			return fmt.Sprintf("%s:%d", s.Name, s.Line)
+		}
+		return s.Name
+	case SourceEnv:
+		return "env " + s.Name
+	case SourceFlag:
+		return "flag -" + s.Name
+	case SourceProvider:
+		return "provider " + s.Name
+	}
+	return s.Kind.String()
+}
+
+// Source 返回键的生效值的来源
+// 启用profile时返回实际生效的"profile.key"的来源
+// 参数:
+// - key: 配置键
+// 返回:
+// - KeySource: 来源信息
+// - bool: 键不存在时返回false
+func (c *Config) Source(key string) (KeySource, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.sourceLocked(key)
+}
+
+// sourceLocked 返回键的生效值的来源，调用方须持有锁
+func (c *Config) sourceLocked(key string) (KeySource, bool) {
+	r, ok := c.resolveEntryLocked(key)
+	if !ok {
+		return KeySource{}, false
+	}
+	if r.env != "" {
+		return KeySource{Kind: SourceEnv, Name: r.env, Layer: LayerEnv}, true
+	}
+	src := c.meta[r.layer][r.stored]
+	src.Layer = r.layer
+	return src, true
+}
+
+// setSourceLocked 记录指定层中键的来源，修改时间取当前时间；调用方须持有写锁
+func (c *Config) setSourceLocked(layer Layer, key string, src KeySource) {
+	key = c.normKey(key)
+	if c.meta[layer] == nil {
+		c.meta[layer] = make(map[string]KeySource)
+	}
+	src.Layer = layer
+	src.Modified = time.Now()
+	c.meta[layer][key] = src
+}
+
+// keyRef 返回错误信息中引用键的形式
+// 值来自文件、环境变量等时附带来源，如`"port" (app.ini:12)`，否则只有带引号的键名
+func (c *Config) keyRef(key string) string {
+	if src, ok := c.Source(key); ok && src.Kind != SourceAPI {
+		return fmt.Sprintf("%q (%s)", key, src)
+	}
+	return fmt.Sprintf("%q", key)
+}
+
+// copySources 返回m的副本，m为nil时返回nil
+func copySources(m map[string]KeySource) map[string]KeySource {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]KeySource, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
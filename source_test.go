@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	os.WriteFile(path, []byte("# comment\nname = app\n\nport = abc\n"), 0o644)
+	cfg, _ := NewConfig()
+	before := time.Now()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetDefault("timeout", "30s")
+
+	src, ok := cfg.Source("port")
+	if !ok || src.Kind != SourceFile || src.Name != path || src.Line != 4 || src.Layer != LayerFile {
+		t.Errorf("Source(port) = %+v, want %s line 4 in the file layer", src, path)
+	}
+	if src.Modified.Before(before) {
+		t.Errorf("Source(port).Modified = %v, want at or after %v", src.Modified, before)
+	}
+	if got := src.String(); got != path+":4" {
+		t.Errorf("Source(port).String() = %q", got)
+	}
+	if src, _ := cfg.Source("timeout"); src.Kind != SourceAPI || src.Layer != LayerDefault || src.String() != "api" {
+		t.Errorf("Source(timeout) = %+v, want the API in the default layer", src)
+	}
+
+	// 覆盖文件中的值后来源随之改变
+	cfg.Set("name", "other")
+	if src, _ := cfg.Source("name"); src.Kind != SourceAPI || src.Line != 0 {
+		t.Errorf("Source(name) after Set = %+v, want the API", src)
+	}
+	t.Setenv("APP_PORT", "9090")
+	cfg.BindEnv("APP")
+	if src, _ := cfg.Source("port"); src.Kind != SourceEnv || src.Name != "APP_PORT" || src.String() != "env APP_PORT" {
+		t.Errorf("Source(port) with APP_PORT = %+v, want the env var", src)
+	}
+	if _, ok := cfg.Source("missing"); ok {
+		t.Error("Source(missing) reported a source")
+	}
+
+	// 错误信息中附带来源
+	os.Unsetenv("APP_PORT")
+	_, err := cfg.GetInt("port")
+	if err == nil || !strings.Contains(err.Error(), path+":4") {
+		t.Errorf("GetInt(port) error = %v, want the file and line", err)
+	}
+}
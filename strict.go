@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// loadSettings 是LoadFromFile等加载方法的可选设置
+type loadSettings struct {
+	strict        bool
+	duplicates    DuplicatePolicy
+	duplicatesSet bool
+	onDuplicate   func(LineError)
+	warn          func(LineError) // 报告非严格模式下被忽略的行与重复的键，由Config写入日志
+	format        string
+	noInclude     bool              // 不处理include指令，用于LoadVerified，使签名覆盖全部内容
+	conditions    map[string]string // WithConditions设置的条件指令变量
+}
+
+// DuplicatePolicy 决定同一文件中重复出现的键如何处理
+type DuplicatePolicy int
+
+const (
+	// DuplicateLastWins 以最后一次出现的值为准，是默认行为
+	DuplicateLastWins DuplicatePolicy = iota
+	// DuplicateFirstWins 以第一次出现的值为准，之后的行被忽略
+	DuplicateFirstWins
+	// DuplicateError 使加载失败，返回列出所有重复行的*ParseError
+	DuplicateError
+	// DuplicateCollect 把所有出现的值按顺序合并为列表值，可通过GetStringSlice读取；
+	// 保存时只保留第一次出现的行
+	DuplicateCollect
+)
+
+// String 返回策略名称
+func (d DuplicatePolicy) String() string {
+	switch d {
+	case DuplicateLastWins:
+		return "last-wins"
+	case DuplicateFirstWins:
+		return "first-wins"
+	case DuplicateError:
+		return "error"
+	case DuplicateCollect:
+		return "collect"
+	}
+	return fmt.Sprintf("DuplicatePolicy(%d)", int(d))
+}
+
+// WithDuplicateKeys 设置重复键的处理策略
+// 通过include引入的文件覆盖之前文件中的键不算重复；未设置时StrictMode下为DuplicateError，否则为DuplicateLastWins
+func WithDuplicateKeys(policy DuplicatePolicy) LoadOption {
+	return func(s *loadSettings) {
+		s.duplicates = policy
+		s.duplicatesSet = true
+	}
+}
+
+// OnDuplicate 设置重复键的报告函数，每个重复出现的行调用一次，与处理策略无关
+func OnDuplicate(fn func(LineError)) LoadOption {
+	return func(s *loadSettings) {
+		s.onDuplicate = fn
+	}
+}
+
+// WithFileFormat 强制按指定格式解析，忽略扩展名与内容检测
+// 格式名为"json"、"yaml"、"toml"、"ini"(key=value)、"env"、"properties"、"xml"、"hcl"之一，
+// 或RegisterCodec注册的名称
+func WithFileFormat(format string) LoadOption {
+	return func(s *loadSettings) {
+		s.format = strings.ToLower(format)
+	}
+}
+
+// duplicatePolicy 返回生效的重复键策略
+func (s loadSettings) duplicatePolicy() DuplicatePolicy {
+	if !s.duplicatesSet && s.strict {
+		return DuplicateError
+	}
+	return s.duplicates
+}
+
+// LoadOption 用于定制LoadFromFile、LoadFromReader、LoadFromGlob的解析行为
+type LoadOption func(*loadSettings)
+
+// StrictMode 开启严格解析
+// 默认情况下不含"="(或"key: value"中的":")的行被忽略、重复的键以最后一次为准；严格模式下没有分隔符或键名为空的行、
+// 未闭合的段落头、同一文件中重复的键(可通过WithDuplicateKeys改变)以及不是合法UTF-8的行
+// 都会使加载失败，返回列出所有问题的*ParseError，配置保持不变
+func StrictMode() LoadOption {
+	return func(s *loadSettings) {
+		s.strict = true
+	}
+}
+
+// newLoadSettings 应用opts得到加载设置
+func newLoadSettings(opts []LoadOption) loadSettings {
+	var s loadSettings
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// LineError 描述文件中一行内容的问题，从io.Reader加载时File为"<input>"
+type LineError struct {
+	File    string
+	Line    int
+	Content string
+	Message string
+}
+
+// String 返回"文件:行号: 原因: 内容"形式的描述
+func (e LineError) String() string {
+	return fmt.Sprintf("%s:%d: %s: %q", e.File, e.Line, e.Message, e.Content)
+}
+
+// ParseError 汇总严格模式下发现的所有格式问题
+type ParseError struct {
+	Errors []LineError
+}
+
+// Error 返回所有问题的描述，每条一行
+func (e *ParseError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, le := range e.Errors {
+		lines[i] = le.String()
+	}
+	return "config parse failed:\n" + strings.Join(lines, "\n")
+}
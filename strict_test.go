@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrictMode(t *testing.T) {
+	input := "a = 1\njunk line\n[server\n = 2\nbad = \xff\xfe\nb = 2\n"
+	path := filepath.Join(t.TempDir(), "app.conf")
+	os.WriteFile(path, []byte(input), 0o644)
+
+	// 默认忽略格式错误的行
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatalf("non-strict load: %v", err)
+	}
+	if cfg.Get("a") != "1" || cfg.Get("b") != "2" {
+		t.Errorf("non-strict values = %v", cfg.GetAll())
+	}
+
+	cfg, _ = NewConfig()
+	cfg.Set("keep", "1")
+	err := cfg.LoadFromFile(path, StrictMode())
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("strict load error = %v, want *ParseError", err)
+	}
+	wantLines := []int{2, 3, 4, 5}
+	if len(pe.Errors) != len(wantLines) {
+		t.Fatalf("ParseError lists %d problems, want %d:\n%v", len(pe.Errors), len(wantLines), err)
+	}
+	for i, le := range pe.Errors {
+		if le.File != path || le.Line != wantLines[i] {
+			t.Errorf("problem %d = %s:%d, want %s:%d", i, le.File, le.Line, path, wantLines[i])
+		}
+	}
+	if pe.Errors[0].Content != "junk line" {
+		t.Errorf("problem content = %q, want the offending line", pe.Errors[0].Content)
+	}
+	if msg := err.Error(); !strings.Contains(msg, path+":2:") || !strings.Contains(msg, `"junk line"`) {
+		t.Errorf("Error() =\n%s\nwant the file, line and content", msg)
+	}
+	// 失败的加载不修改配置
+	if cfg.Len() != 1 || cfg.Get("keep") != "1" {
+		t.Errorf("config after a failed strict load = %v", cfg.GetAll())
+	}
+
+	// 从io.Reader加载时文件名为<input>
+	err = cfg.LoadFromReader(strings.NewReader("junk\n"), StrictMode())
+	if err == nil || !strings.Contains(err.Error(), "<input>:1:") {
+		t.Errorf("LoadFromReader strict error = %v, want <input>:1", err)
+	}
+	if err := cfg.LoadFromReader(strings.NewReader("c: 3\n# comment\n\n"), StrictMode()); err != nil {
+		t.Errorf("strict load of a valid file: %v", err)
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"app.conf":    "include base.conf\na = 1\nb = x\na = 2\n[s]\nk = 1\n[s]\nk = 2\n",
+		"base.conf":   "a = 0\nb = base\n",
+		"strict.conf": "a = 1\na = 2\na = 3\n",
+	})
+	var reported []LineError
+	report := OnDuplicate(func(le LineError) { reported = append(reported, le) })
+
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(filepath.Join(dir, "app.conf"), WithDuplicateKeys(DuplicateFirstWins), report); err != nil {
+		t.Fatal(err)
+	}
+	// include引入的键被覆盖不算重复
+	if cfg.Get("a") != "1" || cfg.Get("b") != "x" || cfg.Get("s.k") != "1" {
+		t.Errorf("first-wins values = %v", cfg.GetAll())
+	}
+	if len(reported) != 2 || reported[0].Line != 4 || reported[1].Line != 8 || reported[0].Content != "a = 2" {
+		t.Errorf("reported duplicates = %+v, want lines 4 and 8", reported)
+	}
+
+	cfg, _ = NewConfig()
+	if err := cfg.LoadFromFile(filepath.Join(dir, "strict.conf"), WithDuplicateKeys(DuplicateCollect)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cfg.GetStringSlice("a"); strings.Join(got, "|") != "1|2|3" {
+		t.Errorf("collected a = %q, want [1 2 3]", got)
+	}
+
+	// 严格模式默认拒绝重复键，显式策略优先
+	cfg, _ = NewConfig()
+	err := cfg.LoadFromFile(filepath.Join(dir, "strict.conf"), StrictMode())
+	var pe *ParseError
+	if !errors.As(err, &pe) || len(pe.Errors) != 2 || pe.Errors[0].Line != 2 {
+		t.Errorf("strict duplicate error = %v, want lines 2 and 3", err)
+	}
+	if err := cfg.LoadFromFile(filepath.Join(dir, "strict.conf"), StrictMode(), WithDuplicateKeys(DuplicateLastWins)); err != nil || cfg.Get("a") != "3" {
+		t.Errorf("strict last-wins = %v, a = %q", err, cfg.Get("a"))
+	}
+	if got := DuplicateCollect.String(); got == "" || got == "DuplicatePolicy(3)" {
+		t.Errorf("DuplicateCollect.String() = %q", got)
+	}
+}
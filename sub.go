@@ -0,0 +1,75 @@
+package config
+
+import "strings"
+
+// Sub 返回以prefix为根的子配置
+// 返回的Config包含所有以"prefix."开头的键，键名去掉该前缀，
+// 例如Sub("server.http")中"server.http.port"对应"port"。
+// 子配置是独立的副本，各层合并后的生效值写入其文件层，修改它不会影响原配置。
+// ENC(...)值保持密文并共享原配置的Cipher；开启插值时，
+// 含${...}引用的值在原配置中展开后写入，以便引用前缀之外的键。
+// 参数:
+// - prefix: 子配置的键前缀，不含末尾的"."
+// 返回:
+// - *Config: 子配置实例；没有匹配的键时返回空配置
+func (c *Config) Sub(prefix string) *Config {
+	prefix = c.normKey(strings.TrimSuffix(prefix, c.sep()))
+	sub := &Config{data: make(map[string]string), normalizer: c.normalizer, delimiter: c.delimiter}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	sub.cipher = c.cipher
+	for k, v := range c.effectiveLocked() {
+		if rest, ok := strings.CutPrefix(k, prefix+c.sep()); ok && rest != "" {
+			if !isEncrypted(v) {
+				v = c.expandValueLocked(k, v)
+			}
+			sub.data[rest] = v
+		}
+	}
+	return sub
+}
+
+// GetAllWithPrefix 返回所有以prefix开头的键值对副本
+// 返回的键保持完整键名，不去掉前缀
+// 参数:
+// - prefix: 键前缀，如"server."
+// 返回:
+// - map[string]string: 匹配的配置数据副本
+func (c *Config) GetAllWithPrefix(prefix string) map[string]string {
+	return c.prefixValues(prefix, false)
+}
+
+// GetStringMap 返回prefix下的所有键值对，键名去掉前缀
+// 例如GetStringMap("labels")对"labels.env"、"labels.team"返回{"env": ..., "team": ...}；
+// 更深层的键保留剩余的路径，如"labels.a.b"对应"a.b"；层级分隔符由WithKeyDelimiter设置
+// 参数:
+// - prefix: 键前缀，末尾的分隔符可省略
+// 返回:
+// - map[string]string: 去掉前缀后的键值对；没有匹配的键时返回空map
+func (c *Config) GetStringMap(prefix string) map[string]string {
+	if sep := c.sep(); prefix != "" && !strings.HasSuffix(prefix, sep) {
+		prefix += sep
+	}
+	return c.prefixValues(prefix, true)
+}
+
+// prefixValues 返回以prefix开头的生效值，strip为true时去掉键的前缀
+// 值的处理方式与Get一致
+func (c *Config) prefixValues(prefix string, strip bool) map[string]string {
+	prefix = c.normKey(prefix)
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	result := make(map[string]string)
+	for k, v := range c.effectiveLocked() {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok || (strip && rest == "") {
+			continue
+		}
+		if !strip {
+			rest = k
+		}
+		result[rest] = c.coerceLocked(k, c.expandValueLocked(k, c.decodeLocked(v)))
+	}
+	return result
+}
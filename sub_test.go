@@ -0,0 +1,75 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyDelimiterBoundaries(t *testing.T) {
+	cfg, err := NewConfigWithOptions(WithKeyDelimiter("/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen []string
+	cfg.Subscribe("db/*", func(key, _, _ string) { seen = append(seen, key) })
+
+	cfg.Set("labels/env", "prod")
+	cfg.Set("labels/a/b", "deep")
+	cfg.Set("labels.team", "core")
+	cfg.Set("db/host", "db.local")
+	cfg.Set("db/primary/host", "p.local")
+	cfg.Set("db/read.only", "true")
+
+	want := map[string]string{"env": "prod", "a/b": "deep"}
+	for _, prefix := range []string{"labels", "labels/"} {
+		if got := cfg.GetStringMap(prefix); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetStringMap(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+	if got, want := seen, []string{"db/host", "db/read.only"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Subscribe(db/*) saw %v, want %v", got, want)
+	}
+
+	cfg.Set("prod/db/host", "prod.local")
+	cfg.Set("prod.db/primary/host", "ignored")
+	if err := cfg.SetProfile("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("db/host"); got != "prod.local" {
+		t.Errorf("Get(db/host) with profile = %q, want prod.local", got)
+	}
+	if got := cfg.Get("db/primary/host"); got != "p.local" {
+		t.Errorf("Get(db/primary/host) with profile = %q, want p.local", got)
+	}
+	if src, ok := cfg.Source("db/host"); !ok || src.Layer != LayerFile {
+		t.Errorf("Source(db/host) = %v, %v", src, ok)
+	}
+	if got := cfg.GetAll()["db/host"]; got != "prod.local" {
+		t.Errorf("GetAll()[db/host] = %q, want prod.local", got)
+	}
+}
+
+func TestGetStringMap(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("labels.env", "prod")
+	cfg.Set("labels.team", "core")
+	cfg.Set("labels.a.b", "deep")
+	cfg.Set("labelsx.other", "no")
+	cfg.Set("labels", "root")
+	cfg.SetDefault("labels.region", "eu")
+	cfg.SetOverride("labels.team", "platform")
+
+	want := map[string]string{"env": "prod", "team": "platform", "a.b": "deep", "region": "eu"}
+	for _, prefix := range []string{"labels", "labels."} {
+		if got := cfg.GetStringMap(prefix); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetStringMap(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+	if got := cfg.GetStringMap("missing"); got == nil || len(got) != 0 {
+		t.Errorf("GetStringMap(missing) = %#v, want an empty map", got)
+	}
+	// GetAllWithPrefix保留完整键名，不要求分隔符边界
+	if got := cfg.GetAllWithPrefix("labelsx"); !reflect.DeepEqual(got, map[string]string{"labelsx.other": "no"}) {
+		t.Errorf("GetAllWithPrefix(labelsx) = %v", got)
+	}
+}
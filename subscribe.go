@@ -0,0 +1,184 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// change 描述一次键值变化，oldOK/newOK表示变化前后键是否存在
+// actor是UpdateAs提供的操作者，用于审计日志
+type change struct {
+	key   string
+	old   string
+	new   string
+	oldOK bool
+	newOK bool
+	actor string
+}
+
+// subscriber 是一个已注册的变更订阅
+type subscriber struct {
+	pattern string
+	fn      func(key, oldValue, newValue string)
+}
+
+// Subscription 是Subscribe返回的订阅句柄
+type Subscription struct {
+	c  *Config
+	id uint64
+}
+
+// Subscribe 订阅匹配pattern的键的变化
+// Set、Delete、文件加载、各层写入以及Watch热加载导致的生效值变化都会触发回调，
+// 生效值未变化的写入(包括被更高优先级层遮蔽的写入)不会触发。新增的键oldValue为空字符串，删除的键newValue为空字符串。
+// pattern支持通配符："*"匹配单个层级中的任意字符(不跨越层级分隔符，默认为"."，见WithKeyDelimiter),
+// "**"匹配任意多个层级，"?"匹配单个字符。例如"server.*"匹配server.port，
+// "server.**"匹配server下的所有键。
+// 回调在触发变化的goroutine中、配置锁之外同步执行。
+// 参数:
+// - pattern: 键名或通配符模式
+// - fn: 变化回调
+// 返回:
+// - *Subscription: 用于取消订阅的句柄
+func (c *Config) Subscribe(pattern string, fn func(key, oldValue, newValue string)) *Subscription {
+	c.lock()
+	defer c.mutex.Unlock()
+	if c.subs == nil {
+		c.subs = make(map[uint64]*subscriber)
+	}
+	c.nextSubID++
+	c.subs[c.nextSubID] = &subscriber{pattern: c.normKey(pattern), fn: fn}
+	return &Subscription{c: c, id: c.nextSubID}
+}
+
+// Unsubscribe 取消订阅，重复调用无副作用
+func (s *Subscription) Unsubscribe() {
+	s.c.lock()
+	defer s.c.mutex.Unlock()
+	delete(s.c.subs, s.id)
+}
+
+// setLocked 在文件层写入键值并把生效值的变化追加到changes，调用方须持有写锁
+func (c *Config) setLocked(key, value string, changes []change) []change {
+	return c.writeLayerLocked(LayerFile, key, value, false, changes)
+}
+
+// deleteLocked 从文件层删除键并把生效值的变化追加到changes，调用方须持有写锁
+func (c *Config) deleteLocked(key string, changes []change) []change {
+	return c.writeLayerLocked(LayerFile, key, "", true, changes)
+}
+
+// replaceLocked 用values整体替换文件层数据并返回生效值的差异，调用方须持有写锁
+func (c *Config) replaceLocked(values map[string]string) []change {
+	return c.diffLocked(func() {
+		c.data = normKeys(c, values)
+		c.meta[LayerFile] = nil
+	})
+}
+
+// diffLocked 执行mutate并返回其前后所有键生效值的差异，调用方须持有写锁
+func (c *Config) diffLocked(mutate func()) []change {
+	keys := c.keysLocked()
+	before := make(map[string]change, len(keys))
+	for k := range keys {
+		old, ok := c.resolveLocked(k)
+		before[k] = change{key: k, old: old, oldOK: ok}
+	}
+
+	mutate()
+
+	for k := range c.keysLocked() {
+		if _, ok := before[k]; !ok {
+			before[k] = change{key: k}
+		}
+	}
+	var changes []change
+	for k, ch := range before {
+		ch.new, ch.newOK = c.resolveLocked(k)
+		if ch.newOK != ch.oldOK || ch.new != ch.old {
+			changes = append(changes, ch)
+		}
+	}
+	return changes
+}
+
+// notify 在锁外把变化分发给匹配的订阅者，同一订阅者按键名顺序收到通知
+func (c *Config) notify(changes []change) {
+	if len(changes) == 0 {
+		return
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].key < changes[j].key })
+	c.mutex.RLock()
+	subs := make([]*subscriber, 0, len(c.subs))
+	ids := make([]uint64, 0, len(c.subs))
+	for id := range c.subs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		subs = append(subs, c.subs[id])
+	}
+	audit := c.audit
+	var events []AuditEvent
+	if audit != nil {
+		events = c.auditEventsLocked(changes)
+	}
+	c.mutex.RUnlock()
+	for _, e := range events {
+		audit(e)
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	for _, sub := range subs {
+		for _, ch := range changes {
+			if c.matchKey(sub.pattern, ch.key) {
+				sub.fn(ch.key, ch.old, ch.new)
+			}
+		}
+	}
+}
+
+// matchPattern 判断key是否匹配通配符模式
+// "*"匹配不含"."的任意字符串，"**"匹配任意字符串，"?"匹配除"."外的单个字符
+func matchPattern(pattern, key string) bool {
+	return matchPatternSep(pattern, key, ".")
+}
+
+// matchPatternSep 与matchPattern相同，以sep代替"."作为"*"与"?"不跨越的边界
+func matchPatternSep(pattern, key, sep string) bool {
+	for len(pattern) > 0 {
+		switch {
+		case len(pattern) >= 2 && pattern[0] == '*' && pattern[1] == '*':
+			rest := pattern[2:]
+			for i := 0; i <= len(key); i++ {
+				if matchPatternSep(rest, key[i:], sep) {
+					return true
+				}
+			}
+			return false
+		case pattern[0] == '*':
+			rest := pattern[1:]
+			for i := 0; i <= len(key); i++ {
+				if matchPatternSep(rest, key[i:], sep) {
+					return true
+				}
+				if strings.HasPrefix(key[i:], sep) {
+					break
+				}
+			}
+			return false
+		case pattern[0] == '?':
+			if len(key) == 0 || strings.HasPrefix(key, sep) {
+				return false
+			}
+		default:
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	cfg, _ := NewConfig()
+	var server, all []string
+	sub := cfg.Subscribe("server.*", func(key, oldValue, newValue string) {
+		server = append(server, key+":"+oldValue+"->"+newValue)
+	})
+	cfg.Subscribe("**", func(key, oldValue, newValue string) {
+		all = append(all, key)
+	})
+
+	cfg.Set("server.port", "8080")
+	cfg.Set("server.port", "8080") // 值未变化
+	cfg.Set("server.tls.cert", "a.pem")
+	cfg.Set("db.host", "localhost")
+	cfg.Set("server.port", "9090")
+	cfg.Delete("server.port")
+	want := []string{"server.port:->8080", "server.port:8080->9090", "server.port:9090->"}
+	if !reflect.DeepEqual(server, want) {
+		t.Errorf("server.* notifications = %v, want %v", server, want)
+	}
+	if want := []string{"server.port", "server.tls.cert", "db.host", "server.port", "server.port"}; !reflect.DeepEqual(all, want) {
+		t.Errorf("** notifications = %v, want %v", all, want)
+	}
+
+	// 被覆盖层遮蔽的写入不改变生效值
+	server = nil
+	cfg.SetOverride("server.host", "a")
+	cfg.Set("server.host", "b")
+	if want := []string{"server.host:->a"}; !reflect.DeepEqual(server, want) {
+		t.Errorf("notifications with an override = %v, want %v", server, want)
+	}
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	server = nil
+	cfg.Set("server.port", "1")
+	if len(server) != 0 {
+		t.Errorf("notified after Unsubscribe: %v", server)
+	}
+}
+
+func TestSubscribeLoadOrderAndReentry(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("b", "old")
+	var keys []string
+	cfg.Subscribe("*", func(key, oldValue, newValue string) {
+		keys = append(keys, key)
+		// 回调在锁外执行，可以读取配置
+		if got := cfg.Get(key); got != newValue {
+			t.Errorf("Get(%s) in callback = %q, want %q", key, got, newValue)
+		}
+	})
+	if err := cfg.LoadFromReader(strings.NewReader("c = 3\na = 1\nb = new\n")); err != nil {
+		t.Fatal(err)
+	}
+	// 一次加载的变化按键名顺序通知
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("load notifications = %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// TemplateData 是LoadTemplate执行模板时的数据
+// 模板中以{{.Env.HOME}}、{{.Hostname}}、{{.Data.region}}等形式访问
+type TemplateData struct {
+	// Env 是执行时的环境变量
+	Env map[string]string
+	// Hostname 是本机主机名，获取失败时为空字符串
+	Hostname string
+	// Data 是调用方传入的数据
+	Data interface{}
+}
+
+// LoadTemplate 以text/template执行文件内容，再按LoadFromFile的规则解析结果并合并到文件层
+// 一份模板可以为不同主机生成各自的配置，例如"listen = {{.Hostname}}:8080"。
+// 除TemplateData的字段外，模板还可以调用env "NAME"读取环境变量、default "值" .X在.X为空时取默认值；
+// 引用不存在的map键时执行失败，以免拼写错误静默生成空值。
+// 格式按去掉".tmpl"或".tpl"扩展名后的文件名选择(如app.yaml.tmpl为YAML)，可用WithFileFormat指定。
+// 键的来源记录为模板文件；由于文件内容是模板而不是配置，不记录文件结构，也不加载profile文件，
+// 写回时SaveToFile应使用其它文件名，以免模板被覆盖
+// 参数:
+// - filename: 模板文件路径
+// - data: 模板中通过.Data访问的数据，可以为nil
+// - opts: 严格模式、强制格式等解析设置
+// 返回:
+// - error: 文件操作、模板解析与执行或配置解析错误(如果有)
+func (c *Config) LoadTemplate(filename string, data interface{}, opts ...LoadOption) error {
+	release, err := lockConfigFile(context.Background(), filename, false, false)
+	if err != nil {
+		return err
+	}
+	content, err := readConfigFile(filename)
+	release()
+	if err != nil {
+		return err
+	}
+	rendered, err := renderTemplate(filename, content, data)
+	if err != nil {
+		return err
+	}
+	settings := newLoadSettings(opts)
+	if settings.format == "" {
+		settings.format = detectFormat(trimTemplateExt(trimCompressionExt(filename)), rendered)
+	}
+	return c.loadContent(filename, rendered, false, settings)
+}
+
+// renderTemplate 以TemplateData执行content中的模板
+func renderTemplate(name string, content []byte, data interface{}) ([]byte, error) {
+	tmpl, err := template.New(filepath.Base(name)).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"env": os.Getenv,
+			"default": func(def string, v interface{}) interface{} {
+				if v == nil || v == "" {
+					return def
+				}
+				return v
+			},
+		}).
+		Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, TemplateData{Env: env, Hostname: hostname, Data: data}); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out.Bytes(), nil
+}
+
+// trimTemplateExt 去掉文件名末尾的".tmpl"或".tpl"扩展名
+func trimTemplateExt(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".tmpl", ".tpl":
+		return strings.TrimSuffix(filename, filepath.Ext(filename))
+	}
+	return filename
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	t.Setenv("CONFIG_TEMPLATE_REGION", "eu-west")
+	host, _ := os.Hostname()
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"app.conf.tmpl": "listen = {{.Hostname}}:8080\n" +
+			"region = {{.Env.CONFIG_TEMPLATE_REGION}}\n" +
+			"{{range $i, $r := .Data.Replicas}}replica.{{$i}} = {{$r}}\n{{end}}" +
+			"{{if .Data.Debug}}log.level = debug\n{{end}}",
+		"app-prod.conf.tmpl": "region = never\n",
+	})
+	path := filepath.Join(dir, "app.conf.tmpl")
+
+	type params struct {
+		Replicas []string
+		Debug    bool
+	}
+	cfg, _ := NewConfig()
+	cfg.SetProfile("prod")
+	cfg.Set("name", "app")
+	if err := cfg.LoadTemplate(path, params{Replicas: []string{"db1", "db2"}, Debug: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"name":      "app",
+		"listen":    host + ":8080",
+		"region":    "eu-west", // 不加载profile文件
+		"replica.0": "db1",
+		"replica.1": "db2",
+		"log.level": "debug",
+	}
+	if got := cfg.GetAll(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAll = %v, want %v", got, want)
+	}
+	if src, _ := cfg.Source("region"); src.Kind != SourceFile || src.Name != path {
+		t.Errorf("Source(region) = %+v, want the template file", src)
+	}
+
+	// 同一模板为另一台主机生成不同的配置
+	other, _ := NewConfig()
+	if err := other.LoadTemplate(path, params{Replicas: []string{"db9"}}); err != nil {
+		t.Fatal(err)
+	}
+	if other.Get("replica.0") != "db9" || other.Get("replica.1") != "" || other.Get("log.level") != "" {
+		t.Errorf("second rendering = %v", other.GetAll())
+	}
+}
+
+func TestLoadTemplateFormat(t *testing.T) {
+	t.Setenv("CONFIG_TEMPLATE_USER", "svc")
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		// 去掉.tpl之后按.yaml解析
+		"app.yaml.tpl":  "server:\n  user: {{env \"CONFIG_TEMPLATE_USER\"}}\n  mode: {{default \"dev\" .Data.mode}}\n",
+		"settings.tmpl": `{"port": {{.Data.port}}}`,
+	})
+	cfg, _ := NewConfig()
+	if err := cfg.LoadTemplate(filepath.Join(dir, "app.yaml.tpl"), map[string]string{"mode": ""}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Get("server.user") != "svc" || cfg.Get("server.mode") != "dev" {
+		t.Errorf("YAML template = %v", cfg.GetAll())
+	}
+	if err := cfg.LoadTemplate(filepath.Join(dir, "settings.tmpl"), map[string]int{"port": 9090}, WithFileFormat("json")); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("port"); got != "9090" {
+		t.Errorf("forced JSON template port = %q, want 9090", got)
+	}
+}
+
+func TestLoadTemplateErrors(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"typo.conf.tmpl":   "region = {{.Data.regoin}}\n",
+		"syntax.conf.tmpl": "a = {{.Hostname\n",
+		"bad.json.tmpl":    `{"port": {{.Data.port}}`,
+	})
+	cfg, _ := NewConfig()
+	cfg.Set("keep", "1")
+	data := map[string]string{"region": "eu", "port": "80"}
+
+	// 拼错的map键使执行失败，而不是生成空值
+	err := cfg.LoadTemplate(filepath.Join(dir, "typo.conf.tmpl"), data)
+	if err == nil || !strings.Contains(err.Error(), "regoin") {
+		t.Errorf("misspelled key error = %v", err)
+	}
+	if err := cfg.LoadTemplate(filepath.Join(dir, "syntax.conf.tmpl"), nil); err == nil {
+		t.Error("a template syntax error was accepted")
+	}
+	if err := cfg.LoadTemplate(filepath.Join(dir, "bad.json.tmpl"), data); err == nil {
+		t.Error("rendered invalid JSON was accepted")
+	}
+	if err := cfg.LoadTemplate(filepath.Join(dir, "missing.tmpl"), nil); !os.IsNotExist(err) {
+		t.Errorf("missing template error = %v, want not exist", err)
+	}
+	if got := cfg.GetAll(); len(got) != 1 || got["keep"] != "1" {
+		t.Errorf("failed loads changed the config: %v", got)
+	}
+}
@@ -0,0 +1,822 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LoadFromTOML 从TOML文件加载配置
+// 表与点分键展开为以"."连接的键，数组与表数组元素使用"[i]"下标，
+// 例如[[servers]]中的host对应键"servers[0].host"。
+// 整数统一转换为十进制形式，日期时间保持原始文本。
+// 启用profile时随后加载同目录下的profile文件，规则与LoadFromFile相同。
+// 参数:
+// - filename: TOML文件路径
+// 返回:
+// - error: 文件读取或TOML解析错误(如果有)
+func (c *Config) LoadFromTOML(filename string) error {
+	content, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	values, err := decodeTOML(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	if err := c.mergeValuesFrom(values, KeySource{Kind: SourceFile, Name: filename}); err != nil {
+		return err
+	}
+	return c.loadProfileFile(context.Background(), filename, loadSettings{})
+}
+
+// SaveToTOML 将文件层配置还原为嵌套结构并以TOML格式保存到文件
+// 与SaveToFile一样通过临时文件加重命名原子写入
+// 对象元素组成的数组写为[[表数组]]，其余数组写为行内数组
+// 参数:
+// - filename: 目标文件路径
+// - opts: 文件权限、属主等可选设置
+// 返回:
+// - error: 键路径冲突或文件操作错误(如果有)
+func (c *Config) SaveToTOML(filename string, opts ...SaveOption) error {
+	content, err := encodeTOML(c.LayerValues(LayerFile))
+	if err != nil {
+		return err
+	}
+	return writeBytesAtomic(filename, content, opts...)
+}
+
+// decodeTOML 解析TOML文档并展开为扁平键值对
+func decodeTOML(content []byte) (map[string]string, error) {
+	root, err := parseTOMLDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	flattenValue("", root, values)
+	return values, nil
+}
+
+// parseTOMLDocument 解析TOML文档，返回根表
+func parseTOMLDocument(content []byte) (map[string]interface{}, error) {
+	if !utf8.Valid(content) {
+		return nil, errors.New("toml: invalid UTF-8")
+	}
+	p := &tomlParser{
+		s:       strings.ReplaceAll(string(content), "\r\n", "\n"),
+		line:    1,
+		root:    make(map[string]interface{}),
+		defined: make(map[string]bool),
+	}
+	p.current = p.root
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.root, nil
+}
+
+// tomlParser 是逐字符工作的TOML解析器
+type tomlParser struct {
+	s       string
+	i       int
+	line    int
+	root    map[string]interface{}
+	current map[string]interface{}
+	// defined 记录已通过[table]显式定义的表路径，用于检测重复定义
+	defined map[string]bool
+}
+
+// errorf 返回带当前行号的解析错误
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool { return p.i >= len(p.s) }
+
+func (p *tomlParser) peekByte() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+// skipSpace 跳过行内空白
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+// skipBlank 跳过空白、换行和注释
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.s[p.i] {
+		case ' ', '\t':
+			p.i++
+		case '\n':
+			p.i++
+			p.line++
+		case '#':
+			for !p.eof() && p.s[p.i] != '\n' {
+				p.i++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// expectLineEnd 要求当前行剩余部分只有空白或注释
+func (p *tomlParser) expectLineEnd() error {
+	p.skipSpace()
+	if p.peekByte() == '#' {
+		for !p.eof() && p.s[p.i] != '\n' {
+			p.i++
+		}
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.s[p.i] != '\n' {
+		return p.errorf("unexpected %q at end of line", p.s[p.i:min(len(p.s), p.i+10)])
+	}
+	return nil
+}
+
+// parse 解析整个文档
+func (p *tomlParser) parse() error {
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+		var err error
+		if p.s[p.i] == '[' {
+			err = p.parseTableHeader()
+		} else {
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.expectLineEnd(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseTableHeader 解析[table]或[[array.of.tables]]
+func (p *tomlParser) parseTableHeader() error {
+	isArray := strings.HasPrefix(p.s[p.i:], "[[")
+	if isArray {
+		p.i += 2
+	} else {
+		p.i++
+	}
+	p.skipSpace()
+	path, err := p.parseKeyPath()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.s[p.i:], closing) {
+		return p.errorf("expected %q after table name", closing)
+	}
+	p.i += len(closing)
+
+	parent, err := p.walkTables(p.root, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	fullName := strings.Join(path, ".")
+
+	if isArray {
+		var arr []interface{}
+		switch existing := parent[last].(type) {
+		case nil:
+		case []interface{}:
+			arr = existing
+		default:
+			return p.errorf("%q is already defined and is not an array of tables", fullName)
+		}
+		table := make(map[string]interface{})
+		parent[last] = append(arr, table)
+		p.current = table
+		return nil
+	}
+
+	if p.defined[fullName] {
+		return p.errorf("table %q is defined twice", fullName)
+	}
+	p.defined[fullName] = true
+	switch existing := parent[last].(type) {
+	case nil:
+		table := make(map[string]interface{})
+		parent[last] = table
+		p.current = table
+	case map[string]interface{}:
+		p.current = existing
+	default:
+		return p.errorf("%q is already defined as a value", fullName)
+	}
+	return nil
+}
+
+// walkTables 沿路径定位(必要时创建)表，遇到表数组时使用最后一个元素
+func (p *tomlParser) walkTables(table map[string]interface{}, path []string) (map[string]interface{}, error) {
+	for _, name := range path {
+		switch next := table[name].(type) {
+		case nil:
+			child := make(map[string]interface{})
+			table[name] = child
+			table = child
+		case map[string]interface{}:
+			table = next
+		case []interface{}:
+			if len(next) == 0 {
+				return nil, p.errorf("%q is not a table", name)
+			}
+			child, ok := next[len(next)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("%q is a static array, not an array of tables", name)
+			}
+			table = child
+		default:
+			return nil, p.errorf("%q is already defined as a value", name)
+		}
+	}
+	return table, nil
+}
+
+// parseKeyValue 解析key = value并写入table
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	path, err := p.parseKeyPath()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peekByte() != '=' {
+		return p.errorf("expected '=' after key %q", strings.Join(path, "."))
+	}
+	p.i++
+	p.skipSpace()
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.walkTables(table, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	if _, exists := parent[last]; exists {
+		return p.errorf("key %q is defined twice", strings.Join(path, "."))
+	}
+	parent[last] = value
+	return nil
+}
+
+// parseKeyPath 解析可能带点和引号的键，如a."b.c".d
+func (p *tomlParser) parseKeyPath() ([]string, error) {
+	var path []string
+	for {
+		p.skipSpace()
+		var part string
+		switch ch := p.peekByte(); {
+		case ch == '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		case ch == '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		default:
+			start := p.i
+			for !p.eof() && isBareKeyChar(p.s[p.i]) {
+				p.i++
+			}
+			if start == p.i {
+				return nil, p.errorf("expected key")
+			}
+			part = p.s[start:p.i]
+		}
+		path = append(path, part)
+		p.skipSpace()
+		if p.peekByte() != '.' {
+			return path, nil
+		}
+		p.i++
+	}
+}
+
+// isBareKeyChar 判断字符是否可出现在裸键中
+func isBareKeyChar(ch byte) bool {
+	return ch >= 'A' && ch <= 'Z' || ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-'
+}
+
+// parseValue 解析任意TOML值
+func (p *tomlParser) parseValue() (interface{}, error) {
+	switch {
+	case strings.HasPrefix(p.s[p.i:], `"""`):
+		return p.parseMultilineBasicString()
+	case strings.HasPrefix(p.s[p.i:], `'''`):
+		return p.parseMultilineLiteralString()
+	case p.peekByte() == '"':
+		return p.parseBasicString()
+	case p.peekByte() == '\'':
+		return p.parseLiteralString()
+	case p.peekByte() == '[':
+		return p.parseArray()
+	case p.peekByte() == '{':
+		return p.parseInlineTable()
+	case p.eof():
+		return nil, p.errorf("expected value")
+	}
+	return p.parseBareValue()
+}
+
+// parseArray 解析数组，允许跨行、注释和末尾逗号
+func (p *tomlParser) parseArray() (interface{}, error) {
+	p.i++
+	result := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.peekByte() == ']' {
+			p.i++
+			return result, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+		p.skipBlank()
+		switch p.peekByte() {
+		case ',':
+			p.i++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+// parseInlineTable 解析{a = 1, b.c = 2}形式的行内表
+func (p *tomlParser) parseInlineTable() (interface{}, error) {
+	p.i++
+	result := make(map[string]interface{})
+	p.skipSpace()
+	if p.peekByte() == '}' {
+		p.i++
+		return result, nil
+	}
+	for {
+		p.skipSpace()
+		if err := p.parseKeyValue(result); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peekByte() {
+		case ',':
+			p.i++
+		case '}':
+			p.i++
+			return result, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+// parseBasicString 解析双引号字符串
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.i++
+	var b strings.Builder
+	for {
+		if p.eof() || p.s[p.i] == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		ch := p.s[p.i]
+		switch ch {
+		case '"':
+			p.i++
+			return b.String(), nil
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(ch)
+			p.i++
+		}
+	}
+}
+
+// parseMultilineBasicString 解析"""多行字符串
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	p.i += 3
+	if p.peekByte() == '\n' {
+		p.i++
+		p.line++
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+		if strings.HasPrefix(p.s[p.i:], `"""`) {
+			// 允许结尾处最多两个额外引号属于字符串内容
+			p.i += 3
+			for extra := 0; extra < 2 && p.peekByte() == '"'; extra++ {
+				b.WriteByte('"')
+				p.i++
+			}
+			return b.String(), nil
+		}
+		ch := p.s[p.i]
+		switch {
+		case ch == '\\' && p.isLineEndingBackslash():
+			// 行尾反斜杠：去掉换行及下一行开头的空白
+			p.i++
+			for !p.eof() && strings.IndexByte(" \t\n", p.s[p.i]) >= 0 {
+				if p.s[p.i] == '\n' {
+					p.line++
+				}
+				p.i++
+			}
+		case ch == '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			if ch == '\n' {
+				p.line++
+			}
+			b.WriteByte(ch)
+			p.i++
+		}
+	}
+}
+
+// isLineEndingBackslash 判断当前反斜杠之后是否只有空白直到行尾
+func (p *tomlParser) isLineEndingBackslash() bool {
+	for j := p.i + 1; j < len(p.s); j++ {
+		switch p.s[j] {
+		case ' ', '\t':
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// parseEscape 解析基本字符串中的转义序列
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	p.i++
+	if p.eof() {
+		return p.errorf("unterminated escape sequence")
+	}
+	ch := p.s[p.i]
+	p.i++
+	switch ch {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte(0x1b)
+	case '"', '\\':
+		b.WriteByte(ch)
+	case 'u', 'U':
+		size := 4
+		if ch == 'U' {
+			size = 8
+		}
+		if p.i+size > len(p.s) {
+			return p.errorf("short unicode escape")
+		}
+		n, err := strconv.ParseUint(p.s[p.i:p.i+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(n)) {
+			return p.errorf("invalid unicode escape \\%c%s", ch, p.s[p.i:p.i+size])
+		}
+		b.WriteRune(rune(n))
+		p.i += size
+	default:
+		return p.errorf("invalid escape sequence \\%c", ch)
+	}
+	return nil
+}
+
+// parseLiteralString 解析单引号字面量字符串
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.i++
+	start := p.i
+	for !p.eof() && p.s[p.i] != '\'' {
+		if p.s[p.i] == '\n' {
+			return "", p.errorf("unterminated literal string")
+		}
+		p.i++
+	}
+	if p.eof() {
+		return "", p.errorf("unterminated literal string")
+	}
+	s := p.s[start:p.i]
+	p.i++
+	return s, nil
+}
+
+// parseMultilineLiteralString 解析三个单引号包围的多行字面量字符串
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	p.i += 3
+	if p.peekByte() == '\n' {
+		p.i++
+		p.line++
+	}
+	end := strings.Index(p.s[p.i:], `'''`)
+	if end < 0 {
+		return "", p.errorf("unterminated multi-line literal string")
+	}
+	end += p.i
+	for extra := 0; extra < 2 && end+3 < len(p.s) && p.s[end+3] == '\''; extra++ {
+		end++
+	}
+	s := p.s[p.i:end]
+	p.line += strings.Count(s, "\n")
+	p.i = end + 3
+	return s, nil
+}
+
+// parseBareValue 解析布尔值、数字和日期时间
+func (p *tomlParser) parseBareValue() (interface{}, error) {
+	start := p.i
+	for !p.eof() && strings.IndexByte(" \t\n,]}#", p.s[p.i]) < 0 {
+		p.i++
+	}
+	// 日期与时间之间允许使用空格分隔，如1979-05-27 07:32:00
+	if p.i-start == 10 && p.i+3 < len(p.s) && p.s[p.i] == ' ' &&
+		isDigit(p.s[p.i+1]) && isDigit(p.s[p.i+2]) && p.s[p.i+3] == ':' {
+		p.i++
+		for !p.eof() && strings.IndexByte(" \t\n,]}#", p.s[p.i]) < 0 {
+			p.i++
+		}
+	}
+	token := p.s[start:p.i]
+
+	switch token {
+	case "true", "false":
+		return token, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return token, nil
+	}
+	if isTOMLDateTime(token) {
+		return token, nil
+	}
+	if n, ok := parseTOMLInt(token); ok {
+		return n, nil
+	}
+	if f, ok := parseTOMLFloat(token); ok {
+		return f, nil
+	}
+	return nil, p.errorf("invalid value %q", token)
+}
+
+func isDigit(ch byte) bool { return ch >= '0' && ch <= '9' }
+
+// isTOMLDateTime 粗略判断是否为日期、时间或日期时间
+func isTOMLDateTime(s string) bool {
+	if len(s) >= 10 && isDigit(s[0]) && s[4] == '-' && s[7] == '-' {
+		return true
+	}
+	return len(s) >= 8 && isDigit(s[0]) && s[2] == ':' && s[5] == ':'
+}
+
+// parseTOMLInt 解析十进制、十六进制、八进制和二进制整数，返回十进制文本
+func parseTOMLInt(s string) (string, bool) {
+	if s == "" || strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") || strings.Contains(s, "__") {
+		return "", false
+	}
+	clean := strings.ReplaceAll(s, "_", "")
+	base := 10
+	switch {
+	case strings.HasPrefix(clean, "0x"):
+		base, clean = 16, clean[2:]
+	case strings.HasPrefix(clean, "0o"):
+		base, clean = 8, clean[2:]
+	case strings.HasPrefix(clean, "0b"):
+		base, clean = 2, clean[2:]
+	default:
+		digits := strings.TrimLeft(clean, "+-")
+		if len(digits) > 1 && digits[0] == '0' {
+			return "", false
+		}
+	}
+	n, err := strconv.ParseInt(clean, base, 64)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatInt(n, 10), true
+}
+
+// parseTOMLFloat 校验浮点数并返回去掉下划线后的文本
+func parseTOMLFloat(s string) (string, bool) {
+	if strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") || strings.Contains(s, "__") {
+		return "", false
+	}
+	clean := strings.ReplaceAll(s, "_", "")
+	if strings.HasPrefix(clean, ".") || strings.HasSuffix(clean, ".") || strings.Contains(clean, ".e") {
+		return "", false
+	}
+	// 与整数相同，整数部分不允许前导零，如07或01.5
+	if digits := strings.TrimLeft(clean, "+-"); len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]) {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(clean, 64); err != nil {
+		return "", false
+	}
+	return strings.TrimPrefix(clean, "+"), true
+}
+
+// encodeTOML 将扁平键值对编码为TOML文档
+func encodeTOML(data map[string]string) ([]byte, error) {
+	tree, err := nestValues(data)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	writeTOMLTable(&b, tree, nil, false)
+	return []byte(strings.TrimLeft(b.String(), "\n")), nil
+}
+
+// writeTOMLTable 写出一个表：先写简单键值，再写子表和表数组
+// path为表路径，isArrayElem表示该表是表数组的一个元素
+func writeTOMLTable(b *strings.Builder, table map[string]interface{}, path []string, isArrayElem bool) {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var simple, subTables, tableArrays []string
+	for _, k := range keys {
+		switch v := table[k].(type) {
+		case map[string]interface{}:
+			subTables = append(subTables, k)
+		case []interface{}:
+			if isTableArray(v) {
+				tableArrays = append(tableArrays, k)
+			} else {
+				simple = append(simple, k)
+			}
+		default:
+			simple = append(simple, k)
+		}
+	}
+
+	if len(path) > 0 && (isArrayElem || len(simple) > 0 || len(table) == 0) {
+		b.WriteString("\n")
+		if isArrayElem {
+			b.WriteString("[[" + tomlKeyPath(path) + "]]\n")
+		} else {
+			b.WriteString("[" + tomlKeyPath(path) + "]\n")
+		}
+	}
+	for _, k := range simple {
+		b.WriteString(tomlKey(k))
+		b.WriteString(" = ")
+		b.WriteString(tomlInlineValue(table[k]))
+		b.WriteString("\n")
+	}
+	for _, k := range subTables {
+		writeTOMLTable(b, table[k].(map[string]interface{}), appendPath(path, k), false)
+	}
+	for _, k := range tableArrays {
+		for _, elem := range table[k].([]interface{}) {
+			writeTOMLTable(b, elem.(map[string]interface{}), appendPath(path, k), true)
+		}
+	}
+}
+
+// appendPath 返回追加了name的新路径切片，不修改原切片
+func appendPath(path []string, name string) []string {
+	next := make([]string, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, name)
+}
+
+// isTableArray 判断数组是否全部由表组成
+func isTableArray(arr []interface{}) bool {
+	if len(arr) == 0 {
+		return false
+	}
+	for _, elem := range arr {
+		if _, ok := elem.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlInlineValue 将值格式化为行内TOML表示
+func tomlInlineValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return `""`
+	case string:
+		return tomlQuote(val)
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, elem := range val {
+			parts[i] = tomlInlineValue(elem)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = tomlKey(k) + " = " + tomlInlineValue(val[k])
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	}
+	return formatScalar(v)
+}
+
+// tomlKeyPath 将表路径格式化为点分TOML键
+func tomlKeyPath(path []string) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = tomlKey(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlKey 格式化单个键，非裸键字符时加引号
+func tomlKey(k string) string {
+	if k == "" {
+		return `""`
+	}
+	for i := 0; i < len(k); i++ {
+		if !isBareKeyChar(k[i]) {
+			return tomlQuote(k)
+		}
+	}
+	return k
+}
+
+// tomlQuote 将字符串格式化为TOML基本字符串
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
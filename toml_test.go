@@ -0,0 +1,97 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "tables and dotted keys",
+			input: "title = \"app\" # comment\n[server]\nhost = \"localhost\"\nlimits.max = 10\n[server.tls]\nenabled = true\n",
+			want:  map[string]string{"title": "app", "server.host": "localhost", "server.limits.max": "10", "server.tls.enabled": "true"},
+		},
+		{
+			name:  "array of tables",
+			input: "[[servers]]\nhost = \"a\"\n[[servers]]\nhost = \"b\"\n",
+			want:  map[string]string{"servers[0].host": "a", "servers[1].host": "b"},
+		},
+		{
+			name:  "arrays and inline tables",
+			input: "ports = [\n  80,\n  443, # https\n]\nowner = {name = \"x\", id.n = 1}\n",
+			want:  map[string]string{"ports[0]": "80", "ports[1]": "443", "owner.name": "x", "owner.id.n": "1"},
+		},
+		{
+			name:  "integers",
+			input: "dec = 1_000\nhex = 0xff\noct = 0o17\nbin = 0b101\nneg = -3\n",
+			want:  map[string]string{"dec": "1000", "hex": "255", "oct": "15", "bin": "5", "neg": "-3"},
+		},
+		{
+			name:  "floats and dates",
+			input: "f = 1_000.5\ne = 1e3\nz = 0.5\nd = 1979-05-27\ndt = 1979-05-27T07:32:00Z\n",
+			want:  map[string]string{"f": "1000.5", "e": "1e3", "z": "0.5", "d": "1979-05-27", "dt": "1979-05-27T07:32:00Z"},
+		},
+		{
+			name:  "strings",
+			input: "basic = \"a\\tb\\u00e9\"\nlit = 'C:\\path'\nml = \"\"\"\nline1\nline2\"\"\"\nfold = \"\"\"a \\\n   b\"\"\"\nmllit = '''\nraw\\n'''\n",
+			want:  map[string]string{"basic": "a\tbé", "lit": `C:\path`, "ml": "line1\nline2", "fold": "a b", "mllit": `raw\n`},
+		},
+		{
+			name:  "quoted keys",
+			input: "\"a.b\" = 1\nsite.\"x.y\" = 2\n",
+			want:  map[string]string{"a.b": "1", "site.x.y": "2"},
+		},
+		{name: "duplicate key", input: "a = 1\na = 2\n", wantErr: true},
+		{name: "duplicate table", input: "[a]\nx = 1\n[a]\ny = 2\n", wantErr: true},
+		{name: "missing value", input: "a =\n", wantErr: true},
+		{name: "trailing content", input: "a = 1 b\n", wantErr: true},
+		{name: "unterminated string", input: "a = \"x\n", wantErr: true},
+		{name: "invalid escape", input: "a = \"\\q\"\n", wantErr: true},
+		{name: "invalid number", input: "a = 1__0\n", wantErr: true},
+		{name: "leading zero", input: "a = 07\n", wantErr: true},
+		{name: "leading zero float", input: "a = 01.5\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTOML([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeTOML error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeTOML = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"title":           "app",
+		"server.host":     "localhost",
+		"server.port":     "8080",
+		"ports[0]":        "80",
+		"ports[1]":        "443",
+		"servers[0].host": "a",
+		"servers[1].host": "b",
+		"quoted":          "say \"hi\"\n",
+		"a.b c":           "spaces",
+		"version":         "1.0.0",
+	}
+	content, err := encodeTOML(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeTOML(content)
+	if err != nil {
+		t.Fatalf("decodeTOML(%s): %v", content, err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("round trip of\n%s= %q, want %q", content, got, values)
+	}
+}
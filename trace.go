@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Tracer 为配置的加载创建span，由SetTracer设置
+// 接口只包含本包用到的部分，OpenTelemetry的trace.Tracer可以用几行代码适配：
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, config.Span) {
+//		ctx, span := o.t.Start(ctx, name, trace.WithAttributes(toKeyValues(attrs)...))
+//		return ctx, otelSpan{span}
+//	}
+//
+// 其中otelSpan的End在err非nil时调用RecordError与SetStatus(codes.Error, ...)后结束span
+type Tracer interface {
+	// Start 创建名为name的span，返回的ctx作为之后操作的父context
+	Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span)
+}
+
+// Span 是Tracer创建的span
+type Span interface {
+	// AddEvent 在span上记录事件
+	AddEvent(name string, attrs ...slog.Attr)
+	// End 结束span，操作失败时err非nil
+	End(err error)
+}
+
+// SetTracer 设置创建span的Tracer，为nil时停止跟踪
+// 设置后以下操作各创建一个span，出错时span以该错误结束：
+// - LoadFromFile与LoadFromFileContext："config.LoadFromFile"，属性config.file
+// - 配置源的加载(LoadFromProvider、LoadFromURL、WatchProvider、AddProvider等)：
+// "config.provider.Load"，属性config.provider；ctx传给ContextProvider，远程请求因此成为子span
+// - Watch、WatchProvider与AddProvider在后台的每次重新加载："config.Reload"，属性config.source；
+// 失败之后第一次成功时带有"config.reconnected"事件，以便在跟踪中看到配置源恢复的时间
+// 参数:
+// - t: Tracer实现
+func (c *Config) SetTracer(t Tracer) {
+	if t == nil {
+		c.tracer.Store(nil)
+		return
+	}
+	c.tracer.Store(&t)
+}
+
+// startSpan 在设置了Tracer时创建span，否则返回ctx与不做任何事的span
+func (c *Config) startSpan(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span) {
+	t := c.tracer.Load()
+	if t == nil {
+		return ctx, noopSpan{}
+	}
+	return (*t).Start(ctx, name, attrs...)
+}
+
+// traceReload 为一次后台重新加载记录span，source此前失败而这次成功时添加重连事件
+func (c *Config) traceReload(source string, err error) {
+	var recovered bool
+	if err != nil {
+		c.reloadFailing.Store(source, true)
+	} else {
+		_, recovered = c.reloadFailing.LoadAndDelete(source)
+	}
+	if c.tracer.Load() == nil {
+		return
+	}
+	_, span := c.startSpan(context.Background(), "config.Reload", slog.String("config.source", source))
+	if recovered {
+		span.AddEvent("config.reconnected")
+	}
+	span.End(err)
+}
+
+// noopSpan 是未设置Tracer时使用的span
+type noopSpan struct{}
+
+func (noopSpan) AddEvent(string, ...slog.Attr) {}
+func (noopSpan) End(error)                     {}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// recordedSpan 是recordingTracer记录的一个span
+type recordedSpan struct {
+	name   string
+	attrs  map[string]string
+	events []string
+	err    error
+	parent string
+}
+
+// spanKey 是recordingTracer在context中保存当前span名称的键
+type spanKey struct{}
+
+// recordingTracer 记录所有结束的span
+type recordingTracer struct {
+	mutex sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span) {
+	s := &recordedSpan{name: name, attrs: make(map[string]string)}
+	s.parent, _ = ctx.Value(spanKey{}).(string)
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value.String()
+	}
+	return context.WithValue(ctx, spanKey{}, name), &tracedSpan{t: t, s: s}
+}
+
+// ended 返回已结束的span
+func (t *recordingTracer) ended() []*recordedSpan {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return append([]*recordedSpan(nil), t.spans...)
+}
+
+type tracedSpan struct {
+	t *recordingTracer
+	s *recordedSpan
+}
+
+func (s *tracedSpan) AddEvent(name string, _ ...slog.Attr) { s.s.events = append(s.s.events, name) }
+
+func (s *tracedSpan) End(err error) {
+	s.s.err = err
+	s.t.mutex.Lock()
+	defer s.t.mutex.Unlock()
+	s.t.spans = append(s.t.spans, s.s)
+}
+
+// ctxProvider 是记录LoadContext收到的父span的配置源
+type ctxProvider struct {
+	*pushProvider
+	parent string
+}
+
+func (p *ctxProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	p.parent, _ = ctx.Value(spanKey{}).(string)
+	return p.Load()
+}
+
+func TestSetTracer(t *testing.T) {
+	tr := &recordingTracer{}
+	cfg, _ := NewConfig()
+	cfg.SetTracer(tr)
+
+	path := filepath.Join(t.TempDir(), "app.conf")
+	os.WriteFile(path, []byte("port = 80\n"), 0o644)
+	cfg.LoadFromFile(path)
+	cfg.LoadFromFile(filepath.Join(t.TempDir(), "missing.conf"))
+
+	p := &ctxProvider{pushProvider: newPushProvider(map[string]string{"x": "1"})}
+	done := reloads(cfg)
+	if err := cfg.AddProvider(p); err != nil {
+		t.Fatal(err)
+	}
+	if p.parent != "config.provider.Load" {
+		t.Errorf("LoadContext parent span = %q, want the provider span", p.parent)
+	}
+	p.push(Update{Err: errors.New("down")})
+	waitReload(t, done)
+	p.push(Update{Values: map[string]string{"x": "2"}})
+	waitReload(t, done)
+	p.push(Update{Values: map[string]string{"x": "3"}})
+	waitReload(t, done)
+	cfg.Close()
+
+	spans := tr.ended()
+	if len(spans) != 6 {
+		t.Fatalf("recorded %d spans, want 6", len(spans))
+	}
+	if s := spans[0]; s.name != "config.LoadFromFile" || s.attrs["config.file"] != path || s.err != nil {
+		t.Errorf("file span = %+v", s)
+	}
+	if s := spans[1]; s.name != "config.LoadFromFile" || s.err == nil {
+		t.Errorf("missing file span = %+v, want the error", s)
+	}
+	if s := spans[2]; s.name != "config.provider.Load" || s.attrs["config.provider"] != "config.ctxProvider" {
+		t.Errorf("provider span = %+v", s)
+	}
+	// 失败之后第一次成功的重新加载带有重连事件
+	reload := spans[3:]
+	if reload[0].name != "config.Reload" || reload[0].err == nil || len(reload[0].events) != 0 {
+		t.Errorf("failed reload span = %+v", reload[0])
+	}
+	if len(reload[1].events) != 1 || reload[1].events[0] != "config.reconnected" || reload[1].err != nil {
+		t.Errorf("recovered reload span = %+v, want the reconnected event", reload[1])
+	}
+	if len(reload[2].events) != 0 {
+		t.Errorf("later reload span = %+v, want no event", reload[2])
+	}
+
+	cfg.SetTracer(nil)
+	cfg.LoadFromFile(path)
+	if len(tr.ended()) != 6 {
+		t.Error("spans recorded after SetTracer(nil)")
+	}
+}
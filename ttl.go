@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// ttlEntry 是SetWithTTL写入的一个待过期的值
+type ttlEntry struct {
+	timer *time.Timer
+}
+
+// SetWithTTL 写入一个在ttl之后自动过期的值
+// 值写入覆盖层，优先于文件、环境变量与默认值；过期后从覆盖层删除，
+// 键回退到下层的值(没有下层值时键不再存在)，订阅者收到相应的变化通知。
+// 适合运行时临时推送的功能开关等覆盖值。
+// 过期前再次对同一个键调用SetWithTTL会重新计时；通过MergeLayer、ClearLayer、Restore等
+// 修改覆盖层中的该键会取消过期。配置冻结后到期的值不再删除。
+// 参数:
+// - key: 配置键
+// - value: 要存储的值
+// - ttl: 有效期，必须大于0
+// - onExpire: 值过期删除后调用的回调，可以为nil；回调在独立的goroutine中执行
+// 返回:
+// - error: key为空或ttl不大于0时返回错误，配置已冻结时返回ErrFrozen
+func (c *Config) SetWithTTL(key, value string, ttl time.Duration, onExpire func(key string)) error {
+	if key == "" {
+		return errors.New("key cannot be empty")
+	}
+	if ttl <= 0 {
+		return errors.New("ttl must be positive")
+	}
+	key = c.normKey(key)
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	changes := c.writeLayerLocked(LayerOverride, key, value, false, nil)
+	e := &ttlEntry{}
+	e.timer = time.AfterFunc(ttl, func() {
+		c.expire(key, e, onExpire)
+	})
+	if c.ttls == nil {
+		c.ttls = make(map[string]*ttlEntry)
+	}
+	c.ttls[key] = e
+	c.unlockNotify(changes)
+	return nil
+}
+
+// expire 删除到期的值；e已被替换或取消时什么都不做
+func (c *Config) expire(key string, e *ttlEntry, onExpire func(key string)) {
+	c.lock()
+	if c.ttls[key] != e || c.frozen {
+		c.mutex.Unlock()
+		return
+	}
+	changes := c.writeLayerLocked(LayerOverride, key, "", true, nil)
+	c.unlockNotify(changes)
+	if onExpire != nil {
+		onExpire(key)
+	}
+}
+
+// cancelTTLLocked 取消键的过期计时，调用方须持有写锁
+func (c *Config) cancelTTLLocked(key string) {
+	if e, ok := c.ttls[key]; ok {
+		e.timer.Stop()
+		delete(c.ttls, key)
+	}
+}
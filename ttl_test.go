@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetWithTTL(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("feature.x", "false")
+	var mu sync.Mutex
+	var changes []string
+	cfg.Subscribe("feature.*", func(key, oldValue, newValue string) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, key+":"+oldValue+"->"+newValue)
+	})
+	expired := make(chan string, 2)
+	if err := cfg.SetWithTTL("feature.x", "true", 20*time.Millisecond, func(key string) { expired <- key }); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetWithTTL("feature.y", "on", 20*time.Millisecond, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("feature.x"); got != "true" {
+		t.Errorf("Get(feature.x) before expiry = %q, want true", got)
+	}
+
+	select {
+	case key := <-expired:
+		if key != "feature.x" {
+			t.Errorf("onExpire key = %q", key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("value did not expire")
+	}
+	// 过期后回退到下层的值，没有下层值的键被删除
+	if got := cfg.Get("feature.x"); got != "false" {
+		t.Errorf("Get(feature.x) after expiry = %q, want the file value", got)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for cfg.Has("feature.y") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cfg.Has("feature.y") {
+		t.Error("feature.y still set after expiry")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) < 3 || changes[0] != "feature.x:false->true" {
+		t.Errorf("notifications = %v", changes)
+	}
+}
+
+func TestSetWithTTLCancel(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.SetWithTTL("a", "1", 20*time.Millisecond, nil)
+	// 重新计时
+	cfg.SetWithTTL("a", "2", time.Hour, nil)
+	// 通过覆盖层的其它写入取消过期
+	cfg.SetWithTTL("b", "1", 20*time.Millisecond, nil)
+	cfg.MergeLayer(LayerOverride, map[string]string{"b": "kept"})
+	time.Sleep(60 * time.Millisecond)
+	if got := cfg.Get("a"); got != "2" {
+		t.Errorf("Get(a) = %q, want the renewed value", got)
+	}
+	if got := cfg.Get("b"); got != "kept" {
+		t.Errorf("Get(b) = %q, want the value written with MergeLayer", got)
+	}
+
+	if err := cfg.SetWithTTL("", "1", time.Second, nil); err == nil {
+		t.Error("SetWithTTL with an empty key succeeded")
+	}
+	if err := cfg.SetWithTTL("c", "1", 0, nil); err == nil {
+		t.Error("SetWithTTL with a zero ttl succeeded")
+	}
+	cfg.Freeze()
+	if err := cfg.SetWithTTL("c", "1", time.Second, nil); !errors.Is(err, ErrFrozen) {
+		t.Errorf("SetWithTTL on a frozen config error = %v, want ErrFrozen", err)
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import "errors"
+
+// txnOp 是事务中缓存的一次写操作
+type txnOp struct {
+	key   string
+	value string
+	del   bool
+}
+
+// Txn 是Update中使用的事务，缓存Set与Delete操作直到事务提交
+// Txn只能在传给Update的函数内使用
+type Txn struct {
+	c    *Config
+	ops  []txnOp
+	keys map[string]struct{} // 事务涉及的键
+}
+
+// Set 在事务中写入文件层键值
+// 参数:
+// - key: 配置键
+// - value: 要存储的值
+// 返回:
+// - error: 当key为空时返回错误
+func (tx *Txn) Set(key, value string) error {
+	if key == "" {
+		return errors.New("key cannot be empty")
+	}
+	tx.record(txnOp{key: tx.c.normKey(key), value: value})
+	return nil
+}
+
+// Delete 在事务中从文件层删除键
+// 参数:
+// - key: 要删除的配置键
+func (tx *Txn) Delete(key string) {
+	tx.record(txnOp{key: tx.c.normKey(key), del: true})
+}
+
+func (tx *Txn) record(op txnOp) {
+	tx.ops = append(tx.ops, op)
+	if tx.keys == nil {
+		tx.keys = make(map[string]struct{})
+	}
+	tx.keys[op.key] = struct{}{}
+}
+
+// Update 在事务中批量修改配置
+// fn中通过tx缓存的Set与Delete在fn返回nil后一次性在同一次加锁中应用，
+// 订阅者针对每个键只收到一次合并后的变化；fn返回错误时所有操作被丢弃。
+// 与Config.Set、Config.Delete相同，传入Alias登记的旧键时操作作用于新键；
+// 启用EnableWriteBack时每个键的最终结果先写入配置源，任何一次写入失败时本地配置保持不变并返回错误，
+// 此前已写入配置源的键不会回滚
+// 参数:
+// - fn: 事务函数
+// 返回:
+// - error: fn返回的错误或写回错误，配置已冻结时返回ErrFrozen
+func (c *Config) Update(fn func(tx *Txn) error) error {
+	return c.UpdateAs("", fn)
+}
+
+// UpdateAs 与Update相同，并把actor记录为审计日志中这些修改的操作者
+// 参数:
+// - actor: 操作者标识，如用户名或服务名
+// - fn: 事务函数
+// 返回:
+// - error: fn返回的错误或写回错误，配置已冻结时返回ErrFrozen
+func (c *Config) UpdateAs(actor string, fn func(tx *Txn) error) error {
+	tx := &Txn{c: c}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if len(tx.ops) == 0 {
+		return nil
+	}
+
+	aliases := c.currentAliases()
+	keys := make(map[string]struct{}, len(tx.keys))
+	for i := range tx.ops {
+		// 与Set、Delete相同，传入旧键时作用于新键
+		tx.ops[i].key = aliases.canonicalKey(tx.ops[i].key)
+		keys[tx.ops[i].key] = struct{}{}
+	}
+	if err := c.writeBackTxn(tx.ops); err != nil {
+		return err
+	}
+
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	for _, op := range tx.ops {
+		if op.del {
+			// Delete同时删除以旧键存储的值，旧键的生效值同样可能变化
+			for _, old := range c.alias.aliasedBy[op.key] {
+				keys[old] = struct{}{}
+			}
+		}
+	}
+	before := make(map[string]change, len(keys))
+	for key := range keys {
+		old, ok := c.resolveLocked(key)
+		before[key] = change{key: key, old: old, oldOK: ok}
+	}
+	for _, op := range tx.ops {
+		if !op.del {
+			c.setLocked(op.key, op.value, nil)
+			continue
+		}
+		c.deleteLocked(op.key, nil)
+		for _, old := range c.alias.aliasedBy[op.key] {
+			c.deleteLocked(old, nil)
+		}
+	}
+	var changes []change
+	for key, ch := range before {
+		ch.new, ch.newOK = c.resolveLocked(key)
+		if ch.newOK != ch.oldOK || ch.new != ch.old {
+			ch.actor = actor
+			changes = append(changes, ch)
+		}
+	}
+	c.unlockNotify(changes)
+	return nil
+}
+
+// writeBackTxn 在启用写回时按操作顺序把每个键的最后一次操作写入配置源，须在不持有锁时调用
+func (c *Config) writeBackTxn(ops []txnOp) error {
+	last := make(map[string]int, len(ops))
+	for i, op := range ops {
+		last[op.key] = i
+	}
+	for i, op := range ops {
+		if last[op.key] != i {
+			continue
+		}
+		err := c.writeBackTo(op.key, func(p WritableProvider) error {
+			if op.del {
+				return p.Delete(op.key)
+			}
+			return p.Put(op.key, op.value)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestUpdateAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      func(tx *Txn) error
+		file    map[string]string // 事务之后文件层的内容
+		changed []string          // 收到通知的键
+	}{
+		{
+			name:    "set old key",
+			fn:      func(tx *Txn) error { return tx.Set("old", "2") },
+			file:    map[string]string{"new": "2", "old": "legacy"},
+			changed: []string{"new"},
+		},
+		{
+			name:    "delete old key",
+			fn:      func(tx *Txn) error { tx.Delete("old"); return nil },
+			file:    map[string]string{},
+			changed: []string{"new", "old"},
+		},
+		{
+			name: "set old then delete new",
+			fn: func(tx *Txn) error {
+				tx.Set("old", "3")
+				tx.Delete("new")
+				return nil
+			},
+			file:    map[string]string{},
+			changed: []string{"new", "old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := NewConfig()
+			cfg.Set("new", "1")
+			cfg.Set("old", "legacy")
+			if err := cfg.Alias("old", "new"); err != nil {
+				t.Fatal(err)
+			}
+			var changed []string
+			cfg.Subscribe("*", func(key, _, _ string) { changed = append(changed, key) })
+			if err := cfg.Update(tt.fn); err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.LayerValues(LayerFile); !reflect.DeepEqual(got, tt.file) {
+				t.Errorf("file layer = %v, want %v", got, tt.file)
+			}
+			want, wantOK := tt.file["new"]
+			for _, key := range []string{"new", "old"} {
+				if got, ok := cfg.Lookup(key); got != want || ok != wantOK {
+					t.Errorf("Lookup(%q) = %q, %v, want %q, %v", key, got, ok, want, wantOK)
+				}
+			}
+			sort.Strings(changed)
+			if !reflect.DeepEqual(changed, tt.changed) {
+				t.Errorf("changed keys = %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestCaseInsensitiveKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(cfg *Config) error
+	}{
+		{"Set", func(cfg *Config) error { return cfg.Set("SERVER.PORT", "9090") }},
+		{"Update", func(cfg *Config) error {
+			return cfg.Update(func(tx *Txn) error { return tx.Set("SERVER.PORT", "9090") })
+		}},
+		{"UpdateAs", func(cfg *Config) error {
+			return cfg.UpdateAs("admin", func(tx *Txn) error { return tx.Set("Server.Port", "9090") })
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := NewConfigWithOptions(WithCaseInsensitiveKeys())
+			cfg.EnableHistory(10)
+			var fired []string
+			cfg.Subscribe("server.port", func(key, _, _ string) { fired = append(fired, key) })
+			if err := tt.write(cfg); err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"server.port"}; !reflect.DeepEqual(fired, want) {
+				t.Errorf("Subscribe fired for %v, want %v", fired, want)
+			}
+			if got := cfg.LayerValues(LayerFile); !reflect.DeepEqual(got, map[string]string{"server.port": "9090"}) {
+				t.Errorf("file layer = %v", got)
+			}
+			if h := cfg.History("SERVER.Port"); len(h) != 1 || h[0].New != "9090" {
+				t.Errorf("History = %+v, want one entry", h)
+			}
+			if got := cfg.GetStringMap("SERVER"); got["port"] != "9090" {
+				t.Errorf("GetStringMap = %v", got)
+			}
+			if err := cfg.Update(func(tx *Txn) error { tx.Delete("SERVER.PORT"); return nil }); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Has("server.port") || len(fired) != 2 {
+				t.Errorf("Delete through Update: Has = %v, notifications = %v", cfg.Has("server.port"), fired)
+			}
+		})
+	}
+}
+
+// recordingProvider 是记录写回操作的WritableProvider，fail中的键写入失败
+type recordingProvider struct {
+	ops  []string
+	fail map[string]bool
+}
+
+func (p *recordingProvider) Load() (map[string]string, error) { return nil, nil }
+func (p *recordingProvider) Watch(chan<- Update) error        { return nil }
+
+func (p *recordingProvider) Put(key, value string) error {
+	if p.fail[key] {
+		return errors.New("unavailable")
+	}
+	p.ops = append(p.ops, "put "+key+"="+value)
+	return nil
+}
+
+func (p *recordingProvider) Delete(key string) error {
+	if p.fail[key] {
+		return errors.New("unavailable")
+	}
+	p.ops = append(p.ops, "delete "+key)
+	return nil
+}
+
+func TestUpdateWriteBack(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      func(tx *Txn) error
+		fail    map[string]bool
+		remote  []string
+		file    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "final op per key in order",
+			fn: func(tx *Txn) error {
+				tx.Set("b", "1")
+				tx.Set("a", "1")
+				tx.Set("b", "2")
+				tx.Delete("keep")
+				return nil
+			},
+			remote: []string{"put a=1", "put b=2", "delete keep"},
+			file:   map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:   "alias written as new key",
+			fn:     func(tx *Txn) error { return tx.Set("old", "3") },
+			remote: []string{"put new=3"},
+			file:   map[string]string{"keep": "0", "new": "3"},
+		},
+		{
+			name: "failure leaves local unchanged",
+			fn: func(tx *Txn) error {
+				tx.Set("a", "1")
+				tx.Set("b", "1")
+				return nil
+			},
+			fail:    map[string]bool{"b": true},
+			remote:  []string{"put a=1"},
+			file:    map[string]string{"keep": "0"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := NewConfig()
+			cfg.Set("keep", "0")
+			cfg.Alias("old", "new")
+			p := &recordingProvider{fail: tt.fail}
+			cfg.EnableWriteBack(p)
+			if err := cfg.Update(tt.fn); (err != nil) != tt.wantErr {
+				t.Fatalf("Update error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(p.ops, tt.remote) {
+				t.Errorf("remote ops = %v, want %v", p.ops, tt.remote)
+			}
+			if got := cfg.LayerValues(LayerFile); !reflect.DeepEqual(got, tt.file) {
+				t.Errorf("file layer = %v, want %v", got, tt.file)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("a", "1")
+	cfg.Set("b", "2")
+	var notes []string
+	cfg.Subscribe("*", func(key, oldValue, newValue string) {
+		notes = append(notes, key+":"+oldValue+"->"+newValue)
+	})
+
+	err := cfg.Update(func(tx *Txn) error {
+		tx.Set("a", "10")
+		tx.Set("a", "11")
+		tx.Delete("b")
+		tx.Set("c", "3")
+		tx.Set("d", "x")
+		tx.Delete("d")
+		// 事务提交前配置不变
+		if got := cfg.Get("a"); got != "1" {
+			t.Errorf("Get(a) inside the transaction = %q, want 1", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"a": "11", "c": "3"}; !reflect.DeepEqual(cfg.GetAll(), want) {
+		t.Errorf("after Update = %v, want %v", cfg.GetAll(), want)
+	}
+	// 每个键只通知一次合并后的变化，最终不变的d不通知
+	if want := []string{"a:1->11", "b:2->", "c:->3"}; !reflect.DeepEqual(notes, want) {
+		t.Errorf("notifications = %v, want %v", notes, want)
+	}
+
+	notes = nil
+	errAbort := errors.New("abort")
+	if err := cfg.Update(func(tx *Txn) error {
+		tx.Set("a", "lost")
+		return errAbort
+	}); !errors.Is(err, errAbort) {
+		t.Errorf("Update error = %v, want the function's error", err)
+	}
+	if err := cfg.Update(func(tx *Txn) error { return tx.Set("", "x") }); err == nil {
+		t.Error("Update with an empty key succeeded")
+	}
+	if got := cfg.Get("a"); got != "11" || len(notes) != 0 {
+		t.Errorf("after aborted updates a = %q, notifications %v", got, notes)
+	}
+
+	cfg.Freeze()
+	if err := cfg.Update(func(tx *Txn) error { return tx.Set("a", "1") }); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Update on a frozen config error = %v, want ErrFrozen", err)
+	}
+}
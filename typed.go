@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// WithTypedValues 让Config在加载JSON、YAML与TOML文件时记录值的原始类型，供GetValue使用
+// 值仍以字符串存储，Get等方法的结果不变；记录的类型只在键的当前值与加载时的值相同时生效，
+// 因此Set等修改之后GetValue返回新的字符串值。LoadFromFile、LoadFromJSON、LoadFromYAML、LoadFromReader
+// 与Watch的重新加载都会记录类型，配置源的值不记录
+func WithTypedValues() ConfigOption {
+	return func(c *Config) {
+		c.typedValues = true
+		c.types = make(map[string]interface{})
+	}
+}
+
+// GetValue 获取键的值，保留加载时的类型
+// 键为叶子时，使用WithTypedValues创建且值来自JSON、YAML或TOML文件时返回int64、float64、bool或nil，
+// 否则返回string；键为对象或数组的前缀(如"server"、"servers")时，返回由其下各键还原的
+// map[string]interface{}或[]interface{}，叶子的规则相同。值经过解密、插值、profile与类型转换等处理，与Get一致
+// 参数:
+// - key: 配置键或键前缀
+// 返回:
+// - interface{}: 值
+// - bool: 键与以它为前缀的键都不存在时返回false
+func (c *Config) GetValue(key string) (interface{}, bool) {
+	key = c.normKey(key)
+	if val, ok := c.lookup(key); ok {
+		c.mutex.RLock()
+		t, typed := c.types[key]
+		c.mutex.RUnlock()
+		return typedLeaf(val, t, typed), true
+	}
+	segs, err := parseKeyPathSep(key, c.sep())
+	if err != nil {
+		return nil, false
+	}
+	all := c.allValues()
+	c.mutex.RLock()
+	var root interface{}
+	for k, val := range all {
+		if !strings.HasPrefix(k, key+c.sep()) && !strings.HasPrefix(k, key+"[") {
+			continue
+		}
+		path, err := parseKeyPathSep(k, c.sep())
+		if err != nil {
+			continue
+		}
+		t, typed := c.types[k]
+		if node, err := insertPath(root, path, typedLeaf(val, t, typed)); err == nil {
+			root = node
+		}
+	}
+	c.mutex.RUnlock()
+	node := root
+	for _, seg := range segs {
+		switch {
+		case seg.isIndex:
+			arr, _ := node.([]interface{})
+			if seg.index >= len(arr) {
+				return nil, false
+			}
+			node = arr[seg.index]
+		default:
+			m, _ := node.(map[string]interface{})
+			if node = m[seg.name]; node == nil {
+				return nil, false
+			}
+		}
+	}
+	return node, node != nil
+}
+
+// decodeContent 按格式解析内容，启用WithTypedValues且格式保留类型时同时返回规范化键对应的值类型，否则types为nil
+// 类型不在这里写入Config，由合并内容的调用方在同一次加锁中、检查通过之后以recordTypesLocked记录
+func (c *Config) decodeContent(format string, content []byte) (values map[string]string, types map[string]interface{}, err error) {
+	if !c.typedValues {
+		values, err = decodeFormat(format, content)
+		return values, nil, err
+	}
+	var root map[string]interface{}
+	content = trimBOM(content)
+	switch format {
+	case "json":
+		root, err = parseJSONDocument(content)
+	case "yaml":
+		root, err = parseYAMLDocument(content)
+	case "toml":
+		root, err = parseTOMLDocument(content)
+	default:
+		values, err = decodeFormat(format, content)
+		return values, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	values = make(map[string]string)
+	flattenValue("", root, values)
+	raw := make(map[string]interface{})
+	flattenTypes("", root, raw)
+	types = make(map[string]interface{}, len(raw))
+	for k, t := range raw {
+		types[c.normKey(k)] = t
+	}
+	return values, types, nil
+}
+
+// recordTypesLocked 为合并的values记录decodeContent返回的类型，没有类型的键清除旧记录；types为nil时不做任何事
+// values的键须已规范化，调用方须持有写锁
+func (c *Config) recordTypesLocked(values map[string]string, types map[string]interface{}) {
+	if types == nil {
+		return
+	}
+	for k := range values {
+		if t, ok := types[k]; ok {
+			c.types[k] = t
+		} else {
+			delete(c.types, k)
+		}
+	}
+}
+
+// flattenTypes 按flattenValue的键记录嵌套结构中不是字符串的叶子，空对象与空数组也被记录
+func flattenTypes(prefix string, value interface{}, out map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && prefix != "" {
+			out[prefix] = map[string]interface{}{}
+		}
+		for k, child := range v {
+			flattenTypes(joinKey(prefix, k), child, out)
+		}
+	case []interface{}:
+		if len(v) == 0 && prefix != "" {
+			out[prefix] = []interface{}{}
+		}
+		for i, child := range v {
+			flattenTypes(prefix+"["+strconv.Itoa(i)+"]", child, out)
+		}
+	case string:
+	default:
+		if prefix != "" {
+			out[prefix] = v
+		}
+	}
+}
+
+// typedLeaf 在记录的类型与当前值一致时返回带类型的值，否则返回字符串
+func typedLeaf(val string, t interface{}, typed bool) interface{} {
+	if !typed {
+		return val
+	}
+	switch v := t.(type) {
+	case map[string]interface{}, []interface{}:
+		if val == "" {
+			return v
+		}
+	case json.Number:
+		if v.String() == val {
+			if i, err := v.Int64(); err == nil {
+				return i
+			}
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+		}
+	default:
+		if formatScalar(v) == val {
+			return v
+		}
+	}
+	return val
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetValueKeepsTypesOnRejectedLoad(t *testing.T) {
+	cfg, err := NewConfigWithOptions(WithTypedValues())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.DefineKey("port", Int, Between(1, 65535))
+	load := func(doc string) error {
+		return cfg.LoadFromReader(strings.NewReader(doc), WithFileFormat("json"))
+	}
+	if err := load(`{"flag": true, "port": 8080}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := load(`{"flag": "true", "port": 70000}`); err == nil {
+		t.Fatal("load of out-of-range port succeeded")
+	}
+	if v, _ := cfg.GetValue("flag"); v != true {
+		t.Errorf("GetValue(flag) after rejected load = %#v, want true", v)
+	}
+	if v, _ := cfg.GetValue("port"); v != int64(8080) {
+		t.Errorf("GetValue(port) after rejected load = %#v, want int64(8080)", v)
+	}
+
+	if err := load(`{"flag": "true"}`); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := cfg.GetValue("flag"); v != "true" {
+		t.Errorf("GetValue(flag) after string load = %#v, want \"true\"", v)
+	}
+}
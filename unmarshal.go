@@ -0,0 +1,286 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tagName 是结构体字段上用于指定配置键的标签名
+const tagName = "config"
+
+// unitTagName 是time.Duration字段上用于指定不带单位数值的默认单位的标签名
+const unitTagName = "unit"
+
+// durationUnits 是unit标签可用的单位名称
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Unmarshal 将配置值按结构体标签绑定到结构体字段
+// 字段使用`config:"server.port"`标签指定键名，未设置标签时使用小写字段名，
+// `config:"-"`表示跳过该字段。嵌套结构体的键名作为其字段的前缀，
+// 如外层标签"server"与内层标签"port"组合为"server.port"。
+// 支持string、各类整数、浮点数、bool、time.Duration、
+// 实现encoding.TextUnmarshaler的类型以及以逗号分隔的切片(拆分规则与GetStringSlice相同)；
+// 元素为结构体(或结构体指针)的切片从"servers[0].host"、"servers[1].host"形式的下标键块解码，长度与GetSliceLen相同。
+// time.Duration字段可以用`unit:"seconds"`标签指定默认单位，此时不带单位的数值(如"timeout=30"或"1.5")
+// 按该单位解析，带单位的值仍按time.ParseDuration解析；可用单位为ns、us、ms、s、m、h及其英文全称。
+// 配置中不存在的键保持字段原值不变。
+// 参数:
+// - v: 指向结构体的非空指针
+// 返回:
+// - error: 目标类型不合法或值解析失败时返回错误
+func (c *Config) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("unmarshal target must be a non-nil pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("unmarshal target must point to a struct")
+	}
+	return c.unmarshalStruct(rv, "")
+}
+
+// unmarshalStruct 递归绑定结构体的各个字段
+func (c *Config) unmarshalStruct(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+		key := c.joinKey(prefix, name)
+		fv := rv.Field(i)
+		unit, err := fieldUnit(field)
+		if err != nil {
+			return err
+		}
+
+		if isNestedStruct(field.Type) {
+			if field.Type.Kind() == reflect.Ptr {
+				if !c.hasPrefix(key + c.sep()) {
+					continue
+				}
+				if fv.IsNil() {
+					fv.Set(reflect.New(field.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := c.unmarshalStruct(fv, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if isStructSlice(field.Type) {
+			if err := c.unmarshalStructSlice(fv, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		val, ok := c.lookup(key)
+		if !ok {
+			continue
+		}
+		if err := setFieldValue(fv, val, unit); err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// unmarshalStructSlice 把key[0]、key[1]...下的键块逐个解码为切片元素，没有下标键时保持字段原值
+func (c *Config) unmarshalStructSlice(fv reflect.Value, key string) error {
+	n := c.GetSliceLen(key)
+	if n == 0 {
+		return nil
+	}
+	slice := reflect.MakeSlice(fv.Type(), n, n)
+	for i := 0; i < n; i++ {
+		elem := slice.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			elem = elem.Elem()
+		}
+		if err := c.unmarshalStruct(elem, IndexKey(key, i)); err != nil {
+			return err
+		}
+	}
+	fv.Set(slice)
+	return nil
+}
+
+// isStructSlice 判断字段类型是否为元素按嵌套配置段处理的切片
+func isStructSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && isNestedStruct(t.Elem())
+}
+
+// hasPrefix 检查是否存在以prefix开头的配置键
+func (c *Config) hasPrefix(prefix string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for k := range c.keysLocked() {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldKey 从结构体字段解析配置键名，第二个返回值为false表示跳过该字段
+func fieldKey(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get(tagName)
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, true
+}
+
+// fieldUnit 解析字段的unit标签，未设置时返回0
+func fieldUnit(field reflect.StructField) (time.Duration, error) {
+	name, ok := field.Tag.Lookup(unitTagName)
+	if !ok {
+		return 0, nil
+	}
+	unit, ok := durationUnits[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("field %s: unknown duration unit %q", field.Name, name)
+	}
+	return unit, nil
+}
+
+// parseDuration 解析时长；unit大于0时不带单位的数值按unit解析
+func parseDuration(val string, unit time.Duration) (time.Duration, error) {
+	if unit > 0 {
+		if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+			if n > math.MaxInt64/int64(unit) || n < math.MinInt64/int64(unit) {
+				return 0, fmt.Errorf("duration %s %s out of range", val, unit)
+			}
+			return time.Duration(n) * unit, nil
+		}
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			d := f * float64(unit)
+			if d > math.MaxInt64 || d < math.MinInt64 {
+				return 0, fmt.Errorf("duration %s %s out of range", val, unit)
+			}
+			return time.Duration(d), nil
+		}
+	}
+	return time.ParseDuration(val)
+}
+
+// joinKey 使用"."连接键前缀与键名
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// isNestedStruct 判断字段类型是否应作为嵌套配置段处理
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setFieldValue 将字符串值解析并写入字段，unit是time.Duration字段的默认单位，为0表示必须带单位
+func setFieldValue(fv reflect.Value, val string, unit time.Duration) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setFieldValue(fv.Elem(), val, unit)
+	}
+
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	// 字符串与Get一致保留值的原样，列表中带引号的元素可以有首尾空白
+	if fv.Kind() != reflect.String {
+		val = strings.TrimSpace(val)
+	}
+	if fv.Type() == durationType {
+		d, err := parseDuration(val, unit)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		b, err := parseBool(val)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		parts, err := splitList(val, sliceSettings{delimiter: ",", quotes: true})
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setFieldValue(slice.Index(i), part, unit); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		fv.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalDurationUnit(t *testing.T) {
+	type settings struct {
+		Timeout  time.Duration   `config:"timeout" unit:"seconds"`
+		Interval time.Duration   `config:"interval" unit:"ms"`
+		Retry    time.Duration   `config:"retry" unit:"Minutes"`
+		Backoff  []time.Duration `config:"backoff" unit:"s"`
+		Plain    time.Duration   `config:"plain"`
+	}
+	cfg, _ := NewConfig()
+	cfg.Set("timeout", "30")
+	cfg.Set("interval", "1.5")
+	cfg.Set("retry", "2m30s") // 带单位的值照常解析
+	cfg.Set("backoff", "1,2,4")
+	cfg.Set("plain", "5s")
+
+	var s settings
+	if err := cfg.Unmarshal(&s); err != nil {
+		t.Fatal(err)
+	}
+	want := settings{
+		Timeout:  30 * time.Second,
+		Interval: 1500 * time.Microsecond,
+		Retry:    150 * time.Second,
+		Backoff:  []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		Plain:    5 * time.Second,
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Unmarshal = %+v, want %+v", s, want)
+	}
+
+	// 没有unit标签的字段不接受不带单位的数值
+	cfg.Set("plain", "5")
+	if err := cfg.Unmarshal(&s); err == nil {
+		t.Error("Unmarshal of a bare number without a unit tag succeeded")
+	}
+	cfg.Set("plain", "5s")
+	cfg.Set("timeout", "9223372036854775807")
+	if err := cfg.Unmarshal(&s); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("Unmarshal of an overflowing duration error = %v", err)
+	}
+
+	// 能被单位整除的值写回为不带单位的整数
+	out, _ := NewConfig()
+	if err := out.Marshal(settings{Timeout: time.Minute, Interval: 1500 * time.Microsecond, Plain: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Get("timeout") != "60" || out.Get("interval") != "1.5ms" || out.Get("plain") != "1s" {
+		t.Errorf("Marshal = %v", out.GetAll())
+	}
+
+	var bad struct {
+		D time.Duration `config:"d" unit:"fortnight"`
+	}
+	if err := cfg.Unmarshal(&bad); err == nil || !strings.Contains(err.Error(), `unknown duration unit "fortnight"`) {
+		t.Errorf("Unmarshal with an unknown unit error = %v", err)
+	}
+}
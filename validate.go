@@ -0,0 +1,313 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueType 表示配置值的期望类型
+type ValueType int
+
+const (
+	Any      ValueType = iota // 不限制类型
+	String                    // 任意字符串
+	Int                       // 十进制整数
+	Float                     // 浮点数
+	Bool                      // 布尔值，接受parseBool支持的写法
+	Duration                  // time.ParseDuration格式的时长
+)
+
+// String 返回类型名称
+func (t ValueType) String() string {
+	switch t {
+	case Any:
+		return "any"
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case Duration:
+		return "duration"
+	}
+	return "ValueType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// check 检查value是否符合类型
+func (t ValueType) check(value string) error {
+	var err error
+	switch t {
+	case Int:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case Float:
+		_, err = strconv.ParseFloat(value, 64)
+	case Bool:
+		_, err = parseBool(value)
+	case Duration:
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", t, value)
+	}
+	return nil
+}
+
+// Validator 是自定义校验函数，值不合法时返回描述原因的错误
+type Validator func(value string) error
+
+// Rule 描述单个键的校验规则，未设置的字段不参与校验
+type Rule struct {
+	// Required 要求键必须存在
+	Required bool
+	// Type 是值的期望类型
+	Type ValueType
+	// Min和Max 限制数值的取值范围(包含边界)，值必须能解析为数字
+	Min, Max *float64
+	// Pattern 是值必须完整匹配的正则表达式
+	Pattern string
+	// Enum 列出允许的取值
+	Enum []string
+	// Validators 是依次执行的自定义校验函数
+	Validators []Validator
+}
+
+// Schema 将键映射到校验规则
+// 键中可以使用与Subscribe相同的通配符，例如"servers[*].port"，
+// 此时规则作用于所有匹配的键，Required表示至少存在一个匹配的键
+type Schema map[string]Rule
+
+// Limit 返回指向v的指针，便于填写Rule的Min和Max
+func Limit(v float64) *float64 {
+	return &v
+}
+
+// Violation 记录一条校验失败
+// 值来自文件时带有File和Line，指出违反规则的行(非key=value格式的文件Line为0)
+type Violation struct {
+	Key     string
+	Message string
+	File    string
+	Line    int
+}
+
+// String 返回"文件:行号: key ...: 原因"形式的描述，没有位置信息时省略前缀
+func (v Violation) String() string {
+	msg := fmt.Sprintf("key %q: %s", v.Key, v.Message)
+	switch {
+	case v.File != "" && v.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", v.File, v.Line, msg)
+	case v.File != "":
+		return v.File + ": " + msg
+	}
+	return msg
+}
+
+// ValidationError 汇总Validate发现的所有校验失败
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Error 返回所有校验失败的描述，每条一行
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+	return "config validation failed:\n" + strings.Join(lines, "\n")
+}
+
+// Validate 按schema校验当前生效的配置值
+// 所有规则都会执行，发现的问题一次性返回
+// 参数:
+// - schema: 校验规则
+// 返回:
+// - error: 存在校验失败时返回*ValidationError，规则本身无效(如正则表达式错误)时返回普通错误
+func (c *Config) Validate(schema Schema) error {
+	all := c.allValues()
+	var violations []Violation
+	for _, pattern := range sortedRuleKeys(schema) {
+		rule := schema[pattern]
+		var re *regexp.Regexp
+		if rule.Pattern != "" {
+			var err error
+			if re, err = regexp.Compile("^(?:" + rule.Pattern + ")$"); err != nil {
+				return fmt.Errorf("key %q: invalid pattern: %w", pattern, err)
+			}
+		}
+
+		keys := matchingKeys(all, pattern, c.sep())
+		if len(keys) == 0 {
+			if rule.Required {
+				violations = append(violations, Violation{Key: pattern, Message: "required key is missing"})
+			}
+			continue
+		}
+		for _, key := range keys {
+			for _, msg := range rule.check(strings.TrimSpace(all[key]), re) {
+				violations = append(violations, c.violation(key, msg))
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// Require 检查所有给定的键都存在，一次报告全部缺失的键
+// 值为空字符串的键视为存在，需要非空值时使用Validate配合Rule.Pattern等规则
+// 参数:
+// - keys: 必需的配置键
+// 返回:
+// - error: 有键缺失时返回*ValidationError，每个缺失的键一条
+func (c *Config) Require(keys ...string) error {
+	var violations []Violation
+	for _, key := range keys {
+		if _, ok := c.lookup(key); !ok {
+			violations = append(violations, Violation{Key: key, Message: "required key is missing"})
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// violation 创建键的校验失败，值来自文件时带上文件与行号
+func (c *Config) violation(key, msg string) Violation {
+	v := Violation{Key: key, Message: msg}
+	if src, ok := c.Source(key); ok && src.Kind == SourceFile {
+		v.File, v.Line = src.Name, src.Line
+	}
+	return v
+}
+
+// check 对单个值执行规则，返回所有失败描述
+func (r Rule) check(value string, re *regexp.Regexp) []string {
+	var msgs []string
+	if err := r.Type.check(value); err != nil {
+		msgs = append(msgs, err.Error())
+	}
+	if r.Min != nil || r.Max != nil {
+		n, err := strconv.ParseFloat(value, 64)
+		switch {
+		case err != nil:
+			msgs = append(msgs, fmt.Sprintf("value %q is not a number", value))
+		case r.Min != nil && n < *r.Min:
+			msgs = append(msgs, fmt.Sprintf("value %s is less than minimum %s", value, formatLimit(*r.Min)))
+		case r.Max != nil && n > *r.Max:
+			msgs = append(msgs, fmt.Sprintf("value %s is greater than maximum %s", value, formatLimit(*r.Max)))
+		}
+	}
+	if re != nil && !re.MatchString(value) {
+		msgs = append(msgs, fmt.Sprintf("value %q does not match pattern %q", value, r.Pattern))
+	}
+	if len(r.Enum) > 0 && !containsString(r.Enum, value) {
+		msgs = append(msgs, fmt.Sprintf("value %q is not one of [%s]", value, strings.Join(r.Enum, ", ")))
+	}
+	for _, fn := range r.Validators {
+		if err := fn(value); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return msgs
+}
+
+// matchingKeys 返回all中匹配pattern的键，sep为层级分隔符；pattern不含通配符时按精确键查找
+func matchingKeys(all map[string]string, pattern, sep string) []string {
+	if !strings.ContainsAny(pattern, "*?") {
+		if _, ok := all[pattern]; ok {
+			return []string{pattern}
+		}
+		return nil
+	}
+	var keys []string
+	for _, key := range sortedKeys(all) {
+		if matchPatternSep(pattern, key, sep) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func sortedRuleKeys(schema Schema) []string {
+	keys := make([]string, 0, len(schema))
+	for k := range schema {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatLimit(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidPort 校验值是否为1到65535之间的端口号
+func ValidPort(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port %q", value)
+	}
+	return nil
+}
+
+// ValidURL 校验值是否为带协议和主机的绝对URL
+func ValidURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid URL %q", value)
+	}
+	return nil
+}
+
+// ValidEmail 校验值是否为单个邮件地址(不含显示名)
+func ValidEmail(value string) error {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return fmt.Errorf("invalid email address %q", value)
+	}
+	return nil
+}
+
+// ValidHostPort 校验值是否为"host:port"形式的地址
+func ValidHostPort(value string) error {
+	_, port, err := net.SplitHostPort(value)
+	if err != nil || ValidPort(port) != nil {
+		return fmt.Errorf("invalid host:port %q", value)
+	}
+	return nil
+}
+
+// ValidDuration 校验值是否为time.ParseDuration格式的时长
+func ValidDuration(value string) error {
+	return Duration.check(value)
+}
+
+// FileExists 校验值是否为已存在的文件或目录路径
+func FileExists(value string) error {
+	if _, err := os.Stat(value); err != nil {
+		return fmt.Errorf("file %q does not exist", value)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	os.WriteFile(path, []byte("server.port = 70000\nlog.level = loud\nadmin.email = root\n"), 0o644)
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("timeout", "soon")
+	cfg.Set("servers[0].port", "80")
+	cfg.Set("servers[1].port", "http")
+
+	schema := Schema{
+		"server.port":     {Required: true, Type: Int, Min: Limit(1), Max: Limit(65535)},
+		"log.level":       {Enum: []string{"debug", "info", "warn"}},
+		"admin.email":     {Validators: []Validator{ValidEmail}},
+		"timeout":         {Type: Duration},
+		"servers[*].port": {Validators: []Validator{ValidPort}},
+		"db.url":          {Required: true, Pattern: `postgres://.+`},
+		"name":            {Pattern: `[a-z]+`}, // 可选键缺失时不校验
+	}
+	err := cfg.Validate(schema)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate error = %v, want *ValidationError", err)
+	}
+	var keys []string
+	for _, v := range verr.Violations {
+		keys = append(keys, v.Key)
+	}
+	// 所有问题一次报告，按规则键排序
+	want := []string{"admin.email", "db.url", "log.level", "server.port", "servers[1].port", "timeout"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("violations for %v, want %v\n%v", keys, want, err)
+	}
+	msg := err.Error()
+	for _, part := range []string{
+		path + ":1: key \"server.port\": value 70000 is greater than maximum 65535",
+		`key "db.url": required key is missing`,
+		`value "loud" is not one of [debug, info, warn]`,
+		`invalid duration "soon"`,
+	} {
+		if !strings.Contains(msg, part) {
+			t.Errorf("error does not contain %q:\n%s", part, msg)
+		}
+	}
+
+	cfg.Set("server.port", "8080")
+	cfg.Set("log.level", "info")
+	cfg.Set("admin.email", "root@example.com")
+	cfg.Set("timeout", "5s")
+	cfg.Set("servers[1].port", "443")
+	cfg.Set("db.url", "postgres://db/app")
+	if err := cfg.Validate(schema); err != nil {
+		t.Errorf("Validate after fixing all values: %v", err)
+	}
+
+	if err := cfg.Validate(Schema{"name": {Pattern: "("}}); err == nil || errors.As(err, &verr) {
+		t.Errorf("Validate with an invalid pattern error = %v, want a plain error", err)
+	}
+}
+
+func TestBuiltinValidators(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "exists")
+	os.WriteFile(file, nil, 0o644)
+	tests := []struct {
+		name string
+		fn   Validator
+		good []string
+		bad  []string
+	}{
+		{"ValidPort", ValidPort, []string{"1", "65535"}, []string{"0", "65536", "http"}},
+		{"ValidURL", ValidURL, []string{"https://example.com/x"}, []string{"example.com", "/path", "://"}},
+		{"ValidEmail", ValidEmail, []string{"a@example.com"}, []string{"a", "A <a@example.com>"}},
+		{"ValidHostPort", ValidHostPort, []string{"localhost:80", "[::1]:443"}, []string{"localhost", "host:port"}},
+		{"ValidDuration", ValidDuration, []string{"1h30m"}, []string{"90"}},
+		{"FileExists", FileExists, []string{file}, []string{file + ".missing"}},
+	}
+	for _, tt := range tests {
+		for _, v := range tt.good {
+			if err := tt.fn(v); err != nil {
+				t.Errorf("%s(%q) = %v, want nil", tt.name, v, err)
+			}
+		}
+		for _, v := range tt.bad {
+			if err := tt.fn(v); err == nil {
+				t.Errorf("%s(%q) = nil, want an error", tt.name, v)
+			}
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("db.host", "localhost")
+	cfg.Set("db.user", "")
+	cfg.SetDefault("db.port", "5432")
+
+	if err := cfg.Require("db.host", "db.user", "db.port"); err != nil {
+		t.Errorf("Require of present keys = %v", err)
+	}
+	err := cfg.Require("db.host", "db.password", "db.user", "db.name")
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Require error = %v, want *ValidationError", err)
+	}
+	var missing []string
+	for _, v := range ve.Violations {
+		missing = append(missing, v.Key)
+	}
+	if !reflect.DeepEqual(missing, []string{"db.password", "db.name"}) {
+		t.Errorf("missing keys = %v, want all of them in order", missing)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "db.password") || !strings.Contains(msg, "db.name") {
+		t.Errorf("Error() =\n%s", msg)
+	}
+}
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultProvider 从HashiCorp Vault的KV v2引擎读取机密并放到指定的键前缀下
+// Secrets把机密路径映射为键前缀，例如{"myapp/db": "db"}时
+// secret/data/myapp/db中的password字段对应键"db.password"；嵌套的JSON值按JSON文件的规则展开。
+// 令牌可续期时在剩余有效期不足三分之一时通过renew-self续期；
+// 机密带有租约时在租约到期前重新读取，否则按RefreshInterval定期读取。
+// 读取的值通常是敏感信息，建议对相应前缀调用MarkSecret。
+type VaultProvider struct {
+	// Address 是Vault地址，如"https://vault:8200"，为空时使用VAULT_ADDR环境变量
+	Address string
+	// Token 是Vault令牌，为空时使用VAULT_TOKEN环境变量
+	Token string
+	// Namespace 是Vault企业版的命名空间，通过X-Vault-Namespace请求头发送
+	Namespace string
+	// Mount 是KV v2引擎的挂载路径，默认为"secret"
+	Mount string
+	// Secrets 把机密路径(相对Mount)映射为键前缀，前缀为空时字段名直接作为键
+	Secrets map[string]string
+	// TLSConfig 在Client为nil时用于构造HTTPS客户端
+	TLSConfig *tls.Config
+	// Client 用于发送请求，为nil时根据TLSConfig构造
+	Client *http.Client
+	// RefreshInterval 是机密没有租约时Watch重新读取的间隔
+	RefreshInterval time.Duration
+	// RetryInterval 是请求失败后的重试间隔
+	RetryInterval time.Duration
+
+	mutex      sync.Mutex
+	token      string
+	renewAt    time.Time // 令牌需要续期的时间，零值表示不需要续期
+	checked    bool      // 是否已查询过令牌的有效期
+	leaseUntil time.Time // 最早到期的机密租约，零值表示没有租约
+	httpClient *http.Client
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// NewVaultProvider 创建读取secrets中各机密路径的Vault配置源
+// 参数:
+// - address: Vault地址，为空时使用VAULT_ADDR环境变量
+// - token: Vault令牌，为空时使用VAULT_TOKEN环境变量
+// - secrets: 机密路径到键前缀的映射
+// 返回:
+// - *VaultProvider: 配置源实例
+func NewVaultProvider(address, token string, secrets map[string]string) *VaultProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &VaultProvider{
+		Address:         address,
+		Token:           token,
+		Mount:           "secret",
+		Secrets:         secrets,
+		RefreshInterval: 5 * time.Minute,
+		RetryInterval:   5 * time.Second,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *VaultProvider) Close() error {
+	p.cancel()
+	return nil
+}
+
+// Load 必要时续期令牌，然后读取全部机密
+func (p *VaultProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时中止请求
+func (p *VaultProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	if err := p.renewToken(ctx); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(p.Secrets))
+	for path := range p.Secrets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	values := make(map[string]string)
+	var leaseUntil time.Time
+	for _, path := range paths {
+		data, lease, err := p.readSecret(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		flattenValue(p.Secrets[path], data, values)
+		if lease > 0 {
+			until := time.Now().Add(lease)
+			if leaseUntil.IsZero() || until.Before(leaseUntil) {
+				leaseUntil = until
+			}
+		}
+	}
+	p.mutex.Lock()
+	p.leaseUntil = leaseUntil
+	p.mutex.Unlock()
+	return values, nil
+}
+
+// Watch 在令牌需要续期、租约即将到期或到达RefreshInterval时重新读取，数据变化时发送完整数据
+func (p *VaultProvider) Watch(ch chan<- Update) error {
+	current, err := p.Load()
+	if err != nil {
+		return err
+	}
+	wait := p.nextRefresh()
+	for {
+		select {
+		case <-time.After(wait):
+		case <-p.ctx.Done():
+			return ErrProviderClosed
+		}
+
+		next, err := p.Load()
+		if p.ctx.Err() != nil {
+			return ErrProviderClosed
+		}
+		if err != nil {
+			select {
+			case ch <- Update{Err: err}:
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+			wait = p.RetryInterval
+			continue
+		}
+		wait = p.nextRefresh()
+		if maps.Equal(next, current) {
+			continue
+		}
+		current = next
+		select {
+		case ch <- Update{Values: maps.Clone(next)}:
+		case <-p.ctx.Done():
+			return ErrProviderClosed
+		}
+	}
+}
+
+// nextRefresh 返回距离下一次需要读取的时间
+// 取RefreshInterval、令牌续期时间与租约剩余时间的三分之二中最早的一个
+func (p *VaultProvider) nextRefresh() time.Duration {
+	wait := p.RefreshInterval
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now()
+	if !p.renewAt.IsZero() {
+		wait = min(wait, p.renewAt.Sub(now))
+	}
+	if !p.leaseUntil.IsZero() {
+		wait = min(wait, p.leaseUntil.Sub(now)*2/3)
+	}
+	return max(wait, time.Second)
+}
+
+// readSecret 读取一个KV v2机密，返回其数据与租约时长
+func (p *VaultProvider) readSecret(ctx context.Context, path string) (map[string]interface{}, time.Duration, error) {
+	mount := strings.Trim(p.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+	var resp struct {
+		LeaseDuration int `json:"lease_duration"`
+		Data          struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := p.request(ctx, http.MethodGet, "/v1/"+mount+"/data/"+strings.Trim(path, "/"), nil, &resp); err != nil {
+		return nil, 0, fmt.Errorf("vault: read %s: %w", path, err)
+	}
+	return resp.Data.Data, time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+// renewToken 首次调用时查询令牌有效期，之后在到达续期时间时续期
+func (p *VaultProvider) renewToken(ctx context.Context) error {
+	p.mutex.Lock()
+	checked, renewAt := p.checked, p.renewAt
+	p.mutex.Unlock()
+
+	var auth struct {
+		TTL       int  `json:"ttl"`
+		Renewable bool `json:"renewable"`
+	}
+	switch {
+	case !checked:
+		var resp struct {
+			Data struct {
+				TTL       int  `json:"ttl"`
+				Renewable bool `json:"renewable"`
+			} `json:"data"`
+		}
+		if err := p.request(ctx, http.MethodGet, "/v1/auth/token/lookup-self", nil, &resp); err != nil {
+			return fmt.Errorf("vault: lookup token: %w", err)
+		}
+		auth.TTL, auth.Renewable = resp.Data.TTL, resp.Data.Renewable
+	case !renewAt.IsZero() && !time.Now().Before(renewAt):
+		var resp struct {
+			Auth struct {
+				ClientToken   string `json:"client_token"`
+				LeaseDuration int    `json:"lease_duration"`
+				Renewable     bool   `json:"renewable"`
+			} `json:"auth"`
+		}
+		if err := p.request(ctx, http.MethodPost, "/v1/auth/token/renew-self", map[string]string{}, &resp); err != nil {
+			return fmt.Errorf("vault: renew token: %w", err)
+		}
+		auth.TTL, auth.Renewable = resp.Auth.LeaseDuration, resp.Auth.Renewable
+		if resp.Auth.ClientToken != "" {
+			p.mutex.Lock()
+			p.token = resp.Auth.ClientToken
+			p.mutex.Unlock()
+		}
+	default:
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.checked = true
+	p.renewAt = time.Time{}
+	// ttl为0的令牌(如root令牌)不会过期，不可续期的令牌到期后请求会失败并报告错误
+	if auth.Renewable && auth.TTL > 0 {
+		p.renewAt = time.Now().Add(time.Duration(auth.TTL) * time.Second * 2 / 3)
+	}
+	return nil
+}
+
+// request 发送带令牌的请求并解码JSON响应
+func (p *VaultProvider) request(ctx context.Context, method, path string, body, resp interface{}) error {
+	address := p.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return fmt.Errorf("no address configured")
+	}
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(address, "/")+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", p.currentToken())
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := p.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		if json.Unmarshal(msg, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("%s: %s", httpResp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("%s: %s", httpResp.Status, bytes.TrimSpace(msg))
+	}
+	dec := json.NewDecoder(httpResp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(resp); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// currentToken 返回请求使用的令牌，续期返回的新令牌优先
+func (p *VaultProvider) currentToken() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.token != "" {
+		return p.token
+	}
+	if p.Token != "" {
+		return p.Token
+	}
+	return os.Getenv("VAULT_TOKEN")
+}
+
+// client 返回发送请求使用的HTTP客户端
+func (p *VaultProvider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if p.TLSConfig != nil {
+			transport.TLSClientConfig = p.TLSConfig
+		}
+		p.httpClient = &http.Client{Transport: transport}
+	}
+	return p.httpClient
+}
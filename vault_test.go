@@ -0,0 +1,226 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeVault 模拟Vault中VaultProvider用到的令牌与KV v2接口
+type fakeVault struct {
+	mutex     sync.Mutex
+	token     string                 // 有效令牌
+	ttl       int                    // lookup-self与renew-self返回的令牌有效期(秒)
+	renewed   string                 // renew-self返回的新令牌，为空时沿用原令牌
+	lease     int                    // 机密的lease_duration
+	secrets   map[string]interface{} // KV v2路径(如"secret/data/myapp/db")到机密数据
+	calls     []string               // 按顺序记录的请求路径
+	namespace string                 // 最近一次请求的X-Vault-Namespace
+}
+
+func newFakeVault(t *testing.T, token string) (*fakeVault, *httptest.Server) {
+	f := &fakeVault{token: token, secrets: map[string]interface{}{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	f.namespace = r.Header.Get("X-Vault-Namespace")
+	if r.Header.Get("X-Vault-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+	switch path := strings.TrimPrefix(r.URL.Path, "/v1/"); path {
+	case "auth/token/lookup-self":
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"ttl": f.ttl, "renewable": f.ttl > 0}})
+	case "auth/token/renew-self":
+		if f.renewed != "" {
+			f.token = f.renewed
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{
+			"client_token": f.token, "lease_duration": f.ttl, "renewable": true,
+		}})
+	default:
+		data, ok := f.secrets[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_duration": f.lease,
+			"data":           map[string]interface{}{"data": data},
+		})
+	}
+}
+
+// setSecret 修改一个机密的数据
+func (f *fakeVault) setSecret(path string, data map[string]interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.secrets[path] = data
+}
+
+// takeCalls 返回并清空已记录的请求
+func (f *fakeVault) takeCalls() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	calls := f.calls
+	f.calls = nil
+	return calls
+}
+
+func TestVaultLoad(t *testing.T) {
+	f, srv := newFakeVault(t, "s.root")
+	f.setSecret("kv/data/myapp/db", map[string]interface{}{
+		"password": "hunter2",
+		"pool":     map[string]interface{}{"max": 10},
+	})
+	f.setSecret("kv/data/myapp/api", map[string]interface{}{"key": "k-123"})
+
+	p := NewVaultProvider(srv.URL, "s.root", map[string]string{"myapp/db": "db", "/myapp/api/": ""})
+	defer p.Close()
+	p.Mount = "/kv/"
+	p.Namespace = "team-a"
+	got, err := p.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"db.password": "hunter2", "db.pool.max": "10", "key": "k-123"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %v, want %v", got, want)
+	}
+	wantCalls := []string{"GET /v1/auth/token/lookup-self", "GET /v1/kv/data/myapp/api", "GET /v1/kv/data/myapp/db"}
+	if calls := f.takeCalls(); !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("requests = %v, want %v", calls, wantCalls)
+	}
+	f.mutex.Lock()
+	if f.namespace != "team-a" {
+		t.Errorf("X-Vault-Namespace = %q, want team-a", f.namespace)
+	}
+	f.mutex.Unlock()
+
+	// root令牌ttl为0：不再查询也不续期
+	if _, err := p.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if calls := f.takeCalls(); len(calls) != 2 {
+		t.Errorf("second Load requests = %v, want only the two secrets", calls)
+	}
+}
+
+func TestVaultErrors(t *testing.T) {
+	f, srv := newFakeVault(t, "s.good")
+	f.setSecret("secret/data/app", map[string]interface{}{"a": "1"})
+
+	p := NewVaultProvider(srv.URL, "s.bad", map[string]string{"app": ""})
+	defer p.Close()
+	if _, err := p.Load(); err == nil || !strings.Contains(err.Error(), "lookup token") || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Load with a bad token error = %v, want the lookup failure with Vault's message", err)
+	}
+
+	p = NewVaultProvider(srv.URL, "s.good", map[string]string{"missing": ""})
+	defer p.Close()
+	if _, err := p.Load(); err == nil || !strings.Contains(err.Error(), "read missing") || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Load of a missing secret error = %v, want the read failure with the status", err)
+	}
+}
+
+func TestVaultTokenRenewal(t *testing.T) {
+	f, srv := newFakeVault(t, "s.first")
+	f.ttl = 3600
+	f.renewed = "s.second"
+	f.setSecret("secret/data/app", map[string]interface{}{"a": "1"})
+
+	p := NewVaultProvider(srv.URL, "s.first", map[string]string{"app": ""})
+	defer p.Close()
+	if _, err := p.Load(); err != nil {
+		t.Fatal(err)
+	}
+	// 可续期令牌在有效期的三分之二时续期
+	if until := time.Until(p.renewAt); until < 39*time.Minute || until > 40*time.Minute {
+		t.Errorf("renewal scheduled in %v, want about 40m", until)
+	}
+	if wait := p.nextRefresh(); wait != p.RefreshInterval {
+		t.Errorf("nextRefresh = %v, want RefreshInterval %v", wait, p.RefreshInterval)
+	}
+
+	p.mutex.Lock()
+	p.renewAt = time.Now().Add(-time.Second)
+	p.mutex.Unlock()
+	f.takeCalls()
+	if _, err := p.Load(); err != nil {
+		t.Fatalf("Load after renewal: %v", err)
+	}
+	wantCalls := []string{"POST /v1/auth/token/renew-self", "GET /v1/secret/data/app"}
+	if calls := f.takeCalls(); !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("requests = %v, want %v", calls, wantCalls)
+	}
+	// 续期返回的新令牌用于之后的请求
+	if _, err := p.Load(); err != nil {
+		t.Fatalf("Load with the renewed token: %v", err)
+	}
+}
+
+func TestVaultLeaseRefresh(t *testing.T) {
+	f, srv := newFakeVault(t, "s.root")
+	f.lease = 30
+	f.setSecret("secret/data/app", map[string]interface{}{"a": "1"})
+
+	p := NewVaultProvider(srv.URL, "s.root", map[string]string{"app": ""})
+	defer p.Close()
+	if _, err := p.Load(); err != nil {
+		t.Fatal(err)
+	}
+	// 租约剩余时间的三分之二早于RefreshInterval
+	if wait := p.nextRefresh(); wait < 19*time.Second || wait > 20*time.Second {
+		t.Errorf("nextRefresh with a 30s lease = %v, want about 20s", wait)
+	}
+}
+
+func TestVaultWatch(t *testing.T) {
+	f, srv := newFakeVault(t, "s.root")
+	f.setSecret("secret/data/app", map[string]interface{}{"a": "1"})
+
+	p := NewVaultProvider(srv.URL, "s.root", map[string]string{"app": "app"})
+	p.RefreshInterval = time.Millisecond // nextRefresh不短于1秒
+	ch := make(chan Update, 4)
+	done := make(chan error, 1)
+	go func() { done <- p.Watch(ch) }()
+	defer func() {
+		p.Close()
+		if err := <-done; err != ErrProviderClosed {
+			t.Errorf("Watch returned %v, want ErrProviderClosed", err)
+		}
+	}()
+
+	// 等首次读取完成后再修改机密
+	for deadline, n := time.Now().Add(5*time.Second), 0; n < 2; n += len(f.takeCalls()) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the initial load")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	f.setSecret("secret/data/app", map[string]interface{}{"a": "2"})
+	select {
+	case u := <-ch:
+		if u.Err != nil {
+			t.Fatal(u.Err)
+		}
+		if want := map[string]string{"app.a": "2"}; !reflect.DeepEqual(u.Values, want) {
+			t.Errorf("update = %v, want %v", u.Values, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+}
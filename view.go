@@ -0,0 +1,43 @@
+package config
+
+// readView 是某一时刻全部生效值的只读视图
+// 发布后不再修改，读取者无需加锁即可访问values
+type readView struct {
+	values map[string]string
+	// cached为false表示当前设置下生效值依赖调用时的环境变量，不能缓存，读取者须加锁解析
+	cached bool
+	alias  aliasTable // 发布视图时的别名与弃用登记，供lookup无锁使用
+}
+
+// lock 获取写锁并清空只读视图
+// 所有修改配置的操作都通过lock加锁，视图在写锁下被清空，之后第一次读取时重建，
+// 因此读取者不会在修改完成后看到旧值
+func (c *Config) lock() {
+	c.mutex.Lock()
+	c.view.Store(nil)
+}
+
+// loadView 返回当前的只读视图，视图已被清空时在读锁下重建
+// 启用BindEnv或插值时值在每次读取时都可能随环境变量变化，WithoutReadView时不缓存视图，都返回cached为false的视图
+func (c *Config) loadView() *readView {
+	if v := c.view.Load(); v != nil {
+		return v
+	}
+	c.viewMu.Lock()
+	defer c.viewMu.Unlock()
+	if v := c.view.Load(); v != nil {
+		return v
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	v := &readView{alias: c.alias}
+	if c.env == nil && !c.interpolate && !c.noReadView {
+		v.cached = true
+		v.values = c.effectiveLocked()
+		for k, val := range v.values {
+			v.values[k] = c.coerceLocked(k, c.decodeLocked(val))
+		}
+	}
+	c.view.Store(v)
+	return v
+}
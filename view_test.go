@@ -0,0 +1,159 @@
+package config
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReadView(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.SetDefault("port", "80")
+	if got := cfg.Get("port"); got != "80" {
+		t.Fatalf("Get(port) = %q, want 80", got)
+	}
+	// 每次修改后读取立即看到新值，而不是之前发布的视图
+	cfg.Set("port", "8080")
+	if got := cfg.Get("port"); got != "8080" {
+		t.Errorf("Get(port) after Set = %q, want 8080", got)
+	}
+	cfg.Delete("port")
+	if got := cfg.Get("port"); got != "80" {
+		t.Errorf("Get(port) after Delete = %q, want the default", got)
+	}
+	cfg.SetOverride("port", "1")
+	if got, ok := cfg.Lookup("port"); !ok || got != "1" {
+		t.Errorf("Lookup(port) after SetOverride = %q, %v", got, ok)
+	}
+
+	// 并发读写：读取者只会看到某次写入后的完整值
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if v := cfg.Get("n"); v != "" {
+					if _, err := strconv.Atoi(v); err != nil {
+						t.Errorf("Get(n) = %q during writes", v)
+						return
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		cfg.Set("n", strconv.Itoa(i))
+	}
+	close(stop)
+	wg.Wait()
+	if got := cfg.Get("n"); got != "999" {
+		t.Errorf("Get(n) after the writes = %q, want 999", got)
+	}
+}
+
+func TestReadViewUncached(t *testing.T) {
+	for name, cfg := range map[string]*Config{
+		"env":             func() *Config { c, _ := NewConfig(); c.BindEnv("VIEWTEST"); return c }(),
+		"WithoutReadView": func() *Config { c, _ := NewConfigWithOptions(WithoutReadView()); return c }(),
+	} {
+		cfg.Set("port", "80")
+		t.Setenv("VIEWTEST_PORT", "9090")
+		want := "80"
+		if name == "env" {
+			want = "9090"
+		}
+		if got := cfg.Get("port"); got != want {
+			t.Errorf("%s: Get(port) = %q, want %q", name, got, want)
+		}
+		// 环境变量变化时不使用缓存的旧值
+		t.Setenv("VIEWTEST_PORT", "7070")
+		if name == "env" {
+			want = "7070"
+		}
+		if got := cfg.Get("port"); got != want {
+			t.Errorf("%s: Get(port) after the env change = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// benchConfig 创建含n个键的Config，用于比较默认视图与WithoutReadView
+func benchConfig(b *testing.B, n int, opts ...ConfigOption) *Config {
+	cfg, err := NewConfigWithOptions(opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		cfg.Set("key"+strconv.Itoa(i), strconv.Itoa(i))
+	}
+	return cfg
+}
+
+var benchModes = []struct {
+	name string
+	opts []ConfigOption
+}{
+	{"view", nil},
+	{"noview", []ConfigOption{WithoutReadView()}},
+}
+
+func BenchmarkGet(b *testing.B) {
+	for _, mode := range benchModes {
+		b.Run(mode.name, func(b *testing.B) {
+			cfg := benchConfig(b, 1000, mode.opts...)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					cfg.Get("key500")
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkSetParallel(b *testing.B) {
+	for _, mode := range benchModes {
+		b.Run(mode.name, func(b *testing.B) {
+			cfg := benchConfig(b, 1000, mode.opts...)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					cfg.Set("key"+strconv.Itoa(i%1000), "v")
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkMixed 中每8次操作有一次Set，其余为Get
+func BenchmarkMixed(b *testing.B) {
+	for _, mode := range benchModes {
+		for _, n := range []int{10, 1000} {
+			b.Run(mode.name+"/keys="+strconv.Itoa(n), func(b *testing.B) {
+				cfg := benchConfig(b, n, mode.opts...)
+				b.ResetTimer()
+				var ops atomic.Int64
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						i := ops.Add(1)
+						key := "key" + strconv.Itoa(int(i)%n)
+						if i%8 == 0 {
+							cfg.Set(key, "v")
+						} else {
+							cfg.Get(key)
+						}
+					}
+				})
+			})
+		}
+	}
+}
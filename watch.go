@@ -0,0 +1,346 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval 是Watch默认的文件轮询间隔
+const DefaultWatchInterval = time.Second
+
+// watchSettings 保存Watch的可选设置
+type watchSettings struct {
+	interval   time.Duration
+	conditions map[string]string // WatchConditions设置的条件指令变量
+}
+
+// WatchOption 用于定制Watch的行为
+type WatchOption func(*watchSettings)
+
+// WithPollInterval 设置文件轮询间隔，默认为DefaultWatchInterval
+func WithPollInterval(d time.Duration) WatchOption {
+	return func(s *watchSettings) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// OnReload 注册热加载回调
+// 每次Watch检测到文件变化并尝试重新加载后调用，
+// 加载成功时err为nil，失败时配置保持原值不变。
+// 回调在监视goroutine中执行，持有锁之外调用，可以安全地读取配置。
+// 参数:
+// - fn: 回调函数
+func (c *Config) OnReload(fn func(err error)) {
+	c.lock()
+	defer c.mutex.Unlock()
+	c.reloadHooks = append(c.reloadHooks, fn)
+}
+
+// Watch 监视配置文件并在内容变化时自动重新加载
+// 通过定期读取文件并比较内容摘要检测变化，因此也能感知
+// 原子替换(rename)和符号链接切换。重新加载时按与LoadFromFile相同的规则选择格式，解析成功后用新数据
+// 整体替换当前配置数据，未出现在文件中的键(包括Set写入的键)会被移除。
+// 新内容违反DefineKey登记的定义时不会被应用，配置保持原值，*ValidationError通过OnReload回调报告。
+// 通过SetProfile启用profile时同时监视profile文件(如config-prod.ini)，任一文件变化时两者一起重新加载，
+// profile文件中的键覆盖主文件的同名键，与LoadFromFile相同。
+// 重新加载沿用LoadFromFile加载该文件时传入的LoadOption(WithFileFormat、StrictMode、WithDuplicateKeys等)。
+// 无法解析或被拒绝的内容只报告一次，文件内容再次变化后才重新尝试。
+// Watch本身不加载文件，调用前应先完成初始加载。
+// 参数:
+// - filename: 要监视的文件路径
+// - opts: 轮询间隔等可选设置
+// 返回:
+// - func(): 停止监视的函数，可重复调用
+// - error: 首次读取文件失败时返回错误
+func (c *Config) Watch(filename string, opts ...WatchOption) (func(), error) {
+	return c.watch(context.Background(), filename, opts)
+}
+
+// WatchContext 与Watch相同，ctx约束首次读取，ctx结束时停止监视
+// 参数:
+// - ctx: 决定监视期限的context
+// - filename: 要监视的文件路径
+// - opts: 轮询间隔等可选设置
+// 返回:
+// - error: 首次读取文件失败或ctx的错误(如果有)
+func (c *Config) WatchContext(ctx context.Context, filename string, opts ...WatchOption) error {
+	stop, err := c.watch(ctx, filename, opts)
+	if err != nil {
+		return err
+	}
+	context.AfterFunc(ctx, stop)
+	return nil
+}
+
+// watch 在ctx的约束下读取文件作为变化检测的起点，然后在后台轮询
+func (c *Config) watch(ctx context.Context, filename string, opts []WatchOption) (func(), error) {
+	settings := watchSettings{interval: DefaultWatchInterval}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	content, err := readFileContext(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	// 设置了profile时profile文件的变化同样触发重新加载，profile在监视期间可以改变
+	companions := func() []string {
+		if profile := c.Profile(); profile != "" {
+			return []string{profileFilename(filename, profile)}
+		}
+		return nil
+	}
+	last := watchDigest(content, companions())
+
+	done := make(chan struct{})
+	var once sync.Once
+	stop := func() { once.Do(func() { close(done) }) }
+
+	go pollFile(filename, companions, last, settings.interval, done, func(content []byte, err error) {
+		if err == nil {
+			err = c.reloadContent(filename, content, settings.conditions)
+		}
+		c.fireReload(filename, err)
+	})
+	return stop, nil
+}
+
+// pollFile 按interval轮询文件直到done被关闭，内容变化或读取失败时以filename的内容调用fn
+// companions不为nil时，它返回的各文件的出现、消失与内容变化也视为变化，摘要的计算方式见watchDigest。
+// 每个摘要只交给fn一次，无法解析的内容不会在每次轮询时重复回调；
+// 同一读取错误只报告一次，读取恢复后即使内容与之前相同也再次调用fn
+func pollFile(filename string, companions func() []string, last [sha256.Size]byte, interval time.Duration, done <-chan struct{}, fn func(content []byte, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var readErr error
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		content, err := readConfigFile(filename)
+		if err != nil {
+			if readErr == nil || readErr.Error() != err.Error() {
+				readErr = err
+				fn(nil, err)
+			}
+			continue
+		}
+		var names []string
+		if companions != nil {
+			names = companions()
+		}
+		sum := watchDigest(content, names)
+		if readErr == nil && sum == last {
+			continue
+		}
+		readErr = nil
+		last = sum
+		fn(content, nil)
+	}
+}
+
+// watchDigest 计算content与companions中各文件内容的联合摘要，不存在或无法读取的文件记为缺失
+// 没有companions时与sha256.Sum256(content)相同
+func watchDigest(content []byte, companions []string) [sha256.Size]byte {
+	if len(companions) == 0 {
+		return sha256.Sum256(content)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:", len(content))
+	h.Write(content)
+	for _, name := range companions {
+		if extra, err := readConfigFile(name); err == nil {
+			fmt.Fprintf(h, "%d:%s%d:", len(name), name, len(extra))
+			h.Write(extra)
+		} else {
+			fmt.Fprintf(h, "%d:%s-", len(name), name)
+		}
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// FileProvider 把本地配置文件作为Provider，可以与远程配置源一起通过AddProvider组合
+// 格式的选择规则与LoadFromFile相同，变化检测方式与Watch相同
+type FileProvider struct {
+	filename string
+	settings watchSettings
+	done     chan struct{}
+	once     sync.Once
+
+	mutex sync.Mutex
+	last  [sha256.Size]byte // 最近一次Load读取的内容摘要，Watch以此为起点检测变化
+}
+
+// NewFileProvider 创建读取filename的FileProvider
+// 参数:
+// - filename: 配置文件路径
+// - opts: 轮询间隔等可选设置
+// 返回:
+// - *FileProvider: 配置源
+func NewFileProvider(filename string, opts ...WatchOption) *FileProvider {
+	settings := watchSettings{interval: DefaultWatchInterval}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	return &FileProvider{filename: filename, settings: settings, done: make(chan struct{})}
+}
+
+// Load 读取并解析文件
+func (p *FileProvider) Load() (map[string]string, error) {
+	content, err := readConfigFile(p.filename)
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	p.last = sha256.Sum256(content)
+	p.mutex.Unlock()
+	return decodeFile(p.filename, content, p.settings.conditions)
+}
+
+// Watch 轮询文件，内容与最近一次Load相比变化时发送解析后的完整数据，直到Close被调用
+func (p *FileProvider) Watch(ch chan<- Update) error {
+	p.mutex.Lock()
+	last := p.last
+	p.mutex.Unlock()
+	pollFile(p.filename, nil, last, p.settings.interval, p.done, func(content []byte, err error) {
+		var values map[string]string
+		if err == nil {
+			values, err = decodeFile(p.filename, content, p.settings.conditions)
+		}
+		select {
+		case ch <- Update{Values: values, Err: err}:
+		case <-p.done:
+		}
+	})
+	return nil
+}
+
+// Close 停止Watch，可重复调用
+func (p *FileProvider) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+// reloadContent 解析文件内容并整体替换配置数据，解析设置沿用LoadFromFile加载filename时的设置，
+// conditions不为nil时代替其中key=value文件条件指令使用的变量。
+// 设置了profile时像LoadFromFile一样再读取profile文件，其中的键覆盖filename中的同名键；
+// 内容违反DefineKey登记的定义时返回*ValidationError，配置保持原值
+func (c *Config) reloadContent(filename string, content []byte, conditions map[string]string) error {
+	c.mutex.RLock()
+	settings := c.fileSettings[filename]
+	c.mutex.RUnlock()
+	if conditions != nil {
+		settings.conditions = conditions
+	}
+	values, types, err := c.decodeReload(filename, content, settings)
+	if err != nil {
+		return err
+	}
+	pos := make(map[string]linePos, len(values))
+	for k := range values {
+		pos[k] = linePos{file: filename}
+	}
+	if profile := c.Profile(); profile != "" {
+		variant := profileFilename(filename, profile)
+		extra, err := readConfigFile(variant)
+		switch {
+		case err == nil:
+			overlay, overlayTypes, err := c.decodeReload(variant, extra, settings)
+			if err != nil {
+				return fmt.Errorf("%s: %w", variant, err)
+			}
+			for k, v := range overlay {
+				values[k] = v
+				pos[k] = linePos{file: variant}
+			}
+			types = mergeTypes(types, overlay, overlayTypes)
+		case !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+	}
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return ErrFrozen
+	}
+	if err := c.checkDocumentLocked(filename, values, pos, true); err != nil {
+		c.mutex.Unlock()
+		return err
+	}
+	c.recordTypesLocked(values, types)
+	changes := c.replaceLocked(values)
+	for k := range values {
+		c.setSourceLocked(LayerFile, k, KeySource{Kind: SourceFile, Name: pos[k].file})
+	}
+	c.unlockNotify(changes)
+	return nil
+}
+
+// decodeReload 按LoadFromFile的规则与settings解析重新加载的文件内容，返回键规范化后的键值对及decodeContent记录的类型
+func (c *Config) decodeReload(filename string, content []byte, settings loadSettings) (map[string]string, map[string]interface{}, error) {
+	var values map[string]string
+	var types map[string]interface{}
+	var err error
+	format := settings.format
+	if format == "" {
+		format = detectFormat(filename, content)
+	}
+	if format == "ini" {
+		settings.warn = c.logLineWarning
+		var doc *kvDocument
+		if doc, err = parseKeyValue(bytes.NewReader(content), filename, !settings.noInclude, settings); err == nil {
+			values = doc.values
+		}
+	} else {
+		values, types, err = c.decodeContent(format, content)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return normKeys(c, values), types, nil
+}
+
+// mergeTypes 把overlay中键的类型合并到types，overlay没有记录类型的键清除types中的旧类型
+// 两者都为nil时返回nil
+func mergeTypes(types map[string]interface{}, overlay map[string]string, overlayTypes map[string]interface{}) map[string]interface{} {
+	if types == nil && overlayTypes == nil {
+		return nil
+	}
+	if types == nil {
+		types = make(map[string]interface{})
+	}
+	for k := range overlay {
+		if t, ok := overlayTypes[k]; ok {
+			types[k] = t
+		} else {
+			delete(types, k)
+		}
+	}
+	return types
+}
+
+// fireReload 记录重新加载的结果、指标与span，并在锁外依次调用热加载回调，source为重新加载的文件或配置源
+func (c *Config) fireReload(source string, err error) {
+	c.logReload(source, err)
+	c.observeReload(source, err)
+	c.traceReload(source, err)
+	c.mutex.RLock()
+	hooks := append([]func(error){}, c.reloadHooks...)
+	c.mutex.RUnlock()
+	for _, fn := range hooks {
+		fn(err)
+	}
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// watchTestInterval 是测试中Watch的轮询间隔
+const watchTestInterval = 5 * time.Millisecond
+
+// startWatch 加载filename并开始监视，返回接收每次重新加载结果的通道
+func startWatch(t *testing.T, cfg *Config, filename string, opts ...WatchOption) <-chan error {
+	t.Helper()
+	if err := cfg.LoadFromFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	reloads := make(chan error, 16)
+	cfg.OnReload(func(err error) { reloads <- err })
+	stop, err := cfg.Watch(filename, append([]WatchOption{WithPollInterval(watchTestInterval)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	return reloads
+}
+
+// writeAndWait 以重命名原子地替换文件，并等待下一次重新加载的结果
+func writeAndWait(t *testing.T, reloads <-chan error, filename, content string) error {
+	t.Helper()
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reloads:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+		return nil
+	}
+}
+
+func TestWatchReloadSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		want    string
+	}{
+		{"valid", "a = 2\nb = x\n", false, "2"},
+		{"wrong type", "a = notint\nb = x\n", true, "1"},
+		{"required missing", "a = 3\n", true, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "app.ini")
+			os.WriteFile(filename, []byte("a = 1\nb = x\n"), 0o644)
+			cfg, _ := NewConfig()
+			cfg.DefineKey("a", Int)
+			cfg.DefineKey("b", String, Required())
+			reloads := startWatch(t, cfg, filename)
+
+			err := writeAndWait(t, reloads, filename, tt.content)
+			var verr *ValidationError
+			if tt.wantErr != errors.As(err, &verr) {
+				t.Fatalf("reload error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := cfg.Get("a"); got != tt.want {
+				t.Errorf("a = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchReloadProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile bool   // 修改profile文件而不是主文件
+		content string // 为空时删除该文件
+		want    string
+	}{
+		{"base change keeps profile value", false, "port = 81\n", "9090"},
+		{"profile change", true, "port = 9091\n", "9091"},
+		{"profile removed", true, "", "80"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			base := filepath.Join(dir, "config.ini")
+			variant := filepath.Join(dir, "config-prod.ini")
+			os.WriteFile(base, []byte("port = 80\n"), 0o644)
+			os.WriteFile(variant, []byte("port = 9090\n"), 0o644)
+			cfg, _ := NewConfig()
+			cfg.SetProfile("prod")
+			reloads := startWatch(t, cfg, base)
+			if got := cfg.Get("port"); got != "9090" {
+				t.Fatalf("port = %q before reload, want 9090", got)
+			}
+
+			target := base
+			if tt.profile {
+				target = variant
+			}
+			var err error
+			if tt.content == "" {
+				os.Remove(target)
+				select {
+				case err = <-reloads:
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for reload")
+				}
+			} else {
+				err = writeAndWait(t, reloads, target, tt.content)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.Get("port"); got != tt.want {
+				t.Errorf("port = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchReloadProfileSource(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.ini")
+	variant := filepath.Join(dir, "config-prod.ini")
+	os.WriteFile(base, []byte("port = 80\nhost = a\n"), 0o644)
+	os.WriteFile(variant, []byte("port = 9090\n"), 0o644)
+	cfg, _ := NewConfig()
+	cfg.SetProfile("prod")
+	reloads := startWatch(t, cfg, base)
+	if err := writeAndWait(t, reloads, base, "port = 80\nhost = b\n"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"port": variant, "host": base} {
+		if got := cfg.Origin(key); got != want {
+			t.Errorf("Origin(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestWatchReportsParseFailureOnce(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.json")
+	os.WriteFile(filename, []byte(`{"a": 1}`), 0o644)
+	cfg, _ := NewConfig()
+	reloads := startWatch(t, cfg, filename)
+
+	if err := writeAndWait(t, reloads, filename, `{"a": `); err == nil {
+		t.Fatal("reload of truncated JSON succeeded")
+	}
+	select {
+	case err := <-reloads:
+		t.Fatalf("unchanged invalid file reloaded again: %v", err)
+	case <-time.After(20 * watchTestInterval):
+	}
+	if got := cfg.Get("a"); got != "1" {
+		t.Errorf("a = %q after failed reload, want 1", got)
+	}
+	if err := writeAndWait(t, reloads, filename, `{"a": 2}`); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Get("a"); got != "2" {
+		t.Errorf("a = %q after fixed reload, want 2", got)
+	}
+}
+
+func TestWatchReusesLoadOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []LoadOption
+		initial string
+		content string
+		wantErr bool
+		want    string
+	}{
+		{"strict rejects malformed line", []LoadOption{StrictMode()}, "a = 1\n", "a = 2\noops\n", true, "1"},
+		{"first wins", []LoadOption{WithDuplicateKeys(DuplicateFirstWins)}, "a = 1\n", "a = 2\na = 3\n", false, "2"},
+		{"forced format", []LoadOption{WithFileFormat("json")}, `{"a": 1}`, `{"a": 4}`, false, "4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "app.conf")
+			os.WriteFile(filename, []byte(tt.initial), 0o644)
+			cfg, _ := NewConfig()
+			if err := cfg.LoadFromFile(filename, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			reloads := make(chan error, 16)
+			cfg.OnReload(func(err error) { reloads <- err })
+			stop, err := cfg.Watch(filename, WithPollInterval(watchTestInterval))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer stop()
+
+			err = writeAndWait(t, reloads, filename, tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reload error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := cfg.Get("a"); got != tt.want {
+				t.Errorf("a = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+// GetMatching 返回键匹配通配符模式的所有生效值
+// 模式的规则与Subscribe相同："*"匹配不含"."的任意字符串，"**"匹配任意字符串，"?"匹配除"."外的单个字符，
+// 例如"db.*.host"匹配每个副本的host，"servers[*].port"匹配数组每个元素的port。
+// 值的处理方式与Get一致，MarkSecret标记的键也返回明文
+// 参数:
+// - pattern: 通配符模式，不含通配符时按精确键查找
+// 返回:
+// - map[string]string: 匹配的键及其值；没有匹配时返回空映射
+func (c *Config) GetMatching(pattern string) map[string]string {
+	pattern = c.normKey(pattern)
+	all := c.allValues()
+	matches := make(map[string]string)
+	for _, key := range matchingKeys(all, pattern, c.sep()) {
+		matches[key] = all[key]
+	}
+	return matches
+}
+
+// DeleteMatching 从文件层删除键匹配通配符模式的所有键，模式的规则与GetMatching相同
+// 与Delete一样只作用于文件层，别名的旧键一并删除；所有键在一次修改中删除，订阅者对每个键各收到一次通知。
+// 启用写回时先逐个从配置源删除，其中一个失败时本地不做任何修改
+// 参数:
+// - pattern: 通配符模式，如"cache.**"
+// 返回:
+// - map[string]string: 被删除的键及其在文件层中存储的值
+// - error: 写回失败时返回错误，配置已冻结时返回ErrFrozen
+func (c *Config) DeleteMatching(pattern string) (map[string]string, error) {
+	pattern = c.normKey(pattern)
+	c.mutex.RLock()
+	keys := matchingKeys(c.data, pattern, c.sep())
+	c.mutex.RUnlock()
+	for _, key := range keys {
+		if err := c.writeBackTo(key, func(p WritableProvider) error { return p.Delete(key) }); err != nil {
+			return nil, err
+		}
+	}
+
+	c.lock()
+	if c.frozen {
+		c.mutex.Unlock()
+		return nil, ErrFrozen
+	}
+	deleted := make(map[string]string, len(keys))
+	var changes []change
+	for _, key := range keys {
+		val, ok := c.data[key]
+		if !ok {
+			continue
+		}
+		deleted[key] = val
+		changes = c.deleteLocked(key, changes)
+		for _, old := range c.alias.aliasedBy[key] {
+			changes = c.deleteLocked(old, changes)
+		}
+	}
+	c.unlockNotify(changes)
+	return deleted, nil
+}
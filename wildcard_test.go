@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetMatching(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("db.primary.host", "10.0.0.1")
+	cfg.Set("db.replica1.host", "10.0.0.2")
+	cfg.Set("db.replica1.port", "5432")
+	cfg.Set("db.replica1.tls.host", "nested")
+	cfg.Set("servers[0].port", "80")
+	cfg.Set("servers[1].port", "81")
+	cfg.SetDefault("db.replica2.host", "10.0.0.3")
+	cfg.MarkSecret("db.*.host")
+
+	tests := map[string]map[string]string{
+		"db.*.host": {"db.primary.host": "10.0.0.1", "db.replica1.host": "10.0.0.2", "db.replica2.host": "10.0.0.3"},
+		"db.**.host": {"db.primary.host": "10.0.0.1", "db.replica1.host": "10.0.0.2", "db.replica2.host": "10.0.0.3",
+			"db.replica1.tls.host": "nested"},
+		"db.replica?.port": {"db.replica1.port": "5432"},
+		"servers[*].port":  {"servers[0].port": "80", "servers[1].port": "81"},
+		"db.primary.host":  {"db.primary.host": "10.0.0.1"},
+		"cache.*":          {},
+	}
+	for pattern, want := range tests {
+		if got := cfg.GetMatching(pattern); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetMatching(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
+
+func TestDeleteMatching(t *testing.T) {
+	cfg, _ := NewConfig()
+	cfg.Set("cache.ttl", "60")
+	cfg.Set("cache.redis.addr", "localhost:6379")
+	cfg.Set("name", "app")
+	cfg.SetDefault("cache.size", "100") // 只删除文件层
+	var notified []string
+	cfg.Subscribe("cache.**", func(key, _, _ string) { notified = append(notified, key) })
+
+	deleted, err := cfg.DeleteMatching("cache.**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"cache.ttl": "60", "cache.redis.addr": "localhost:6379"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("DeleteMatching = %v, want %v", deleted, want)
+	}
+	if cfg.Has("cache.ttl") || cfg.Get("cache.size") != "100" || cfg.Get("name") != "app" {
+		t.Errorf("after DeleteMatching = %v", cfg.GetAll())
+	}
+	if len(notified) != 2 {
+		t.Errorf("notified %v, want one notification per key", notified)
+	}
+
+	// 写回失败时本地不做任何修改
+	cfg.Set("cache.a", "1")
+	cfg.Set("cache.b", "2")
+	p := &recordingProvider{fail: map[string]bool{"cache.b": true}}
+	cfg.EnableWriteBack(p)
+	if _, err := cfg.DeleteMatching("cache.*"); err == nil {
+		t.Fatal("DeleteMatching with a failing write-back succeeded")
+	}
+	if !cfg.Has("cache.a") || !cfg.Has("cache.b") {
+		t.Errorf("local keys changed after a failed write-back: %v", cfg.GetAll())
+	}
+
+	cfg.Freeze()
+	if _, err := cfg.DeleteMatching("*"); !errors.Is(err, ErrFrozen) {
+		t.Errorf("DeleteMatching on a frozen config error = %v, want ErrFrozen", err)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WritableProvider 是支持写回的配置源
+// Put与Delete使用配置键，由实现按Load相反的规则映射为远程键
+type WritableProvider interface {
+	Provider
+	Put(key, value string) error
+	Delete(key string) error
+}
+
+// EnableWriteBack 启用写回，之后Set、Delete与Update先写入配置源，成功后再修改本地配置
+// 写入失败时本地配置保持不变并返回错误，因此Config成为远程存储的读写门面。
+// 写回只作用于文件层的写入；SetDefault、SetOverride、MergeLayer等仍只修改本地。
+// 配置源同时被WatchProvider或AddProvider监视时，写入的值会随后被同步回来，结果与本地修改一致。
+// 内置的EtcdProvider、ConsulProvider与RedisProvider实现了WritableProvider。
+// 参数:
+// - p: 写回的配置源，为nil时关闭写回
+func (c *Config) EnableWriteBack(p WritableProvider) {
+	c.lock()
+	defer c.mutex.Unlock()
+	c.writeBack = p
+}
+
+// writeBackTo 在启用写回时调用fn写入配置源，须在不持有锁时调用
+// 配置已冻结时返回ErrFrozen，避免写入远程后本地修改失败
+func (c *Config) writeBackTo(key string, fn func(p WritableProvider) error) error {
+	c.mutex.RLock()
+	p, frozen := c.writeBack, c.frozen
+	c.mutex.RUnlock()
+	if p == nil {
+		return nil
+	}
+	if frozen {
+		return ErrFrozen
+	}
+	if err := fn(p); err != nil {
+		return fmt.Errorf("write back %s: %w", key, err)
+	}
+	return nil
+}
+
+// remotePath 是remoteKey的逆映射：把配置键中的delimiter替换为sep并加上前缀
+// 前缀非空且不以sep结尾时在两者之间插入sep
+func remotePath(key, prefix, delimiter, sep string) string {
+	if delimiter != "" && delimiter != sep {
+		key = strings.ReplaceAll(key, delimiter, sep)
+	}
+	if prefix == "" || strings.HasSuffix(prefix, sep) {
+		return prefix + key
+	}
+	return prefix + sep + key
+}
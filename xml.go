@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadFromXML 从XML文件加载配置
+// 根元素本身不出现在键中，子元素按层级以"."连接，属性对应"元素键.@属性名"，
+// 元素的文本内容(去除首尾空白)作为元素键的值；同一父元素下重复出现的同名元素使用"[i]"下标。
+// 例如<configuration><db host="h"><port>5432</port></db></configuration>
+// 对应键"db.@host"与"db.port"。命名空间前缀被忽略，注释与处理指令被跳过。
+// 加载的键会覆盖已有的同名键，启用profile时随后加载同目录下的profile文件
+// 参数:
+// - filename: XML文件路径
+// 返回:
+// - error: 文件读取或XML解析错误(如果有)
+func (c *Config) LoadFromXML(filename string) error {
+	content, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	values, err := decodeXML(content)
+	if err != nil {
+		return err
+	}
+	if err := c.mergeValuesFrom(values, KeySource{Kind: SourceFile, Name: filename}); err != nil {
+		return err
+	}
+	return c.loadProfileFile(context.Background(), filename, loadSettings{})
+}
+
+// xmlElement 是解析过程中的XML元素
+type xmlElement struct {
+	name     string
+	attrs    []xml.Attr
+	text     strings.Builder
+	children []*xmlElement
+}
+
+// decodeXML 解析XML文档并展开为扁平键值对
+func decodeXML(content []byte) (map[string]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	dec.Strict = true
+	var root *xmlElement
+	var stack []*xmlElement
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			el := &xmlElement{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, el)
+			} else if root != nil {
+				return nil, errors.New("xml config must have a single root element")
+			} else {
+				root = el
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("xml config has no root element")
+	}
+	values := make(map[string]string)
+	flattenXML("", root, values)
+	return values, nil
+}
+
+// flattenXML 展开元素的属性、文本与子元素
+func flattenXML(prefix string, el *xmlElement, out map[string]string) {
+	attrs := 0
+	for _, attr := range el.attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		out[joinKey(prefix, "@"+attr.Name.Local)] = attr.Value
+		attrs++
+	}
+	// 只有命名空间声明的空元素与没有属性的空元素一样对应空值
+	text := strings.TrimSpace(el.text.String())
+	if prefix != "" && (text != "" || (len(el.children) == 0 && attrs == 0)) {
+		out[prefix] = text
+	}
+
+	counts := make(map[string]int)
+	for _, child := range el.children {
+		counts[child.name]++
+	}
+	seen := make(map[string]int)
+	for _, child := range el.children {
+		key := joinKey(prefix, child.name)
+		if counts[child.name] > 1 {
+			key += "[" + strconv.Itoa(seen[child.name]) + "]"
+			seen[child.name]++
+		}
+		flattenXML(key, child, out)
+	}
+}
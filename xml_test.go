@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromXML(t *testing.T) {
+	dir := writeFiles(t, t.TempDir(), map[string]string{
+		"app.config": `<?xml version="1.0" encoding="utf-8"?>
+<!-- legacy .NET settings -->
+<configuration xmlns:c="urn:legacy">
+  <db host="db1" c:pool="10">
+    <port> 5432 </port>
+  </db>
+  <servers>
+    <server>a</server>
+    <server>b</server>
+  </servers>
+  <users>
+    <user role="admin"><name>alice</name></user>
+    <user><name>bob</name></user>
+  </users>
+  <c:banner><![CDATA[<hello> & welcome]]></c:banner>
+  <motto>fast &amp; small</motto>
+  <empty/>
+  <scoped xmlns="urn:other"/>
+</configuration>
+`,
+		"app-prod.config": `<configuration><db host="prod-db"/></configuration>`,
+	})
+	path := filepath.Join(dir, "app.config")
+
+	cfg, _ := NewConfig()
+	cfg.Set("db.port", "1")
+	cfg.Set("keep", "yes")
+	if err := cfg.LoadFromXML(path); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"db.@host":            "db1",
+		"db.@pool":            "10", // 命名空间前缀被忽略
+		"db.port":             "5432",
+		"servers.server[0]":   "a",
+		"servers.server[1]":   "b",
+		"users.user[0].@role": "admin",
+		"users.user[0].name":  "alice",
+		"users.user[1].name":  "bob",
+		"banner":              "<hello> & welcome",
+		"motto":               "fast & small",
+		"empty":               "",
+		"scoped":              "",
+		"keep":                "yes",
+	}
+	if got := cfg.GetAll(); len(got) != len(want) {
+		t.Errorf("GetAll = %v, want %d keys", got, len(want))
+	}
+	for key, v := range want {
+		if got := cfg.Get(key); got != v {
+			t.Errorf("Get(%s) = %q, want %q", key, got, v)
+		}
+	}
+	if src, _ := cfg.Source("db.port"); src.Kind != SourceFile || src.Name != path {
+		t.Errorf("Source(db.port) = %+v, want the XML file", src)
+	}
+
+	// 启用profile时随后加载同目录下的profile文件
+	prod, _ := NewConfig()
+	prod.SetProfile("prod")
+	if err := prod.LoadFromXML(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := prod.Get("db.@host"); got != "prod-db" {
+		t.Errorf("db.@host with the prod profile = %q, want prod-db", got)
+	}
+	if got := prod.Get("db.port"); got != "5432" {
+		t.Errorf("db.port with the prod profile = %q, want the base value", got)
+	}
+}
+
+func TestLoadFromXMLErrors(t *testing.T) {
+	dir := t.TempDir()
+	cfg, _ := NewConfig()
+	if err := cfg.LoadFromXML(filepath.Join(dir, "missing.xml")); !os.IsNotExist(err) {
+		t.Errorf("LoadFromXML of a missing file error = %v, want not exist", err)
+	}
+
+	path := filepath.Join(dir, "bad.xml")
+	os.WriteFile(path, []byte("<config><a>1</a></config><other/>"), 0o644)
+	if err := cfg.LoadFromXML(path); err == nil || !strings.Contains(err.Error(), "single root element") {
+		t.Errorf("two roots error = %v", err)
+	}
+	os.WriteFile(path, []byte("<!-- nothing here -->\n"), 0o644)
+	if err := cfg.LoadFromXML(path); err == nil || !strings.Contains(err.Error(), "no root element") {
+		t.Errorf("missing root error = %v", err)
+	}
+	os.WriteFile(path, []byte("<config><a>1</b></config>"), 0o644)
+	if err := cfg.LoadFromXML(path); err == nil {
+		t.Error("mismatched tags were accepted")
+	}
+	os.WriteFile(path, []byte("<config><a>1</a>"), 0o644)
+	if err := cfg.LoadFromXML(path); err == nil {
+		t.Error("an unclosed root element was accepted")
+	}
+	if cfg.Len() != 0 {
+		t.Errorf("failed loads left values behind: %v", cfg.GetAll())
+	}
+}
@@ -0,0 +1,815 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LoadFromYAML 从YAML文件加载配置
+// 嵌套映射展开为以"."连接的键，序列元素使用"[i]"下标。
+// 支持块映射、块序列、流式[...]与{...}、单双引号字符串、
+// 以及"|"和">"块标量；不支持锚点、别名和多文档。
+// 启用profile时随后加载同目录下的profile文件，规则与LoadFromFile相同。
+// 参数:
+// - filename: YAML文件路径
+// 返回:
+// - error: 文件读取或YAML解析错误(如果有)
+func (c *Config) LoadFromYAML(filename string) error {
+	content, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	values, types, err := c.decodeContent("yaml", content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	if err := c.mergeDocumentFrom(filename, values, types, KeySource{Kind: SourceFile, Name: filename}); err != nil {
+		return err
+	}
+	return c.loadProfileFile(context.Background(), filename, loadSettings{})
+}
+
+// SaveToYAML 将文件层配置还原为嵌套结构并以YAML格式保存到文件
+// 与SaveToFile一样通过临时文件加重命名原子写入
+// 参数:
+// - filename: 目标文件路径
+// - opts: 文件权限、属主等可选设置
+// 返回:
+// - error: 键路径冲突或文件操作错误(如果有)
+func (c *Config) SaveToYAML(filename string, opts ...SaveOption) error {
+	content, err := encodeYAML(c.LayerValues(LayerFile))
+	if err != nil {
+		return err
+	}
+	return writeBytesAtomic(filename, content, opts...)
+}
+
+// decodeYAML 解析YAML文档并展开为扁平键值对
+func decodeYAML(content []byte) (map[string]string, error) {
+	root, err := parseYAMLDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	flattenValue("", root, values)
+	return values, nil
+}
+
+// parseYAMLDocument 解析YAML文档，顶层必须是映射，空文档返回空映射
+func parseYAMLDocument(content []byte) (map[string]interface{}, error) {
+	if !utf8.Valid(content) {
+		return nil, errors.New("yaml: invalid UTF-8")
+	}
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	p := &yamlParser{lines: strings.Split(text, "\n")}
+
+	doc, err := p.parseDocument()
+	if err != nil {
+		return nil, err
+	}
+	switch root := doc.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return root, nil
+	}
+	return nil, errors.New("yaml: config must be a mapping at top level")
+}
+
+// yamlParser 是按行工作的YAML子集解析器
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+// yamlLine 表示去掉注释后的一行内容及其缩进
+type yamlLine struct {
+	indent int
+	text   string
+}
+
+// errorf 返回带当前行号的解析错误
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return p.errorfAt(p.pos, format, args...)
+}
+
+// errorfAt 返回带第index行(从0开始)行号的解析错误
+func (p *yamlParser) errorfAt(index int, format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", index+1, fmt.Sprintf(format, args...))
+}
+
+// parseDocument 解析整个文档，允许开头出现一个"---"
+func (p *yamlParser) parseDocument() (interface{}, error) {
+	line, ok, err := p.peek()
+	if err != nil || !ok {
+		return nil, err
+	}
+	if line.indent == 0 && (line.text == "---" || strings.HasPrefix(line.text, "--- ")) {
+		if rest := strings.TrimSpace(line.text[3:]); rest != "" {
+			return nil, p.errorf("content after document marker is not supported")
+		}
+		p.pos++
+	}
+
+	doc, err := p.parseNode(0)
+	if err != nil {
+		return nil, err
+	}
+	line, ok, err = p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if line.text == "---" || line.text == "..." {
+			if line.text == "..." {
+				return doc, nil
+			}
+			return nil, p.errorf("multiple documents are not supported")
+		}
+		return nil, p.errorf("unexpected content %q", line.text)
+	}
+	return doc, nil
+}
+
+// peek 返回下一行非空、非注释内容，不移动位置
+func (p *yamlParser) peek() (yamlLine, bool, error) {
+	for p.pos < len(p.lines) {
+		raw := p.lines[p.pos]
+		text := strings.TrimRight(stripYAMLComment(raw), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" {
+			p.pos++
+			continue
+		}
+		indent := len(text) - len(trimmed)
+		if trimmed[0] == '\t' {
+			return yamlLine{}, false, p.errorf("tabs are not allowed for indentation")
+		}
+		return yamlLine{indent: indent, text: trimmed}, true, nil
+	}
+	return yamlLine{}, false, nil
+}
+
+// parseNode 解析缩进不小于minIndent的节点
+func (p *yamlParser) parseNode(minIndent int) (interface{}, error) {
+	line, ok, err := p.peek()
+	if err != nil || !ok || line.indent < minIndent {
+		return nil, err
+	}
+	if line.text == "---" || line.text == "..." {
+		return nil, nil
+	}
+	if isYAMLSeqItem(line.text) {
+		return p.parseSeq(line.indent)
+	}
+	return p.parseMap(line.indent)
+}
+
+// parseMap 解析缩进为indent的块映射
+func (p *yamlParser) parseMap(indent int) (interface{}, error) {
+	result := make(map[string]interface{})
+	for {
+		line, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || line.indent < indent || line.text == "---" || line.text == "..." {
+			return result, nil
+		}
+		if line.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if isYAMLSeqItem(line.text) {
+			return nil, p.errorf("unexpected sequence item in mapping")
+		}
+
+		key, rest, err := p.splitMapEntry(line.text)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := result[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+
+		value, err := p.parseValue(rest, indent, true)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+}
+
+// parseSeq 解析缩进为indent的块序列
+func (p *yamlParser) parseSeq(indent int) (interface{}, error) {
+	var result []interface{}
+	for {
+		line, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || line.indent < indent || !isYAMLSeqItem(line.text) {
+			if ok && line.indent > indent {
+				return nil, p.errorf("unexpected indentation")
+			}
+			return result, nil
+		}
+		if line.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		content := strings.TrimLeft(line.text[1:], " ")
+		if content == "" {
+			p.pos++
+			item, err := p.parseNode(indent + 1)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+			continue
+		}
+
+		// "- key: value"或"- - item"：把条目内容视为更深缩进的一行重新解析
+		childIndent := indent + (len(line.text) - len(content))
+		if isYAMLSeqItem(content) || isYAMLMapEntry(content) {
+			p.lines[p.pos] = strings.Repeat(" ", childIndent) + content
+			item, err := p.parseNode(childIndent)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+			continue
+		}
+
+		p.pos++
+		item, err := p.parseValue(content, indent, false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+}
+
+// parseValue 解析冒号或"- "之后的内容
+// parentIndent为所属映射键或序列项的缩进，allowSameIndentSeq表示
+// 是否允许与父键同缩进的序列(如"key:\n- a")
+func (p *yamlParser) parseValue(rest string, parentIndent int, allowSameIndentSeq bool) (interface{}, error) {
+	if rest == "" {
+		line, ok, err := p.peek()
+		if err != nil || !ok {
+			return nil, err
+		}
+		if allowSameIndentSeq && line.indent == parentIndent && isYAMLSeqItem(line.text) {
+			return p.parseSeq(parentIndent)
+		}
+		return p.parseNode(parentIndent + 1)
+	}
+	if rest[0] == '|' || rest[0] == '>' {
+		return p.parseBlockScalar(rest, parentIndent)
+	}
+	// rest来自调用方已经读过的上一行，错误报告该行的行号
+	if rest[0] == '&' || rest[0] == '*' {
+		return nil, p.errorfAt(p.pos-1, "anchors and aliases are not supported")
+	}
+	if strings.HasPrefix(rest, "!!") {
+		if _, after, found := strings.Cut(rest, " "); found {
+			rest = strings.TrimSpace(after)
+		}
+	}
+	value, err := parseYAMLInline(rest)
+	if err != nil {
+		return nil, p.errorfAt(p.pos-1, "%v", err)
+	}
+	return value, nil
+}
+
+// parseBlockScalar 解析"|"(保留换行)或">"(折叠换行)块标量
+func (p *yamlParser) parseBlockScalar(header string, parentIndent int) (interface{}, error) {
+	folded := header[0] == '>'
+	chomp := byte(0)
+	for _, ch := range header[1:] {
+		switch ch {
+		case '-', '+':
+			chomp = byte(ch)
+		case ' ':
+		default:
+			if ch < '1' || ch > '9' {
+				return nil, p.errorf("invalid block scalar header %q", header)
+			}
+		}
+	}
+
+	var lines []string
+	contentIndent := -1
+	for p.pos < len(p.lines) {
+		raw := strings.TrimRight(p.lines[p.pos], " \t")
+		trimmed := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(trimmed)
+		if trimmed == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if indent <= parentIndent {
+			break
+		}
+		if contentIndent < 0 {
+			contentIndent = indent
+		}
+		if indent < contentIndent {
+			return nil, p.errorf("block scalar line is less indented than its first line")
+		}
+		lines = append(lines, raw[contentIndent:])
+		p.pos++
+	}
+
+	// 末尾空行交由chomp规则处理
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var text string
+	if folded {
+		var b strings.Builder
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "" || lines[i-1] == "":
+				b.WriteByte('\n')
+			case strings.HasPrefix(line, " "):
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+			b.WriteString(line)
+		}
+		text = b.String()
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+
+	switch chomp {
+	case '-':
+	case '+':
+		text += strings.Repeat("\n", trailing+1)
+	default:
+		if len(lines) > 0 {
+			text += "\n"
+		}
+	}
+	return text, nil
+}
+
+// splitMapEntry 将"key: value"拆分为键和值部分
+func (p *yamlParser) splitMapEntry(text string) (string, string, error) {
+	var key string
+	rest := text
+	if text[0] == '"' || text[0] == '\'' {
+		end := quotedEnd(text)
+		if end < 0 {
+			return "", "", p.errorf("unterminated quoted key")
+		}
+		k, err := parseYAMLQuoted(text[:end+1])
+		if err != nil {
+			return "", "", p.errorf("%v", err)
+		}
+		key, rest = k, text[end+1:]
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", p.errorf("expected ':' after key")
+		}
+		return key, strings.TrimSpace(rest[1:]), nil
+	}
+
+	idx := mapSeparatorIndex(text)
+	if idx < 0 {
+		return "", "", p.errorf("expected 'key: value', got %q", text)
+	}
+	return strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx+1:]), nil
+}
+
+// mapSeparatorIndex 返回普通键后": "或行尾":"的位置，不存在时返回-1
+func mapSeparatorIndex(text string) int {
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return i
+		}
+	}
+	return -1
+}
+
+// isYAMLSeqItem 判断一行是否为块序列条目
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isYAMLMapEntry 判断内容是否以映射键开头
+func isYAMLMapEntry(text string) bool {
+	if text == "" || text[0] == '[' || text[0] == '{' {
+		return false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := quotedEnd(text)
+		return end >= 0 && strings.HasPrefix(text[end+1:], ":")
+	}
+	return mapSeparatorIndex(text) > 0
+}
+
+// stripYAMLComment 去掉引号之外以" #"开始的注释
+func stripYAMLComment(line string) string {
+	inSingle, inDouble := false, false
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case ch == '\\' && inDouble:
+			i++
+		case ch == '"' && !inSingle:
+			inDouble = !inDouble
+		case ch == '\'' && !inDouble:
+			inSingle = !inSingle
+		case ch == '#' && !inSingle && !inDouble:
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// quotedEnd 返回以引号开头的字符串中闭合引号的位置，未闭合时返回-1
+func quotedEnd(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q:
+			if q == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// parseYAMLInline 解析单行内的标量或流式集合
+func parseYAMLInline(s string) (interface{}, error) {
+	if s != "" && (s[0] == '[' || s[0] == '{') {
+		fp := &yamlFlowParser{s: s}
+		v, err := fp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		fp.skipSpace()
+		if fp.i != len(fp.s) {
+			return nil, fmt.Errorf("unexpected %q after flow collection", fp.s[fp.i:])
+		}
+		return v, nil
+	}
+	return parseYAMLScalar(s)
+}
+
+// parseYAMLScalar 解析标量，null和~返回nil，未加引号的数字与布尔字面量返回json.Number与bool，
+// 展开后的字符串与原样相同，类型供WithTypedValues使用
+func parseYAMLScalar(s string) (interface{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		end := quotedEnd(s)
+		if end != len(s)-1 {
+			return nil, fmt.Errorf("malformed quoted scalar %s", s)
+		}
+		return parseYAMLQuoted(s)
+	}
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if n, ok := inferScalar(s).(json.Number); ok {
+		return n, nil
+	}
+	return s, nil
+}
+
+// parseYAMLQuoted 解析单引号或双引号字符串
+func parseYAMLQuoted(s string) (string, error) {
+	body := s[1 : len(s)-1]
+	if s[0] == '\'' {
+		return strings.ReplaceAll(body, "''", "'"), nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		if ch != '\\' {
+			b.WriteByte(ch)
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", errors.New("trailing backslash in quoted string")
+		}
+		switch body[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'e':
+			b.WriteByte(0x1b)
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '"', '\\', '/', ' ':
+			b.WriteByte(body[i])
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[body[i]]
+			if i+1+size > len(body) {
+				return "", fmt.Errorf("short escape sequence in %s", s)
+			}
+			n, err := strconv.ParseUint(body[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence in %s", s)
+			}
+			b.WriteRune(rune(n))
+			i += size
+		default:
+			return "", fmt.Errorf("unknown escape \\%c", body[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// yamlFlowParser 解析[...]与{...}流式集合
+type yamlFlowParser struct {
+	s string
+	i int
+}
+
+func (fp *yamlFlowParser) skipSpace() {
+	for fp.i < len(fp.s) && (fp.s[fp.i] == ' ' || fp.s[fp.i] == '\t') {
+		fp.i++
+	}
+}
+
+func (fp *yamlFlowParser) parseValue() (interface{}, error) {
+	fp.skipSpace()
+	if fp.i >= len(fp.s) {
+		return nil, errors.New("unexpected end of flow collection")
+	}
+	switch fp.s[fp.i] {
+	case '[':
+		return fp.parseSeq()
+	case '{':
+		return fp.parseMap()
+	case '"', '\'':
+		end := quotedEnd(fp.s[fp.i:])
+		if end < 0 {
+			return nil, errors.New("unterminated quoted string")
+		}
+		raw := fp.s[fp.i : fp.i+end+1]
+		fp.i += end + 1
+		return parseYAMLQuoted(raw)
+	}
+	start := fp.i
+	for fp.i < len(fp.s) && !strings.ContainsRune(",]}", rune(fp.s[fp.i])) {
+		if fp.s[fp.i] == ':' && fp.i+1 < len(fp.s) && fp.s[fp.i+1] == ' ' {
+			break
+		}
+		fp.i++
+	}
+	return parseYAMLScalar(strings.TrimSpace(fp.s[start:fp.i]))
+}
+
+func (fp *yamlFlowParser) parseSeq() (interface{}, error) {
+	fp.i++
+	result := []interface{}{}
+	for {
+		fp.skipSpace()
+		if fp.i < len(fp.s) && fp.s[fp.i] == ']' {
+			fp.i++
+			return result, nil
+		}
+		item, err := fp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+		fp.skipSpace()
+		if fp.i >= len(fp.s) {
+			return nil, errors.New("unterminated flow sequence")
+		}
+		switch fp.s[fp.i] {
+		case ',':
+			fp.i++
+		case ']':
+		default:
+			return nil, fmt.Errorf("unexpected %q in flow sequence", fp.s[fp.i])
+		}
+	}
+}
+
+func (fp *yamlFlowParser) parseMap() (interface{}, error) {
+	fp.i++
+	result := map[string]interface{}{}
+	for {
+		fp.skipSpace()
+		if fp.i < len(fp.s) && fp.s[fp.i] == '}' {
+			fp.i++
+			return result, nil
+		}
+		k, err := fp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		var key string
+		switch k := k.(type) {
+		case string:
+			key = k
+		case json.Number, bool:
+			key = formatScalar(k)
+		default:
+			return nil, errors.New("flow mapping key must be a scalar")
+		}
+		fp.skipSpace()
+		if fp.i >= len(fp.s) || fp.s[fp.i] != ':' {
+			return nil, fmt.Errorf("expected ':' after flow mapping key %q", key)
+		}
+		fp.i++
+		value, err := fp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+		fp.skipSpace()
+		if fp.i >= len(fp.s) {
+			return nil, errors.New("unterminated flow mapping")
+		}
+		switch fp.s[fp.i] {
+		case ',':
+			fp.i++
+		case '}':
+		default:
+			return nil, fmt.Errorf("unexpected %q in flow mapping", fp.s[fp.i])
+		}
+	}
+}
+
+// encodeYAML 将扁平键值对编码为块格式YAML文档
+func encodeYAML(data map[string]string) ([]byte, error) {
+	tree, err := nestValues(data)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	writeYAMLMap(&b, tree, 0, false)
+	return []byte(b.String()), nil
+}
+
+// writeYAMLMap 以indent缩进写出映射，inline为true时首个键紧跟在"- "之后
+func writeYAMLMap(b *strings.Builder, m map[string]interface{}, indent int, inline bool) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pad := strings.Repeat(" ", indent)
+	for i, k := range keys {
+		if i > 0 || !inline {
+			b.WriteString(pad)
+		}
+		b.WriteString(yamlQuote(k))
+		b.WriteString(":")
+		writeYAMLChild(b, m[k], indent)
+	}
+}
+
+// writeYAMLSeq 以indent缩进写出序列，inline为true时首项紧跟在"- "之后
+func writeYAMLSeq(b *strings.Builder, items []interface{}, indent int, inline bool) {
+	pad := strings.Repeat(" ", indent)
+	for i, item := range items {
+		if i > 0 || !inline {
+			b.WriteString(pad)
+		}
+		b.WriteString("-")
+		switch child := item.(type) {
+		case map[string]interface{}:
+			if len(child) > 0 {
+				b.WriteString(" ")
+				writeYAMLMap(b, child, indent+2, true)
+				continue
+			}
+		case []interface{}:
+			if len(child) > 0 {
+				b.WriteString(" ")
+				writeYAMLSeq(b, child, indent+2, true)
+				continue
+			}
+		}
+		writeYAMLChild(b, item, indent)
+	}
+}
+
+// writeYAMLChild 写出键或序列项之后的值
+func writeYAMLChild(b *strings.Builder, v interface{}, indent int) {
+	switch child := v.(type) {
+	case map[string]interface{}:
+		b.WriteString("\n")
+		writeYAMLMap(b, child, indent+2, false)
+	case []interface{}:
+		b.WriteString("\n")
+		writeYAMLSeq(b, child, indent+2, false)
+	case nil:
+		b.WriteString(" null\n")
+	case string:
+		if writeYAMLLiteral(b, child, indent+2) {
+			return
+		}
+		b.WriteString(" ")
+		b.WriteString(yamlQuote(child))
+		b.WriteString("\n")
+	default:
+		b.WriteString(" ")
+		b.WriteString(formatScalar(child))
+		b.WriteString("\n")
+	}
+}
+
+// writeYAMLLiteral 将多行字符串写为"|"块标量，不适合块标量时返回false
+func writeYAMLLiteral(b *strings.Builder, s string, indent int) bool {
+	if !strings.Contains(s, "\n") || strings.ContainsAny(s, "\r\t") ||
+		strings.HasPrefix(s, " ") || strings.HasSuffix(s, "\n\n") {
+		return false
+	}
+	body, header := s, " |-"
+	if strings.HasSuffix(s, "\n") {
+		body, header = s[:len(s)-1], " |"
+	}
+	lines := strings.Split(body, "\n")
+	for _, line := range lines {
+		if line != strings.TrimRight(line, " ") {
+			return false
+		}
+	}
+
+	pad := strings.Repeat(" ", indent)
+	b.WriteString(header)
+	b.WriteString("\n")
+	for _, line := range lines {
+		if line != "" {
+			b.WriteString(pad)
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return true
+}
+
+// yamlQuote 在字符串会被误解析时为其加上双引号
+func yamlQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	needsQuote := strings.ContainsAny(s, "\n\r\t\"\\") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.HasSuffix(s, ":") ||
+		s != strings.TrimSpace(s) ||
+		strings.ContainsRune("-?:,[]{}#&*!|>'%@`", rune(s[0]))
+	if !needsQuote {
+		switch strings.ToLower(s) {
+		case "true", "false", "yes", "no", "on", "off", "null", "~":
+			needsQuote = true
+		}
+	}
+	if !needsQuote {
+		if _, isNum := inferScalar(s).(string); !isNum {
+			needsQuote = true
+		}
+	}
+	if needsQuote {
+		return strconv.Quote(s)
+	}
+	return s
+}
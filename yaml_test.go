@@ -0,0 +1,117 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "block mapping",
+			input: "server:\n  host: localhost # comment\n  port: 8080\n",
+			want:  map[string]string{"server.host": "localhost", "server.port": "8080"},
+		},
+		{
+			name:  "block sequence",
+			input: "hosts:\n  - a\n  - b\nusers:\n- name: x\n  role: admin\n",
+			want:  map[string]string{"hosts[0]": "a", "hosts[1]": "b", "users[0].name": "x", "users[0].role": "admin"},
+		},
+		{
+			name:  "flow collections",
+			input: "ports: [80, 443]\nlabels: {env: prod, team: 'core'}\n",
+			want:  map[string]string{"ports[0]": "80", "ports[1]": "443", "labels.env": "prod", "labels.team": "core"},
+		},
+		{
+			name:  "quoted strings",
+			input: "a: \"x\\ty #1\"\nb: 'it''s'\nc: \"\"\n",
+			want:  map[string]string{"a": "x\ty #1", "b": "it's", "c": ""},
+		},
+		{
+			name:  "null and booleans",
+			input: "a: ~\nb: null\nc: true\nd:\n",
+			want:  map[string]string{"a": "", "b": "", "c": "true", "d": ""},
+		},
+		{
+			name:  "literal block scalar",
+			input: "cert: |\n  line1\n  line2\nnext: 1\n",
+			want:  map[string]string{"cert": "line1\nline2\n", "next": "1"},
+		},
+		{
+			name:  "folded block scalar",
+			input: "desc: >\n  one\n  two\n",
+			want:  map[string]string{"desc": "one two\n"},
+		},
+		{
+			name:  "document marker",
+			input: "---\na: 1\n",
+			want:  map[string]string{"a": "1"},
+		},
+		{name: "empty document", input: "", want: map[string]string{}},
+		{name: "top level sequence", input: "- a\n", wantErr: true},
+		{name: "unterminated quote", input: "a: \"x\n", wantErr: true},
+		{name: "unterminated flow", input: "a: [1, 2\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeYAML([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeYAML error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeYAML = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeYAMLErrorLine(t *testing.T) {
+	tests := []struct {
+		input string
+		line  string
+	}{
+		{"a: 1\nb: 'x' y\n", "line 2:"},
+		{"a: 1\nb: *ref\n", "line 2:"},
+		{"a:\n  - [1\n", "line 2:"},
+		{"a: 1\na: 2\n", "line 2:"},
+		{"a:\n\tb: 1\n", "line 2:"},
+	}
+	for _, tt := range tests {
+		_, err := decodeYAML([]byte(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.line) {
+			t.Errorf("decodeYAML(%q) error = %v, want %s", tt.input, err, tt.line)
+		}
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"server.host":     "localhost",
+		"server.port":     "8080",
+		"hosts[0]":        "a",
+		"hosts[1]":        "b",
+		"servers[0].name": "x",
+		"quoted":          "a: b # c",
+		"multiline":       "line1\nline2\n",
+		"version":         "1.0",
+		"empty":           "",
+		"flag":            "yes",
+	}
+	content, err := encodeYAML(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeYAML(content)
+	if err != nil {
+		t.Fatalf("decodeYAML(%s): %v", content, err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("round trip of\n%s= %q, want %q", content, got, values)
+	}
+}
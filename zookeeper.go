@@ -0,0 +1,556 @@
+package config
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ZooKeeperProvider 从ZooKeeper读取一棵znode子树，直接实现ZooKeeper客户端协议，不依赖第三方客户端
+// Root下每个znode的路径(去掉Root)中的"/"替换为Delimiter后作为键，节点数据作为值，
+// 例如Root为"/myapp"时"/myapp/server/port"对应键"server.port"；有子节点且数据为空的节点只作为路径，不产生键。
+// Watch在读取时为每个节点注册数据与子节点watch，任何节点变化后重新读取整棵子树并重新注册。
+type ZooKeeperProvider struct {
+	// Servers 是ZooKeeper服务器地址列表，如"zk1:2181"，连接时从随机一台开始依次尝试
+	Servers []string
+	// Root 是要读取的子树根路径
+	Root string
+	// Delimiter 替换路径中的"/"，为空时保留"/"
+	Delimiter string
+	// Username和Password 非空时以digest方式认证，用于读取设置了ACL的节点
+	Username string
+	Password string
+	// SessionTimeout 是请求的会话超时时间，实际值由服务端协商决定
+	SessionTimeout time.Duration
+	// DialTimeout 是建立连接的超时时间
+	DialTimeout time.Duration
+	// RetryInterval 是Watch连接断开后的重试间隔
+	RetryInterval time.Duration
+
+	mutex  sync.Mutex
+	conn   *zkConn
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewZooKeeperProvider 创建读取root子树的ZooKeeper配置源
+// 参数:
+// - servers: ZooKeeper服务器地址列表
+// - root: 子树根路径，如"/myapp"
+// 返回:
+// - *ZooKeeperProvider: 配置源实例
+func NewZooKeeperProvider(servers []string, root string) *ZooKeeperProvider {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ZooKeeperProvider{
+		Servers:        servers,
+		Root:           root,
+		Delimiter:      ".",
+		SessionTimeout: 10 * time.Second,
+		DialTimeout:    5 * time.Second,
+		RetryInterval:  time.Second,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// LoadFromZooKeeper 从ZooKeeper加载root子树下的所有节点并合并到文件层
+// 需要持续同步时可使用AddProvider(NewZooKeeperProvider(servers, root))
+// 参数:
+// - servers: ZooKeeper服务器地址列表
+// - root: 子树根路径
+// 返回:
+// - error: 连接、请求或认证错误(如果有)
+func (c *Config) LoadFromZooKeeper(servers []string, root string) error {
+	p := NewZooKeeperProvider(servers, root)
+	defer p.Close()
+	return c.LoadFromProvider(p)
+}
+
+// Close 关闭配置源并终止正在进行的Watch
+func (p *ZooKeeperProvider) Close() error {
+	p.cancel()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.conn != nil {
+		p.conn.c.Close()
+	}
+	return nil
+}
+
+// Load 建立一个会话读取整棵子树，读取完成后关闭会话
+func (p *ZooKeeperProvider) Load() (map[string]string, error) {
+	return p.LoadContext(context.Background())
+}
+
+// LoadContext 与Load相同，ctx取消或超时时关闭连接以中止读取
+func (p *ZooKeeperProvider) LoadContext(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := providerContext(ctx, p.ctx)
+	defer cancel()
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.c.Close() })
+	defer stop()
+	values, err := p.readTree(conn, false)
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("zookeeper: %w", ctx.Err())
+	}
+	return values, err
+}
+
+// Watch 读取子树并注册watch，节点变化时重新读取并在数据变化时发送完整数据
+// 连接断开或会话过期时按RetryInterval重连，重连后重新读取以弥补断开期间错过的变化
+func (p *ZooKeeperProvider) Watch(ch chan<- Update) error {
+	current, err := p.Load()
+	if err != nil {
+		return err
+	}
+	for {
+		err := p.watchOnce(&current, ch)
+		if p.ctx.Err() != nil {
+			return ErrProviderClosed
+		}
+		if err != nil {
+			select {
+			case ch <- Update{Err: err}:
+			case <-p.ctx.Done():
+				return ErrProviderClosed
+			}
+		}
+		select {
+		case <-time.After(p.RetryInterval):
+		case <-p.ctx.Done():
+			return ErrProviderClosed
+		}
+	}
+}
+
+// watchOnce 建立一个会话并处理watch事件，current在数据变化时被更新
+func (p *ZooKeeperProvider) watchOnce(current *map[string]string, ch chan<- Update) error {
+	conn, err := p.dial(p.ctx)
+	if err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	if p.ctx.Err() != nil {
+		p.mutex.Unlock()
+		conn.Close()
+		return nil
+	}
+	p.conn = conn
+	p.mutex.Unlock()
+	defer func() {
+		p.mutex.Lock()
+		p.conn = nil
+		p.mutex.Unlock()
+		conn.Close()
+	}()
+
+	// 在会话超时的三分之一内没有请求时发送心跳，避免会话过期
+	ping := time.NewTicker(conn.timeout / 3)
+	defer ping.Stop()
+	for {
+		next, err := p.readTree(conn, true)
+		if err != nil {
+			return err
+		}
+		if !maps.Equal(next, *current) {
+			*current = next
+			select {
+			case ch <- Update{Values: maps.Clone(next)}:
+			case <-p.ctx.Done():
+				return nil
+			}
+		}
+
+	wait:
+		for {
+			select {
+			case <-conn.events:
+				break wait
+			case <-ping.C:
+				if err := conn.send(zkXidPing, zkOpPing, nil); err != nil {
+					return fmt.Errorf("zookeeper: ping: %w", err)
+				}
+			case <-conn.done:
+				return fmt.Errorf("zookeeper: %w", conn.err)
+			case <-p.ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// readTree 递归读取Root子树，watch为true时同时注册数据与子节点watch
+func (p *ZooKeeperProvider) readTree(conn *zkConn, watch bool) (map[string]string, error) {
+	root := "/" + strings.Trim(p.Root, "/")
+	delimiter := p.Delimiter
+	if delimiter == "" {
+		delimiter = "/"
+	}
+	values := make(map[string]string)
+	if err := p.readNode(conn, root, "", delimiter, watch, values); err != nil {
+		return nil, fmt.Errorf("zookeeper: read %s: %w", root, err)
+	}
+	return values, nil
+}
+
+// readNode 读取一个节点的数据与子节点
+func (p *ZooKeeperProvider) readNode(conn *zkConn, path, key, delimiter string, watch bool, values map[string]string) error {
+	data, err := conn.getData(path, watch)
+	if err != nil {
+		return err
+	}
+	children, err := conn.getChildren(path, watch)
+	if err != nil {
+		return err
+	}
+	if key != "" && (len(data) > 0 || len(children) == 0) {
+		values[key] = string(data)
+	}
+	sort.Strings(children)
+	for _, child := range children {
+		childKey := child
+		if key != "" {
+			childKey = key + delimiter + child
+		}
+		err := p.readNode(conn, strings.TrimSuffix(path, "/")+"/"+child, childKey, delimiter, watch, values)
+		// 子节点可能在列出后被删除，其父节点的子节点watch会触发重新读取
+		if err != nil && !errors.Is(err, zkErrNoNode) {
+			return err
+		}
+	}
+	return nil
+}
+
+// dial 依次尝试各服务器建立会话并完成认证
+func (p *ZooKeeperProvider) dial(ctx context.Context) (*zkConn, error) {
+	if len(p.Servers) == 0 {
+		return nil, errors.New("zookeeper: no servers configured")
+	}
+	var errs []error
+	start := rand.Intn(len(p.Servers))
+	for i := range p.Servers {
+		addr := p.Servers[(start+i)%len(p.Servers)]
+		conn, err := p.connect(ctx, addr)
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("zookeeper: %w", ctx.Err())
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+	}
+	return nil, fmt.Errorf("zookeeper: %w", errors.Join(errs...))
+}
+
+// connect 连接一台服务器并建立新会话
+func (p *ZooKeeperProvider) connect(ctx context.Context, addr string) (*zkConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.DialTimeout)
+	defer cancel()
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// ConnectRequest: protocolVersion, lastZxidSeen, timeOut, sessionId, passwd
+	req := binary.BigEndian.AppendUint32(nil, 0)
+	req = binary.BigEndian.AppendUint64(req, 0)
+	req = binary.BigEndian.AppendUint32(req, uint32(p.SessionTimeout.Milliseconds()))
+	req = binary.BigEndian.AppendUint64(req, 0)
+	req = zkAppendBytes(req, make([]byte, 16))
+	c.SetDeadline(time.Now().Add(p.DialTimeout))
+	if err := zkWriteFrame(c, req); err != nil {
+		c.Close()
+		return nil, err
+	}
+	frame, err := zkReadFrame(c)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	r := zkReader{b: frame}
+	r.int32() // protocolVersion
+	timeout := time.Duration(r.int32()) * time.Millisecond
+	if r.err != nil {
+		c.Close()
+		return nil, r.err
+	}
+	if timeout <= 0 {
+		c.Close()
+		return nil, zkErrSessionExpired
+	}
+	c.SetDeadline(time.Time{})
+
+	conn := &zkConn{
+		c:       c,
+		timeout: timeout,
+		replies: make(chan zkReply),
+		events:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go conn.readLoop()
+	if p.Username != "" {
+		body := binary.BigEndian.AppendUint32(nil, 0)
+		body = zkAppendString(body, "digest")
+		body = zkAppendBytes(body, []byte(p.Username+":"+p.Password))
+		if _, err := conn.call(zkXidAuth, zkOpAuth, body); err != nil {
+			conn.c.Close()
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// ZooKeeper协议中的操作码与特殊xid
+const (
+	zkOpGetData      = 4
+	zkOpGetChildren  = 8
+	zkOpPing         = 11
+	zkOpAuth         = 100
+	zkOpCloseSession = -11
+
+	zkXidWatchEvent = -1
+	zkXidPing       = -2
+	zkXidAuth       = -4
+)
+
+// zkError 是服务端返回的错误码
+type zkError int32
+
+const (
+	zkErrNoNode         zkError = -101
+	zkErrNoAuth         zkError = -102
+	zkErrSessionExpired zkError = -112
+	zkErrAuthFailed     zkError = -115
+)
+
+func (e zkError) Error() string {
+	switch e {
+	case zkErrNoNode:
+		return "node does not exist"
+	case zkErrNoAuth:
+		return "not authorized"
+	case zkErrSessionExpired:
+		return "session expired"
+	case zkErrAuthFailed:
+		return "authentication failed"
+	}
+	return fmt.Sprintf("error code %d", int32(e))
+}
+
+// zkReply 是一个请求的回复
+type zkReply struct {
+	xid  int32
+	code int32
+	body []byte
+}
+
+// zkConn 是一个ZooKeeper会话连接
+// 后台goroutine读取所有回复，watch事件通过events通知，其余回复交给等待中的请求；
+// 请求由同一个goroutine串行发送
+type zkConn struct {
+	c       net.Conn
+	timeout time.Duration
+	xid     int32
+	replies chan zkReply
+	events  chan struct{}
+	done    chan struct{}
+	err     error // 读取失败的原因，done关闭后有效
+}
+
+// Close 关闭会话与连接
+func (conn *zkConn) Close() error {
+	conn.call(conn.nextXid(), zkOpCloseSession, nil)
+	return conn.c.Close()
+}
+
+// readLoop 读取回复直到连接断开
+func (conn *zkConn) readLoop() {
+	defer close(conn.done)
+	for {
+		frame, err := zkReadFrame(conn.c)
+		if err != nil {
+			conn.err = err
+			return
+		}
+		r := zkReader{b: frame}
+		reply := zkReply{xid: r.int32()}
+		r.int64() // zxid
+		reply.code = r.int32()
+		if r.err != nil {
+			conn.err = r.err
+			return
+		}
+		reply.body = r.b
+		switch reply.xid {
+		case zkXidWatchEvent:
+			select {
+			case conn.events <- struct{}{}:
+			default:
+			}
+		case zkXidPing:
+		default:
+			select {
+			case conn.replies <- reply:
+			case <-time.After(conn.timeout):
+				conn.err = errors.New("reply not consumed")
+				return
+			}
+		}
+	}
+}
+
+func (conn *zkConn) nextXid() int32 {
+	conn.xid++
+	return conn.xid
+}
+
+// send 写出一个请求帧
+func (conn *zkConn) send(xid, op int32, body []byte) error {
+	frame := binary.BigEndian.AppendUint32(nil, uint32(xid))
+	frame = binary.BigEndian.AppendUint32(frame, uint32(op))
+	frame = append(frame, body...)
+	conn.c.SetWriteDeadline(time.Now().Add(conn.timeout))
+	return zkWriteFrame(conn.c, frame)
+}
+
+// call 发送请求并等待对应的回复
+func (conn *zkConn) call(xid, op int32, body []byte) ([]byte, error) {
+	if err := conn.send(xid, op, body); err != nil {
+		return nil, err
+	}
+	select {
+	case reply := <-conn.replies:
+		if reply.xid != xid {
+			return nil, fmt.Errorf("unexpected reply xid %d, want %d", reply.xid, xid)
+		}
+		if reply.code != 0 {
+			return nil, zkError(reply.code)
+		}
+		return reply.body, nil
+	case <-conn.done:
+		return nil, conn.err
+	case <-time.After(conn.timeout):
+		return nil, errors.New("request timed out")
+	}
+}
+
+// getData 读取节点数据
+func (conn *zkConn) getData(path string, watch bool) ([]byte, error) {
+	body, err := conn.call(conn.nextXid(), zkOpGetData, zkAppendBool(zkAppendString(nil, path), watch))
+	if err != nil {
+		return nil, err
+	}
+	r := zkReader{b: body}
+	data := r.bytes()
+	return data, r.err
+}
+
+// getChildren 读取子节点名称
+func (conn *zkConn) getChildren(path string, watch bool) ([]string, error) {
+	body, err := conn.call(conn.nextXid(), zkOpGetChildren, zkAppendBool(zkAppendString(nil, path), watch))
+	if err != nil {
+		return nil, err
+	}
+	r := zkReader{b: body}
+	n := r.int32()
+	children := make([]string, 0, max(n, 0))
+	for i := int32(0); i < n && r.err == nil; i++ {
+		children = append(children, string(r.bytes()))
+	}
+	return children, r.err
+}
+
+// zkReadFrame 读取一个带4字节长度前缀的帧
+func zkReadFrame(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > 16<<20 {
+		return nil, fmt.Errorf("frame too large (%d bytes)", n)
+	}
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// zkWriteFrame 写出一个带4字节长度前缀的帧
+func zkWriteFrame(w io.Writer, frame []byte) error {
+	_, err := w.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(frame))), frame...))
+	return err
+}
+
+func zkAppendBytes(b, data []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+func zkAppendString(b []byte, s string) []byte {
+	return zkAppendBytes(b, []byte(s))
+}
+
+func zkAppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+// zkReader 按jute编码解析回复，出错后的读取返回零值并保留第一个错误
+type zkReader struct {
+	b   []byte
+	err error
+}
+
+func (r *zkReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.b) < n {
+		r.err = errors.New("zookeeper: truncated reply")
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *zkReader) int32() int32 {
+	if v := r.next(4); v != nil {
+		return int32(binary.BigEndian.Uint32(v))
+	}
+	return 0
+}
+
+func (r *zkReader) int64() int64 {
+	if v := r.next(8); v != nil {
+		return int64(binary.BigEndian.Uint64(v))
+	}
+	return 0
+}
+
+// bytes 读取长度前缀的字节串，长度为-1表示空值
+func (r *zkReader) bytes() []byte {
+	n := r.int32()
+	if n <= 0 {
+		return nil
+	}
+	return r.next(int(n))
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeZooKeeper 在net.Listen上实现ZooKeeperProvider用到的那部分ZooKeeper协议
+// 支持会话建立、getData、getChildren、ping、digest认证与关闭会话，update修改节点并向注册了watch的连接发送事件
+type fakeZooKeeper struct {
+	ln       net.Listener
+	mutex    sync.Mutex
+	nodes    map[string]string // 节点路径到数据
+	auth     string            // 非空时要求"user:password" digest认证
+	watching []net.Conn        // 注册过watch的连接
+	watched  chan struct{}     // 每次注册watch时通知
+}
+
+func newFakeZooKeeper(t *testing.T, nodes map[string]string) *fakeZooKeeper {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := &fakeZooKeeper{ln: ln, nodes: nodes, watched: make(chan struct{}, 64)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go z.serve(c)
+		}
+	}()
+	return z
+}
+
+func (z *fakeZooKeeper) serve(c net.Conn) {
+	defer c.Close()
+	if _, err := zkReadFrame(c); err != nil {
+		return
+	}
+	resp := binary.BigEndian.AppendUint32(nil, 0)      // protocolVersion
+	resp = binary.BigEndian.AppendUint32(resp, 3000)   // timeOut
+	resp = binary.BigEndian.AppendUint64(resp, 0x1234) // sessionId
+	resp = zkAppendBytes(resp, make([]byte, 16))       // passwd
+	if err := zkWriteFrame(c, resp); err != nil {
+		return
+	}
+	authed := false
+	for {
+		frame, err := zkReadFrame(c)
+		if err != nil {
+			return
+		}
+		r := zkReader{b: frame}
+		xid, op := r.int32(), r.int32()
+		var body []byte
+		code := int32(0)
+		switch op {
+		case zkOpAuth:
+			r.int32()
+			scheme, cred := string(r.bytes()), string(r.bytes())
+			if scheme != "digest" || cred != z.auth {
+				code = int32(zkErrAuthFailed)
+			} else {
+				authed = true
+			}
+		case zkOpGetData, zkOpGetChildren:
+			path, watch := string(r.bytes()), r.next(1)[0] == 1
+			z.mutex.Lock()
+			data, ok := z.nodes[path]
+			children := z.childrenLocked(path)
+			if watch && ok {
+				z.watching = append(z.watching, c)
+			}
+			z.mutex.Unlock()
+			switch {
+			case z.auth != "" && !authed:
+				code = int32(zkErrNoAuth)
+			case !ok:
+				code = int32(zkErrNoNode)
+			case op == zkOpGetData:
+				body = zkAppendString(nil, data)
+			default:
+				body = binary.BigEndian.AppendUint32(nil, uint32(len(children)))
+				for _, child := range children {
+					body = zkAppendString(body, child)
+				}
+			}
+			if watch && ok {
+				z.watched <- struct{}{}
+			}
+		case zkOpCloseSession:
+			zkWriteFrame(c, zkReplyHeader(xid, 0))
+			return
+		}
+		if err := zkWriteFrame(c, append(zkReplyHeader(xid, code), body...)); err != nil {
+			return
+		}
+	}
+}
+
+// childrenLocked 返回path的直接子节点名称
+func (z *fakeZooKeeper) childrenLocked(path string) []string {
+	var children []string
+	for p := range z.nodes {
+		if rest, ok := strings.CutPrefix(p, strings.TrimSuffix(path, "/")+"/"); ok && !strings.Contains(rest, "/") {
+			children = append(children, rest)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+// update 修改节点数据并向注册过watch的连接发送NodeDataChanged事件
+func (z *fakeZooKeeper) update(path, data string) {
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
+	z.nodes[path] = data
+	event := binary.BigEndian.AppendUint32(zkReplyHeader(zkXidWatchEvent, 0), 3) // NodeDataChanged
+	event = binary.BigEndian.AppendUint32(event, 3)                              // SyncConnected
+	event = zkAppendString(event, path)
+	for _, c := range z.watching {
+		zkWriteFrame(c, event)
+	}
+	z.watching = nil
+}
+
+// zkReplyHeader 返回回复头：xid、zxid与错误码
+func zkReplyHeader(xid, code int32) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(xid))
+	b = binary.BigEndian.AppendUint64(b, 1)
+	return binary.BigEndian.AppendUint32(b, uint32(code))
+}
+
+func zkTestTree() map[string]string {
+	return map[string]string{
+		"/myapp":             "",
+		"/myapp/name":        "app",
+		"/myapp/server":      "",
+		"/myapp/server/port": "8080",
+		"/other":             "ignored",
+	}
+}
+
+func TestZooKeeperLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		auth     string
+		user     string
+		password string
+		want     map[string]string
+		wantErr  error
+	}{
+		{"no acl", "", "", "", map[string]string{"name": "app", "server.port": "8080"}, nil},
+		{"digest auth", "admin:s3cret", "admin", "s3cret", map[string]string{"name": "app", "server.port": "8080"}, nil},
+		{"wrong password", "admin:s3cret", "admin", "nope", nil, zkErrAuthFailed},
+		{"missing credentials", "admin:s3cret", "", "", nil, zkErrNoAuth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			z := newFakeZooKeeper(t, zkTestTree())
+			z.auth = tt.auth
+			p := NewZooKeeperProvider([]string{z.ln.Addr().String()}, "/myapp")
+			defer p.Close()
+			p.Username, p.Password = tt.user, tt.password
+			got, err := p.Load()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZooKeeperWatch(t *testing.T) {
+	z := newFakeZooKeeper(t, zkTestTree())
+	p := NewZooKeeperProvider([]string{z.ln.Addr().String()}, "/myapp")
+	p.RetryInterval = 10 * time.Millisecond
+	ch := make(chan Update, 4)
+	done := make(chan error, 1)
+	go func() { done <- p.Watch(ch) }()
+	defer func() {
+		p.Close()
+		<-done
+	}()
+
+	// 4个节点各注册数据与子节点watch之后再修改
+	for i := 0; i < 8; i++ {
+		select {
+		case <-z.watched:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for watches")
+		}
+	}
+	z.update("/myapp/server/port", "9090")
+	select {
+	case u := <-ch:
+		if u.Err != nil {
+			t.Fatal(u.Err)
+		}
+		if want := map[string]string{"name": "app", "server.port": "9090"}; !reflect.DeepEqual(u.Values, want) {
+			t.Errorf("update = %v, want %v", u.Values, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for update")
+	}
+}